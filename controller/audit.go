@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"time"
+	"wms/domain"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID is the middleware tagging every request with an ID, the client's X-Request-ID
+// when it sends one. It is echoed back and recorded on the audit entries of the request.
+func (c *Controller) RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+		ctx.Set(domain.RequestIDKey, requestID)
+		ctx.Header(requestIDHeader, requestID)
+		ctx.Next()
+	}
+}
+
+// GET API to page through the audit log of the caller's tenant
+func (c *Controller) GetAuditEntries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, ok := auditFilter(ctx)
+		if !ok {
+			return
+		}
+
+		entries, err := c.service.FetchAuditEntries(ctx, filter)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Audit entries fetched successfully", mapResponses(entries, newAuditEntryResponse))
+	}
+}
+
+// GET API to download the audit log of the caller's tenant as CSV or JSON lines
+func (c *Controller) ExportAuditEntries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, ok := auditFilter(ctx)
+		if !ok {
+			return
+		}
+		format := ctx.DefaultQuery("format", "csv")
+
+		data, err := c.service.ExportAuditEntries(ctx, filter, format)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		contentType := "text/csv"
+		if format == "jsonl" {
+			contentType = "application/x-ndjson"
+		}
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=audit-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format))
+		ctx.Data(http.StatusOK, contentType, data)
+	}
+}
+
+// GET API to check that no entry of the audit log was altered, removed or reordered
+func (c *Controller) VerifyAuditLog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		verification, err := c.service.VerifyAuditLog(ctx)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Audit log verified", verification)
+	}
+}
+
+// auditFilter reads the audit log filters of the query string, answering 400 when one is
+// malformed. from and to are RFC 3339 timestamps.
+func auditFilter(ctx *gin.Context) (domain.AuditFilter, bool) {
+	filter := domain.AuditFilter{
+		Actor:    ctx.Query("actor"),
+		Action:   ctx.Query("action"),
+		Entity:   ctx.Query("entity"),
+		EntityID: ctx.Query("entity_id"),
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := ctx.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("Invalid %s format, expected RFC 3339", name))
+				return domain.AuditFilter{}, false
+			}
+			*target = &t
+		}
+	}
+	if value := ctx.Query("after_seq"); value != "" {
+		seq, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seq < 0 {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid after_seq")
+			return domain.AuditFilter{}, false
+		}
+		filter.AfterSeq = seq
+	}
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid limit")
+			return domain.AuditFilter{}, false
+		}
+		filter.Limit = limit
+	}
+	return filter, true
+}
+
+// auditEntryResponse is an entry of the audit log with the hashes chaining it
+type auditEntryResponse struct {
+	Seq       int64           `json:"seq"`
+	TenantID  *uuid.UUID      `json:"tenant_id,omitempty"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Diff      json.RawMessage `json:"diff,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+func newAuditEntryResponse(entry domain.AuditEntry) auditEntryResponse {
+	return auditEntryResponse{
+		Seq:       entry.Seq,
+		TenantID:  entry.TenantID,
+		Actor:     entry.Actor,
+		Action:    entry.Action,
+		Entity:    entry.Entity,
+		EntityID:  entry.EntityID,
+		Before:    json.RawMessage(entry.Before),
+		After:     json.RawMessage(entry.After),
+		Diff:      json.RawMessage(entry.Diff),
+		RequestID: entry.RequestID,
+		CreatedAt: entry.CreatedAt,
+		PrevHash:  entry.PrevHash,
+		Hash:      entry.Hash,
+	}
+}
@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/omniful/go_commons/log"
+	"net/http"
+	"strings"
+	"time"
+	"wms/domain"
+	"wms/pkg/auth"
+)
+
+// Authenticate is the middleware guarding the API. The first authenticator finding
+// credentials of its kind on a request decides; the principal it resolves is placed on
+// the context for the service layer.
+func (c *Controller) Authenticate(authenticators ...auth.Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx, ctx.Request)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				// Why credentials were rejected is logged, not told to the caller
+				log.Infof("%s %s: rejected credentials: %v", ctx.Request.Method, ctx.FullPath(), err)
+				ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				standardErrorResponse(ctx, http.StatusUnauthorized, "Invalid credentials")
+				ctx.Abort()
+				return
+			}
+
+			principal, err = c.service.ResolveGrants(ctx, principal)
+			if err != nil {
+				errorResponse(ctx, err)
+				ctx.Abort()
+				return
+			}
+			ctx.Set(domain.PrincipalKey, principal)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("WWW-Authenticate", "Bearer")
+		standardErrorResponse(ctx, http.StatusUnauthorized, "Authentication required")
+		ctx.Abort()
+	}
+}
+
+// Require is the middleware giving a route the permission it needs, or the permissions
+// any of which will do. It lets through principals holding one at any hub; services check
+// the hub of each action and which permission it takes.
+func (c *Controller) Require(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := domain.PrincipalFrom(ctx)
+		for _, permission := range permissions {
+			if principal.CanAnywhere(permission) {
+				ctx.Next()
+				return
+			}
+		}
+		standardErrorResponse(ctx, http.StatusForbidden, "Missing permission "+strings.Join(permissions, " or "))
+		ctx.Abort()
+	}
+}
+
+// GET API returning the principal the request was authenticated as
+func (c *Controller) WhoAmI() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := domain.PrincipalFrom(ctx)
+		standardSuccessResponse(ctx, http.StatusOK, "Principal fetched successfully", principal)
+	}
+}
+
+// createAPIKeyRequest is the body of POST /tenant/:id/api-key
+type createAPIKeyRequest struct {
+	Name  string     `json:"name" binding:"required,max=100"`
+	Role  string     `json:"role" binding:"omitempty,role"`
+	HubID *uuid.UUID `json:"hub_id"`
+}
+
+// POST API to issue an API key for a tenant, holding the integration role unless another
+// one is given. The key is only ever shown in this response.
+func (c *Controller) CreateAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid tenant ID format")
+			return
+		}
+
+		var request createAPIKeyRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		key, err := c.service.CreateAPIKey(ctx, tenantID, request.Name, domain.Grant{Role: request.Role, HubID: request.HubID})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/api-key/"+key.ID.String(), "API key created successfully", newIssuedAPIKeyResponse(key))
+	}
+}
+
+func (c *Controller) GetAPIKeyByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keyID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid API key ID format")
+			return
+		}
+
+		key, err := c.service.FetchAPIKeyByID(ctx, keyID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "API key fetched successfully", newAPIKeyResponse(key))
+	}
+}
+
+func (c *Controller) GetAPIKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid tenant ID format")
+			return
+		}
+
+		keys, err := c.service.FetchAPIKeys(ctx, tenantID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "API keys fetched successfully", mapResponses(keys, newAPIKeyResponse))
+	}
+}
+
+// DELETE API to revoke an API key
+func (c *Controller) RevokeAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keyID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid API key ID format")
+			return
+		}
+
+		if err = c.service.RevokeAPIKey(ctx, keyID); err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "API key revoked successfully", nil)
+	}
+}
+
+// assignRoleRequest is the body of POST /tenant/:id/role
+type assignRoleRequest struct {
+	Subject string     `json:"subject" binding:"required,max=100"`
+	Role    string     `json:"role" binding:"required,role"`
+	HubID   *uuid.UUID `json:"hub_id"`
+}
+
+// POST API to give a subject a role in a tenant, at one hub when a hub ID is given
+func (c *Controller) AssignRole() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid tenant ID format")
+			return
+		}
+
+		var request assignRoleRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		assignment, err := c.service.AssignRole(ctx, domain.RoleAssignment{
+			TenantID: tenantID,
+			Subject:  request.Subject,
+			Role:     request.Role,
+			HubID:    request.HubID,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/role/"+assignment.ID.String(), "Role assigned successfully", newRoleAssignmentResponse(assignment))
+	}
+}
+
+func (c *Controller) GetRoleAssignmentByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		assignmentID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid role assignment ID format")
+			return
+		}
+
+		assignment, err := c.service.FetchRoleAssignmentByID(ctx, assignmentID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Role assignment fetched successfully", newRoleAssignmentResponse(assignment))
+	}
+}
+
+func (c *Controller) GetRoleAssignments() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid tenant ID format")
+			return
+		}
+
+		assignments, err := c.service.FetchRoleAssignments(ctx, tenantID, ctx.Query("subject"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Role assignments fetched successfully", mapResponses(assignments, newRoleAssignmentResponse))
+	}
+}
+
+// DELETE API to take a role away
+func (c *Controller) RemoveRoleAssignment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		assignmentID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid role assignment ID format")
+			return
+		}
+
+		if err = c.service.RemoveRoleAssignment(ctx, assignmentID); err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Role assignment removed successfully", nil)
+	}
+}
+
+// apiKeyResponse is an API key of a tenant, without the key itself
+type apiKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(key domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// issuedAPIKeyResponse is an API key just issued, the one response carrying the key
+type issuedAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+func newIssuedAPIKeyResponse(key domain.NewAPIKey) issuedAPIKeyResponse {
+	return issuedAPIKeyResponse{apiKeyResponse: newAPIKeyResponse(key.APIKey), Key: key.Key}
+}
+
+// roleAssignmentResponse is a role given to a subject in a tenant, at one hub or all of them
+type roleAssignmentResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  uuid.UUID  `json:"tenant_id"`
+	Subject   string     `json:"subject"`
+	Role      string     `json:"role"`
+	HubID     *uuid.UUID `json:"hub_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func newRoleAssignmentResponse(assignment domain.RoleAssignment) roleAssignmentResponse {
+	return roleAssignmentResponse{
+		ID:        assignment.ID,
+		TenantID:  assignment.TenantID,
+		Subject:   assignment.Subject,
+		Role:      assignment.Role,
+		HubID:     assignment.HubID,
+		CreatedAt: assignment.CreatedAt,
+	}
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createBarcodeRequest is the body of POST /barcode
+type createBarcodeRequest struct {
+	Code       string     `json:"code" binding:"required,max=50"`
+	Type       string     `json:"type" binding:"required"`
+	SkuID      *uuid.UUID `json:"sku_id"`
+	UOM        string     `json:"uom"`
+	LocationID *uuid.UUID `json:"location_id"`
+}
+
+// POST API to register a barcode for a SKU, one of its pack levels or a location
+func (c *Controller) CreateBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createBarcodeRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		barcode, err := c.service.CreateBarcode(ctx, domain.Barcode{
+			Code:       request.Code,
+			Type:       request.Type,
+			SkuID:      request.SkuID,
+			UOM:        request.UOM,
+			LocationID: request.LocationID,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/barcode/"+barcode.ID.String(), "Barcode created successfully", newBarcodeResponse(barcode))
+	}
+}
+
+func (c *Controller) GetBarcodeByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		barcodeID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid barcode ID format")
+			return
+		}
+		barcode, err := c.service.FetchBarcodeByID(ctx, barcodeID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Barcode fetched successfully", newBarcodeResponse(barcode))
+	}
+}
+
+func (c *Controller) GetSkuBarcodes() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		barcodes, err := c.service.FetchBarcodes(ctx, &skuID, nil)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Barcodes fetched successfully", mapResponses(barcodes, newBarcodeResponse))
+	}
+}
+
+func (c *Controller) GetLocationBarcodes() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid location ID format")
+			return
+		}
+
+		barcodes, err := c.service.FetchBarcodes(ctx, nil, &locationID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Barcodes fetched successfully", mapResponses(barcodes, newBarcodeResponse))
+	}
+}
+
+func (c *Controller) DeleteBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		barcodeID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid barcode ID format")
+			return
+		}
+
+		if err = c.service.DeleteBarcode(ctx, barcodeID); err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Barcode deleted successfully", nil)
+	}
+}
+
+// GET API to resolve a scanned value to a SKU, pack level, serial, lot or location,
+// optionally within one hub
+func (c *Controller) ResolveScan() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := parseOptionalUUID(ctx.Query("hub_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		result, err := c.service.ResolveScan(ctx, ctx.Param("code"), hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Scan resolved successfully", newScanResultResponse(result))
+	}
+}
+
+// barcodeResponse is a barcode of a SKU, one of its pack levels or a location
+type barcodeResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Code       string     `json:"code"`
+	Type       string     `json:"type"`
+	SkuID      *uuid.UUID `json:"sku_id,omitempty"`
+	UOM        string     `json:"uom,omitempty"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newBarcodeResponse(barcode domain.Barcode) barcodeResponse {
+	return barcodeResponse{
+		ID:         barcode.ID,
+		Code:       barcode.Code,
+		Type:       barcode.Type,
+		SkuID:      barcode.SkuID,
+		UOM:        barcode.UOM,
+		LocationID: barcode.LocationID,
+		CreatedAt:  barcode.CreatedAt,
+	}
+}
+
+// scanResultResponse is what a scanned value resolved to
+type scanResultResponse struct {
+	Code     string            `json:"code"`
+	Kind     string            `json:"kind"`
+	Barcode  *barcodeResponse  `json:"barcode,omitempty"`
+	Sku      *skuResponse      `json:"sku,omitempty"`
+	UOM      string            `json:"uom,omitempty"`
+	Factor   int               `json:"factor,omitempty"`
+	Location *locationResponse `json:"location,omitempty"`
+	Serials  []serialResponse  `json:"serials,omitempty"`
+	Lots     []lotResponse     `json:"lots,omitempty"`
+}
+
+func newScanResultResponse(result domain.ScanResult) scanResultResponse {
+	response := scanResultResponse{
+		Code:     result.Code,
+		Kind:     result.Kind,
+		Sku:      skuRef(result.Sku),
+		UOM:      result.UOM,
+		Factor:   result.Factor,
+		Location: locationRef(result.Location),
+	}
+	if result.Barcode != nil {
+		barcode := newBarcodeResponse(*result.Barcode)
+		response.Barcode = &barcode
+	}
+	if len(result.Serials) > 0 {
+		response.Serials = mapResponses(result.Serials, newSerialResponse)
+	}
+	if len(result.Lots) > 0 {
+		response.Lots = mapResponses(result.Lots, newLotResponse)
+	}
+	return response
+}
@@ -1,31 +1,101 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/omniful/go_commons/log"
 	"net/http"
 	"wms/domain"
+	"wms/pkg"
 	"wms/service"
 )
 
+// BasePath is the prefix of every route of the API
+const BasePath = "/api/v1"
+
 type Controller struct {
 	service service.Service
 }
 
 func NewController(s service.Service) *Controller {
+	registerValidations()
 	return &Controller{
 		service: s,
 	}
 }
 
+// statusCodes are the HTTP statuses of the kinds of errors
+var statusCodes = map[string]int{
+	domain.CodeBadRequest:        http.StatusBadRequest,
+	domain.CodeUnauthenticated:   http.StatusUnauthorized,
+	domain.CodeNotFound:          http.StatusNotFound,
+	domain.CodeConflict:          http.StatusConflict,
+	domain.CodeInsufficientStock: http.StatusConflict,
+	domain.CodeValidation:        http.StatusUnprocessableEntity,
+	domain.CodeForbidden:         http.StatusForbidden,
+	domain.CodeUnavailable:       http.StatusServiceUnavailable,
+	domain.CodeInternal:          http.StatusInternalServerError,
+}
+
+// requestErrorCodes are the codes of the errors the controller answers itself
+var requestErrorCodes = map[int]string{
+	http.StatusBadRequest:          domain.CodeBadRequest,
+	http.StatusUnauthorized:        domain.CodeUnauthenticated,
+	http.StatusForbidden:           domain.CodeForbidden,
+	http.StatusNotFound:            domain.CodeNotFound,
+	http.StatusInternalServerError: domain.CodeInternal,
+}
+
 // Standardized error response
 func standardErrorResponse(ctx *gin.Context, statusCode int, message string) {
 	ctx.JSON(statusCode, gin.H{
 		"status":  "error",
+		"code":    requestErrorCodes[statusCode],
 		"message": message,
 	})
 }
 
+// errorResponse responds to an error of the service layer with the status of its kind.
+// Internal errors are logged and answered without their detail.
+func errorResponse(ctx *gin.Context, err error) {
+	e := pkg.ClassifyError(err)
+	if e.Code == domain.CodeInternal {
+		log.Errorf("%s %s: %v", ctx.Request.Method, ctx.FullPath(), err)
+		e.Message = "Internal server error"
+	}
+
+	body := gin.H{
+		"status":  "error",
+		"code":    e.Code,
+		"message": e.Message,
+	}
+	if len(e.Fields) > 0 {
+		body["errors"] = e.Fields
+	}
+	ctx.JSON(statusCodes[e.Code], body)
+}
+
+// bindError is the error of a request body that does not decode into its request type or
+// breaks the rules of its binding tags, listing every field in violation
+func bindError(err error) error {
+	var violations validator.ValidationErrors
+	if errors.As(err, &violations) {
+		return domain.InvalidFields(fieldErrors(violations)...)
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return domain.InvalidFields(domain.FieldError{
+			Field:   typeError.Field,
+			Message: fmt.Sprintf("expected %s, got %s", typeError.Type, typeError.Value),
+		})
+	}
+	return &domain.Error{Code: domain.CodeBadRequest, Message: "Invalid request body"}
+}
+
 // Standardized success response
 func standardSuccessResponse(ctx *gin.Context, statusCode int, message string, data interface{}) {
 	ctx.JSON(statusCode, gin.H{
@@ -35,14 +105,21 @@ func standardSuccessResponse(ctx *gin.Context, statusCode int, message string, d
 	})
 }
 
+// createdResponse answers 201 with the resource just created, pointing the Location header
+// at the route fetching it, given relative to BasePath
+func createdResponse(ctx *gin.Context, path, message string, data interface{}) {
+	ctx.Header("Location", BasePath+path)
+	standardSuccessResponse(ctx, http.StatusCreated, message, data)
+}
+
 func (c *Controller) GetHubs() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		hubs, err := c.service.FetchHubs(ctx)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch hubs")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusOK, "Hubs fetched successfully", hubs)
+		standardSuccessResponse(ctx, http.StatusOK, "Hubs fetched successfully", newHubResponses(hubs))
 	}
 }
 
@@ -50,10 +127,10 @@ func (c *Controller) GetSkus() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		skus, err := c.service.FetchSkus(ctx)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch SKUs")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusOK, "SKUs fetched successfully", skus)
+		standardSuccessResponse(ctx, http.StatusOK, "SKUs fetched successfully", newSkuResponses(skus))
 	}
 }
 
@@ -66,10 +143,10 @@ func (c *Controller) GetHubByID() gin.HandlerFunc {
 		}
 		hub, err := c.service.FetchHubByID(ctx, hubID)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusNotFound, "Hub not found")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusOK, "Hub fetched successfully", hub)
+		standardSuccessResponse(ctx, http.StatusOK, "Hub fetched successfully", newHubResponse(hub))
 	}
 }
 
@@ -82,10 +159,10 @@ func (c *Controller) GetSkuByID() gin.HandlerFunc {
 		}
 		sku, err := c.service.FetchSkuByID(ctx, skuID)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusNotFound, "SKU not found")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusOK, "SKU fetched successfully", sku)
+		standardSuccessResponse(ctx, http.StatusOK, "SKU fetched successfully", newSkuResponse(sku))
 	}
 }
 
@@ -108,70 +185,89 @@ func (c *Controller) GetInventory() gin.HandlerFunc {
 			return
 		}
 
+		if uom := ctx.Query("uom"); uom != "" {
+			inventory, err := c.service.FetchInventoryInUOM(ctx, skuID, hubID, uom)
+			if err != nil {
+				errorResponse(ctx, err)
+				return
+			}
+			standardSuccessResponse(ctx, http.StatusOK, "Inventory fetched successfully", newInventoryInUOMResponse(inventory))
+			return
+		}
+
 		// Fetch inventory from the service layer
 		inventory, err := c.service.FetchInventory(ctx, skuID, hubID)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusNotFound, "Inventory not found")
+			errorResponse(ctx, err)
 			return
 		}
 
-		standardSuccessResponse(ctx, http.StatusOK, "Inventory fetched successfully", inventory)
+		standardSuccessResponse(ctx, http.StatusOK, "Inventory fetched successfully", newInventoryResponse(inventory))
 	}
 }
 
 // POST API to create Hub
 func (c *Controller) CreateHub() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var hub domain.Hub
-		if err := ctx.ShouldBindJSON(&hub); err != nil {
-			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+		var request createHubRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
 			return
 		}
 
-		err := c.service.CreateHub(ctx, hub)
+		hub, err := c.service.CreateHub(ctx, request.toHub())
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusInternalServerError, "Failed to create hub")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusCreated, "Hub created successfully", nil)
+		createdResponse(ctx, "/hub/"+hub.ID.String(), "Hub created successfully", newHubResponse(hub))
 	}
 }
 
 // POST API to create SKU
 func (c *Controller) CreateSKU() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var sku domain.SKU
-		if err := ctx.ShouldBindJSON(&sku); err != nil {
-			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+		var request createSkuRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
 			return
 		}
 
-		err := c.service.CreateSKU(ctx, sku)
+		sku, err := c.service.CreateSKU(ctx, request.toSku())
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusInternalServerError, "Failed to create SKU")
+			errorResponse(ctx, err)
 			return
 		}
-		standardSuccessResponse(ctx, http.StatusCreated, "SKU created successfully", nil)
+		createdResponse(ctx, "/sku/"+sku.ID.String(), "SKU created successfully", newSkuResponse(sku))
 	}
 }
 
+// decreaseInventoryRequest is the body of POST /inventory
+type decreaseInventoryRequest struct {
+	SkuID   uuid.UUID `json:"sku_id" binding:"required"`
+	HubID   uuid.UUID `json:"hub_id" binding:"required"`
+	Qty     int       `json:"available_qty" binding:"gte=0"`
+	UOM     string    `json:"uom"`
+	Serials []string  `json:"serials"`
+}
+
 // Update inventory quantities (decrease available, increase allocated/damaged)
 func (c *Controller) DecreaseInventory() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var request struct {
-			SkuID uuid.UUID `json:"sku_id"`
-			HubID uuid.UUID `json:"hub_id"`
-			Qty   int       `json:"available_qty"`
-		}
+		var request decreaseInventoryRequest
 
 		if err := ctx.ShouldBindJSON(&request); err != nil {
-			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		qty, ok := c.baseQty(ctx, request.SkuID, request.UOM, request.Qty)
+		if !ok {
 			return
 		}
 
-		err := c.service.DecreaseInventoryQty(ctx, request.SkuID, request.HubID, request.Qty)
+		err := c.service.DecreaseInventoryQty(ctx, request.SkuID, request.HubID, qty, request.Serials)
 		if err != nil {
-			standardErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+			errorResponse(ctx, err)
 			return
 		}
 
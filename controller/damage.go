@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createStockTransitionRequest is the body of POST /inventory/transition
+type createStockTransitionRequest struct {
+	SkuID      uuid.UUID `json:"sku_id" binding:"required"`
+	HubID      uuid.UUID `json:"hub_id" binding:"required"`
+	Type       string    `json:"type" binding:"required,transition_type"`
+	Qty        int       `json:"qty" binding:"gt=0"`
+	UOM        string    `json:"uom"`
+	ReasonCode string    `json:"reason_code" binding:"required"`
+	DocumentNo string    `json:"document_no" binding:"max=50"`
+	UnitCost   float64   `json:"unit_cost" binding:"gte=0"`
+	Serials    []string  `json:"serials"`
+	Note       string    `json:"note" binding:"max=500"`
+}
+
+// POST API to move stock into quarantine, between quarantine, damaged and available,
+// or out of the hub as a write-off or return to vendor
+func (c *Controller) CreateStockTransition() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createStockTransitionRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		qty, ok := c.baseQty(ctx, request.SkuID, request.UOM, request.Qty)
+		if !ok {
+			return
+		}
+
+		transition, err := c.service.CreateStockTransition(ctx, domain.StockTransition{
+			SkuID:      request.SkuID,
+			HubID:      request.HubID,
+			Type:       request.Type,
+			Qty:        qty,
+			ReasonCode: request.ReasonCode,
+			DocumentNo: request.DocumentNo,
+			UnitCost:   request.UnitCost,
+			Serials:    request.Serials,
+			Note:       request.Note,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/inventory/transition/"+transition.ID.String(), "Stock transition recorded successfully", newStockTransitionResponse(transition))
+	}
+}
+
+func (c *Controller) GetStockTransitionByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		transitionID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid stock transition ID format")
+			return
+		}
+		transition, err := c.service.FetchStockTransitionByID(ctx, transitionID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Stock transition fetched successfully", newStockTransitionResponse(transition))
+	}
+}
+
+// GET API to list the stock transitions of a hub, optionally by type and SKU
+func (c *Controller) GetStockTransitions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		skuID, err := parseOptionalUUID(ctx.Query("sku_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		transitions, err := c.service.FetchStockTransitions(ctx, hubID, ctx.Query("type"), skuID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Stock transitions fetched successfully", mapResponses(transitions, newStockTransitionResponse))
+	}
+}
+
+// stockTransitionResponse is a move of units into, between or out of the non-sellable buckets
+type stockTransitionResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	HubID      uuid.UUID  `json:"hub_id"`
+	SkuID      uuid.UUID  `json:"sku_id"`
+	Type       string     `json:"type"`
+	Qty        int        `json:"qty"`
+	ReasonCode string     `json:"reason_code"`
+	DocumentNo string     `json:"document_no"`
+	UnitCost   float64    `json:"unit_cost"`
+	TotalCost  float64    `json:"total_cost"`
+	SellerID   *uuid.UUID `json:"seller_id,omitempty"`
+	Serials    []string   `json:"serials,omitempty"`
+	Note       string     `json:"note"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newStockTransitionResponse(transition domain.StockTransition) stockTransitionResponse {
+	return stockTransitionResponse{
+		ID:         transition.ID,
+		HubID:      transition.HubID,
+		SkuID:      transition.SkuID,
+		Type:       transition.Type,
+		Qty:        transition.Qty,
+		ReasonCode: transition.ReasonCode,
+		DocumentNo: transition.DocumentNo,
+		UnitCost:   transition.UnitCost,
+		TotalCost:  transition.TotalCost,
+		SellerID:   transition.SellerID,
+		Serials:    transition.Serials,
+		Note:       transition.Note,
+		CreatedAt:  transition.CreatedAt,
+	}
+}
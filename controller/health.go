@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"wms/domain"
+	"wms/pkg/health"
+)
+
+// GET API for the liveness probe: the process answers, whatever its dependencies
+func (c *Controller) Live() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		standardSuccessResponse(ctx, http.StatusOK, "Service is alive", nil)
+	}
+}
+
+// GET API for the readiness probe, reporting the status of each dependency. It answers 503
+// when a check fails, so orchestrators stop routing traffic to the instance.
+func (c *Controller) Ready(checkers map[string]health.Checker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := health.Run(ctx.Request.Context(), checkers)
+		if !report.Ready() {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":  "error",
+				"code":    domain.CodeUnavailable,
+				"message": "Service is not ready",
+				"data":    report,
+			})
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Service is ready", report)
+	}
+}
@@ -0,0 +1,423 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createPurchaseOrderRequest is the body of POST /purchase-order
+type createPurchaseOrderRequest struct {
+	SellerID   uuid.UUID `json:"seller_id" binding:"required"`
+	HubID      uuid.UUID `json:"hub_id" binding:"required"`
+	PoNo       string    `json:"po_no" binding:"required,max=50"`
+	ExpectedAt string    `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	Lines      []struct {
+		SkuID      uuid.UUID `json:"sku_id" binding:"required"`
+		OrderedQty int       `json:"ordered_qty" binding:"gt=0"`
+		UOM        string    `json:"uom"`
+	} `json:"lines" binding:"required,min=1,dive"`
+}
+
+// POST API to record a purchase order of a seller for a hub
+func (c *Controller) CreatePurchaseOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createPurchaseOrderRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		expectedAt, err := parseDate(request.ExpectedAt)
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid expected_at format, expected YYYY-MM-DD")
+			return
+		}
+
+		po := domain.PurchaseOrder{
+			SellerID:   request.SellerID,
+			HubID:      request.HubID,
+			PoNo:       request.PoNo,
+			ExpectedAt: expectedAt,
+		}
+		for _, line := range request.Lines {
+			qty, ok := c.baseQty(ctx, line.SkuID, line.UOM, line.OrderedQty)
+			if !ok {
+				return
+			}
+			po.Lines = append(po.Lines, domain.PurchaseOrderLine{SkuID: line.SkuID, OrderedQty: qty})
+		}
+
+		po, err = c.service.CreatePurchaseOrder(ctx, po)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/purchase-order/"+po.ID.String(), "Purchase order created successfully", newPurchaseOrderResponse(po))
+	}
+}
+
+func (c *Controller) GetPurchaseOrderByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		poID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid purchase order ID format")
+			return
+		}
+		po, err := c.service.FetchPurchaseOrderByID(ctx, poID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Purchase order fetched successfully", newPurchaseOrderResponse(po))
+	}
+}
+
+// GET API to list the purchase orders of a hub, optionally by seller and status
+func (c *Controller) GetPurchaseOrders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		sellerID, err := parseOptionalUUID(ctx.Query("seller_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid seller ID format")
+			return
+		}
+
+		pos, err := c.service.FetchPurchaseOrders(ctx, hubID, sellerID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Purchase orders fetched successfully", mapResponses(pos, newPurchaseOrderResponse))
+	}
+}
+
+// createASNRequest is the body of POST /asn
+type createASNRequest struct {
+	SellerID         uuid.UUID  `json:"seller_id" binding:"required"`
+	HubID            uuid.UUID  `json:"hub_id" binding:"required"`
+	PurchaseOrderID  *uuid.UUID `json:"purchase_order_id"`
+	AsnNo            string     `json:"asn_no" binding:"required,max=50"`
+	ExpectedAt       string     `json:"expected_at" binding:"omitempty,datetime=2006-01-02"`
+	OverTolerancePct int        `json:"over_tolerance_pct" binding:"gte=0,lte=100"`
+	Lines            []struct {
+		SkuID       uuid.UUID `json:"sku_id" binding:"required"`
+		ExpectedQty int       `json:"expected_qty" binding:"gt=0"`
+		UOM         string    `json:"uom"`
+	} `json:"lines" binding:"required,min=1,dive"`
+}
+
+// POST API to record an advance shipping notice, optionally against a purchase order
+func (c *Controller) CreateASN() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createASNRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		expectedAt, err := parseDate(request.ExpectedAt)
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid expected_at format, expected YYYY-MM-DD")
+			return
+		}
+
+		asn := domain.ASN{
+			SellerID:         request.SellerID,
+			HubID:            request.HubID,
+			PurchaseOrderID:  request.PurchaseOrderID,
+			AsnNo:            request.AsnNo,
+			ExpectedAt:       expectedAt,
+			OverTolerancePct: request.OverTolerancePct,
+		}
+		for _, line := range request.Lines {
+			qty, ok := c.baseQty(ctx, line.SkuID, line.UOM, line.ExpectedQty)
+			if !ok {
+				return
+			}
+			asn.Lines = append(asn.Lines, domain.AsnLine{SkuID: line.SkuID, ExpectedQty: qty})
+		}
+
+		asn, err = c.service.CreateASN(ctx, asn)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/asn/"+asn.ID.String(), "ASN created successfully", newAsnResponse(asn))
+	}
+}
+
+func (c *Controller) GetASNByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		asnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid ASN ID format")
+			return
+		}
+		asn, err := c.service.FetchASNByID(ctx, asnID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "ASN fetched successfully", newAsnResponse(asn))
+	}
+}
+
+// GET API to list the ASNs of a hub, optionally by seller and status
+func (c *Controller) GetASNs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		sellerID, err := parseOptionalUUID(ctx.Query("seller_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid seller ID format")
+			return
+		}
+
+		asns, err := c.service.FetchASNs(ctx, hubID, sellerID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "ASNs fetched successfully", mapResponses(asns, newAsnResponse))
+	}
+}
+
+// receiveASNRequest is the body of POST /asn/:id/receive
+type receiveASNRequest struct {
+	Lots []struct {
+		SkuID      uuid.UUID  `json:"sku_id" binding:"required"`
+		LotNumber  string     `json:"lot_number"`
+		MfgDate    string     `json:"mfg_date" binding:"omitempty,datetime=2006-01-02"`
+		ExpiryDate string     `json:"expiry_date" binding:"omitempty,datetime=2006-01-02"`
+		Qty        int        `json:"qty" binding:"gt=0"`
+		UOM        string     `json:"uom"`
+		Serials    []string   `json:"serials"`
+		LocationID *uuid.UUID `json:"location_id"`
+	} `json:"lots" binding:"required,min=1,dive"`
+}
+
+// POST API to receive lots against an ASN
+func (c *Controller) ReceiveASN() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		asnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid ASN ID format")
+			return
+		}
+
+		var request receiveASNRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		receipts := make([]domain.AsnReceipt, 0, len(request.Lots))
+		for _, lot := range request.Lots {
+			mfgDate, err := parseDate(lot.MfgDate)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid mfg_date format, expected YYYY-MM-DD")
+				return
+			}
+			expiryDate, err := parseDate(lot.ExpiryDate)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid expiry_date format, expected YYYY-MM-DD")
+				return
+			}
+			qty, ok := c.baseQty(ctx, lot.SkuID, lot.UOM, lot.Qty)
+			if !ok {
+				return
+			}
+			receipts = append(receipts, domain.AsnReceipt{
+				SkuID: lot.SkuID,
+				LotReceipt: domain.LotReceipt{
+					LotNumber:  lot.LotNumber,
+					MfgDate:    mfgDate,
+					ExpiryDate: expiryDate,
+					Qty:        qty,
+					Serials:    lot.Serials,
+					LocationID: lot.LocationID,
+				},
+			})
+		}
+
+		asn, err := c.service.ReceiveASN(ctx, asnID, receipts)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "ASN received successfully", newAsnResponse(asn))
+	}
+}
+
+// POST API to close an ASN, leaving whatever didn't arrive short
+func (c *Controller) CloseASN() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		asnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid ASN ID format")
+			return
+		}
+		asn, err := c.service.CloseASN(ctx, asnID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "ASN closed successfully", newAsnResponse(asn))
+	}
+}
+
+// GET API to report expected against received units of closed ASNs per seller and SKU,
+// optionally for a seller, a hub and a range of YYYY-MM-DD closing dates (to is inclusive)
+func (c *Controller) GetAsnVariance() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sellerID, err := parseOptionalUUID(ctx.Query("seller_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid seller ID format")
+			return
+		}
+		hubID, err := parseOptionalUUID(ctx.Query("hub_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		from, err := parseDate(ctx.Query("from"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid from format, expected YYYY-MM-DD")
+			return
+		}
+		to, err := parseDate(ctx.Query("to"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid to format, expected YYYY-MM-DD")
+			return
+		}
+		if to != nil {
+			end := to.AddDate(0, 0, 1)
+			to = &end
+		}
+
+		variance, err := c.service.FetchAsnVariance(ctx, sellerID, hubID, from, to)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "ASN variance fetched successfully", variance)
+	}
+}
+
+// purchaseOrderResponse is a purchase order of a seller with its lines
+type purchaseOrderResponse struct {
+	ID         uuid.UUID                   `json:"id"`
+	SellerID   uuid.UUID                   `json:"seller_id"`
+	HubID      uuid.UUID                   `json:"hub_id"`
+	PoNo       string                      `json:"po_no"`
+	Status     string                      `json:"status"`
+	ExpectedAt *time.Time                  `json:"expected_at,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	UpdatedAt  time.Time                   `json:"updated_at"`
+	Lines      []purchaseOrderLineResponse `json:"lines"`
+}
+
+func newPurchaseOrderResponse(po domain.PurchaseOrder) purchaseOrderResponse {
+	return purchaseOrderResponse{
+		ID:         po.ID,
+		SellerID:   po.SellerID,
+		HubID:      po.HubID,
+		PoNo:       po.PoNo,
+		Status:     po.Status,
+		ExpectedAt: po.ExpectedAt,
+		CreatedAt:  po.CreatedAt,
+		UpdatedAt:  po.UpdatedAt,
+		Lines:      mapResponses(po.Lines, newPurchaseOrderLineResponse),
+	}
+}
+
+// purchaseOrderLineResponse is a SKU ordered on a purchase order
+type purchaseOrderLineResponse struct {
+	ID              uuid.UUID `json:"id"`
+	PurchaseOrderID uuid.UUID `json:"purchase_order_id"`
+	SkuID           uuid.UUID `json:"sku_id"`
+	OrderedQty      int       `json:"ordered_qty"`
+	ReceivedQty     int       `json:"received_qty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func newPurchaseOrderLineResponse(line domain.PurchaseOrderLine) purchaseOrderLineResponse {
+	return purchaseOrderLineResponse{
+		ID:              line.ID,
+		PurchaseOrderID: line.PurchaseOrderID,
+		SkuID:           line.SkuID,
+		OrderedQty:      line.OrderedQty,
+		ReceivedQty:     line.ReceivedQty,
+		CreatedAt:       line.CreatedAt,
+		UpdatedAt:       line.UpdatedAt,
+	}
+}
+
+// asnResponse is an advance shipping notice with its lines
+type asnResponse struct {
+	ID               uuid.UUID         `json:"id"`
+	SellerID         uuid.UUID         `json:"seller_id"`
+	HubID            uuid.UUID         `json:"hub_id"`
+	PurchaseOrderID  *uuid.UUID        `json:"purchase_order_id,omitempty"`
+	AsnNo            string            `json:"asn_no"`
+	Status           string            `json:"status"`
+	ExpectedAt       *time.Time        `json:"expected_at,omitempty"`
+	OverTolerancePct int               `json:"over_tolerance_pct"`
+	ClosedAt         *time.Time        `json:"closed_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	Lines            []asnLineResponse `json:"lines"`
+}
+
+func newAsnResponse(asn domain.ASN) asnResponse {
+	return asnResponse{
+		ID:               asn.ID,
+		SellerID:         asn.SellerID,
+		HubID:            asn.HubID,
+		PurchaseOrderID:  asn.PurchaseOrderID,
+		AsnNo:            asn.AsnNo,
+		Status:           asn.Status,
+		ExpectedAt:       asn.ExpectedAt,
+		OverTolerancePct: asn.OverTolerancePct,
+		ClosedAt:         asn.ClosedAt,
+		CreatedAt:        asn.CreatedAt,
+		UpdatedAt:        asn.UpdatedAt,
+		Lines:            mapResponses(asn.Lines, newAsnLineResponse),
+	}
+}
+
+// asnLineResponse is a SKU expected on an ASN with what was received of it
+type asnLineResponse struct {
+	ID             uuid.UUID `json:"id"`
+	AsnID          uuid.UUID `json:"asn_id"`
+	SkuID          uuid.UUID `json:"sku_id"`
+	ExpectedQty    int       `json:"expected_qty"`
+	ReceivedQty    int       `json:"received_qty"`
+	QuarantinedQty int       `json:"quarantined_qty"`
+	Unexpected     bool      `json:"unexpected"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func newAsnLineResponse(line domain.AsnLine) asnLineResponse {
+	return asnLineResponse{
+		ID:             line.ID,
+		AsnID:          line.AsnID,
+		SkuID:          line.SkuID,
+		ExpectedQty:    line.ExpectedQty,
+		ReceivedQty:    line.ReceivedQty,
+		QuarantinedQty: line.QuarantinedQty,
+		Unexpected:     line.Unexpected,
+		CreatedAt:      line.CreatedAt,
+		UpdatedAt:      line.UpdatedAt,
+	}
+}
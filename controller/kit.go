@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// setKitComponentsRequest is the body of PUT /sku/:id/kit
+type setKitComponentsRequest struct {
+	Components []struct {
+		SkuID uuid.UUID `json:"sku_id" binding:"required"`
+		Qty   int       `json:"qty" binding:"gt=0"`
+		UOM   string    `json:"uom"`
+	} `json:"components" binding:"dive"`
+}
+
+// PUT API to replace the bill of materials of a kit SKU. An empty list makes it a plain SKU again.
+func (c *Controller) SetKitComponents() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		var request setKitComponentsRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		components := make([]domain.KitComponent, 0, len(request.Components))
+		for _, component := range request.Components {
+			qty, ok := c.baseQty(ctx, component.SkuID, component.UOM, component.Qty)
+			if !ok {
+				return
+			}
+			components = append(components, domain.KitComponent{ComponentSkuID: component.SkuID, Qty: qty})
+		}
+
+		components, err = c.service.SetKitComponents(ctx, skuID, components)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kit components saved successfully", mapResponses(components, newKitComponentResponse))
+	}
+}
+
+func (c *Controller) GetKitComponents() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		components, err := c.service.FetchKitComponents(ctx, skuID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kit components fetched successfully", mapResponses(components, newKitComponentResponse))
+	}
+}
+
+// GET API to compute how many kits each hub, or one hub, can sell from assembled and component stock
+func (c *Controller) GetKitAvailability() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+		hubID, err := parseOptionalUUID(ctx.Query("hub_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		availability, err := c.service.FetchKitAvailability(ctx, skuID, hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kit availability fetched successfully", availability)
+	}
+}
+
+// createKittingOrderRequest is the body of POST /kitting
+type createKittingOrderRequest struct {
+	HubID      uuid.UUID  `json:"hub_id" binding:"required"`
+	KitSkuID   uuid.UUID  `json:"kit_sku_id" binding:"required"`
+	Qty        int        `json:"qty" binding:"gt=0"`
+	UOM        string     `json:"uom"`
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate string     `json:"expiry_date" binding:"omitempty,datetime=2006-01-02"`
+	LocationID *uuid.UUID `json:"location_id"`
+}
+
+// POST API to open a kitting order, reserving the components of the kits to assemble
+func (c *Controller) CreateKittingOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createKittingOrderRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		expiryDate, err := parseDate(request.ExpiryDate)
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid expiry_date format, expected YYYY-MM-DD")
+			return
+		}
+		qty, ok := c.baseQty(ctx, request.KitSkuID, request.UOM, request.Qty)
+		if !ok {
+			return
+		}
+
+		order, err := c.service.CreateKittingOrder(ctx, domain.KittingOrder{
+			HubID:      request.HubID,
+			KitSkuID:   request.KitSkuID,
+			Qty:        qty,
+			LotNumber:  request.LotNumber,
+			ExpiryDate: expiryDate,
+			LocationID: request.LocationID,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/kitting/"+order.ID.String(), "Kitting order created successfully", newKittingOrderResponse(order))
+	}
+}
+
+func (c *Controller) GetKittingOrderByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid kitting order ID format")
+			return
+		}
+		order, err := c.service.FetchKittingOrderByID(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kitting order fetched successfully", newKittingOrderResponse(order))
+	}
+}
+
+// GET API to list the kitting orders of a hub, optionally by status
+func (c *Controller) GetKittingOrders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		orders, err := c.service.FetchKittingOrders(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kitting orders fetched successfully", mapResponses(orders, newKittingOrderResponse))
+	}
+}
+
+// POST API to complete a kitting order, consuming its components and receiving the kits
+func (c *Controller) CompleteKittingOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid kitting order ID format")
+			return
+		}
+
+		order, err := c.service.CompleteKittingOrder(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kitting order completed successfully", newKittingOrderResponse(order))
+	}
+}
+
+// POST API to cancel a kitting order, releasing its components
+func (c *Controller) CancelKittingOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid kitting order ID format")
+			return
+		}
+
+		order, err := c.service.CancelKittingOrder(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Kitting order cancelled successfully", newKittingOrderResponse(order))
+	}
+}
+
+// kitComponentResponse is a component SKU of a kit and the quantity one kit takes
+type kitComponentResponse struct {
+	ID             uuid.UUID `json:"id"`
+	KitSkuID       uuid.UUID `json:"kit_sku_id"`
+	ComponentSkuID uuid.UUID `json:"component_sku_id"`
+	Qty            int       `json:"qty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newKitComponentResponse(component domain.KitComponent) kitComponentResponse {
+	return kitComponentResponse{
+		ID:             component.ID,
+		KitSkuID:       component.KitSkuID,
+		ComponentSkuID: component.ComponentSkuID,
+		Qty:            component.Qty,
+		CreatedAt:      component.CreatedAt,
+	}
+}
+
+// kittingOrderResponse is an order to assemble kits at a hub
+type kittingOrderResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	HubID       uuid.UUID  `json:"hub_id"`
+	KitSkuID    uuid.UUID  `json:"kit_sku_id"`
+	Qty         int        `json:"qty"`
+	Status      string     `json:"status"`
+	LotNumber   string     `json:"lot_number"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	LocationID  *uuid.UUID `json:"location_id,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func newKittingOrderResponse(order domain.KittingOrder) kittingOrderResponse {
+	return kittingOrderResponse{
+		ID:          order.ID,
+		HubID:       order.HubID,
+		KitSkuID:    order.KitSkuID,
+		Qty:         order.Qty,
+		Status:      order.Status,
+		LotNumber:   order.LotNumber,
+		ExpiryDate:  order.ExpiryDate,
+		LocationID:  order.LocationID,
+		CompletedAt: order.CompletedAt,
+		CreatedAt:   order.CreatedAt,
+		UpdatedAt:   order.UpdatedAt,
+	}
+}
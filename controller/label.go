@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+// setGS1CompanyPrefixRequest is the body of PUT /tenant/:id/gs1
+type setGS1CompanyPrefixRequest struct {
+	CompanyPrefix string `json:"company_prefix" binding:"required,gs1_prefix"`
+}
+
+// PUT API to set the GS1 company prefix the SSCCs of a tenant are numbered under
+func (c *Controller) SetGS1CompanyPrefix() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tenantID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid tenant ID format")
+			return
+		}
+
+		var request setGS1CompanyPrefixRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		tenant, err := c.service.SetGS1CompanyPrefix(ctx, tenantID, request.CompanyPrefix)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "GS1 company prefix updated successfully", newTenantResponse(tenant))
+	}
+}
+
+// GET API to print the label of a SKU as ZPL or PDF
+func (c *Controller) GetSkuLabel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.printLabel(ctx, "SKU", c.service.SkuLabel)
+	}
+}
+
+// GET API to print the label of a location as ZPL or PDF
+func (c *Controller) GetLocationLabel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.printLabel(ctx, "location", c.service.LocationLabel)
+	}
+}
+
+// GET API to print the shipping label of a package as ZPL or PDF, with its SSCC
+func (c *Controller) GetPackageLabel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c.printLabel(ctx, "package", c.service.PackageLabel)
+	}
+}
+
+// printLabel renders the label of the entity with the ID of the path in the format and
+// number of copies of the query, ZPL and one copy by default
+func (c *Controller) printLabel(ctx *gin.Context, entity string,
+	render func(ctx context.Context, id uuid.UUID, format string, copies int) ([]byte, error)) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		standardErrorResponse(ctx, http.StatusBadRequest, fmt.Sprintf("Invalid %s ID format", entity))
+		return
+	}
+	format := ctx.DefaultQuery("format", domain.LabelFormatZPL)
+	if !domain.IsLabelFormat(format) {
+		standardErrorResponse(ctx, http.StatusBadRequest, "Invalid format, expected zpl or pdf")
+		return
+	}
+	copies, err := strconv.Atoi(ctx.DefaultQuery("copies", "1"))
+	if err != nil {
+		standardErrorResponse(ctx, http.StatusBadRequest, "Invalid copies, expected a number")
+		return
+	}
+
+	data, err := render(ctx, id, format, copies)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	contentType := "application/pdf"
+	if format == domain.LabelFormatZPL {
+		contentType = "application/zpl"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", strings.ToLower(entity), id, format))
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// tenantResponse is a tenant without the counters it keeps for numbering
+type tenantResponse struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	Email            string    `json:"email"`
+	GSTIN            *string   `json:"gstin,omitempty"`
+	GS1CompanyPrefix string    `json:"gs1_company_prefix,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func newTenantResponse(tenant domain.Tenant) tenantResponse {
+	return tenantResponse{
+		ID:               tenant.ID,
+		Name:             tenant.Name,
+		Email:            tenant.Email,
+		GSTIN:            tenant.GSTIN,
+		GS1CompanyPrefix: tenant.GS1CompanyPrefix,
+		CreatedAt:        tenant.CreatedAt,
+		UpdatedAt:        tenant.UpdatedAt,
+	}
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// POST API to create a location in a hub
+func (c *Controller) CreateLocation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		var request createLocationRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		location, err := c.service.CreateLocation(ctx, request.toLocation(hubID))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/location/"+location.ID.String(), "Location created successfully", newLocationResponse(location))
+	}
+}
+
+// updateLocationRequest is the body of PUT /location/:id
+type updateLocationRequest struct {
+	Type       string  `json:"type" binding:"required,location_type"`
+	MaxUnits   int     `json:"max_units" binding:"gte=0"`
+	MaxWeight  float64 `json:"max_weight" binding:"gte=0"`
+	MaxVolume  float64 `json:"max_volume" binding:"gte=0"`
+	Category   string  `json:"category" binding:"max=100"`
+	Sequence   int     `json:"sequence" binding:"gte=0"`
+	ShelfLevel int     `json:"shelf_level" binding:"gte=0"`
+	Active     bool    `json:"active"`
+}
+
+// PUT API to change the type, capacities, putaway attributes or active flag of a location
+func (c *Controller) UpdateLocation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid location ID format")
+			return
+		}
+
+		var request updateLocationRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		err = c.service.UpdateLocation(ctx, domain.Location{
+			ID:         locationID,
+			Type:       request.Type,
+			MaxUnits:   request.MaxUnits,
+			MaxWeight:  request.MaxWeight,
+			MaxVolume:  request.MaxVolume,
+			Category:   request.Category,
+			Sequence:   request.Sequence,
+			ShelfLevel: request.ShelfLevel,
+			Active:     request.Active,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Location updated successfully", nil)
+	}
+}
+
+// List the locations of a hub, ?level= and ?type= filter them
+func (c *Controller) GetLocations() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		locations, err := c.service.FetchLocations(ctx, hubID, ctx.Query("level"), ctx.Query("type"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Locations fetched successfully", mapResponses(locations, newLocationResponse))
+	}
+}
+
+func (c *Controller) GetLocationByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid location ID format")
+			return
+		}
+		location, err := c.service.FetchLocationByID(ctx, locationID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Location fetched successfully", newLocationResponse(location))
+	}
+}
+
+// Stock held in a location
+func (c *Controller) GetLocationStock() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid location ID format")
+			return
+		}
+		stock, err := c.service.FetchLocationStock(ctx, locationID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Location stock fetched successfully", mapResponses(stock, newLocationStockResponse))
+	}
+}
+
+// Locations holding a SKU at a hub, with hub totals
+func (c *Controller) GetSkuLocationStock() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Query("sku_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		hubID, err := uuid.Parse(ctx.Query("hub_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid Hub ID format")
+			return
+		}
+
+		stock, err := c.service.FetchSkuLocationStock(ctx, skuID, hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Location stock fetched successfully", newSkuLocationStockResponse(stock))
+	}
+}
+
+// moveLocationStockRequest is the body of POST /inventory/move
+type moveLocationStockRequest struct {
+	SkuID          uuid.UUID `json:"sku_id" binding:"required"`
+	HubID          uuid.UUID `json:"hub_id" binding:"required"`
+	FromLocationID uuid.UUID `json:"from_location_id" binding:"required"`
+	ToLocationID   uuid.UUID `json:"to_location_id" binding:"required"`
+	Qty            int       `json:"qty" binding:"gt=0"`
+}
+
+// Move stock from one bin to another
+func (c *Controller) MoveLocationStock() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request moveLocationStockRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		err := c.service.MoveLocationStock(ctx, request.SkuID, request.HubID, request.FromLocationID, request.ToLocationID, request.Qty)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Stock moved successfully", nil)
+	}
+}
+
+// locationResponse is a node of the location hierarchy of a hub
+type locationResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	HubID      uuid.UUID  `json:"hub_id"`
+	ParentID   *uuid.UUID `json:"parent_id,omitempty"`
+	Code       string     `json:"code"`
+	Level      string     `json:"level"`
+	Type       string     `json:"type"`
+	MaxUnits   int        `json:"max_units"`
+	MaxWeight  float64    `json:"max_weight"`
+	MaxVolume  float64    `json:"max_volume"`
+	Category   string     `json:"category,omitempty"`
+	Sequence   int        `json:"sequence"`
+	ShelfLevel int        `json:"shelf_level"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func newLocationResponse(location domain.Location) locationResponse {
+	return locationResponse{
+		ID:         location.ID,
+		HubID:      location.HubID,
+		ParentID:   location.ParentID,
+		Code:       location.Code,
+		Level:      location.Level,
+		Type:       location.Type,
+		MaxUnits:   location.MaxUnits,
+		MaxWeight:  location.MaxWeight,
+		MaxVolume:  location.MaxVolume,
+		Category:   location.Category,
+		Sequence:   location.Sequence,
+		ShelfLevel: location.ShelfLevel,
+		Active:     location.Active,
+		CreatedAt:  location.CreatedAt,
+		UpdatedAt:  location.UpdatedAt,
+	}
+}
+
+// locationRef is a location embedded in another response, nil when it was not loaded
+func locationRef(location *domain.Location) *locationResponse {
+	if location == nil {
+		return nil
+	}
+	response := newLocationResponse(*location)
+	return &response
+}
+
+// locationStockResponse is the quantity of a SKU held in a location
+type locationStockResponse struct {
+	ID         uuid.UUID         `json:"id"`
+	HubID      uuid.UUID         `json:"hub_id"`
+	SkuID      uuid.UUID         `json:"sku_id"`
+	LocationID uuid.UUID         `json:"location_id"`
+	Qty        int               `json:"qty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Location   *locationResponse `json:"location,omitempty"`
+	Sku        *skuResponse      `json:"sku,omitempty"`
+}
+
+func newLocationStockResponse(stock domain.LocationStock) locationStockResponse {
+	return locationStockResponse{
+		ID:         stock.ID,
+		HubID:      stock.HubID,
+		SkuID:      stock.SkuID,
+		LocationID: stock.LocationID,
+		Qty:        stock.Qty,
+		CreatedAt:  stock.CreatedAt,
+		UpdatedAt:  stock.UpdatedAt,
+		Location:   locationRef(stock.Location),
+		Sku:        skuRef(stock.Sku),
+	}
+}
+
+// skuLocationStockResponse is the stock of a SKU at a hub with the locations holding it
+type skuLocationStockResponse struct {
+	SkuID     uuid.UUID               `json:"sku_id"`
+	HubID     uuid.UUID               `json:"hub_id"`
+	OnHandQty int                     `json:"on_hand_qty"`
+	Locations []locationStockResponse `json:"locations"`
+}
+
+func newSkuLocationStockResponse(stock domain.SkuLocationStock) skuLocationStockResponse {
+	return skuLocationStockResponse{
+		SkuID:     stock.SkuID,
+		HubID:     stock.HubID,
+		OnHandQty: stock.OnHandQty,
+		Locations: mapResponses(stock.Locations, newLocationStockResponse),
+	}
+}
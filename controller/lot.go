@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"time"
+	"wms/domain"
+)
+
+// receiveInventoryRequest is the body of POST /inventory/receive
+type receiveInventoryRequest struct {
+	SkuID uuid.UUID `json:"sku_id" binding:"required"`
+	HubID uuid.UUID `json:"hub_id" binding:"required"`
+	Lots  []struct {
+		LotNumber  string     `json:"lot_number"`
+		MfgDate    string     `json:"mfg_date" binding:"omitempty,datetime=2006-01-02"`
+		ExpiryDate string     `json:"expiry_date" binding:"omitempty,datetime=2006-01-02"`
+		Qty        int        `json:"qty" binding:"gt=0"`
+		UOM        string     `json:"uom"`
+		Serials    []string   `json:"serials"`
+		LocationID *uuid.UUID `json:"location_id"`
+	} `json:"lots" binding:"required,min=1,dive"`
+}
+
+// Receive stock of a SKU at a hub, optionally split into lots
+func (c *Controller) ReceiveInventory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request receiveInventoryRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		receipts := make([]domain.LotReceipt, 0, len(request.Lots))
+		for _, lot := range request.Lots {
+			mfgDate, err := parseDate(lot.MfgDate)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid mfg_date format, expected YYYY-MM-DD")
+				return
+			}
+			expiryDate, err := parseDate(lot.ExpiryDate)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid expiry_date format, expected YYYY-MM-DD")
+				return
+			}
+			qty, ok := c.baseQty(ctx, request.SkuID, lot.UOM, lot.Qty)
+			if !ok {
+				return
+			}
+			receipts = append(receipts, domain.LotReceipt{
+				LotNumber:  lot.LotNumber,
+				MfgDate:    mfgDate,
+				ExpiryDate: expiryDate,
+				Qty:        qty,
+				Serials:    lot.Serials,
+				LocationID: lot.LocationID,
+			})
+		}
+
+		err := c.service.ReceiveInventory(ctx, request.SkuID, request.HubID, receipts)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		standardSuccessResponse(ctx, http.StatusOK, "Inventory received successfully", nil)
+	}
+}
+
+// allocateInventoryRequest is the body of POST /inventory/allocate
+type allocateInventoryRequest struct {
+	SkuID   uuid.UUID `json:"sku_id" binding:"required"`
+	HubID   uuid.UUID `json:"hub_id" binding:"required"`
+	Qty     int       `json:"qty" binding:"gt=0"`
+	UOM     string    `json:"uom"`
+	Serials []string  `json:"serials"`
+}
+
+// Allocate available stock first-expired-first-out
+func (c *Controller) AllocateInventory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request allocateInventoryRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		qty, ok := c.baseQty(ctx, request.SkuID, request.UOM, request.Qty)
+		if !ok {
+			return
+		}
+
+		err := c.service.AllocateInventory(ctx, request.SkuID, request.HubID, qty, request.Serials)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		standardSuccessResponse(ctx, http.StatusOK, "Inventory allocated successfully", nil)
+	}
+}
+
+func (c *Controller) GetLots() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Query("sku_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		hubID, err := uuid.Parse(ctx.Query("hub_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid Hub ID format")
+			return
+		}
+
+		if uom := ctx.Query("uom"); uom != "" {
+			lots, err := c.service.FetchLotsInUOM(ctx, skuID, hubID, uom)
+			if err != nil {
+				errorResponse(ctx, err)
+				return
+			}
+			standardSuccessResponse(ctx, http.StatusOK, "Lots fetched successfully", mapResponses(lots, newLotInUOMResponse))
+			return
+		}
+
+		lots, err := c.service.FetchLots(ctx, skuID, hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Lots fetched successfully", mapResponses(lots, newLotResponse))
+	}
+}
+
+// Near expiry report of a hub, ?days= sets the look-ahead window
+func (c *Controller) GetNearExpiryLots() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		var days *int
+		if daysParam, ok := ctx.GetQuery("days"); ok {
+			value, err := strconv.Atoi(daysParam)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid days value")
+				return
+			}
+			days = &value
+		}
+
+		lots, err := c.service.FetchNearExpiryLots(ctx, hubID, days)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Near expiry lots fetched successfully", lots)
+	}
+}
+
+func (c *Controller) BlockExpiredLots() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		blocked, err := c.service.BlockExpiredLots(ctx, hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Expired lots blocked successfully", gin.H{"blocked": blocked})
+	}
+}
+
+// parseDate parses an optional YYYY-MM-DD value
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// parseOptionalUUID parses an optional UUID value
+func parseOptionalUUID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// lotResponse is a lot of a SKU at a hub, in its base UOM
+type lotResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	InventoryID   uuid.UUID  `json:"inventory_id"`
+	SkuID         uuid.UUID  `json:"sku_id"`
+	HubID         uuid.UUID  `json:"hub_id"`
+	LotNumber     string     `json:"lot_number"`
+	MfgDate       *time.Time `json:"mfg_date,omitempty"`
+	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
+	AvailableQty  int        `json:"available_qty"`
+	AllocatedQty  int        `json:"allocated_qty"`
+	QuarantineQty int        `json:"quarantine_qty"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newLotResponse(lot domain.Lot) lotResponse {
+	return lotResponse{
+		ID:            lot.ID,
+		InventoryID:   lot.InventoryID,
+		SkuID:         lot.SkuID,
+		HubID:         lot.HubID,
+		LotNumber:     lot.LotNumber,
+		MfgDate:       lot.MfgDate,
+		ExpiryDate:    lot.ExpiryDate,
+		AvailableQty:  lot.AvailableQty,
+		AllocatedQty:  lot.AllocatedQty,
+		QuarantineQty: lot.QuarantineQty,
+		Status:        lot.Status,
+		CreatedAt:     lot.CreatedAt,
+		UpdatedAt:     lot.UpdatedAt,
+	}
+}
+
+// lotInUOMResponse is a lot with the quantities in another UOM
+type lotInUOMResponse struct {
+	lotResponse
+	UOM           string  `json:"uom"`
+	AvailableQty  float64 `json:"available_qty"`
+	AllocatedQty  float64 `json:"allocated_qty"`
+	QuarantineQty float64 `json:"quarantine_qty"`
+}
+
+func newLotInUOMResponse(lot domain.LotInUOM) lotInUOMResponse {
+	return lotInUOMResponse{
+		lotResponse:   newLotResponse(lot.Lot),
+		UOM:           lot.UOM,
+		AvailableQty:  lot.AvailableQty,
+		AllocatedQty:  lot.AllocatedQty,
+		QuarantineQty: lot.QuarantineQty,
+	}
+}
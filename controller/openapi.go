@@ -0,0 +1,322 @@
+package controller
+
+import (
+	_ "embed"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"sync"
+	"wms/domain"
+	"wms/pkg/health"
+	"wms/pkg/openapi"
+)
+
+// streamParams are the subscription of the inventory streams, the whole tenant when
+// neither a hub nor SKUs are given
+var streamParams = []openapi.Param{
+	{Name: "hub_id", Description: "only changes at this hub", Format: "uuid"},
+	{Name: "sku_ids", Description: "only changes of these SKUs, comma-separated"},
+	{Name: "after_id", Description: "resume after this event, from now when absent", Format: "integer"},
+}
+
+// operations document every route of the API. The router test fails when a route is
+// missing here, so a new route cannot go undocumented.
+var operations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Tag: "Docs", Summary: "Get this OpenAPI document",
+		Public: true, ContentTypes: []string{"application/json"}},
+	{Method: http.MethodGet, Path: "/docs", ID: "getSwaggerUI", Tag: "Docs", Summary: "Browse this document in Swagger UI",
+		Public: true, ContentTypes: []string{"text/html"}},
+	{Method: http.MethodGet, Path: "/", ID: "ping", Tag: "Docs", Summary: "Check that the API answers"},
+	{Method: http.MethodGet, Path: "/health/live", ID: "getLiveness", Tag: "Health", Summary: "Liveness probe: the process answers",
+		Public: true},
+	{Method: http.MethodGet, Path: "/health/ready", ID: "getReadiness", Tag: "Health", Summary: "Readiness probe: the status of each dependency, 503 when one fails",
+		Public: true, Response: health.Report{}},
+	{Method: http.MethodGet, Path: "/hub", ID: "getHubs", Tag: "Hubs", Summary: "List hubs",
+		Permission: domain.PermInventoryRead, Response: []hubResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id", ID: "getHubByID", Tag: "Hubs", Summary: "Get a hub",
+		Permission: domain.PermInventoryRead, Response: hubResponse{}},
+	{Method: http.MethodPost, Path: "/hub", ID: "createHub", Tag: "Hubs", Summary: "Create a hub",
+		Permission: domain.PermHubManage, Request: createHubRequest{}, Response: hubResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/hub/:id/near-expiry", ID: "getNearExpiryLots", Tag: "Lots", Summary: "List lots of a hub expiring soon",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "days", Description: "days ahead, 30 by default; 0 for lots expiring today or already expired", Format: "integer"}}, Response: []domain.NearExpiryLot{}},
+	{Method: http.MethodPost, Path: "/hub/:id/block-expired", ID: "blockExpiredLots", Tag: "Lots", Summary: "Block the expired lots of a hub",
+		Permission: domain.PermInventoryAdjust, Response: map[string]int{}},
+	{Method: http.MethodGet, Path: "/hub/:id/location", ID: "getLocations", Tag: "Locations", Summary: "List locations of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "level", Description: "only locations of this level"}, {Name: "type", Description: "only locations of this type"}}, Response: []locationResponse{}},
+	{Method: http.MethodPost, Path: "/hub/:id/location", ID: "createLocation", Tag: "Locations", Summary: "Create a location in a hub",
+		Permission: domain.PermLocationManage, Request: createLocationRequest{}, Response: locationResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/hub/:id/putaway", ID: "getPutawayTasks", Tag: "Putaway", Summary: "List putaway tasks of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only tasks in this status"}}, Response: []putawayTaskResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/order", ID: "getOrders", Tag: "Orders", Summary: "List outbound orders of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only orders in this status"}}, Response: []orderResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/wave", ID: "getWaves", Tag: "Orders", Summary: "List pick waves of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only waves in this status"}}, Response: []waveResponse{}},
+	{Method: http.MethodPost, Path: "/hub/:id/wave", ID: "planWaves", Tag: "Orders", Summary: "Plan pick waves of the open orders of a hub, one per carrier, cutoff and zone",
+		Permission: domain.PermOrderManage, Request: planWavesRequest{}, OptionalBody: true, Response: []waveResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/carton/:id", ID: "getCartonByID", Tag: "Packing", Summary: "Get a carton type",
+		Permission: domain.PermInventoryRead, Response: cartonResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/carton", ID: "getCartons", Tag: "Packing", Summary: "List cartons of a hub",
+		Permission: domain.PermInventoryRead, Response: []cartonResponse{}},
+	{Method: http.MethodPost, Path: "/hub/:id/carton", ID: "createCarton", Tag: "Packing", Summary: "Create a carton type in a hub",
+		Permission: domain.PermLocationManage, Request: createCartonRequest{}, Response: cartonResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/hub/:id/shipment", ID: "getShipments", Tag: "Shipments", Summary: "List shipments of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only shipments in this status"}}, Response: []shipmentResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/manifest", ID: "getManifests", Tag: "Shipments", Summary: "List manifests of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "date", Description: "only manifests of this date", Format: "date"}}, Response: []manifestResponse{}},
+	{Method: http.MethodPost, Path: "/hub/:id/manifest", ID: "createManifest", Tag: "Shipments", Summary: "Create a carrier manifest of the packed shipments of a hub",
+		Permission: domain.PermOrderManage, Request: createManifestRequest{}, Response: manifestResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/hub/:id/return", ID: "getReturns", Tag: "Returns", Summary: "List returns of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only returns in this status"}}, Response: []returnResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/transition", ID: "getStockTransitions", Tag: "Inventory", Summary: "List stock transitions of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "type", Description: "only transitions of this type"}, {Name: "sku_id", Description: "only transitions of this SKU", Format: "uuid"}}, Response: []stockTransitionResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/purchase-order", ID: "getPurchaseOrders", Tag: "Inbound", Summary: "List purchase orders of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "seller_id", Description: "only purchase orders of this seller", Format: "uuid"}, {Name: "status", Description: "only purchase orders in this status"}}, Response: []purchaseOrderResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/asn", ID: "getASNs", Tag: "Inbound", Summary: "List ASNs of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "seller_id", Description: "only ASNs of this seller", Format: "uuid"}, {Name: "status", Description: "only ASNs in this status"}}, Response: []asnResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/qc", ID: "getQcInspections", Tag: "QC", Summary: "List QC inspections of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only inspections in this status"}}, Response: []qcInspectionResponse{}},
+	{Method: http.MethodGet, Path: "/hub/:id/kitting", ID: "getKittingOrders", Tag: "Kits", Summary: "List kitting orders of a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "status", Description: "only kitting orders in this status"}}, Response: []kittingOrderResponse{}},
+	{Method: http.MethodGet, Path: "/location/:id", ID: "getLocationByID", Tag: "Locations", Summary: "Get a location",
+		Permission: domain.PermInventoryRead, Response: locationResponse{}},
+	{Method: http.MethodPut, Path: "/location/:id", ID: "updateLocation", Tag: "Locations", Summary: "Update a location",
+		Permission: domain.PermLocationManage, Request: updateLocationRequest{}},
+	{Method: http.MethodGet, Path: "/location/:id/stock", ID: "getLocationStock", Tag: "Locations", Summary: "List the stock held in a location",
+		Permission: domain.PermInventoryRead, Response: []locationStockResponse{}},
+	{Method: http.MethodGet, Path: "/location/:id/barcode", ID: "getLocationBarcodes", Tag: "Barcodes", Summary: "List barcodes of a location",
+		Permission: domain.PermInventoryRead, Response: []barcodeResponse{}},
+	{Method: http.MethodGet, Path: "/uom", ID: "getUOMs", Tag: "Catalogue", Summary: "List units of measure",
+		Permission: domain.PermInventoryRead, Response: []uomResponse{}},
+	{Method: http.MethodPost, Path: "/uom", ID: "createUOM", Tag: "Catalogue", Summary: "Create a unit of measure",
+		Permission: domain.PermCatalogManage, Request: createUOMRequest{}, Response: uomResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/uom/:code", ID: "getUOM", Tag: "Catalogue", Summary: "Get a unit of measure",
+		Permission: domain.PermInventoryRead, Response: uomResponse{}},
+	{Method: http.MethodGet, Path: "/sku", ID: "getSkus", Tag: "SKUs", Summary: "List SKUs",
+		Permission: domain.PermInventoryRead, Response: []skuResponse{}},
+	{Method: http.MethodGet, Path: "/sku/:id", ID: "getSkuByID", Tag: "SKUs", Summary: "Get a SKU",
+		Permission: domain.PermInventoryRead, Response: skuResponse{}},
+	{Method: http.MethodPost, Path: "/sku", ID: "createSKU", Tag: "SKUs", Summary: "Create a SKU",
+		Permission: domain.PermCatalogManage, Request: createSkuRequest{}, Response: skuResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/sku/:id/pack", ID: "getSkuPacks", Tag: "Catalogue", Summary: "List the pack hierarchy of a SKU",
+		Permission: domain.PermInventoryRead, Response: []skuPackResponse{}},
+	{Method: http.MethodGet, Path: "/sku/:id/barcode", ID: "getSkuBarcodes", Tag: "Barcodes", Summary: "List barcodes of a SKU",
+		Permission: domain.PermInventoryRead, Response: []barcodeResponse{}},
+	{Method: http.MethodPut, Path: "/sku/:id/pack", ID: "setSkuPacks", Tag: "Catalogue", Summary: "Replace the pack hierarchy of a SKU",
+		Permission: domain.PermCatalogManage, Request: setSkuPacksRequest{}, Response: []skuPackResponse{}},
+	{Method: http.MethodGet, Path: "/sku/:id/kit", ID: "getKitComponents", Tag: "Kits", Summary: "List the components of a kit SKU",
+		Permission: domain.PermInventoryRead, Response: []kitComponentResponse{}},
+	{Method: http.MethodPut, Path: "/sku/:id/kit", ID: "setKitComponents", Tag: "Kits", Summary: "Replace the components of a kit SKU",
+		Permission: domain.PermCatalogManage, Request: setKitComponentsRequest{}, Response: []kitComponentResponse{}},
+	{Method: http.MethodGet, Path: "/sku/:id/kit/availability", ID: "getKitAvailability", Tag: "Kits", Summary: "Get the availability of a kit SKU per hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "hub_id", Description: "only this hub", Format: "uuid"}}, Response: []domain.KitAvailability{}},
+	{Method: http.MethodPost, Path: "/inventory", ID: "decreaseInventory", Tag: "Inventory", Summary: "Decrease the available quantity of a SKU at a hub",
+		Permission: domain.PermInventoryAdjust, Request: decreaseInventoryRequest{}},
+	{Method: http.MethodGet, Path: "/inventory", ID: "getInventory", Tag: "Inventory", Summary: "Get the inventory of a SKU at a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "sku_id", Format: "uuid", Required: true}, {Name: "hub_id", Format: "uuid", Required: true}, {Name: "uom", Description: "render the quantities in this unit of measure"}}, Response: inventoryResponse{}},
+	{Method: http.MethodGet, Path: "/inventory/stream", ID: "streamInventory", Tag: "Inventory", Summary: "Stream inventory changes as server-sent events, resuming after the Last-Event-ID header",
+		Permission: domain.PermInventoryRead, Ticket: true, Query: streamParams, Stream: domain.InventoryEvent{}},
+	{Method: http.MethodGet, Path: "/inventory/ws", ID: "streamInventoryWebSocket", Tag: "Inventory", Summary: "Stream inventory changes over a WebSocket",
+		Permission: domain.PermInventoryRead, Ticket: true, Query: streamParams, Stream: domain.InventoryEvent{}, Status: http.StatusSwitchingProtocols},
+	{Method: http.MethodPost, Path: "/inventory/stream/ticket", ID: "issueStreamTicket", Tag: "Inventory", Summary: "Issue a short-lived ticket opening an inventory stream as the caller",
+		Permission: domain.PermInventoryRead, Response: streamTicketResponse{}},
+	{Method: http.MethodPost, Path: "/inventory/receive", ID: "receiveInventory", Tag: "Lots", Summary: "Receive lots of a SKU at a hub",
+		Permission: domain.PermInboundReceive, Request: receiveInventoryRequest{}},
+	{Method: http.MethodPost, Path: "/inventory/allocate", ID: "allocateInventory", Tag: "Lots", Summary: "Allocate units of a SKU at a hub, earliest expiry first",
+		Permission: domain.PermInventoryAdjust, Request: allocateInventoryRequest{}},
+	{Method: http.MethodGet, Path: "/inventory/lots", ID: "getLots", Tag: "Lots", Summary: "List lots of a SKU at a hub",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "sku_id", Format: "uuid", Required: true}, {Name: "hub_id", Format: "uuid", Required: true}, {Name: "uom", Description: "render the quantities in this unit of measure"}}, Response: []lotResponse{}},
+	{Method: http.MethodGet, Path: "/inventory/locations", ID: "getSkuLocationStock", Tag: "Locations", Summary: "Get where the stock of a SKU at a hub sits",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "sku_id", Format: "uuid", Required: true}, {Name: "hub_id", Format: "uuid", Required: true}}, Response: skuLocationStockResponse{}},
+	{Method: http.MethodPost, Path: "/inventory/move", ID: "moveLocationStock", Tag: "Locations", Summary: "Move units of a SKU between locations",
+		Permission: domain.PermInventoryAdjust, Request: moveLocationStockRequest{}},
+	{Method: http.MethodPost, Path: "/inventory/transition", ID: "createStockTransition", Tag: "Inventory", Summary: "Move units between the available, quarantine and damaged buckets",
+		Permission: domain.PermInventoryAdjust, Request: createStockTransitionRequest{}, Response: stockTransitionResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/inventory/transition/:id", ID: "getStockTransitionByID", Tag: "Inventory", Summary: "Get a stock transition",
+		Permission: domain.PermInventoryRead, Response: stockTransitionResponse{}},
+	{Method: http.MethodPost, Path: "/putaway/suggest", ID: "suggestPutaway", Tag: "Putaway", Summary: "Suggest bins to put units of a SKU away to",
+		Permission: domain.PermTaskExecute, Request: suggestPutawayRequest{}, Response: domain.PutawayPlan{}},
+	{Method: http.MethodPost, Path: "/putaway", ID: "createPutawayTasks", Tag: "Putaway", Summary: "Create putaway tasks from a receiving location",
+		Permission: domain.PermInboundReceive, Request: createPutawayTasksRequest{}, Response: []putawayTaskResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodPost, Path: "/putaway/:id/confirm", ID: "confirmPutawayTask", Tag: "Putaway", Summary: "Confirm a putaway task",
+		Permission: domain.PermTaskExecute, Request: confirmPutawayTaskRequest{}, OptionalBody: true},
+	{Method: http.MethodPost, Path: "/order", ID: "createOrder", Tag: "Orders", Summary: "Create an outbound order",
+		Permission: domain.PermOrderManage, Request: createOrderRequest{}, Response: orderResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/order/:id", ID: "getOrderByID", Tag: "Orders", Summary: "Get an outbound order",
+		Permission: domain.PermInventoryRead, Response: orderResponse{}},
+	{Method: http.MethodGet, Path: "/wave/:id", ID: "getWaveByID", Tag: "Orders", Summary: "Get a wave",
+		Permission: domain.PermInventoryRead, Response: waveResponse{}},
+	{Method: http.MethodPost, Path: "/wave/:id/release", ID: "releaseWave", Tag: "Orders", Summary: "Release a wave, creating its pick tasks",
+		Permission: domain.PermOrderManage, Response: []pickTaskResponse{}},
+	{Method: http.MethodGet, Path: "/wave/:id/picklist", ID: "getPickList", Tag: "Orders", Summary: "Get the pick list of a wave in walk order",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "zone_id", Description: "only tasks in this zone", Format: "uuid"}}, Response: []pickTaskResponse{}},
+	{Method: http.MethodPost, Path: "/pick/:id/confirm", ID: "confirmPick", Tag: "Orders", Summary: "Confirm a pick task",
+		Permission: domain.PermTaskExecute, Request: confirmPickRequest{}},
+	{Method: http.MethodGet, Path: "/order/:id/cartonize", ID: "cartonizeOrder", Tag: "Packing", Summary: "Suggest cartons to pack an order into",
+		Permission: domain.PermTaskExecute, Response: []domain.CartonSuggestion{}},
+	{Method: http.MethodGet, Path: "/order/:id/package", ID: "getPackages", Tag: "Packing", Summary: "List packages of an order",
+		Permission: domain.PermInventoryRead, Response: []packageResponse{}},
+	{Method: http.MethodPost, Path: "/order/:id/package", ID: "openPackage", Tag: "Packing", Summary: "Open a package for an order",
+		Permission: domain.PermTaskExecute, Request: openPackageRequest{}, OptionalBody: true, Response: packageResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodPost, Path: "/order/:id/pack", ID: "completePacking", Tag: "Packing", Summary: "Complete packing of an order, creating its shipment",
+		Permission: domain.PermTaskExecute, Response: shipmentResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/package/:id", ID: "getPackageByID", Tag: "Packing", Summary: "Get a package with its items",
+		Permission: domain.PermInventoryRead, Response: packageResponse{}},
+	{Method: http.MethodPost, Path: "/package/:id/scan", ID: "scanPackageItem", Tag: "Packing", Summary: "Scan units of a SKU into a package",
+		Permission: domain.PermTaskExecute, Request: scanPackageItemRequest{}},
+	{Method: http.MethodGet, Path: "/shipment/:id", ID: "getShipmentByID", Tag: "Shipments", Summary: "Get a shipment",
+		Permission: domain.PermInventoryRead, Response: shipmentResponse{}},
+	{Method: http.MethodPut, Path: "/shipment/:id/carrier", ID: "assignCarrier", Tag: "Shipments", Summary: "Assign a carrier to a shipment",
+		Permission: domain.PermOrderManage, Request: assignCarrierRequest{}, Response: shipmentResponse{}},
+	{Method: http.MethodPut, Path: "/package/:id/tracking", ID: "setTrackingNumber", Tag: "Shipments", Summary: "Set the tracking number of a package",
+		Permission: domain.PermOrderManage, Request: setTrackingNumberRequest{}},
+	{Method: http.MethodPost, Path: "/shipment/:id/dispatch", ID: "dispatchShipment", Tag: "Shipments", Summary: "Dispatch a shipment",
+		Permission: domain.PermOrderManage, Response: shipmentResponse{}},
+	{Method: http.MethodGet, Path: "/manifest/:id", ID: "getManifestByID", Tag: "Shipments", Summary: "Get a manifest, as JSON, CSV or PDF",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "format", Description: "csv or pdf for a file instead of JSON"}}, Response: manifestResponse{}, ContentTypes: []string{"text/csv", "application/pdf"}},
+	{Method: http.MethodPost, Path: "/manifest/:id/dispatch", ID: "dispatchManifest", Tag: "Shipments", Summary: "Dispatch a manifest and its shipments",
+		Permission: domain.PermOrderManage, Response: manifestResponse{}},
+	{Method: http.MethodPost, Path: "/return", ID: "createReturn", Tag: "Returns", Summary: "Authorise a return",
+		Permission: domain.PermOrderManage, Request: createReturnRequest{}, Response: returnResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/return/:id", ID: "getReturnByID", Tag: "Returns", Summary: "Get a return",
+		Permission: domain.PermInventoryRead, Response: returnResponse{}},
+	{Method: http.MethodPost, Path: "/return/:id/receive", ID: "receiveReturn", Tag: "Returns", Summary: "Receive the units of a return at a hub",
+		Permission: domain.PermInboundReceive, Request: receiveReturnRequest{}, Response: returnResponse{}},
+	{Method: http.MethodPost, Path: "/return/:id/grade", ID: "gradeReturn", Tag: "Returns", Summary: "Grade the received units of a return",
+		Permission: domain.PermInboundReceive, Request: gradeReturnRequest{}, Response: returnResponse{}},
+	{Method: http.MethodGet, Path: "/report/returns", ID: "getReturnReport", Tag: "Returns", Summary: "Report returned units by SKU, reason and grade",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "seller_id", Format: "uuid"}, {Name: "sku_id", Format: "uuid"}, {Name: "from", Format: "date"}, {Name: "to", Format: "date"}}, Response: []domain.ReturnReport{}},
+	{Method: http.MethodPost, Path: "/purchase-order", ID: "createPurchaseOrder", Tag: "Inbound", Summary: "Create a purchase order",
+		Permission: domain.PermInboundReceive, Request: createPurchaseOrderRequest{}, Response: purchaseOrderResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/purchase-order/:id", ID: "getPurchaseOrderByID", Tag: "Inbound", Summary: "Get a purchase order",
+		Permission: domain.PermInventoryRead, Response: purchaseOrderResponse{}},
+	{Method: http.MethodPost, Path: "/asn", ID: "createASN", Tag: "Inbound", Summary: "Create an ASN",
+		Permission: domain.PermInboundReceive, Request: createASNRequest{}, Response: asnResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/asn/:id", ID: "getASNByID", Tag: "Inbound", Summary: "Get an ASN",
+		Permission: domain.PermInventoryRead, Response: asnResponse{}},
+	{Method: http.MethodPost, Path: "/asn/:id/receive", ID: "receiveASN", Tag: "Inbound", Summary: "Receive lots against an ASN",
+		Permission: domain.PermInboundReceive, Request: receiveASNRequest{}, Response: asnResponse{}},
+	{Method: http.MethodPost, Path: "/asn/:id/close", ID: "closeASN", Tag: "Inbound", Summary: "Close an ASN",
+		Permission: domain.PermInboundReceive, Response: asnResponse{}},
+	{Method: http.MethodGet, Path: "/report/asn-variance", ID: "getAsnVariance", Tag: "Inbound", Summary: "Report the variance between expected and received units",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "seller_id", Format: "uuid"}, {Name: "hub_id", Format: "uuid"}, {Name: "from", Format: "date"}, {Name: "to", Format: "date"}}, Response: []domain.AsnVariance{}},
+	{Method: http.MethodPost, Path: "/qc-rule", ID: "createQcRule", Tag: "QC", Summary: "Create a QC sampling rule",
+		Permission: domain.PermCatalogManage, Request: createQcRuleRequest{}, Response: qcRuleResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/qc-rule", ID: "getQcRules", Tag: "QC", Summary: "List QC sampling rules",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "seller_id", Description: "only rules of this seller", Format: "uuid"}}, Response: []qcRuleResponse{}},
+	{Method: http.MethodGet, Path: "/qc-rule/:id", ID: "getQcRuleByID", Tag: "QC", Summary: "Get a QC sampling rule",
+		Permission: domain.PermInventoryRead, Response: qcRuleResponse{}},
+	{Method: http.MethodPut, Path: "/qc-rule/:id", ID: "updateQcRule", Tag: "QC", Summary: "Update a QC sampling rule",
+		Permission: domain.PermCatalogManage, Request: updateQcRuleRequest{}, Response: qcRuleResponse{}},
+	{Method: http.MethodGet, Path: "/qc/:id", ID: "getQcInspectionByID", Tag: "QC", Summary: "Get a QC inspection",
+		Permission: domain.PermInventoryRead, Response: qcInspectionResponse{}},
+	{Method: http.MethodPost, Path: "/qc/:id/result", ID: "recordQcResults", Tag: "QC", Summary: "Record the results of a QC inspection",
+		Permission: domain.PermTaskExecute, Request: recordQcResultsRequest{}, Response: qcInspectionResponse{}},
+	{Method: http.MethodPost, Path: "/kitting", ID: "createKittingOrder", Tag: "Kits", Summary: "Create a kitting order",
+		Permission: domain.PermOrderManage, Request: createKittingOrderRequest{}, Response: kittingOrderResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/kitting/:id", ID: "getKittingOrderByID", Tag: "Kits", Summary: "Get a kitting order",
+		Permission: domain.PermInventoryRead, Response: kittingOrderResponse{}},
+	{Method: http.MethodPost, Path: "/kitting/:id/complete", ID: "completeKittingOrder", Tag: "Kits", Summary: "Complete a kitting order",
+		Permission: domain.PermTaskExecute, Response: kittingOrderResponse{}},
+	{Method: http.MethodPost, Path: "/kitting/:id/cancel", ID: "cancelKittingOrder", Tag: "Kits", Summary: "Cancel a kitting order",
+		Permission: domain.PermOrderManage, Response: kittingOrderResponse{}},
+	{Method: http.MethodPost, Path: "/barcode", ID: "createBarcode", Tag: "Barcodes", Summary: "Register a barcode, of a SKU with catalog:manage or of a location with location:manage",
+		Permissions: []string{domain.PermCatalogManage, domain.PermLocationManage}, Request: createBarcodeRequest{}, Response: barcodeResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/barcode/:id", ID: "getBarcodeByID", Tag: "Barcodes", Summary: "Get a barcode",
+		Permission: domain.PermInventoryRead, Response: barcodeResponse{}},
+	{Method: http.MethodDelete, Path: "/barcode/:id", ID: "deleteBarcode", Tag: "Barcodes", Summary: "Delete a barcode, of a SKU with catalog:manage or of a location with location:manage",
+		Permissions: []string{domain.PermCatalogManage, domain.PermLocationManage}},
+	{Method: http.MethodGet, Path: "/scan/:code", ID: "resolveScan", Tag: "Barcodes", Summary: "Resolve a scanned value",
+		Permission: domain.PermInventoryRead, Query: []openapi.Param{{Name: "hub_id", Description: "narrow serials, lots and locations down to this hub", Format: "uuid"}}, Response: scanResultResponse{}},
+	{Method: http.MethodPut, Path: "/tenant/:id/gs1", ID: "setGS1CompanyPrefix", Tag: "Labels", Summary: "Set the GS1 company prefix of a tenant",
+		Permission: domain.PermAccessManage, Request: setGS1CompanyPrefixRequest{}, Response: tenantResponse{}},
+	{Method: http.MethodGet, Path: "/sku/:id/label", ID: "getSkuLabel", Tag: "Labels", Summary: "Print the label of a SKU",
+		Permission: domain.PermTaskExecute, Query: []openapi.Param{{Name: "format", Description: "zpl (default) or pdf"}, {Name: "copies", Description: "1 by default", Format: "integer"}}, ContentTypes: []string{"application/zpl", "application/pdf"}},
+	{Method: http.MethodGet, Path: "/location/:id/label", ID: "getLocationLabel", Tag: "Labels", Summary: "Print the label of a location",
+		Permission: domain.PermTaskExecute, Query: []openapi.Param{{Name: "format", Description: "zpl (default) or pdf"}, {Name: "copies", Description: "1 by default", Format: "integer"}}, ContentTypes: []string{"application/zpl", "application/pdf"}},
+	{Method: http.MethodGet, Path: "/package/:id/label", ID: "getPackageLabel", Tag: "Labels", Summary: "Print the shipping label of a package",
+		Permission: domain.PermTaskExecute, Query: []openapi.Param{{Name: "format", Description: "zpl (default) or pdf"}, {Name: "copies", Description: "1 by default", Format: "integer"}}, ContentTypes: []string{"application/zpl", "application/pdf"}},
+	{Method: http.MethodGet, Path: "/whoami", ID: "whoAmI", Tag: "Access", Summary: "Get the principal of the request",
+		Response: domain.Principal{}},
+	{Method: http.MethodPost, Path: "/tenant/:id/api-key", ID: "createAPIKey", Tag: "Access", Summary: "Issue an API key for a tenant",
+		Permission: domain.PermAccessManage, Request: createAPIKeyRequest{}, Response: issuedAPIKeyResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/tenant/:id/api-key", ID: "getAPIKeys", Tag: "Access", Summary: "List API keys of a tenant",
+		Permission: domain.PermAccessManage, Response: []apiKeyResponse{}},
+	{Method: http.MethodGet, Path: "/api-key/:id", ID: "getAPIKeyByID", Tag: "Access", Summary: "Get an API key, without the key",
+		Permission: domain.PermAccessManage, Response: apiKeyResponse{}},
+	{Method: http.MethodDelete, Path: "/api-key/:id", ID: "revokeAPIKey", Tag: "Access", Summary: "Revoke an API key",
+		Permission: domain.PermAccessManage},
+	{Method: http.MethodPost, Path: "/tenant/:id/role", ID: "assignRole", Tag: "Access", Summary: "Assign a role to a subject of a tenant",
+		Permission: domain.PermAccessManage, Request: assignRoleRequest{}, Response: roleAssignmentResponse{}, Status: http.StatusCreated, Location: true},
+	{Method: http.MethodGet, Path: "/tenant/:id/role", ID: "getRoleAssignments", Tag: "Access", Summary: "List role assignments of a tenant",
+		Permission: domain.PermAccessManage, Query: []openapi.Param{{Name: "subject", Description: "only assignments of this subject"}}, Response: []roleAssignmentResponse{}},
+	{Method: http.MethodGet, Path: "/role/:id", ID: "getRoleAssignmentByID", Tag: "Access", Summary: "Get a role assignment",
+		Permission: domain.PermAccessManage, Response: roleAssignmentResponse{}},
+	{Method: http.MethodDelete, Path: "/role/:id", ID: "removeRoleAssignment", Tag: "Access", Summary: "Remove a role assignment",
+		Permission: domain.PermAccessManage},
+	{Method: http.MethodGet, Path: "/audit", ID: "getAuditEntries", Tag: "Audit", Summary: "Page through the audit log",
+		Permission: domain.PermAccessManage, Query: []openapi.Param{{Name: "actor"}, {Name: "action"}, {Name: "entity"}, {Name: "entity_id"}, {Name: "from", Format: "date-time"}, {Name: "to", Format: "date-time"}, {Name: "after_seq", Description: "entries after this sequence number", Format: "integer"}, {Name: "limit", Format: "integer"}}, Response: []auditEntryResponse{}},
+	{Method: http.MethodGet, Path: "/audit/export", ID: "exportAuditEntries", Tag: "Audit", Summary: "Export the audit log as CSV or JSON lines",
+		Permission: domain.PermAccessManage, Query: []openapi.Param{{Name: "actor"}, {Name: "action"}, {Name: "entity"}, {Name: "entity_id"}, {Name: "from", Format: "date-time"}, {Name: "to", Format: "date-time"}, {Name: "after_seq", Description: "entries after this sequence number", Format: "integer"}, {Name: "limit", Format: "integer"}, {Name: "format", Description: "csv (default) or jsonl"}}, ContentTypes: []string{"text/csv", "application/x-ndjson"}},
+	{Method: http.MethodGet, Path: "/audit/verify", ID: "verifyAuditLog", Tag: "Audit", Summary: "Verify the hash chain of the tenant's audit log",
+		Permission: domain.PermAccessManage, Response: domain.AuditVerification{}},
+	{Method: http.MethodGet, Path: "/serial/:serial", ID: "getSerial", Tag: "Inventory", Summary: "Get the history of a serial number",
+		Permission: domain.PermInventoryRead, Response: []serialResponse{}},
+}
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument []byte
+	openAPIErr      error
+)
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+// CheckRoutes verifies that the routes registered with gin and the documented ones are the
+// same
+func CheckRoutes(routes gin.RoutesInfo) error {
+	return openapi.Check(BasePath, routes, operations)
+}
+
+// GET API serving the OpenAPI 3 document of the API
+func (c *Controller) OpenAPI() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		openAPIOnce.Do(func() {
+			spec := openapi.Spec{Title: "Warehouse Management System", Version: "1.0.0", BasePath: BasePath, Enums: enums()}
+			openAPIDocument, openAPIErr = json.Marshal(spec.Document(operations))
+		})
+		if openAPIErr != nil {
+			errorResponse(ctx, openAPIErr)
+			return
+		}
+		ctx.Data(http.StatusOK, "application/json", openAPIDocument)
+	}
+}
+
+// GET API serving Swagger UI on the OpenAPI document
+func (c *Controller) SwaggerUI() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUI)
+	}
+}
+
+// enums are the values the custom validations of request fields accept
+func enums() map[string][]string {
+	transitionTypes := make([]string, 0, len(domain.TransitionRules))
+	for transitionType := range domain.TransitionRules {
+		transitionTypes = append(transitionTypes, transitionType)
+	}
+	sort.Strings(transitionTypes)
+
+	return map[string][]string{
+		"location_level": {domain.LocationLevelZone, domain.LocationLevelAisle, domain.LocationLevelRack, domain.LocationLevelBin},
+		"location_type": {domain.LocationTypePick, domain.LocationTypeReserve, domain.LocationTypeStaging,
+			domain.LocationTypeDock, domain.LocationTypeQuarantine},
+		"return_reason": {domain.ReturnReasonDamagedInTransit, domain.ReturnReasonDefective, domain.ReturnReasonWrongItem,
+			domain.ReturnReasonNotAsDescribed, domain.ReturnReasonNotNeeded, domain.ReturnReasonOther},
+		"return_grade":    {domain.ReturnGradeResellable, domain.ReturnGradeDamaged, domain.ReturnGradeScrap},
+		"role":            {domain.RoleAdmin, domain.RoleHubManager, domain.RolePicker, domain.RoleReadOnly, domain.RoleIntegration},
+		"transition_type": transitionTypes,
+	}
+}
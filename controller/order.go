@@ -0,0 +1,358 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createOrderRequest is the body of POST /order
+type createOrderRequest struct {
+	HubID    uuid.UUID  `json:"hub_id" binding:"required"`
+	OrderNo  string     `json:"order_no" binding:"required,max=50"`
+	Carrier  string     `json:"carrier"`
+	CutoffAt *time.Time `json:"cutoff_at"`
+	Lines    []struct {
+		SkuID uuid.UUID `json:"sku_id" binding:"required"`
+		Qty   int       `json:"qty" binding:"gt=0"`
+		UOM   string    `json:"uom"`
+	} `json:"lines" binding:"required,min=1,dive"`
+}
+
+// POST API to take in an outbound order, allocating its stock
+func (c *Controller) CreateOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createOrderRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		order := domain.OutboundOrder{
+			HubID:    request.HubID,
+			OrderNo:  request.OrderNo,
+			Carrier:  request.Carrier,
+			CutoffAt: request.CutoffAt,
+		}
+		for _, line := range request.Lines {
+			qty, ok := c.baseQty(ctx, line.SkuID, line.UOM, line.Qty)
+			if !ok {
+				return
+			}
+			order.Lines = append(order.Lines, domain.OutboundOrderLine{SkuID: line.SkuID, Qty: qty})
+		}
+
+		order, err := c.service.CreateOrder(ctx, order)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/order/"+order.ID.String(), "Order created successfully", newOrderResponse(order))
+	}
+}
+
+func (c *Controller) GetOrderByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+		order, err := c.service.FetchOrderByID(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Order fetched successfully", newOrderResponse(order))
+	}
+}
+
+// List the orders of a hub, ?status= filters them
+func (c *Controller) GetOrders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		orders, err := c.service.FetchOrders(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Orders fetched successfully", mapResponses(orders, newOrderResponse))
+	}
+}
+
+// planWavesRequest is the body of POST /hub/:id/wave
+type planWavesRequest struct {
+	Carrier      string     `json:"carrier"`
+	CutoffBefore *time.Time `json:"cutoff_before"`
+}
+
+// Group the allocated orders of a hub into waves by carrier and cutoff
+func (c *Controller) PlanWaves() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		var request planWavesRequest
+		if ctx.Request.ContentLength > 0 {
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				errorResponse(ctx, bindError(err))
+				return
+			}
+		}
+
+		waves, err := c.service.PlanWaves(ctx, hubID, request.Carrier, request.CutoffBefore)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/hub/"+hubID.String()+"/wave", "Waves planned successfully", mapResponses(waves, newWaveResponse))
+	}
+}
+
+// List the waves of a hub, ?status= filters them
+func (c *Controller) GetWaves() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		waves, err := c.service.FetchWaves(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Waves fetched successfully", mapResponses(waves, newWaveResponse))
+	}
+}
+
+func (c *Controller) GetWaveByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		waveID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid wave ID format")
+			return
+		}
+		wave, err := c.service.FetchWaveByID(ctx, waveID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Wave fetched successfully", newWaveResponse(wave))
+	}
+}
+
+// Release a wave to the floor, generating its pick list
+func (c *Controller) ReleaseWave() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		waveID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid wave ID format")
+			return
+		}
+		tasks, err := c.service.ReleaseWave(ctx, waveID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Wave released successfully", mapResponses(tasks, newPickTaskResponse))
+	}
+}
+
+// Pick list of a wave sorted by walk path, ?zone_id= narrows it to one zone
+func (c *Controller) GetPickList() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		waveID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid wave ID format")
+			return
+		}
+
+		var zoneID *uuid.UUID
+		if zoneParam := ctx.Query("zone_id"); zoneParam != "" {
+			id, err := uuid.Parse(zoneParam)
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid zone ID format")
+				return
+			}
+			zoneID = &id
+		}
+
+		tasks, err := c.service.FetchPickList(ctx, waveID, zoneID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Pick list fetched successfully", mapResponses(tasks, newPickTaskResponse))
+	}
+}
+
+// confirmPickRequest is the body of POST /pick/:id/confirm
+type confirmPickRequest struct {
+	PickedQty int `json:"picked_qty" binding:"gte=0"`
+}
+
+// Confirm a pick; picking fewer units than asked reports a short pick
+func (c *Controller) ConfirmPick() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		taskID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid pick task ID format")
+			return
+		}
+
+		var request confirmPickRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		err = c.service.ConfirmPick(ctx, taskID, request.PickedQty)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Pick confirmed successfully", nil)
+	}
+}
+
+// orderResponse is an outbound order with its lines
+type orderResponse struct {
+	ID        uuid.UUID           `json:"id"`
+	HubID     uuid.UUID           `json:"hub_id"`
+	OrderNo   string              `json:"order_no"`
+	Carrier   string              `json:"carrier"`
+	CutoffAt  *time.Time          `json:"cutoff_at,omitempty"`
+	Status    string              `json:"status"`
+	WaveID    *uuid.UUID          `json:"wave_id,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Lines     []orderLineResponse `json:"lines"`
+}
+
+func newOrderResponse(order domain.OutboundOrder) orderResponse {
+	return orderResponse{
+		ID:        order.ID,
+		HubID:     order.HubID,
+		OrderNo:   order.OrderNo,
+		Carrier:   order.Carrier,
+		CutoffAt:  order.CutoffAt,
+		Status:    order.Status,
+		WaveID:    order.WaveID,
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.UpdatedAt,
+		Lines:     mapResponses(order.Lines, newOrderLineResponse),
+	}
+}
+
+// orderLineResponse is a line of an outbound order with its fulfilment progress
+type orderLineResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	OrderID      uuid.UUID  `json:"order_id"`
+	SkuID        uuid.UUID  `json:"sku_id"`
+	KitLineID    *uuid.UUID `json:"kit_line_id,omitempty"`
+	Qty          int        `json:"qty"`
+	AllocatedQty int        `json:"allocated_qty"`
+	PickedQty    int        `json:"picked_qty"`
+	ShortQty     int        `json:"short_qty"`
+	PackedQty    int        `json:"packed_qty"`
+	ShippedQty   int        `json:"shipped_qty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func newOrderLineResponse(line domain.OutboundOrderLine) orderLineResponse {
+	return orderLineResponse{
+		ID:           line.ID,
+		OrderID:      line.OrderID,
+		SkuID:        line.SkuID,
+		KitLineID:    line.KitLineID,
+		Qty:          line.Qty,
+		AllocatedQty: line.AllocatedQty,
+		PickedQty:    line.PickedQty,
+		ShortQty:     line.ShortQty,
+		PackedQty:    line.PackedQty,
+		ShippedQty:   line.ShippedQty,
+		CreatedAt:    line.CreatedAt,
+		UpdatedAt:    line.UpdatedAt,
+	}
+}
+
+// waveResponse is a wave of orders, with the orders when they were loaded
+type waveResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	HubID     uuid.UUID       `json:"hub_id"`
+	Carrier   string          `json:"carrier"`
+	CutoffAt  *time.Time      `json:"cutoff_at,omitempty"`
+	ZoneID    *uuid.UUID      `json:"zone_id,omitempty"`
+	Status    string          `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Orders    []orderResponse `json:"orders,omitempty"`
+}
+
+func newWaveResponse(wave domain.Wave) waveResponse {
+	response := waveResponse{
+		ID:        wave.ID,
+		HubID:     wave.HubID,
+		Carrier:   wave.Carrier,
+		CutoffAt:  wave.CutoffAt,
+		ZoneID:    wave.ZoneID,
+		Status:    wave.Status,
+		CreatedAt: wave.CreatedAt,
+		UpdatedAt: wave.UpdatedAt,
+	}
+	if len(wave.Orders) > 0 {
+		response.Orders = mapResponses(wave.Orders, newOrderResponse)
+	}
+	return response
+}
+
+// pickTaskResponse is a pick of an order line from a bin
+type pickTaskResponse struct {
+	ID          uuid.UUID         `json:"id"`
+	WaveID      uuid.UUID         `json:"wave_id"`
+	OrderID     uuid.UUID         `json:"order_id"`
+	OrderLineID uuid.UUID         `json:"order_line_id"`
+	SkuID       uuid.UUID         `json:"sku_id"`
+	LocationID  uuid.UUID         `json:"location_id"`
+	ZoneID      uuid.UUID         `json:"zone_id"`
+	Sequence    int               `json:"sequence"`
+	Qty         int               `json:"qty"`
+	PickedQty   int               `json:"picked_qty"`
+	Status      string            `json:"status"`
+	PickedAt    *time.Time        `json:"picked_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Location    *locationResponse `json:"location,omitempty"`
+}
+
+func newPickTaskResponse(task domain.PickTask) pickTaskResponse {
+	return pickTaskResponse{
+		ID:          task.ID,
+		WaveID:      task.WaveID,
+		OrderID:     task.OrderID,
+		OrderLineID: task.OrderLineID,
+		SkuID:       task.SkuID,
+		LocationID:  task.LocationID,
+		ZoneID:      task.ZoneID,
+		Sequence:    task.Sequence,
+		Qty:         task.Qty,
+		PickedQty:   task.PickedQty,
+		Status:      task.Status,
+		PickedAt:    task.PickedAt,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		Location:    locationRef(task.Location),
+	}
+}
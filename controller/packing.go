@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// POST API to add a carton size to the catalogue of a hub
+func (c *Controller) CreateCarton() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		var request createCartonRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		carton, err := c.service.CreateCarton(ctx, request.toCarton(hubID))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/carton/"+carton.ID.String(), "Carton created successfully", newCartonResponse(carton))
+	}
+}
+
+func (c *Controller) GetCartonByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		cartonID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid carton ID format")
+			return
+		}
+		carton, err := c.service.FetchCartonByID(ctx, cartonID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Carton fetched successfully", newCartonResponse(carton))
+	}
+}
+
+func (c *Controller) GetCartons() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		cartons, err := c.service.FetchCartons(ctx, hubID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Cartons fetched successfully", mapResponses(cartons, newCartonResponse))
+	}
+}
+
+// Suggest cartons for the picked units of an order
+func (c *Controller) CartonizeOrder() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+		suggestions, err := c.service.CartonizeOrder(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Order cartonized successfully", suggestions)
+	}
+}
+
+// openPackageRequest is the body of POST /order/:id/package
+type openPackageRequest struct {
+	CartonID *uuid.UUID `json:"carton_id"`
+}
+
+// Open a package for an order at the packing station, in the suggested carton unless carton_id is given
+func (c *Controller) OpenPackage() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+
+		var request openPackageRequest
+		if ctx.Request.ContentLength > 0 {
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				errorResponse(ctx, bindError(err))
+				return
+			}
+		}
+
+		pkg, err := c.service.OpenPackage(ctx, orderID, request.CartonID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/package/"+pkg.ID.String(), "Package opened successfully", newPackageResponse(pkg))
+	}
+}
+
+func (c *Controller) GetPackageByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		packageID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid package ID format")
+			return
+		}
+		pkg, err := c.service.FetchPackageByID(ctx, packageID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Package fetched successfully", newPackageResponse(pkg))
+	}
+}
+
+func (c *Controller) GetPackages() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+		packages, err := c.service.FetchPackages(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Packages fetched successfully", mapResponses(packages, newPackageResponse))
+	}
+}
+
+// scanPackageItemRequest is the body of POST /package/:id/scan
+type scanPackageItemRequest struct {
+	SkuID   uuid.UUID `json:"sku_id" binding:"required"`
+	Qty     int       `json:"qty" binding:"gt=0"`
+	Serials []string  `json:"serials"`
+}
+
+// Scan picked units into a package
+func (c *Controller) ScanPackageItem() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		packageID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid package ID format")
+			return
+		}
+
+		var request scanPackageItemRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		err = c.service.ScanPackageItem(ctx, packageID, request.SkuID, request.Qty, request.Serials)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Item packed successfully", nil)
+	}
+}
+
+// Close the packages of an order and record the packed shipment
+func (c *Controller) CompletePacking() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orderID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid order ID format")
+			return
+		}
+		shipment, err := c.service.CompletePacking(ctx, orderID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/shipment/"+shipment.ID.String(), "Order packed successfully", newShipmentResponse(shipment))
+	}
+}
+
+// cartonResponse is a carton size of the catalogue of a hub
+type cartonResponse struct {
+	ID          uuid.UUID `json:"id"`
+	HubID       uuid.UUID `json:"hub_id"`
+	Code        string    `json:"code"`
+	InnerLength float64   `json:"inner_length"`
+	InnerWidth  float64   `json:"inner_width"`
+	InnerHeight float64   `json:"inner_height"`
+	TareWeight  float64   `json:"tare_weight"`
+	MaxWeight   float64   `json:"max_weight"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newCartonResponse(carton domain.Carton) cartonResponse {
+	return cartonResponse{
+		ID:          carton.ID,
+		HubID:       carton.HubID,
+		Code:        carton.Code,
+		InnerLength: carton.InnerLength,
+		InnerWidth:  carton.InnerWidth,
+		InnerHeight: carton.InnerHeight,
+		TareWeight:  carton.TareWeight,
+		MaxWeight:   carton.MaxWeight,
+		Active:      carton.Active,
+		CreatedAt:   carton.CreatedAt,
+		UpdatedAt:   carton.UpdatedAt,
+	}
+}
+
+// packageResponse is a package of an order with the units packed in it
+type packageResponse struct {
+	ID               uuid.UUID             `json:"id"`
+	HubID            uuid.UUID             `json:"hub_id"`
+	OrderID          uuid.UUID             `json:"order_id"`
+	CartonID         uuid.UUID             `json:"carton_id"`
+	ShipmentID       *uuid.UUID            `json:"shipment_id,omitempty"`
+	Status           string                `json:"status"`
+	Weight           float64               `json:"weight"`
+	VolumetricWeight float64               `json:"volumetric_weight"`
+	TrackingNumber   string                `json:"tracking_number"`
+	SSCC             *string               `json:"sscc,omitempty"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+	Items            []packageItemResponse `json:"items"`
+	Carton           *cartonResponse       `json:"carton,omitempty"`
+}
+
+func newPackageResponse(pkg domain.Package) packageResponse {
+	response := packageResponse{
+		ID:               pkg.ID,
+		HubID:            pkg.HubID,
+		OrderID:          pkg.OrderID,
+		CartonID:         pkg.CartonID,
+		ShipmentID:       pkg.ShipmentID,
+		Status:           pkg.Status,
+		Weight:           pkg.Weight,
+		VolumetricWeight: pkg.VolumetricWeight,
+		TrackingNumber:   pkg.TrackingNumber,
+		SSCC:             pkg.SSCC,
+		CreatedAt:        pkg.CreatedAt,
+		UpdatedAt:        pkg.UpdatedAt,
+		Items:            mapResponses(pkg.Items, newPackageItemResponse),
+	}
+	if pkg.Carton != nil {
+		carton := newCartonResponse(*pkg.Carton)
+		response.Carton = &carton
+	}
+	return response
+}
+
+// packageItemResponse is a quantity of a SKU packed in a package
+type packageItemResponse struct {
+	ID          uuid.UUID    `json:"id"`
+	PackageID   uuid.UUID    `json:"package_id"`
+	OrderLineID uuid.UUID    `json:"order_line_id"`
+	SkuID       uuid.UUID    `json:"sku_id"`
+	Qty         int          `json:"qty"`
+	Serials     []string     `json:"serials,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Sku         *skuResponse `json:"sku,omitempty"`
+}
+
+func newPackageItemResponse(item domain.PackageItem) packageItemResponse {
+	return packageItemResponse{
+		ID:          item.ID,
+		PackageID:   item.PackageID,
+		OrderLineID: item.OrderLineID,
+		SkuID:       item.SkuID,
+		Qty:         item.Qty,
+		Serials:     item.Serials,
+		CreatedAt:   item.CreatedAt,
+		Sku:         skuRef(item.Sku),
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// suggestPutawayRequest is the body of POST /putaway/suggest
+type suggestPutawayRequest struct {
+	SkuID uuid.UUID `json:"sku_id" binding:"required"`
+	HubID uuid.UUID `json:"hub_id" binding:"required"`
+	Qty   int       `json:"qty" binding:"gt=0"`
+}
+
+// Suggest bins for received stock without creating tasks
+func (c *Controller) SuggestPutaway() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request suggestPutawayRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		plan, err := c.service.SuggestPutaway(ctx, request.SkuID, request.HubID, request.Qty)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Putaway suggested successfully", plan)
+	}
+}
+
+// createPutawayTasksRequest is the body of POST /putaway
+type createPutawayTasksRequest struct {
+	SkuID          uuid.UUID  `json:"sku_id" binding:"required"`
+	HubID          uuid.UUID  `json:"hub_id" binding:"required"`
+	FromLocationID uuid.UUID  `json:"from_location_id" binding:"required"`
+	ToLocationID   *uuid.UUID `json:"to_location_id"`
+	Qty            int        `json:"qty" binding:"gt=0"`
+}
+
+// Create putaway tasks for stock on a dock, into suggested bins unless to_location_id is given
+func (c *Controller) CreatePutawayTasks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createPutawayTasksRequest
+
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		tasks, err := c.service.CreatePutawayTasks(ctx, request.SkuID, request.HubID, request.FromLocationID, request.ToLocationID, request.Qty)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/hub/"+request.HubID.String()+"/putaway", "Putaway tasks created successfully", mapResponses(tasks, newPutawayTaskResponse))
+	}
+}
+
+// List the putaway tasks of a hub, ?status= filters them
+func (c *Controller) GetPutawayTasks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		tasks, err := c.service.FetchPutawayTasks(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Putaway tasks fetched successfully", mapResponses(tasks, newPutawayTaskResponse))
+	}
+}
+
+// confirmPutawayTaskRequest is the body of POST /putaway/:id/confirm
+type confirmPutawayTaskRequest struct {
+	ToLocationID *uuid.UUID `json:"to_location_id"`
+}
+
+// Confirm a putaway task, optionally into a different bin than suggested
+func (c *Controller) ConfirmPutawayTask() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		taskID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid putaway task ID format")
+			return
+		}
+
+		var request confirmPutawayTaskRequest
+		if ctx.Request.ContentLength > 0 {
+			if err := ctx.ShouldBindJSON(&request); err != nil {
+				errorResponse(ctx, bindError(err))
+				return
+			}
+		}
+
+		err = c.service.ConfirmPutawayTask(ctx, taskID, request.ToLocationID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Putaway task confirmed successfully", nil)
+	}
+}
+
+// putawayTaskResponse is a move of received stock from a dock into a bin
+type putawayTaskResponse struct {
+	ID             uuid.UUID    `json:"id"`
+	HubID          uuid.UUID    `json:"hub_id"`
+	SkuID          uuid.UUID    `json:"sku_id"`
+	FromLocationID uuid.UUID    `json:"from_location_id"`
+	ToLocationID   uuid.UUID    `json:"to_location_id"`
+	Qty            int          `json:"qty"`
+	Rule           string       `json:"rule"`
+	Status         string       `json:"status"`
+	CompletedAt    *time.Time   `json:"completed_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Sku            *skuResponse `json:"sku,omitempty"`
+}
+
+func newPutawayTaskResponse(task domain.PutawayTask) putawayTaskResponse {
+	return putawayTaskResponse{
+		ID:             task.ID,
+		HubID:          task.HubID,
+		SkuID:          task.SkuID,
+		FromLocationID: task.FromLocationID,
+		ToLocationID:   task.ToLocationID,
+		Qty:            task.Qty,
+		Rule:           task.Rule,
+		Status:         task.Status,
+		CompletedAt:    task.CompletedAt,
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+		Sku:            skuRef(task.Sku),
+	}
+}
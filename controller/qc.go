@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createQcRuleRequest is the body of POST /qc-rule
+type createQcRuleRequest struct {
+	SellerID    *uuid.UUID `json:"seller_id"`
+	Category    string     `json:"category" binding:"max=100"`
+	SampleSize  int        `json:"sample_size" binding:"gte=0"`
+	SamplePct   int        `json:"sample_pct" binding:"gte=0,lte=100"`
+	MaxFailures int        `json:"max_failures" binding:"gte=0"`
+	Checklist   []string   `json:"checklist"`
+}
+
+// POST API to create a QC rule sampling received stock of a seller, a SKU category or both
+func (c *Controller) CreateQcRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createQcRuleRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		rule, err := c.service.CreateQcRule(ctx, domain.QcRule{
+			SellerID:    request.SellerID,
+			Category:    request.Category,
+			SampleSize:  request.SampleSize,
+			SamplePct:   request.SamplePct,
+			MaxFailures: request.MaxFailures,
+			Checklist:   request.Checklist,
+			Active:      true,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/qc-rule/"+rule.ID.String(), "QC rule created successfully", newQcRuleResponse(rule))
+	}
+}
+
+func (c *Controller) GetQcRuleByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid QC rule ID format")
+			return
+		}
+		rule, err := c.service.FetchQcRuleByID(ctx, ruleID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC rule fetched successfully", newQcRuleResponse(rule))
+	}
+}
+
+// GET API to list QC rules, optionally of one seller
+func (c *Controller) GetQcRules() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sellerID, err := parseOptionalUUID(ctx.Query("seller_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid seller ID format")
+			return
+		}
+
+		rules, err := c.service.FetchQcRules(ctx, sellerID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC rules fetched successfully", mapResponses(rules, newQcRuleResponse))
+	}
+}
+
+// updateQcRuleRequest is the body of PUT /qc-rule/:id
+type updateQcRuleRequest struct {
+	SampleSize  int      `json:"sample_size" binding:"gte=0"`
+	SamplePct   int      `json:"sample_pct" binding:"gte=0,lte=100"`
+	MaxFailures int      `json:"max_failures" binding:"gte=0"`
+	Checklist   []string `json:"checklist"`
+	Active      *bool    `json:"active"`
+}
+
+// PUT API to replace the sampling and checklist of a QC rule, or deactivate it
+func (c *Controller) UpdateQcRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ruleID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid QC rule ID format")
+			return
+		}
+
+		var request updateQcRuleRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		active := request.Active == nil || *request.Active
+
+		rule, err := c.service.UpdateQcRule(ctx, domain.QcRule{
+			ID:          ruleID,
+			SampleSize:  request.SampleSize,
+			SamplePct:   request.SamplePct,
+			MaxFailures: request.MaxFailures,
+			Checklist:   request.Checklist,
+			Active:      active,
+		})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC rule updated successfully", newQcRuleResponse(rule))
+	}
+}
+
+func (c *Controller) GetQcInspectionByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		inspectionID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid QC inspection ID format")
+			return
+		}
+		inspection, err := c.service.FetchQcInspectionByID(ctx, inspectionID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC inspection fetched successfully", newQcInspectionResponse(inspection))
+	}
+}
+
+// GET API to list the QC inspections of a hub, optionally by status
+func (c *Controller) GetQcInspections() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		inspections, err := c.service.FetchQcInspections(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC inspections fetched successfully", mapResponses(inspections, newQcInspectionResponse))
+	}
+}
+
+// recordQcResultsRequest is the body of POST /qc/:id/result
+type recordQcResultsRequest struct {
+	Results []struct {
+		Qty          int      `json:"qty" binding:"gt=0"`
+		Serial       string   `json:"serial"`
+		Passed       bool     `json:"passed"`
+		FailedChecks []string `json:"failed_checks"`
+		Note         string   `json:"note" binding:"max=500"`
+	} `json:"results" binding:"required,min=1,dive"`
+}
+
+// POST API to record pass or fail for inspected units, one serial at a time or
+// several units of a sample alike
+func (c *Controller) RecordQcResults() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		inspectionID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid QC inspection ID format")
+			return
+		}
+
+		var request recordQcResultsRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		results := make([]domain.QcResult, 0, len(request.Results))
+		for _, result := range request.Results {
+			results = append(results, domain.QcResult{
+				Qty:          result.Qty,
+				Serial:       result.Serial,
+				Passed:       result.Passed,
+				FailedChecks: result.FailedChecks,
+				Note:         result.Note,
+			})
+		}
+
+		inspection, err := c.service.RecordQcResults(ctx, inspectionID, results)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "QC results recorded successfully", newQcInspectionResponse(inspection))
+	}
+}
+
+// qcRuleResponse is a rule sampling received stock of a seller, a SKU category or both
+type qcRuleResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	SellerID    *uuid.UUID `json:"seller_id,omitempty"`
+	Category    string     `json:"category"`
+	SampleSize  int        `json:"sample_size"`
+	SamplePct   int        `json:"sample_pct"`
+	MaxFailures int        `json:"max_failures"`
+	Checklist   []string   `json:"checklist"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func newQcRuleResponse(rule domain.QcRule) qcRuleResponse {
+	return qcRuleResponse{
+		ID:          rule.ID,
+		SellerID:    rule.SellerID,
+		Category:    rule.Category,
+		SampleSize:  rule.SampleSize,
+		SamplePct:   rule.SamplePct,
+		MaxFailures: rule.MaxFailures,
+		Checklist:   rule.Checklist,
+		Active:      rule.Active,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+}
+
+// qcInspectionResponse is an inspection of received stock with its recorded results
+type qcInspectionResponse struct {
+	ID           uuid.UUID          `json:"id"`
+	HubID        uuid.UUID          `json:"hub_id"`
+	SkuID        uuid.UUID          `json:"sku_id"`
+	RuleID       uuid.UUID          `json:"rule_id"`
+	Source       string             `json:"source"`
+	RefID        *uuid.UUID         `json:"ref_id,omitempty"`
+	Status       string             `json:"status"`
+	Qty          int                `json:"qty"`
+	SampleSize   int                `json:"sample_size"`
+	MaxFailures  int                `json:"max_failures"`
+	Checklist    []string           `json:"checklist"`
+	Serials      []string           `json:"serials,omitempty"`
+	InspectedQty int                `json:"inspected_qty"`
+	FailedQty    int                `json:"failed_qty"`
+	CompletedAt  *time.Time         `json:"completed_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	Results      []qcResultResponse `json:"results,omitempty"`
+}
+
+func newQcInspectionResponse(inspection domain.QcInspection) qcInspectionResponse {
+	response := qcInspectionResponse{
+		ID:           inspection.ID,
+		HubID:        inspection.HubID,
+		SkuID:        inspection.SkuID,
+		RuleID:       inspection.RuleID,
+		Source:       inspection.Source,
+		RefID:        inspection.RefID,
+		Status:       inspection.Status,
+		Qty:          inspection.Qty,
+		SampleSize:   inspection.SampleSize,
+		MaxFailures:  inspection.MaxFailures,
+		Checklist:    inspection.Checklist,
+		Serials:      inspection.Serials,
+		InspectedQty: inspection.InspectedQty,
+		FailedQty:    inspection.FailedQty,
+		CompletedAt:  inspection.CompletedAt,
+		CreatedAt:    inspection.CreatedAt,
+		UpdatedAt:    inspection.UpdatedAt,
+	}
+	if len(inspection.Results) > 0 {
+		response.Results = mapResponses(inspection.Results, newQcResultResponse)
+	}
+	return response
+}
+
+// qcResultResponse is the outcome of inspecting units of a QC sample
+type qcResultResponse struct {
+	ID           uuid.UUID `json:"id"`
+	InspectionID uuid.UUID `json:"inspection_id"`
+	Qty          int       `json:"qty"`
+	Serial       string    `json:"serial,omitempty"`
+	Passed       bool      `json:"passed"`
+	FailedChecks []string  `json:"failed_checks,omitempty"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newQcResultResponse(result domain.QcResult) qcResultResponse {
+	return qcResultResponse{
+		ID:           result.ID,
+		InspectionID: result.InspectionID,
+		Qty:          result.Qty,
+		Serial:       result.Serial,
+		Passed:       result.Passed,
+		FailedChecks: result.FailedChecks,
+		Note:         result.Note,
+		CreatedAt:    result.CreatedAt,
+	}
+}
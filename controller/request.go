@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"wms/domain"
+)
+
+// createHubRequest is the body of POST /hub
+type createHubRequest struct {
+	TenantID uuid.UUID `json:"tenant_id" binding:"required"`
+	Name     string    `json:"name" binding:"required,max=100"`
+	Code     string    `json:"code" binding:"required,max=20"`
+	Address  string    `json:"address" binding:"required,max=255"`
+	City     *string   `json:"city" binding:"omitempty,max=100"`
+	State    *string   `json:"state" binding:"omitempty,max=100"`
+	Country  *string   `json:"country" binding:"omitempty,max=100"`
+	Pincode  *string   `json:"pincode" binding:"omitempty,max=20"`
+	Location *string   `json:"location" binding:"omitempty,max=30"`
+}
+
+func (r createHubRequest) toHub() domain.Hub {
+	return domain.Hub{
+		TenantID: r.TenantID,
+		Name:     r.Name,
+		Code:     r.Code,
+		Address:  r.Address,
+		City:     r.City,
+		State:    r.State,
+		Country:  r.Country,
+		Pincode:  r.Pincode,
+		Location: r.Location,
+	}
+}
+
+// createSkuRequest is the body of POST /sku. Dimensions are in centimetres, the weight
+// in kilograms.
+type createSkuRequest struct {
+	SellerID    uuid.UUID          `json:"seller_id" binding:"required"`
+	Name        string             `json:"name" binding:"required,max=100"`
+	Code        string             `json:"code" binding:"required,max=50"`
+	Description string             `json:"description" binding:"max=500"`
+	Category    string             `json:"category" binding:"max=100"`
+	Subcategory string             `json:"subcategory" binding:"max=100"`
+	Brand       string             `json:"brand" binding:"max=100"`
+	Model       string             `json:"model" binding:"max=100"`
+	UOM         string             `json:"uom" binding:"required,max=20"`
+	Weight      float64            `json:"weight" binding:"gte=0"`
+	Dimensions  *dimensionsRequest `json:"dimensions"`
+	Serialized  bool               `json:"serialized"`
+}
+
+type dimensionsRequest struct {
+	Length float64 `json:"length" binding:"gte=0"`
+	Width  float64 `json:"width" binding:"gte=0"`
+	Height float64 `json:"height" binding:"gte=0"`
+}
+
+func (r createSkuRequest) toSku() domain.SKU {
+	sku := domain.SKU{
+		SellerID:    r.SellerID,
+		Name:        r.Name,
+		Code:        r.Code,
+		Description: r.Description,
+		Category:    r.Category,
+		Subcategory: r.Subcategory,
+		Brand:       r.Brand,
+		Model:       r.Model,
+		UOM:         r.UOM,
+		Weight:      r.Weight,
+		Serialized:  r.Serialized,
+	}
+	if r.Dimensions != nil {
+		sku.Dimensions, _ = json.Marshal(domain.Dimensions(*r.Dimensions))
+	}
+	return sku
+}
+
+// createLocationRequest is the body of POST /hub/:id/location. The type defaults to the
+// parent's, or reserve for a zone.
+type createLocationRequest struct {
+	ParentID   *uuid.UUID `json:"parent_id"`
+	Code       string     `json:"code" binding:"required,max=50"`
+	Level      string     `json:"level" binding:"required,location_level"`
+	Type       string     `json:"type" binding:"omitempty,location_type"`
+	MaxUnits   int        `json:"max_units" binding:"gte=0"`
+	MaxWeight  float64    `json:"max_weight" binding:"gte=0"`
+	MaxVolume  float64    `json:"max_volume" binding:"gte=0"`
+	Category   string     `json:"category" binding:"max=100"`
+	Sequence   int        `json:"sequence" binding:"gte=0"`
+	ShelfLevel int        `json:"shelf_level" binding:"gte=0"`
+}
+
+func (r createLocationRequest) toLocation(hubID uuid.UUID) domain.Location {
+	return domain.Location{
+		HubID:      hubID,
+		ParentID:   r.ParentID,
+		Code:       r.Code,
+		Level:      r.Level,
+		Type:       r.Type,
+		MaxUnits:   r.MaxUnits,
+		MaxWeight:  r.MaxWeight,
+		MaxVolume:  r.MaxVolume,
+		Category:   r.Category,
+		Sequence:   r.Sequence,
+		ShelfLevel: r.ShelfLevel,
+	}
+}
+
+// createCartonRequest is the body of POST /hub/:id/carton, inner dimensions in
+// centimetres and weights in kilograms
+type createCartonRequest struct {
+	Code        string  `json:"code" binding:"required,max=20"`
+	InnerLength float64 `json:"inner_length" binding:"gt=0"`
+	InnerWidth  float64 `json:"inner_width" binding:"gt=0"`
+	InnerHeight float64 `json:"inner_height" binding:"gt=0"`
+	TareWeight  float64 `json:"tare_weight" binding:"gte=0"`
+	MaxWeight   float64 `json:"max_weight" binding:"gte=0"`
+}
+
+func (r createCartonRequest) toCarton(hubID uuid.UUID) domain.Carton {
+	return domain.Carton{
+		HubID:       hubID,
+		Code:        r.Code,
+		InnerLength: r.InnerLength,
+		InnerWidth:  r.InnerWidth,
+		InnerHeight: r.InnerHeight,
+		TareWeight:  r.TareWeight,
+		MaxWeight:   r.MaxWeight,
+	}
+}
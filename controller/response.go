@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+	"wms/domain"
+)
+
+// hubResponse is a hub as the API returns it, without the tenant it belongs to
+type hubResponse struct {
+	ID        uuid.UUID `json:"id"`
+	TenantID  uuid.UUID `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Address   string    `json:"address"`
+	City      *string   `json:"city,omitempty"`
+	State     *string   `json:"state,omitempty"`
+	Country   *string   `json:"country,omitempty"`
+	Pincode   *string   `json:"pincode,omitempty"`
+	Location  *string   `json:"location,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newHubResponse(hub domain.Hub) hubResponse {
+	return hubResponse{
+		ID:        hub.ID,
+		TenantID:  hub.TenantID,
+		Name:      hub.Name,
+		Code:      hub.Code,
+		Address:   hub.Address,
+		City:      hub.City,
+		State:     hub.State,
+		Country:   hub.Country,
+		Pincode:   hub.Pincode,
+		Location:  hub.Location,
+		CreatedAt: hub.CreatedAt,
+		UpdatedAt: hub.UpdatedAt,
+	}
+}
+
+func newHubResponses(hubs []domain.Hub) []hubResponse {
+	responses := make([]hubResponse, len(hubs))
+	for i, hub := range hubs {
+		responses[i] = newHubResponse(hub)
+	}
+	return responses
+}
+
+// skuResponse is a SKU as the API returns it, without the seller it belongs to
+type skuResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	SellerID    uuid.UUID       `json:"seller_id"`
+	Name        string          `json:"name"`
+	Code        string          `json:"code"`
+	Description string          `json:"description"`
+	Category    string          `json:"category"`
+	Subcategory string          `json:"subcategory"`
+	Brand       string          `json:"brand"`
+	Model       string          `json:"model"`
+	UOM         string          `json:"uom"`
+	Weight      float64         `json:"weight"`
+	Dimensions  json.RawMessage `json:"dimensions,omitempty"`
+	Serialized  bool            `json:"serialized"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func newSkuResponse(sku domain.SKU) skuResponse {
+	response := skuResponse{
+		ID:          sku.ID,
+		SellerID:    sku.SellerID,
+		Name:        sku.Name,
+		Code:        sku.Code,
+		Description: sku.Description,
+		Category:    sku.Category,
+		Subcategory: sku.Subcategory,
+		Brand:       sku.Brand,
+		Model:       sku.Model,
+		UOM:         sku.UOM,
+		Weight:      sku.Weight,
+		Serialized:  sku.Serialized,
+		CreatedAt:   sku.CreatedAt,
+		UpdatedAt:   sku.UpdatedAt,
+	}
+	if len(sku.Dimensions) > 0 && string(sku.Dimensions) != "null" {
+		response.Dimensions = json.RawMessage(sku.Dimensions)
+	}
+	return response
+}
+
+func newSkuResponses(skus []domain.SKU) []skuResponse {
+	responses := make([]skuResponse, len(skus))
+	for i, sku := range skus {
+		responses[i] = newSkuResponse(sku)
+	}
+	return responses
+}
+
+// inventoryResponse is the stock of a SKU at a hub, in its base UOM
+type inventoryResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	SkuID         uuid.UUID  `json:"sku_id"`
+	HubID         uuid.UUID  `json:"hub_id"`
+	AvailableQty  int        `json:"available_qty"`
+	AllocatedQty  int        `json:"allocated_qty"`
+	DamagedQty    int        `json:"damaged_qty"`
+	QuarantineQty int        `json:"quarantine_qty"`
+	Zone          string     `json:"zone"`
+	Rack          string     `json:"rack"`
+	Bin           string     `json:"bin"`
+	MinThreshold  int        `json:"min_threshold"`
+	MaxThreshold  int        `json:"max_threshold"`
+	LastCountedAt *time.Time `json:"last_counted_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newInventoryResponse(inventory domain.Inventory) inventoryResponse {
+	return inventoryResponse{
+		ID:            inventory.ID,
+		SkuID:         inventory.SkuID,
+		HubID:         inventory.HubID,
+		AvailableQty:  inventory.AvailableQty,
+		AllocatedQty:  inventory.AllocatedQty,
+		DamagedQty:    inventory.DamagedQty,
+		QuarantineQty: inventory.QuarantineQty,
+		Zone:          inventory.Zone,
+		Rack:          inventory.Rack,
+		Bin:           inventory.Bin,
+		MinThreshold:  inventory.MinThreshold,
+		MaxThreshold:  inventory.MaxThreshold,
+		LastCountedAt: inventory.LastCountedAt,
+		CreatedAt:     inventory.CreatedAt,
+		UpdatedAt:     inventory.UpdatedAt,
+	}
+}
+
+// inventoryInUOMResponse is the stock of a SKU at a hub with the quantities in another UOM
+type inventoryInUOMResponse struct {
+	inventoryResponse
+	UOM           string  `json:"uom"`
+	AvailableQty  float64 `json:"available_qty"`
+	AllocatedQty  float64 `json:"allocated_qty"`
+	DamagedQty    float64 `json:"damaged_qty"`
+	QuarantineQty float64 `json:"quarantine_qty"`
+}
+
+func newInventoryInUOMResponse(inventory domain.InventoryInUOM) inventoryInUOMResponse {
+	return inventoryInUOMResponse{
+		inventoryResponse: newInventoryResponse(inventory.Inventory),
+		UOM:               inventory.UOM,
+		AvailableQty:      inventory.AvailableQty,
+		AllocatedQty:      inventory.AllocatedQty,
+		DamagedQty:        inventory.DamagedQty,
+		QuarantineQty:     inventory.QuarantineQty,
+	}
+}
+
+// skuRef is a SKU embedded in another response, nil when it was not loaded
+func skuRef(sku *domain.SKU) *skuResponse {
+	if sku == nil {
+		return nil
+	}
+	response := newSkuResponse(*sku)
+	return &response
+}
+
+// mapResponses converts a list of models to their responses
+func mapResponses[M, R any](models []M, convert func(M) R) []R {
+	responses := make([]R, len(models))
+	for i, model := range models {
+		responses[i] = convert(model)
+	}
+	return responses
+}
@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// createReturnRequest is the body of POST /return
+type createReturnRequest struct {
+	OrderID uuid.UUID `json:"order_id" binding:"required"`
+	RmaNo   string    `json:"rma_no" binding:"required,max=50"`
+	Lines   []struct {
+		OrderLineID uuid.UUID `json:"order_line_id" binding:"required"`
+		Qty         int       `json:"qty" binding:"gt=0"`
+		Reason      string    `json:"reason" binding:"required,return_reason"`
+	} `json:"lines" binding:"required,min=1,dive"`
+}
+
+// POST API to authorise the return of units of a shipped order
+func (c *Controller) CreateReturn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createReturnRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		ret := domain.Return{OrderID: request.OrderID, RmaNo: request.RmaNo}
+		for _, line := range request.Lines {
+			ret.Lines = append(ret.Lines, domain.ReturnLine{
+				OrderLineID: line.OrderLineID,
+				Qty:         line.Qty,
+				Reason:      line.Reason,
+			})
+		}
+
+		ret, err := c.service.CreateReturn(ctx, ret)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/return/"+ret.ID.String(), "Return authorised successfully", newReturnResponse(ret))
+	}
+}
+
+func (c *Controller) GetReturnByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		returnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid return ID format")
+			return
+		}
+		ret, err := c.service.FetchReturnByID(ctx, returnID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Return fetched successfully", newReturnResponse(ret))
+	}
+}
+
+// GET API to list the returns of a hub, optionally by status
+func (c *Controller) GetReturns() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		returns, err := c.service.FetchReturns(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Returns fetched successfully", mapResponses(returns, newReturnResponse))
+	}
+}
+
+// receiveReturnRequest is the body of POST /return/:id/receive
+type receiveReturnRequest struct {
+	HubID uuid.UUID `json:"hub_id" binding:"required"`
+	Lines []struct {
+		ReturnLineID uuid.UUID `json:"return_line_id" binding:"required"`
+		ReceivedQty  int       `json:"received_qty" binding:"gte=0"`
+	} `json:"lines" binding:"dive"`
+}
+
+// POST API to receive a return at a hub. Lines default to their authorised quantity.
+func (c *Controller) ReceiveReturn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		returnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid return ID format")
+			return
+		}
+
+		var request receiveReturnRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		receivedQty := make(map[uuid.UUID]int, len(request.Lines))
+		for _, line := range request.Lines {
+			receivedQty[line.ReturnLineID] += line.ReceivedQty
+		}
+
+		ret, err := c.service.ReceiveReturn(ctx, returnID, request.HubID, receivedQty)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Return received successfully", newReturnResponse(ret))
+	}
+}
+
+// gradeReturnRequest is the body of POST /return/:id/grade
+type gradeReturnRequest struct {
+	Items []struct {
+		ReturnLineID uuid.UUID `json:"return_line_id" binding:"required"`
+		Grade        string    `json:"grade" binding:"required,return_grade"`
+		Qty          int       `json:"qty" binding:"gt=0"`
+		Serials      []string  `json:"serials"`
+		Note         string    `json:"note" binding:"max=500"`
+	} `json:"items" binding:"required,min=1,dive"`
+}
+
+// POST API to grade received units of a return as resellable, damaged or scrap
+func (c *Controller) GradeReturn() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		returnID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid return ID format")
+			return
+		}
+
+		var request gradeReturnRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		items := make([]domain.ReturnItem, 0, len(request.Items))
+		for _, item := range request.Items {
+			items = append(items, domain.ReturnItem{
+				ReturnLineID: item.ReturnLineID,
+				Grade:        item.Grade,
+				Qty:          item.Qty,
+				Serials:      item.Serials,
+				Note:         item.Note,
+			})
+		}
+
+		ret, err := c.service.GradeReturn(ctx, returnID, items)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Return graded successfully", newReturnResponse(ret))
+	}
+}
+
+// GET API to report returned units per SKU and reason, optionally for a seller, a SKU and
+// a range of YYYY-MM-DD dates (to is inclusive)
+func (c *Controller) GetReturnReport() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sellerID, err := parseOptionalUUID(ctx.Query("seller_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid seller ID format")
+			return
+		}
+		skuID, err := parseOptionalUUID(ctx.Query("sku_id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		from, err := parseDate(ctx.Query("from"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid from format, expected YYYY-MM-DD")
+			return
+		}
+		to, err := parseDate(ctx.Query("to"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid to format, expected YYYY-MM-DD")
+			return
+		}
+		if to != nil {
+			end := to.AddDate(0, 0, 1)
+			to = &end
+		}
+
+		report, err := c.service.FetchReturnReport(ctx, sellerID, skuID, from, to)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Return report fetched successfully", report)
+	}
+}
+
+// returnResponse is a return authorised against an order with its lines
+type returnResponse struct {
+	ID            uuid.UUID            `json:"id"`
+	RmaNo         string               `json:"rma_no"`
+	OrderID       uuid.UUID            `json:"order_id"`
+	Status        string               `json:"status"`
+	ReceivedHubID *uuid.UUID           `json:"received_hub_id,omitempty"`
+	ReceivedAt    *time.Time           `json:"received_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	Lines         []returnLineResponse `json:"lines"`
+}
+
+func newReturnResponse(ret domain.Return) returnResponse {
+	return returnResponse{
+		ID:            ret.ID,
+		RmaNo:         ret.RmaNo,
+		OrderID:       ret.OrderID,
+		Status:        ret.Status,
+		ReceivedHubID: ret.ReceivedHubID,
+		ReceivedAt:    ret.ReceivedAt,
+		CreatedAt:     ret.CreatedAt,
+		UpdatedAt:     ret.UpdatedAt,
+		Lines:         mapResponses(ret.Lines, newReturnLineResponse),
+	}
+}
+
+// returnLineResponse is a returned order line with the grades given to its units
+type returnLineResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	ReturnID    uuid.UUID            `json:"return_id"`
+	OrderLineID uuid.UUID            `json:"order_line_id"`
+	SkuID       uuid.UUID            `json:"sku_id"`
+	Qty         int                  `json:"qty"`
+	ReceivedQty int                  `json:"received_qty"`
+	GradedQty   int                  `json:"graded_qty"`
+	Reason      string               `json:"reason"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Grades      []returnItemResponse `json:"grades,omitempty"`
+}
+
+func newReturnLineResponse(line domain.ReturnLine) returnLineResponse {
+	response := returnLineResponse{
+		ID:          line.ID,
+		ReturnID:    line.ReturnID,
+		OrderLineID: line.OrderLineID,
+		SkuID:       line.SkuID,
+		Qty:         line.Qty,
+		ReceivedQty: line.ReceivedQty,
+		GradedQty:   line.GradedQty,
+		Reason:      line.Reason,
+		CreatedAt:   line.CreatedAt,
+		UpdatedAt:   line.UpdatedAt,
+	}
+	if len(line.Grades) > 0 {
+		response.Grades = mapResponses(line.Grades, newReturnItemResponse)
+	}
+	return response
+}
+
+// returnItemResponse is a quantity of returned units given the same grade
+type returnItemResponse struct {
+	ID           uuid.UUID `json:"id"`
+	ReturnLineID uuid.UUID `json:"return_line_id"`
+	Grade        string    `json:"grade"`
+	Qty          int       `json:"qty"`
+	Serials      []string  `json:"serials,omitempty"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newReturnItemResponse(item domain.ReturnItem) returnItemResponse {
+	return returnItemResponse{
+		ID:           item.ID,
+		ReturnLineID: item.ReturnLineID,
+		Grade:        item.Grade,
+		Qty:          item.Qty,
+		Serials:      item.Serials,
+		Note:         item.Note,
+		CreatedAt:    item.CreatedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+// Look up a serial number with its lifecycle history
+func (c *Controller) GetSerial() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		serials, err := c.service.FetchSerials(ctx, ctx.Param("serial"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		if len(serials) == 0 {
+			standardErrorResponse(ctx, http.StatusNotFound, "Serial not found")
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Serial fetched successfully", mapResponses(serials, newSerialResponse))
+	}
+}
+
+// serialResponse is a serialized unit with its lifecycle history when it was loaded
+type serialResponse struct {
+	ID           uuid.UUID             `json:"id"`
+	SkuID        uuid.UUID             `json:"sku_id"`
+	HubID        uuid.UUID             `json:"hub_id"`
+	SerialNumber string                `json:"serial_number"`
+	LocationID   *uuid.UUID            `json:"location_id,omitempty"`
+	Location     *locationResponse     `json:"location,omitempty"`
+	Status       string                `json:"status"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	Events       []serialEventResponse `json:"events,omitempty"`
+}
+
+func newSerialResponse(serial domain.Serial) serialResponse {
+	response := serialResponse{
+		ID:           serial.ID,
+		SkuID:        serial.SkuID,
+		HubID:        serial.HubID,
+		SerialNumber: serial.SerialNumber,
+		LocationID:   serial.LocationID,
+		Location:     locationRef(serial.Location),
+		Status:       serial.Status,
+		CreatedAt:    serial.CreatedAt,
+		UpdatedAt:    serial.UpdatedAt,
+	}
+	if len(serial.Events) > 0 {
+		response.Events = mapResponses(serial.Events, newSerialEventResponse)
+	}
+	return response
+}
+
+// serialEventResponse is a step of the lifecycle of a serialized unit
+type serialEventResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	SerialID   uuid.UUID  `json:"serial_id"`
+	HubID      uuid.UUID  `json:"hub_id"`
+	Event      string     `json:"event"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newSerialEventResponse(event domain.SerialEvent) serialEventResponse {
+	return serialEventResponse{
+		ID:         event.ID,
+		SerialID:   event.SerialID,
+		HubID:      event.HubID,
+		Event:      event.Event,
+		LocationID: event.LocationID,
+		CreatedAt:  event.CreatedAt,
+	}
+}
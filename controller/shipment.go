@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"wms/domain"
+)
+
+func (c *Controller) GetShipmentByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		shipmentID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid shipment ID format")
+			return
+		}
+		shipment, err := c.service.FetchShipmentByID(ctx, shipmentID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Shipment fetched successfully", newShipmentResponse(shipment))
+	}
+}
+
+// GET API to list the shipments of a hub, optionally by status
+func (c *Controller) GetShipments() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		shipments, err := c.service.FetchShipments(ctx, hubID, ctx.Query("status"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Shipments fetched successfully", mapResponses(shipments, newShipmentResponse))
+	}
+}
+
+// assignCarrierRequest is the body of PUT /shipment/:id/carrier
+type assignCarrierRequest struct {
+	Carrier string `json:"carrier" binding:"required,max=50"`
+	Service string `json:"service" binding:"max=50"`
+}
+
+// PUT API to assign the carrier and service of a shipment
+func (c *Controller) AssignCarrier() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		shipmentID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid shipment ID format")
+			return
+		}
+
+		var request assignCarrierRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		shipment, err := c.service.AssignCarrier(ctx, shipmentID, request.Carrier, request.Service)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Carrier assigned successfully", newShipmentResponse(shipment))
+	}
+}
+
+// setTrackingNumberRequest is the body of PUT /package/:id/tracking
+type setTrackingNumberRequest struct {
+	TrackingNumber string `json:"tracking_number" binding:"required,max=50"`
+}
+
+// PUT API to capture the carrier tracking number of a package
+func (c *Controller) SetTrackingNumber() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		packageID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid package ID format")
+			return
+		}
+
+		var request setTrackingNumberRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		err = c.service.SetTrackingNumber(ctx, packageID, request.TrackingNumber)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Tracking number saved successfully", nil)
+	}
+}
+
+// createManifestRequest is the body of POST /hub/:id/manifest
+type createManifestRequest struct {
+	Carrier      string `json:"carrier" binding:"required,max=50"`
+	ManifestDate string `json:"manifest_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// POST API to create the end of day manifest of a carrier at a hub
+func (c *Controller) CreateManifest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+
+		var request createManifestRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+		manifestDate, err := parseDate(request.ManifestDate)
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid manifest_date format, expected YYYY-MM-DD")
+			return
+		}
+
+		manifest, err := c.service.CreateManifest(ctx, hubID, request.Carrier, manifestDate)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/manifest/"+manifest.ID.String(), "Manifest created successfully", newManifestResponse(manifest))
+	}
+}
+
+// GET API to list the manifests of a hub, optionally for one date
+func (c *Controller) GetManifests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		hubID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid hub ID format")
+			return
+		}
+		manifestDate, err := parseDate(ctx.Query("date"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+
+		manifests, err := c.service.FetchManifests(ctx, hubID, manifestDate)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Manifests fetched successfully", mapResponses(manifests, newManifestResponse))
+	}
+}
+
+// GET API to fetch a manifest as JSON, or as a file with ?format=csv or ?format=pdf
+func (c *Controller) GetManifestByID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		manifestID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid manifest ID format")
+			return
+		}
+
+		var (
+			data        []byte
+			contentType string
+		)
+		switch ctx.Query("format") {
+		case "", "json":
+			manifest, err := c.service.FetchManifestByID(ctx, manifestID)
+			if err != nil {
+				errorResponse(ctx, err)
+				return
+			}
+			standardSuccessResponse(ctx, http.StatusOK, "Manifest fetched successfully", newManifestResponse(manifest))
+			return
+		case "csv":
+			data, err = c.service.ManifestCSV(ctx, manifestID)
+			contentType = "text/csv"
+		case "pdf":
+			data, err = c.service.ManifestPDF(ctx, manifestID)
+			contentType = "application/pdf"
+		default:
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid format, expected json, csv or pdf")
+			return
+		}
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=manifest-%s.%s", manifestID, ctx.Query("format")))
+		ctx.Data(http.StatusOK, contentType, data)
+	}
+}
+
+// POST API to hand a shipment over to its carrier
+func (c *Controller) DispatchShipment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		shipmentID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid shipment ID format")
+			return
+		}
+		shipment, err := c.service.DispatchShipment(ctx, shipmentID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Shipment dispatched successfully", newShipmentResponse(shipment))
+	}
+}
+
+// POST API to hand every shipment of a manifest over to the carrier
+func (c *Controller) DispatchManifest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		manifestID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid manifest ID format")
+			return
+		}
+		manifest, err := c.service.DispatchManifest(ctx, manifestID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Manifest dispatched successfully", newManifestResponse(manifest))
+	}
+}
+
+// shipmentResponse is the packed shipment of an order with its packages
+type shipmentResponse struct {
+	ID           uuid.UUID         `json:"id"`
+	HubID        uuid.UUID         `json:"hub_id"`
+	OrderID      uuid.UUID         `json:"order_id"`
+	Status       string            `json:"status"`
+	TotalWeight  float64           `json:"total_weight"`
+	Carrier      string            `json:"carrier"`
+	Service      string            `json:"service"`
+	ManifestID   *uuid.UUID        `json:"manifest_id,omitempty"`
+	DispatchedAt *time.Time        `json:"dispatched_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Packages     []packageResponse `json:"packages,omitempty"`
+	Order        *orderResponse    `json:"order,omitempty"`
+}
+
+func newShipmentResponse(shipment domain.Shipment) shipmentResponse {
+	response := shipmentResponse{
+		ID:           shipment.ID,
+		HubID:        shipment.HubID,
+		OrderID:      shipment.OrderID,
+		Status:       shipment.Status,
+		TotalWeight:  shipment.TotalWeight,
+		Carrier:      shipment.Carrier,
+		Service:      shipment.Service,
+		ManifestID:   shipment.ManifestID,
+		DispatchedAt: shipment.DispatchedAt,
+		CreatedAt:    shipment.CreatedAt,
+		UpdatedAt:    shipment.UpdatedAt,
+	}
+	if len(shipment.Packages) > 0 {
+		response.Packages = mapResponses(shipment.Packages, newPackageResponse)
+	}
+	if shipment.Order != nil {
+		order := newOrderResponse(*shipment.Order)
+		response.Order = &order
+	}
+	return response
+}
+
+// manifestResponse is the end of day manifest of a carrier with its shipments
+type manifestResponse struct {
+	ID           uuid.UUID          `json:"id"`
+	HubID        uuid.UUID          `json:"hub_id"`
+	Carrier      string             `json:"carrier"`
+	ManifestDate time.Time          `json:"manifest_date"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Shipments    []shipmentResponse `json:"shipments,omitempty"`
+}
+
+func newManifestResponse(manifest domain.Manifest) manifestResponse {
+	response := manifestResponse{
+		ID:           manifest.ID,
+		HubID:        manifest.HubID,
+		Carrier:      manifest.Carrier,
+		ManifestDate: manifest.ManifestDate,
+		CreatedAt:    manifest.CreatedAt,
+	}
+	if len(manifest.Shipments) > 0 {
+		response.Shipments = mapResponses(manifest.Shipments, newShipmentResponse)
+	}
+	return response
+}
@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/omniful/go_commons/log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wms/domain"
+	"wms/pkg/auth"
+)
+
+const (
+	// streamWriteTimeout is how long a client of a stream may take to accept a message. A
+	// slower one is dropped, and resumes from the last event it got when it reconnects.
+	streamWriteTimeout = 10 * time.Second
+	// streamHeartbeat is how often an idle stream is written to, so dead clients are noticed
+	// and proxies keep the connection open
+	streamHeartbeat = 15 * time.Second
+)
+
+// GET API streaming the inventory changes of a hub, SKUs or the whole tenant as
+// server-sent events, resuming after the Last-Event-ID of a reconnecting client
+func (c *Controller) StreamInventory() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, ok := inventoryEventFilter(ctx)
+		if !ok {
+			return
+		}
+		if lastEventID := ctx.GetHeader("Last-Event-ID"); lastEventID != "" {
+			id, err := strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil || id < 0 {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid Last-Event-ID")
+				return
+			}
+			filter.AfterID = id
+		}
+
+		watchCtx, cancel := streamContext(ctx)
+		defer cancel()
+		events, errs, err := c.service.WatchInventory(watchCtx, filter)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("X-Accel-Buffering", "no")
+		ctx.Status(http.StatusOK)
+
+		rc := http.NewResponseController(ctx.Writer)
+		write := func(message string) error {
+			if err := rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+				return err
+			}
+			if _, err := ctx.Writer.WriteString(message); err != nil {
+				return err
+			}
+			return rc.Flush()
+		}
+		// The retry field has EventSource clients reconnect after a second
+		if write("retry: 1000\n\n") != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case event, open := <-events:
+				if !open {
+					logStreamEnd(ctx, <-errs)
+					return
+				}
+				data, _ := json.Marshal(event)
+				if write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, domain.EventInventoryChanged, data)) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if write(": heartbeat\n\n") != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GET API streaming the inventory changes of a hub, SKUs or the whole tenant over a
+// WebSocket, one JSON text message per event. Browsers may only open it from the origin
+// of the API or one of allowedOrigins.
+func (c *Controller) StreamInventoryWebSocket(allowedOrigins []string) gin.HandlerFunc {
+	upgrader := websocket.Upgrader{CheckOrigin: originChecker(allowedOrigins)}
+	return func(ctx *gin.Context) {
+		filter, ok := inventoryEventFilter(ctx)
+		if !ok {
+			return
+		}
+
+		watchCtx, cancel := streamContext(ctx)
+		defer cancel()
+		events, errs, err := c.service.WatchInventory(watchCtx, filter)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+		if err != nil {
+			// the upgrader has answered the handshake with an error already
+			return
+		}
+		defer conn.Close()
+
+		// Clients only send control frames. Reading handles them, and notices the client
+		// leaving or missing the pongs of two heartbeats.
+		_ = conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * streamHeartbeat))
+		})
+		go func() {
+			defer cancel()
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case event, open := <-events:
+				if !open {
+					err = <-errs
+					logStreamEnd(ctx, err)
+					closeCode := websocket.CloseNormalClosure
+					if !errors.Is(err, context.Canceled) {
+						closeCode = websocket.CloseInternalServerErr
+					}
+					_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, ""), time.Now().Add(streamWriteTimeout))
+					return
+				}
+				_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				if conn.WriteJSON(event) != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)) != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// inventoryEventFilter reads the subscription of an inventory stream from the query string,
+// answering 400 when it is malformed: hub_id, sku_ids as a comma-separated list, and
+// after_id, the event to resume after
+func inventoryEventFilter(ctx *gin.Context) (domain.InventoryEventFilter, bool) {
+	var filter domain.InventoryEventFilter
+	if value := ctx.Query("hub_id"); value != "" {
+		hubID, err := uuid.Parse(value)
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid Hub ID format")
+			return filter, false
+		}
+		filter.HubID = &hubID
+	}
+	if value := ctx.Query("sku_ids"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			skuID, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil {
+				standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+				return filter, false
+			}
+			filter.SkuIDs = append(filter.SkuIDs, skuID)
+		}
+	}
+	if value := ctx.Query("after_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid after_id")
+			return filter, false
+		}
+		filter.AfterID = id
+	}
+	return filter, true
+}
+
+// streamContext is the context a stream is watched with: the request's, which ends when
+// the client goes away, carrying the principal and request ID of the gin context
+func streamContext(ctx *gin.Context) (context.Context, context.CancelFunc) {
+	watchCtx := domain.WithRequestID(ctx.Request.Context(), domain.RequestIDFrom(ctx))
+	if principal, ok := domain.PrincipalFrom(ctx); ok {
+		watchCtx = domain.WithPrincipal(watchCtx, principal)
+	}
+	return context.WithCancel(watchCtx)
+}
+
+// logStreamEnd logs the error a watch ended on, unless it is the client leaving
+func logStreamEnd(ctx *gin.Context, err error) {
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Errorf("%s %s: %v", ctx.Request.Method, ctx.FullPath(), err)
+	}
+}
+
+// originChecker accepts WebSocket handshakes without an Origin header, which only clients
+// other than browsers make, from the host of the API itself and from the allowed origins,
+// "*" allowing any
+func originChecker(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// streamTicketResponse is a ticket opening an inventory stream, sent in the ticket query
+// parameter by clients that cannot set headers
+type streamTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// POST API issuing a short-lived ticket to open an inventory stream as the caller
+func (c *Controller) IssueStreamTicket(tickets *auth.Tickets) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := domain.PrincipalFrom(ctx)
+		ticket, expiresAt, err := tickets.Issue(principal, time.Now())
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "Stream ticket issued successfully", streamTicketResponse{Ticket: ticket, ExpiresAt: expiresAt})
+	}
+}
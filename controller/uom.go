@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"time"
+	"wms/domain"
+)
+
+// createUOMRequest is the body of POST /uom
+type createUOMRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// POST API to add a unit of measure to the catalogue
+func (c *Controller) CreateUOM() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request createUOMRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		uom, err := c.service.CreateUOM(ctx, domain.UOM{Code: request.Code, Name: request.Name})
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		createdResponse(ctx, "/uom/"+url.PathEscape(uom.Code), "UOM created successfully", newUOMResponse(uom))
+	}
+}
+
+func (c *Controller) GetUOM() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uom, err := c.service.FetchUOM(ctx, ctx.Param("code"))
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "UOM fetched successfully", newUOMResponse(uom))
+	}
+}
+
+func (c *Controller) GetUOMs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		uoms, err := c.service.FetchUOMs(ctx)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "UOMs fetched successfully", mapResponses(uoms, newUOMResponse))
+	}
+}
+
+// setSkuPacksRequest is the body of PUT /sku/:id/pack
+type setSkuPacksRequest struct {
+	Packs []struct {
+		UOM    string `json:"uom" binding:"required"`
+		Factor int    `json:"factor" binding:"gt=1"`
+	} `json:"packs" binding:"dive"`
+}
+
+// PUT API to replace the pack hierarchy of a SKU, each pack giving the base units it holds
+func (c *Controller) SetSkuPacks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		var request setSkuPacksRequest
+		if err = ctx.ShouldBindJSON(&request); err != nil {
+			errorResponse(ctx, bindError(err))
+			return
+		}
+
+		packs := make([]domain.SkuPack, 0, len(request.Packs))
+		for _, pack := range request.Packs {
+			packs = append(packs, domain.SkuPack{UOM: pack.UOM, Factor: pack.Factor})
+		}
+
+		packs, err = c.service.SetSkuPacks(ctx, skuID, packs)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "SKU packs saved successfully", mapResponses(packs, newSkuPackResponse))
+	}
+}
+
+func (c *Controller) GetSkuPacks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		skuID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			standardErrorResponse(ctx, http.StatusBadRequest, "Invalid SKU ID format")
+			return
+		}
+
+		packs, err := c.service.FetchSkuPacks(ctx, skuID)
+		if err != nil {
+			errorResponse(ctx, err)
+			return
+		}
+		standardSuccessResponse(ctx, http.StatusOK, "SKU packs fetched successfully", mapResponses(packs, newSkuPackResponse))
+	}
+}
+
+// baseQty converts a request quantity given in uom to the base UOM of the SKU, answering
+// the request with an error when the UOM isn't configured for it
+func (c *Controller) baseQty(ctx *gin.Context, skuID uuid.UUID, uom string, qty int) (int, bool) {
+	if uom == "" {
+		return qty, true
+	}
+	qty, err := c.service.ToBaseQty(ctx, skuID, uom, qty)
+	if err != nil {
+		errorResponse(ctx, err)
+		return 0, false
+	}
+	return qty, true
+}
+
+// uomResponse is a unit of measure of the catalogue
+type uomResponse struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newUOMResponse(uom domain.UOM) uomResponse {
+	return uomResponse{Code: uom.Code, Name: uom.Name, CreatedAt: uom.CreatedAt}
+}
+
+// skuPackResponse is a pack level of a SKU with the base units it holds
+type skuPackResponse struct {
+	ID        uuid.UUID `json:"id"`
+	SkuID     uuid.UUID `json:"sku_id"`
+	UOM       string    `json:"uom"`
+	Factor    int       `json:"factor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSkuPackResponse(pack domain.SkuPack) skuPackResponse {
+	return skuPackResponse{
+		ID:        pack.ID,
+		SkuID:     pack.SkuID,
+		UOM:       pack.UOM,
+		Factor:    pack.Factor,
+		CreatedAt: pack.CreatedAt,
+	}
+}
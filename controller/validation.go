@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+	"sync"
+	"wms/domain"
+)
+
+// validations are the rules request fields can name in their binding tags besides the
+// built-in ones, each backed by the check the domain itself applies
+var validations = map[string]func(string) bool{
+	"location_level":  domain.IsLocationLevel,
+	"location_type":   domain.IsLocationType,
+	"return_reason":   domain.IsReturnReason,
+	"return_grade":    domain.IsReturnGrade,
+	"role":            domain.IsRole,
+	"gs1_prefix":      domain.ValidCompanyPrefix,
+	"transition_type": isTransitionType,
+}
+
+var validationsOnce sync.Once
+
+// registerValidations makes the validator of gin's binding report fields by their JSON
+// names and know the domain rules
+func registerValidations() {
+	validationsOnce.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+		for tag, valid := range validations {
+			valid := valid
+			_ = engine.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+				return valid(fl.Field().String())
+			})
+		}
+	})
+}
+
+func isTransitionType(transitionType string) bool {
+	_, ok := domain.TransitionRules[transitionType]
+	return ok
+}
+
+// fieldErrors turns the violations the validator found into field errors, the field
+// being the JSON path from the request body, e.g. lines[0].qty
+func fieldErrors(violations validator.ValidationErrors) []domain.FieldError {
+	fields := make([]domain.FieldError, len(violations))
+	for i, violation := range violations {
+		field := violation.Namespace()
+		if dot := strings.Index(field, "."); dot >= 0 {
+			field = field[dot+1:]
+		}
+		fields[i] = domain.FieldError{Field: field, Message: violationMessage(violation)}
+	}
+	return fields
+}
+
+func violationMessage(violation validator.FieldError) string {
+	param := violation.Param()
+	length := violation.Kind() == reflect.String || violation.Kind() == reflect.Slice
+	switch violation.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required without " + param
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lte":
+		return "must be at most " + param
+	case "min":
+		if length {
+			return "must have at least " + param + " " + unit(violation.Kind())
+		}
+		return "must be at least " + param
+	case "max":
+		if length {
+			return "must have at most " + param + " " + unit(violation.Kind())
+		}
+		return "must be at most " + param
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "datetime":
+		return "must be a date formatted as YYYY-MM-DD"
+	default:
+		return "is not a valid " + strings.ReplaceAll(violation.Tag(), "_", " ")
+	}
+}
+
+func unit(kind reflect.Kind) string {
+	if kind == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
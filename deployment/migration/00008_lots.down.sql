@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_lots_updated_at ON lots;
+DROP INDEX IF EXISTS idx_lots_hub_expiry;
+DROP INDEX IF EXISTS idx_lots_sku_hub;
+DROP TABLE IF EXISTS lots;
@@ -0,0 +1,31 @@
+CREATE TABLE lots (
+                      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                      inventory_id uuid NOT NULL,
+                      sku_id uuid NOT NULL,
+                      hub_id uuid NOT NULL,
+                      lot_number varchar(50) NOT NULL,
+                      mfg_date date,
+                      expiry_date date,
+                      available_qty integer NOT NULL DEFAULT 0,
+                      allocated_qty integer NOT NULL DEFAULT 0,
+                      status varchar(20) NOT NULL DEFAULT 'active',
+                      created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                      updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                      CONSTRAINT lots_inventory_lot_number_unique UNIQUE (inventory_id, lot_number),
+                      CONSTRAINT fk_lots_inventory FOREIGN KEY (inventory_id)
+                          REFERENCES inventories(id) ON DELETE RESTRICT,
+                      CONSTRAINT fk_lots_sku FOREIGN KEY (sku_id)
+                          REFERENCES skus(id) ON DELETE RESTRICT,
+                      CONSTRAINT fk_lots_hub FOREIGN KEY (hub_id)
+                          REFERENCES hubs(id) ON DELETE RESTRICT,
+                      CONSTRAINT check_lot_qty_positive CHECK (available_qty >= 0 AND allocated_qty >= 0),
+                      CONSTRAINT check_lot_status CHECK (status IN ('active', 'blocked'))
+);
+
+CREATE INDEX idx_lots_sku_hub ON lots(sku_id, hub_id);
+CREATE INDEX idx_lots_hub_expiry ON lots(hub_id, expiry_date);
+
+CREATE TRIGGER update_lots_updated_at
+    BEFORE UPDATE ON lots
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_serial_events_serial_id;
+DROP TABLE IF EXISTS serial_events;
+DROP TRIGGER IF EXISTS update_serials_updated_at ON serials;
+DROP INDEX IF EXISTS idx_serials_serial_number;
+DROP INDEX IF EXISTS idx_serials_sku_hub_status;
+DROP TABLE IF EXISTS serials;
+ALTER TABLE skus DROP COLUMN IF EXISTS serialized;
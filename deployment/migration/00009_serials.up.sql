@@ -0,0 +1,41 @@
+ALTER TABLE skus ADD COLUMN serialized boolean NOT NULL DEFAULT false;
+
+CREATE TABLE serials (
+                         id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                         sku_id uuid NOT NULL,
+                         hub_id uuid NOT NULL,
+                         serial_number varchar(100) NOT NULL,
+                         bin varchar(50),
+                         status varchar(20) NOT NULL,
+                         created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         CONSTRAINT serials_sku_serial_number_unique UNIQUE (sku_id, serial_number),
+                         CONSTRAINT fk_serials_sku FOREIGN KEY (sku_id)
+                             REFERENCES skus(id) ON DELETE RESTRICT,
+                         CONSTRAINT fk_serials_hub FOREIGN KEY (hub_id)
+                             REFERENCES hubs(id) ON DELETE RESTRICT,
+                         CONSTRAINT check_serial_status CHECK (status IN ('available', 'allocated', 'removed'))
+);
+
+CREATE INDEX idx_serials_sku_hub_status ON serials(sku_id, hub_id, status);
+CREATE INDEX idx_serials_serial_number ON serials(serial_number);
+
+CREATE TRIGGER update_serials_updated_at
+    BEFORE UPDATE ON serials
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE serial_events (
+                               id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                               serial_id uuid NOT NULL,
+                               hub_id uuid NOT NULL,
+                               event varchar(20) NOT NULL,
+                               bin varchar(50),
+                               created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                               CONSTRAINT fk_serial_events_serial FOREIGN KEY (serial_id)
+                                   REFERENCES serials(id) ON DELETE CASCADE,
+                               CONSTRAINT fk_serial_events_hub FOREIGN KEY (hub_id)
+                                   REFERENCES hubs(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_serial_events_serial_id ON serial_events(serial_id);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_location_stocks_updated_at ON location_stocks;
+DROP INDEX IF EXISTS idx_location_stocks_sku_hub;
+DROP TABLE IF EXISTS location_stocks;
+DROP TRIGGER IF EXISTS update_locations_updated_at ON locations;
+DROP INDEX IF EXISTS idx_locations_parent_id;
+DROP INDEX IF EXISTS idx_locations_hub_id;
+DROP TABLE IF EXISTS locations;
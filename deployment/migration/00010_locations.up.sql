@@ -0,0 +1,55 @@
+CREATE TABLE locations (
+                           id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                           hub_id uuid NOT NULL,
+                           parent_id uuid,
+                           code varchar(50) NOT NULL,
+                           level varchar(10) NOT NULL,
+                           type varchar(20) NOT NULL,
+                           max_units integer NOT NULL DEFAULT 0,
+                           max_weight numeric(10,3) NOT NULL DEFAULT 0,
+                           max_volume numeric(14,3) NOT NULL DEFAULT 0,
+                           active boolean NOT NULL DEFAULT true,
+                           created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           CONSTRAINT locations_hub_code_unique UNIQUE (hub_id, code),
+                           CONSTRAINT fk_locations_hub FOREIGN KEY (hub_id)
+                               REFERENCES hubs(id) ON DELETE RESTRICT,
+                           CONSTRAINT fk_locations_parent FOREIGN KEY (parent_id)
+                               REFERENCES locations(id) ON DELETE RESTRICT,
+                           CONSTRAINT check_location_level CHECK (level IN ('zone', 'aisle', 'rack', 'bin')),
+                           CONSTRAINT check_location_type CHECK (type IN ('pick', 'reserve', 'staging', 'dock', 'quarantine')),
+                           CONSTRAINT check_location_capacity_positive CHECK (max_units >= 0 AND max_weight >= 0 AND max_volume >= 0)
+);
+
+CREATE INDEX idx_locations_hub_id ON locations(hub_id);
+CREATE INDEX idx_locations_parent_id ON locations(parent_id);
+
+CREATE TRIGGER update_locations_updated_at
+    BEFORE UPDATE ON locations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE location_stocks (
+                                 id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                 hub_id uuid NOT NULL,
+                                 sku_id uuid NOT NULL,
+                                 location_id uuid NOT NULL,
+                                 qty integer NOT NULL DEFAULT 0,
+                                 created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 CONSTRAINT location_stocks_location_sku_unique UNIQUE (location_id, sku_id),
+                                 CONSTRAINT fk_location_stocks_hub FOREIGN KEY (hub_id)
+                                     REFERENCES hubs(id) ON DELETE RESTRICT,
+                                 CONSTRAINT fk_location_stocks_sku FOREIGN KEY (sku_id)
+                                     REFERENCES skus(id) ON DELETE RESTRICT,
+                                 CONSTRAINT fk_location_stocks_location FOREIGN KEY (location_id)
+                                     REFERENCES locations(id) ON DELETE RESTRICT,
+                                 CONSTRAINT check_location_stock_qty_positive CHECK (qty >= 0)
+);
+
+CREATE INDEX idx_location_stocks_sku_hub ON location_stocks(sku_id, hub_id);
+
+CREATE TRIGGER update_location_stocks_updated_at
+    BEFORE UPDATE ON location_stocks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
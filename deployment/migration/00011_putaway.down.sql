@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS update_putaway_tasks_updated_at ON putaway_tasks;
+DROP INDEX IF EXISTS idx_putaway_tasks_hub_status;
+DROP TABLE IF EXISTS putaway_tasks;
+ALTER TABLE locations DROP COLUMN IF EXISTS shelf_level;
+ALTER TABLE locations DROP COLUMN IF EXISTS sequence;
+ALTER TABLE locations DROP COLUMN IF EXISTS category;
@@ -0,0 +1,34 @@
+ALTER TABLE locations ADD COLUMN category varchar(100);
+ALTER TABLE locations ADD COLUMN sequence integer NOT NULL DEFAULT 0;
+ALTER TABLE locations ADD COLUMN shelf_level integer NOT NULL DEFAULT 0;
+
+CREATE TABLE putaway_tasks (
+                               id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                               hub_id uuid NOT NULL,
+                               sku_id uuid NOT NULL,
+                               from_location_id uuid NOT NULL,
+                               to_location_id uuid NOT NULL,
+                               qty integer NOT NULL,
+                               rule varchar(30),
+                               status varchar(20) NOT NULL DEFAULT 'open',
+                               completed_at timestamptz,
+                               created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                               updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                               CONSTRAINT fk_putaway_tasks_hub FOREIGN KEY (hub_id)
+                                   REFERENCES hubs(id) ON DELETE RESTRICT,
+                               CONSTRAINT fk_putaway_tasks_sku FOREIGN KEY (sku_id)
+                                   REFERENCES skus(id) ON DELETE RESTRICT,
+                               CONSTRAINT fk_putaway_tasks_from_location FOREIGN KEY (from_location_id)
+                                   REFERENCES locations(id) ON DELETE RESTRICT,
+                               CONSTRAINT fk_putaway_tasks_to_location FOREIGN KEY (to_location_id)
+                                   REFERENCES locations(id) ON DELETE RESTRICT,
+                               CONSTRAINT check_putaway_qty_positive CHECK (qty > 0),
+                               CONSTRAINT check_putaway_status CHECK (status IN ('open', 'done', 'cancelled'))
+);
+
+CREATE INDEX idx_putaway_tasks_hub_status ON putaway_tasks(hub_id, status);
+
+CREATE TRIGGER update_putaway_tasks_updated_at
+    BEFORE UPDATE ON putaway_tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
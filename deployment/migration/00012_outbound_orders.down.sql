@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS update_pick_tasks_updated_at ON pick_tasks;
+DROP INDEX IF EXISTS idx_pick_tasks_location_status;
+DROP INDEX IF EXISTS idx_pick_tasks_wave_id;
+DROP TABLE IF EXISTS pick_tasks;
+DROP TRIGGER IF EXISTS update_outbound_order_lines_updated_at ON outbound_order_lines;
+DROP INDEX IF EXISTS idx_outbound_order_lines_order_id;
+DROP TABLE IF EXISTS outbound_order_lines;
+DROP TRIGGER IF EXISTS update_outbound_orders_updated_at ON outbound_orders;
+DROP INDEX IF EXISTS idx_outbound_orders_wave_id;
+DROP INDEX IF EXISTS idx_outbound_orders_hub_status;
+DROP TABLE IF EXISTS outbound_orders;
+DROP TRIGGER IF EXISTS update_waves_updated_at ON waves;
+DROP INDEX IF EXISTS idx_waves_hub_id;
+DROP TABLE IF EXISTS waves;
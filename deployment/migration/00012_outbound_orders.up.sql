@@ -0,0 +1,103 @@
+CREATE TABLE waves (
+                       id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                       hub_id uuid NOT NULL,
+                       carrier varchar(50),
+                       cutoff_at timestamptz,
+                       status varchar(20) NOT NULL,
+                       created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                       updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                       CONSTRAINT fk_waves_hub FOREIGN KEY (hub_id)
+                           REFERENCES hubs(id) ON DELETE RESTRICT,
+                       CONSTRAINT check_wave_status CHECK (status IN ('planned', 'released', 'completed'))
+);
+
+CREATE INDEX idx_waves_hub_id ON waves(hub_id);
+
+CREATE TRIGGER update_waves_updated_at
+    BEFORE UPDATE ON waves
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE outbound_orders (
+                                 id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                 hub_id uuid NOT NULL,
+                                 order_no varchar(50) NOT NULL,
+                                 carrier varchar(50),
+                                 cutoff_at timestamptz,
+                                 status varchar(20) NOT NULL,
+                                 wave_id uuid,
+                                 created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 CONSTRAINT outbound_orders_hub_order_no_unique UNIQUE (hub_id, order_no),
+                                 CONSTRAINT fk_outbound_orders_hub FOREIGN KEY (hub_id)
+                                     REFERENCES hubs(id) ON DELETE RESTRICT,
+                                 CONSTRAINT fk_outbound_orders_wave FOREIGN KEY (wave_id)
+                                     REFERENCES waves(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_outbound_orders_hub_status ON outbound_orders(hub_id, status);
+CREATE INDEX idx_outbound_orders_wave_id ON outbound_orders(wave_id);
+
+CREATE TRIGGER update_outbound_orders_updated_at
+    BEFORE UPDATE ON outbound_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE outbound_order_lines (
+                                      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                      order_id uuid NOT NULL,
+                                      sku_id uuid NOT NULL,
+                                      qty integer NOT NULL,
+                                      allocated_qty integer NOT NULL DEFAULT 0,
+                                      picked_qty integer NOT NULL DEFAULT 0,
+                                      short_qty integer NOT NULL DEFAULT 0,
+                                      created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                      updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                      CONSTRAINT fk_outbound_order_lines_order FOREIGN KEY (order_id)
+                                          REFERENCES outbound_orders(id) ON DELETE CASCADE,
+                                      CONSTRAINT fk_outbound_order_lines_sku FOREIGN KEY (sku_id)
+                                          REFERENCES skus(id) ON DELETE RESTRICT,
+                                      CONSTRAINT check_order_line_qty CHECK (qty > 0 AND allocated_qty >= 0 AND picked_qty >= 0 AND short_qty >= 0)
+);
+
+CREATE INDEX idx_outbound_order_lines_order_id ON outbound_order_lines(order_id);
+
+CREATE TRIGGER update_outbound_order_lines_updated_at
+    BEFORE UPDATE ON outbound_order_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE pick_tasks (
+                            id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                            wave_id uuid NOT NULL,
+                            order_id uuid NOT NULL,
+                            order_line_id uuid NOT NULL,
+                            sku_id uuid NOT NULL,
+                            location_id uuid NOT NULL,
+                            zone_id uuid NOT NULL,
+                            sequence integer NOT NULL DEFAULT 0,
+                            qty integer NOT NULL,
+                            picked_qty integer NOT NULL DEFAULT 0,
+                            status varchar(20) NOT NULL,
+                            picked_at timestamptz,
+                            created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                            updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                            CONSTRAINT fk_pick_tasks_wave FOREIGN KEY (wave_id)
+                                REFERENCES waves(id) ON DELETE RESTRICT,
+                            CONSTRAINT fk_pick_tasks_order FOREIGN KEY (order_id)
+                                REFERENCES outbound_orders(id) ON DELETE RESTRICT,
+                            CONSTRAINT fk_pick_tasks_order_line FOREIGN KEY (order_line_id)
+                                REFERENCES outbound_order_lines(id) ON DELETE RESTRICT,
+                            CONSTRAINT fk_pick_tasks_location FOREIGN KEY (location_id)
+                                REFERENCES locations(id) ON DELETE RESTRICT,
+                            CONSTRAINT check_pick_qty CHECK (qty > 0 AND picked_qty >= 0 AND picked_qty <= qty),
+                            CONSTRAINT check_pick_status CHECK (status IN ('open', 'picked', 'short'))
+);
+
+CREATE INDEX idx_pick_tasks_wave_id ON pick_tasks(wave_id);
+CREATE INDEX idx_pick_tasks_location_status ON pick_tasks(location_id, status);
+
+CREATE TRIGGER update_pick_tasks_updated_at
+    BEFORE UPDATE ON pick_tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
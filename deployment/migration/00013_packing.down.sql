@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_package_items_package_id;
+DROP TABLE IF EXISTS package_items;
+DROP TRIGGER IF EXISTS update_packages_updated_at ON packages;
+DROP INDEX IF EXISTS idx_packages_shipment_id;
+DROP INDEX IF EXISTS idx_packages_order_id;
+DROP TABLE IF EXISTS packages;
+DROP TRIGGER IF EXISTS update_shipments_updated_at ON shipments;
+DROP INDEX IF EXISTS idx_shipments_hub_id;
+DROP TABLE IF EXISTS shipments;
+DROP TRIGGER IF EXISTS update_cartons_updated_at ON cartons;
+DROP TABLE IF EXISTS cartons;
+ALTER TABLE outbound_order_lines DROP CONSTRAINT IF EXISTS check_order_line_packed;
+ALTER TABLE outbound_order_lines DROP COLUMN IF EXISTS packed_qty;
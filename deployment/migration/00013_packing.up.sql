@@ -0,0 +1,96 @@
+ALTER TABLE outbound_order_lines ADD COLUMN packed_qty integer NOT NULL DEFAULT 0;
+ALTER TABLE outbound_order_lines ADD CONSTRAINT check_order_line_packed CHECK (packed_qty >= 0 AND packed_qty <= picked_qty);
+
+CREATE TABLE cartons (
+                         id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                         hub_id uuid NOT NULL,
+                         code varchar(20) NOT NULL,
+                         inner_length numeric(8,2) NOT NULL,
+                         inner_width numeric(8,2) NOT NULL,
+                         inner_height numeric(8,2) NOT NULL,
+                         tare_weight numeric(10,3) NOT NULL DEFAULT 0,
+                         max_weight numeric(10,3) NOT NULL DEFAULT 0,
+                         active boolean NOT NULL DEFAULT true,
+                         created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         CONSTRAINT cartons_hub_code_unique UNIQUE (hub_id, code),
+                         CONSTRAINT fk_cartons_hub FOREIGN KEY (hub_id)
+                             REFERENCES hubs(id) ON DELETE RESTRICT,
+                         CONSTRAINT check_carton_dimensions_positive CHECK (inner_length > 0 AND inner_width > 0 AND inner_height > 0),
+                         CONSTRAINT check_carton_weights_positive CHECK (tare_weight >= 0 AND max_weight >= 0)
+);
+
+CREATE TRIGGER update_cartons_updated_at
+    BEFORE UPDATE ON cartons
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE shipments (
+                           id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                           hub_id uuid NOT NULL,
+                           order_id uuid NOT NULL,
+                           status varchar(20) NOT NULL,
+                           total_weight numeric(10,3) NOT NULL DEFAULT 0,
+                           created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           CONSTRAINT shipments_order_unique UNIQUE (order_id),
+                           CONSTRAINT fk_shipments_hub FOREIGN KEY (hub_id)
+                               REFERENCES hubs(id) ON DELETE RESTRICT,
+                           CONSTRAINT fk_shipments_order FOREIGN KEY (order_id)
+                               REFERENCES outbound_orders(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_shipments_hub_id ON shipments(hub_id);
+
+CREATE TRIGGER update_shipments_updated_at
+    BEFORE UPDATE ON shipments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE packages (
+                          id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                          hub_id uuid NOT NULL,
+                          order_id uuid NOT NULL,
+                          carton_id uuid NOT NULL,
+                          shipment_id uuid,
+                          status varchar(20) NOT NULL,
+                          weight numeric(10,3) NOT NULL DEFAULT 0,
+                          volumetric_weight numeric(10,3) NOT NULL DEFAULT 0,
+                          created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          CONSTRAINT fk_packages_hub FOREIGN KEY (hub_id)
+                              REFERENCES hubs(id) ON DELETE RESTRICT,
+                          CONSTRAINT fk_packages_order FOREIGN KEY (order_id)
+                              REFERENCES outbound_orders(id) ON DELETE RESTRICT,
+                          CONSTRAINT fk_packages_carton FOREIGN KEY (carton_id)
+                              REFERENCES cartons(id) ON DELETE RESTRICT,
+                          CONSTRAINT fk_packages_shipment FOREIGN KEY (shipment_id)
+                              REFERENCES shipments(id) ON DELETE SET NULL,
+                          CONSTRAINT check_package_status CHECK (status IN ('open', 'closed'))
+);
+
+CREATE INDEX idx_packages_order_id ON packages(order_id);
+CREATE INDEX idx_packages_shipment_id ON packages(shipment_id);
+
+CREATE TRIGGER update_packages_updated_at
+    BEFORE UPDATE ON packages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE package_items (
+                               id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                               package_id uuid NOT NULL,
+                               order_line_id uuid NOT NULL,
+                               sku_id uuid NOT NULL,
+                               qty integer NOT NULL,
+                               created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                               CONSTRAINT fk_package_items_package FOREIGN KEY (package_id)
+                                   REFERENCES packages(id) ON DELETE CASCADE,
+                               CONSTRAINT fk_package_items_order_line FOREIGN KEY (order_line_id)
+                                   REFERENCES outbound_order_lines(id) ON DELETE RESTRICT,
+                               CONSTRAINT fk_package_items_sku FOREIGN KEY (sku_id)
+                                   REFERENCES skus(id) ON DELETE RESTRICT,
+                               CONSTRAINT check_package_item_qty_positive CHECK (qty > 0)
+);
+
+CREATE INDEX idx_package_items_package_id ON package_items(package_id);
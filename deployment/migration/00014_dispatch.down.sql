@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_events_hub_id;
+DROP TABLE IF EXISTS events;
+ALTER TABLE outbound_order_lines DROP COLUMN IF EXISTS shipped_qty;
+ALTER TABLE package_items DROP COLUMN IF EXISTS serials;
+ALTER TABLE packages DROP COLUMN IF EXISTS tracking_number;
+DROP INDEX IF EXISTS idx_shipments_manifest_id;
+ALTER TABLE shipments DROP CONSTRAINT IF EXISTS fk_shipments_manifest;
+ALTER TABLE shipments DROP COLUMN IF EXISTS dispatched_at;
+ALTER TABLE shipments DROP COLUMN IF EXISTS manifest_id;
+ALTER TABLE shipments DROP COLUMN IF EXISTS service;
+ALTER TABLE shipments DROP COLUMN IF EXISTS carrier;
+DROP INDEX IF EXISTS idx_manifests_hub_date;
+DROP TABLE IF EXISTS manifests;
@@ -0,0 +1,34 @@
+CREATE TABLE manifests (
+                           id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                           hub_id uuid NOT NULL,
+                           carrier varchar(50) NOT NULL,
+                           manifest_date date NOT NULL,
+                           created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           CONSTRAINT fk_manifests_hub FOREIGN KEY (hub_id)
+                               REFERENCES hubs(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_manifests_hub_date ON manifests(hub_id, manifest_date);
+
+ALTER TABLE shipments ADD COLUMN carrier varchar(50);
+ALTER TABLE shipments ADD COLUMN service varchar(50);
+ALTER TABLE shipments ADD COLUMN manifest_id uuid;
+ALTER TABLE shipments ADD CONSTRAINT fk_shipments_manifest FOREIGN KEY (manifest_id)
+    REFERENCES manifests(id) ON DELETE SET NULL;
+ALTER TABLE shipments ADD COLUMN dispatched_at timestamptz;
+CREATE INDEX idx_shipments_manifest_id ON shipments(manifest_id);
+
+ALTER TABLE packages ADD COLUMN tracking_number varchar(100);
+ALTER TABLE package_items ADD COLUMN serials text[];
+ALTER TABLE outbound_order_lines ADD COLUMN shipped_qty integer NOT NULL DEFAULT 0;
+
+CREATE TABLE events (
+                        id bigserial PRIMARY KEY,
+                        type varchar(50) NOT NULL,
+                        hub_id uuid NOT NULL,
+                        entity_id uuid NOT NULL,
+                        payload jsonb,
+                        created_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_events_hub_id ON events(hub_id, id);
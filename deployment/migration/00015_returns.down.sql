@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_ledger_entries_ref;
+DROP INDEX IF EXISTS idx_ledger_entries_sku_hub;
+DROP TABLE IF EXISTS ledger_entries;
+DROP INDEX IF EXISTS idx_return_items_return_line_id;
+DROP TABLE IF EXISTS return_items;
+DROP TRIGGER IF EXISTS update_return_lines_updated_at ON return_lines;
+DROP INDEX IF EXISTS idx_return_lines_sku_id;
+DROP INDEX IF EXISTS idx_return_lines_return_id;
+DROP TABLE IF EXISTS return_lines;
+DROP TRIGGER IF EXISTS update_returns_updated_at ON returns;
+DROP INDEX IF EXISTS idx_returns_order_id;
+DROP TABLE IF EXISTS returns;
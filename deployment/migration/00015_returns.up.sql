@@ -0,0 +1,88 @@
+CREATE TABLE returns (
+                         id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                         rma_no varchar(50) NOT NULL,
+                         order_id uuid NOT NULL,
+                         status varchar(20) NOT NULL,
+                         received_hub_id uuid,
+                         received_at timestamptz,
+                         created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                         CONSTRAINT returns_rma_no_unique UNIQUE (rma_no),
+                         CONSTRAINT fk_returns_order FOREIGN KEY (order_id)
+                             REFERENCES outbound_orders(id) ON DELETE RESTRICT,
+                         CONSTRAINT fk_returns_received_hub FOREIGN KEY (received_hub_id)
+                             REFERENCES hubs(id) ON DELETE RESTRICT,
+                         CONSTRAINT check_return_status CHECK (status IN ('authorized', 'received', 'completed'))
+);
+
+CREATE INDEX idx_returns_order_id ON returns(order_id);
+
+CREATE TRIGGER update_returns_updated_at
+    BEFORE UPDATE ON returns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE return_lines (
+                              id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                              return_id uuid NOT NULL,
+                              order_line_id uuid NOT NULL,
+                              sku_id uuid NOT NULL,
+                              qty integer NOT NULL,
+                              received_qty integer NOT NULL DEFAULT 0,
+                              graded_qty integer NOT NULL DEFAULT 0,
+                              reason varchar(50) NOT NULL,
+                              created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                              updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                              CONSTRAINT fk_return_lines_return FOREIGN KEY (return_id)
+                                  REFERENCES returns(id) ON DELETE CASCADE,
+                              CONSTRAINT fk_return_lines_order_line FOREIGN KEY (order_line_id)
+                                  REFERENCES outbound_order_lines(id) ON DELETE RESTRICT,
+                              CONSTRAINT fk_return_lines_sku FOREIGN KEY (sku_id)
+                                  REFERENCES skus(id) ON DELETE RESTRICT,
+                              CONSTRAINT check_return_line_qty CHECK (qty > 0 AND received_qty >= 0 AND received_qty <= qty),
+                              CONSTRAINT check_return_line_graded CHECK (graded_qty >= 0 AND graded_qty <= received_qty)
+);
+
+CREATE INDEX idx_return_lines_return_id ON return_lines(return_id);
+CREATE INDEX idx_return_lines_sku_id ON return_lines(sku_id);
+
+CREATE TRIGGER update_return_lines_updated_at
+    BEFORE UPDATE ON return_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE return_items (
+                              id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                              return_line_id uuid NOT NULL,
+                              grade varchar(20) NOT NULL,
+                              qty integer NOT NULL,
+                              serials text[],
+                              note varchar(255),
+                              created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                              CONSTRAINT fk_return_items_return_line FOREIGN KEY (return_line_id)
+                                  REFERENCES return_lines(id) ON DELETE CASCADE,
+                              CONSTRAINT check_return_item_grade CHECK (grade IN ('resellable', 'damaged', 'scrap')),
+                              CONSTRAINT check_return_item_qty_positive CHECK (qty > 0)
+);
+
+CREATE INDEX idx_return_items_return_line_id ON return_items(return_line_id);
+
+CREATE TABLE ledger_entries (
+                                id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                sku_id uuid NOT NULL,
+                                hub_id uuid NOT NULL,
+                                bucket varchar(20) NOT NULL,
+                                qty integer NOT NULL,
+                                reason varchar(50),
+                                ref_type varchar(20) NOT NULL,
+                                ref_id uuid NOT NULL,
+                                created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                CONSTRAINT fk_ledger_entries_sku FOREIGN KEY (sku_id)
+                                    REFERENCES skus(id) ON DELETE RESTRICT,
+                                CONSTRAINT fk_ledger_entries_hub FOREIGN KEY (hub_id)
+                                    REFERENCES hubs(id) ON DELETE RESTRICT,
+                                CONSTRAINT check_ledger_entry_qty CHECK (qty <> 0)
+);
+
+CREATE INDEX idx_ledger_entries_sku_hub ON ledger_entries(sku_id, hub_id, created_at);
+CREATE INDEX idx_ledger_entries_ref ON ledger_entries(ref_type, ref_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_stock_transitions_sku_id;
+DROP INDEX IF EXISTS idx_stock_transitions_hub_type;
+DROP TABLE IF EXISTS stock_transitions;
+ALTER TABLE serials DROP CONSTRAINT IF EXISTS check_serial_status;
+ALTER TABLE serials ADD CONSTRAINT check_serial_status
+    CHECK (status IN ('available', 'allocated', 'removed'));
+ALTER TABLE inventories DROP CONSTRAINT IF EXISTS check_qty_positive;
+ALTER TABLE inventories ADD CONSTRAINT check_qty_positive
+    CHECK (available_qty >= 0 AND allocated_qty >= 0 AND damaged_qty >= 0);
+ALTER TABLE inventories DROP COLUMN IF EXISTS quarantine_qty;
@@ -0,0 +1,36 @@
+ALTER TABLE inventories ADD COLUMN quarantine_qty integer NOT NULL DEFAULT 0;
+ALTER TABLE inventories DROP CONSTRAINT check_qty_positive;
+ALTER TABLE inventories ADD CONSTRAINT check_qty_positive
+    CHECK (available_qty >= 0 AND allocated_qty >= 0 AND damaged_qty >= 0 AND quarantine_qty >= 0);
+
+ALTER TABLE serials DROP CONSTRAINT check_serial_status;
+ALTER TABLE serials ADD CONSTRAINT check_serial_status
+    CHECK (status IN ('available', 'allocated', 'quarantined', 'damaged', 'removed'));
+
+CREATE TABLE stock_transitions (
+                                   id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                   hub_id uuid NOT NULL,
+                                   sku_id uuid NOT NULL,
+                                   type varchar(20) NOT NULL,
+                                   qty integer NOT NULL,
+                                   reason_code varchar(50) NOT NULL,
+                                   document_no varchar(50),
+                                   unit_cost numeric(12,2) NOT NULL DEFAULT 0,
+                                   total_cost numeric(14,2) NOT NULL DEFAULT 0,
+                                   seller_id uuid,
+                                   serials text[],
+                                   note varchar(255),
+                                   created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                   CONSTRAINT fk_stock_transitions_hub FOREIGN KEY (hub_id)
+                                       REFERENCES hubs(id) ON DELETE RESTRICT,
+                                   CONSTRAINT fk_stock_transitions_sku FOREIGN KEY (sku_id)
+                                       REFERENCES skus(id) ON DELETE RESTRICT,
+                                   CONSTRAINT fk_stock_transitions_seller FOREIGN KEY (seller_id)
+                                       REFERENCES sellers(id) ON DELETE RESTRICT,
+                                   CONSTRAINT check_stock_transition_type CHECK (type IN ('quarantine', 'release', 'reject', 'damage', 'repair', 'write_off', 'return_to_vendor')),
+                                   CONSTRAINT check_stock_transition_qty_positive CHECK (qty > 0),
+                                   CONSTRAINT check_stock_transition_cost CHECK (unit_cost >= 0 AND total_cost >= 0)
+);
+
+CREATE INDEX idx_stock_transitions_hub_type ON stock_transitions(hub_id, type, created_at);
+CREATE INDEX idx_stock_transitions_sku_id ON stock_transitions(sku_id);
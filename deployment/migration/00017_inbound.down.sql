@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS update_asn_lines_updated_at ON asn_lines;
+DROP INDEX IF EXISTS idx_asn_lines_asn_id;
+DROP TABLE IF EXISTS asn_lines;
+DROP TRIGGER IF EXISTS update_asns_updated_at ON asns;
+DROP INDEX IF EXISTS idx_asns_purchase_order_id;
+DROP INDEX IF EXISTS idx_asns_hub_status;
+DROP TABLE IF EXISTS asns;
+DROP TRIGGER IF EXISTS update_purchase_order_lines_updated_at ON purchase_order_lines;
+DROP TABLE IF EXISTS purchase_order_lines;
+DROP TRIGGER IF EXISTS update_purchase_orders_updated_at ON purchase_orders;
+DROP INDEX IF EXISTS idx_purchase_orders_hub_status;
+DROP TABLE IF EXISTS purchase_orders;
@@ -0,0 +1,101 @@
+CREATE TABLE purchase_orders (
+                                 id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                 seller_id uuid NOT NULL,
+                                 hub_id uuid NOT NULL,
+                                 po_no varchar(50) NOT NULL,
+                                 status varchar(20) NOT NULL,
+                                 expected_at date,
+                                 created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                 CONSTRAINT purchase_orders_seller_po_no_unique UNIQUE (seller_id, po_no),
+                                 CONSTRAINT fk_purchase_orders_seller FOREIGN KEY (seller_id)
+                                     REFERENCES sellers(id) ON DELETE RESTRICT,
+                                 CONSTRAINT fk_purchase_orders_hub FOREIGN KEY (hub_id)
+                                     REFERENCES hubs(id) ON DELETE RESTRICT,
+                                 CONSTRAINT check_purchase_order_status CHECK (status IN ('open', 'partially_received', 'received'))
+);
+
+CREATE INDEX idx_purchase_orders_hub_status ON purchase_orders(hub_id, status);
+
+CREATE TRIGGER update_purchase_orders_updated_at
+    BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE purchase_order_lines (
+                                      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                      purchase_order_id uuid NOT NULL,
+                                      sku_id uuid NOT NULL,
+                                      ordered_qty integer NOT NULL,
+                                      received_qty integer NOT NULL DEFAULT 0,
+                                      created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                      updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                      CONSTRAINT purchase_order_lines_po_sku_unique UNIQUE (purchase_order_id, sku_id),
+                                      CONSTRAINT fk_purchase_order_lines_po FOREIGN KEY (purchase_order_id)
+                                          REFERENCES purchase_orders(id) ON DELETE CASCADE,
+                                      CONSTRAINT fk_purchase_order_lines_sku FOREIGN KEY (sku_id)
+                                          REFERENCES skus(id) ON DELETE RESTRICT,
+                                      CONSTRAINT check_purchase_order_line_qty CHECK (ordered_qty > 0 AND received_qty >= 0)
+);
+
+CREATE TRIGGER update_purchase_order_lines_updated_at
+    BEFORE UPDATE ON purchase_order_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE asns (
+                      id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                      seller_id uuid NOT NULL,
+                      hub_id uuid NOT NULL,
+                      purchase_order_id uuid,
+                      asn_no varchar(50) NOT NULL,
+                      status varchar(20) NOT NULL,
+                      expected_at date,
+                      over_tolerance_pct integer NOT NULL DEFAULT 0,
+                      closed_at timestamptz,
+                      created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                      updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                      CONSTRAINT asns_seller_asn_no_unique UNIQUE (seller_id, asn_no),
+                      CONSTRAINT fk_asns_seller FOREIGN KEY (seller_id)
+                          REFERENCES sellers(id) ON DELETE RESTRICT,
+                      CONSTRAINT fk_asns_hub FOREIGN KEY (hub_id)
+                          REFERENCES hubs(id) ON DELETE RESTRICT,
+                      CONSTRAINT fk_asns_purchase_order FOREIGN KEY (purchase_order_id)
+                          REFERENCES purchase_orders(id) ON DELETE RESTRICT,
+                      CONSTRAINT check_asn_status CHECK (status IN ('expected', 'receiving', 'closed')),
+                      CONSTRAINT check_asn_over_tolerance CHECK (over_tolerance_pct >= 0 AND over_tolerance_pct <= 100)
+);
+
+CREATE INDEX idx_asns_hub_status ON asns(hub_id, status);
+CREATE INDEX idx_asns_purchase_order_id ON asns(purchase_order_id);
+
+CREATE TRIGGER update_asns_updated_at
+    BEFORE UPDATE ON asns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE asn_lines (
+                           id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                           asn_id uuid NOT NULL,
+                           sku_id uuid NOT NULL,
+                           expected_qty integer NOT NULL DEFAULT 0,
+                           received_qty integer NOT NULL DEFAULT 0,
+                           quarantined_qty integer NOT NULL DEFAULT 0,
+                           unexpected boolean NOT NULL DEFAULT false,
+                           created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           CONSTRAINT asn_lines_asn_sku_unique UNIQUE (asn_id, sku_id),
+                           CONSTRAINT fk_asn_lines_asn FOREIGN KEY (asn_id)
+                               REFERENCES asns(id) ON DELETE CASCADE,
+                           CONSTRAINT fk_asn_lines_sku FOREIGN KEY (sku_id)
+                               REFERENCES skus(id) ON DELETE RESTRICT,
+                           CONSTRAINT check_asn_line_qty CHECK (expected_qty >= 0 AND received_qty >= 0
+                               AND quarantined_qty >= 0 AND quarantined_qty <= received_qty)
+);
+
+CREATE INDEX idx_asn_lines_asn_id ON asn_lines(asn_id);
+
+CREATE TRIGGER update_asn_lines_updated_at
+    BEFORE UPDATE ON asn_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
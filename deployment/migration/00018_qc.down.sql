@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_qc_results_inspection_id;
+DROP TABLE IF EXISTS qc_results;
+DROP TRIGGER IF EXISTS update_qc_inspections_updated_at ON qc_inspections;
+DROP INDEX IF EXISTS idx_qc_inspections_hub_status;
+DROP TABLE IF EXISTS qc_inspections;
+DROP TRIGGER IF EXISTS update_qc_rules_updated_at ON qc_rules;
+DROP INDEX IF EXISTS idx_qc_rules_seller_id;
+DROP TABLE IF EXISTS qc_rules;
+ALTER TABLE lots DROP CONSTRAINT IF EXISTS check_lot_qty_positive;
+ALTER TABLE lots ADD CONSTRAINT check_lot_qty_positive CHECK (available_qty >= 0 AND allocated_qty >= 0);
+ALTER TABLE lots DROP COLUMN IF EXISTS quarantine_qty;
@@ -0,0 +1,80 @@
+ALTER TABLE lots ADD COLUMN quarantine_qty integer NOT NULL DEFAULT 0;
+ALTER TABLE lots DROP CONSTRAINT check_lot_qty_positive;
+ALTER TABLE lots ADD CONSTRAINT check_lot_qty_positive
+    CHECK (available_qty >= 0 AND allocated_qty >= 0 AND quarantine_qty >= 0);
+
+CREATE TABLE qc_rules (
+                          id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                          seller_id uuid,
+                          category varchar(100),
+                          sample_size integer NOT NULL DEFAULT 0,
+                          sample_pct integer NOT NULL DEFAULT 0,
+                          max_failures integer NOT NULL DEFAULT 0,
+                          checklist text[],
+                          active boolean NOT NULL DEFAULT true,
+                          created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          CONSTRAINT fk_qc_rules_seller FOREIGN KEY (seller_id)
+                              REFERENCES sellers(id) ON DELETE RESTRICT,
+                          CONSTRAINT check_qc_rule_scope CHECK (seller_id IS NOT NULL OR COALESCE(category, '') <> ''),
+                          CONSTRAINT check_qc_rule_sample CHECK (sample_size >= 0 AND sample_pct >= 0 AND sample_pct <= 100 AND max_failures >= 0)
+);
+
+CREATE INDEX idx_qc_rules_seller_id ON qc_rules(seller_id);
+
+CREATE TRIGGER update_qc_rules_updated_at
+    BEFORE UPDATE ON qc_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE qc_inspections (
+                                id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                hub_id uuid NOT NULL,
+                                sku_id uuid NOT NULL,
+                                rule_id uuid NOT NULL,
+                                source varchar(20) NOT NULL,
+                                ref_id uuid,
+                                status varchar(20) NOT NULL,
+                                qty integer NOT NULL,
+                                sample_size integer NOT NULL,
+                                max_failures integer NOT NULL DEFAULT 0,
+                                checklist text[],
+                                serials text[],
+                                inspected_qty integer NOT NULL DEFAULT 0,
+                                failed_qty integer NOT NULL DEFAULT 0,
+                                completed_at timestamptz,
+                                created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                CONSTRAINT fk_qc_inspections_hub FOREIGN KEY (hub_id)
+                                    REFERENCES hubs(id) ON DELETE RESTRICT,
+                                CONSTRAINT fk_qc_inspections_sku FOREIGN KEY (sku_id)
+                                    REFERENCES skus(id) ON DELETE RESTRICT,
+                                CONSTRAINT fk_qc_inspections_rule FOREIGN KEY (rule_id)
+                                    REFERENCES qc_rules(id) ON DELETE RESTRICT,
+                                CONSTRAINT check_qc_inspection_status CHECK (status IN ('pending', 'passed', 'failed')),
+                                CONSTRAINT check_qc_inspection_qty CHECK (qty > 0 AND sample_size > 0 AND sample_size <= qty
+                                    AND inspected_qty >= 0 AND failed_qty >= 0 AND failed_qty <= inspected_qty AND inspected_qty <= qty)
+);
+
+CREATE INDEX idx_qc_inspections_hub_status ON qc_inspections(hub_id, status);
+
+CREATE TRIGGER update_qc_inspections_updated_at
+    BEFORE UPDATE ON qc_inspections
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE qc_results (
+                            id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                            inspection_id uuid NOT NULL,
+                            qty integer NOT NULL DEFAULT 1,
+                            serial varchar(100),
+                            passed boolean NOT NULL,
+                            failed_checks text[],
+                            note varchar(255),
+                            created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                            CONSTRAINT fk_qc_results_inspection FOREIGN KEY (inspection_id)
+                                REFERENCES qc_inspections(id) ON DELETE CASCADE,
+                            CONSTRAINT check_qc_result_qty_positive CHECK (qty > 0)
+);
+
+CREATE INDEX idx_qc_results_inspection_id ON qc_results(inspection_id);
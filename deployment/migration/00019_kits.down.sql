@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS update_kitting_orders_updated_at ON kitting_orders;
+DROP INDEX IF EXISTS idx_kitting_orders_hub_status;
+DROP TABLE IF EXISTS kitting_orders;
+ALTER TABLE outbound_order_lines DROP CONSTRAINT IF EXISTS fk_outbound_order_lines_kit_line;
+ALTER TABLE outbound_order_lines DROP COLUMN IF EXISTS kit_line_id;
+DROP INDEX IF EXISTS idx_kit_components_component_sku_id;
+DROP TABLE IF EXISTS kit_components;
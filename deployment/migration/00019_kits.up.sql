@@ -0,0 +1,49 @@
+CREATE TABLE kit_components (
+                                id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                kit_sku_id uuid NOT NULL,
+                                component_sku_id uuid NOT NULL,
+                                qty integer NOT NULL,
+                                created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                CONSTRAINT kit_components_kit_component_unique UNIQUE (kit_sku_id, component_sku_id),
+                                CONSTRAINT fk_kit_components_kit_sku FOREIGN KEY (kit_sku_id)
+                                    REFERENCES skus(id) ON DELETE CASCADE,
+                                CONSTRAINT fk_kit_components_component_sku FOREIGN KEY (component_sku_id)
+                                    REFERENCES skus(id) ON DELETE RESTRICT,
+                                CONSTRAINT check_kit_component_qty CHECK (qty > 0),
+                                CONSTRAINT check_kit_component_not_self CHECK (kit_sku_id <> component_sku_id)
+);
+
+CREATE INDEX idx_kit_components_component_sku_id ON kit_components(component_sku_id);
+
+ALTER TABLE outbound_order_lines ADD COLUMN kit_line_id uuid;
+ALTER TABLE outbound_order_lines ADD CONSTRAINT fk_outbound_order_lines_kit_line FOREIGN KEY (kit_line_id)
+    REFERENCES outbound_order_lines(id) ON DELETE CASCADE;
+
+CREATE TABLE kitting_orders (
+                                id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                hub_id uuid NOT NULL,
+                                kit_sku_id uuid NOT NULL,
+                                qty integer NOT NULL,
+                                status varchar(20) NOT NULL,
+                                lot_number varchar(50),
+                                expiry_date date,
+                                location_id uuid,
+                                completed_at timestamptz,
+                                created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                updated_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                CONSTRAINT fk_kitting_orders_hub FOREIGN KEY (hub_id)
+                                    REFERENCES hubs(id) ON DELETE RESTRICT,
+                                CONSTRAINT fk_kitting_orders_kit_sku FOREIGN KEY (kit_sku_id)
+                                    REFERENCES skus(id) ON DELETE RESTRICT,
+                                CONSTRAINT fk_kitting_orders_location FOREIGN KEY (location_id)
+                                    REFERENCES locations(id) ON DELETE SET NULL,
+                                CONSTRAINT check_kitting_order_qty CHECK (qty > 0),
+                                CONSTRAINT check_kitting_order_status CHECK (status IN ('open', 'completed', 'cancelled'))
+);
+
+CREATE INDEX idx_kitting_orders_hub_status ON kitting_orders(hub_id, status);
+
+CREATE TRIGGER update_kitting_orders_updated_at
+    BEFORE UPDATE ON kitting_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sku_packs;
+ALTER TABLE skus DROP CONSTRAINT IF EXISTS fk_skus_uom;
+DROP TABLE IF EXISTS uoms;
@@ -0,0 +1,32 @@
+CREATE TABLE uoms (
+                      code varchar(20) PRIMARY KEY,
+                      name varchar(50) NOT NULL,
+                      created_at timestamptz DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO uoms (code, name) VALUES
+    ('EA', 'Each'),
+    ('IP', 'Inner pack'),
+    ('CS', 'Case'),
+    ('PL', 'Pallet');
+
+INSERT INTO uoms (code, name)
+SELECT DISTINCT uom, uom FROM skus
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE skus ADD CONSTRAINT fk_skus_uom FOREIGN KEY (uom)
+    REFERENCES uoms(code) ON DELETE RESTRICT;
+
+CREATE TABLE sku_packs (
+                           id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                           sku_id uuid NOT NULL,
+                           uom varchar(20) NOT NULL,
+                           factor integer NOT NULL,
+                           created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                           CONSTRAINT sku_packs_sku_uom_unique UNIQUE (sku_id, uom),
+                           CONSTRAINT fk_sku_packs_sku FOREIGN KEY (sku_id)
+                               REFERENCES skus(id) ON DELETE CASCADE,
+                           CONSTRAINT fk_sku_packs_uom FOREIGN KEY (uom)
+                               REFERENCES uoms(code) ON DELETE RESTRICT,
+                           CONSTRAINT check_sku_pack_factor CHECK (factor > 1)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_lots_lot_number;
+DROP INDEX IF EXISTS idx_barcodes_location_id;
+DROP INDEX IF EXISTS idx_barcodes_sku_id;
+DROP TABLE IF EXISTS barcodes;
@@ -0,0 +1,23 @@
+CREATE TABLE barcodes (
+                          id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                          code varchar(50) NOT NULL,
+                          type varchar(10) NOT NULL,
+                          sku_id uuid,
+                          uom varchar(20),
+                          location_id uuid,
+                          created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          CONSTRAINT barcodes_code_unique UNIQUE (code),
+                          CONSTRAINT fk_barcodes_sku FOREIGN KEY (sku_id)
+                              REFERENCES skus(id) ON DELETE CASCADE,
+                          CONSTRAINT fk_barcodes_uom FOREIGN KEY (uom)
+                              REFERENCES uoms(code) ON DELETE RESTRICT,
+                          CONSTRAINT fk_barcodes_location FOREIGN KEY (location_id)
+                              REFERENCES locations(id) ON DELETE CASCADE,
+                          CONSTRAINT check_barcode_type CHECK (type IN ('ean13', 'upca', 'gtin14', 'internal')),
+                          CONSTRAINT check_barcode_target CHECK ((sku_id IS NULL) <> (location_id IS NULL)),
+                          CONSTRAINT check_barcode_uom CHECK (uom IS NULL OR uom = '' OR sku_id IS NOT NULL)
+);
+
+CREATE INDEX idx_barcodes_sku_id ON barcodes(sku_id);
+CREATE INDEX idx_barcodes_location_id ON barcodes(location_id);
+CREATE INDEX idx_lots_lot_number ON lots(lot_number);
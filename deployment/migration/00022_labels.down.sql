@@ -0,0 +1,5 @@
+ALTER TABLE packages DROP CONSTRAINT IF EXISTS packages_sscc_unique;
+ALTER TABLE packages DROP COLUMN IF EXISTS sscc;
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS check_tenant_gs1_company_prefix;
+ALTER TABLE tenants DROP COLUMN IF EXISTS sscc_serial;
+ALTER TABLE tenants DROP COLUMN IF EXISTS gs1_company_prefix;
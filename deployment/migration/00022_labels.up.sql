@@ -0,0 +1,7 @@
+ALTER TABLE tenants ADD COLUMN gs1_company_prefix varchar(10);
+ALTER TABLE tenants ADD COLUMN sscc_serial bigint NOT NULL DEFAULT 0;
+ALTER TABLE tenants ADD CONSTRAINT check_tenant_gs1_company_prefix
+    CHECK (gs1_company_prefix IS NULL OR gs1_company_prefix ~ '^[0-9]{7,10}$');
+
+ALTER TABLE packages ADD COLUMN sscc varchar(18);
+ALTER TABLE packages ADD CONSTRAINT packages_sscc_unique UNIQUE (sscc);
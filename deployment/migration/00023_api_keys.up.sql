@@ -0,0 +1,15 @@
+CREATE TABLE api_keys (
+                          id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                          tenant_id uuid NOT NULL,
+                          name varchar(100) NOT NULL,
+                          prefix varchar(12) NOT NULL,
+                          key_hash varchar(64) NOT NULL,
+                          last_used_at timestamptz,
+                          revoked_at timestamptz,
+                          created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                          CONSTRAINT api_keys_key_hash_unique UNIQUE (key_hash),
+                          CONSTRAINT fk_api_keys_tenant FOREIGN KEY (tenant_id)
+                              REFERENCES tenants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
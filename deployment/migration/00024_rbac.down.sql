@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_role_assignments_subject;
+DROP INDEX IF EXISTS role_assignments_unique;
+DROP TABLE IF EXISTS role_assignments;
@@ -0,0 +1,18 @@
+CREATE TABLE role_assignments (
+                                  id uuid DEFAULT uuid_generate_v4() PRIMARY KEY,
+                                  tenant_id uuid NOT NULL,
+                                  subject varchar(255) NOT NULL,
+                                  role varchar(20) NOT NULL,
+                                  hub_id uuid,
+                                  created_at timestamptz DEFAULT CURRENT_TIMESTAMP,
+                                  CONSTRAINT fk_role_assignments_tenant FOREIGN KEY (tenant_id)
+                                      REFERENCES tenants(id) ON DELETE CASCADE,
+                                  CONSTRAINT fk_role_assignments_hub FOREIGN KEY (hub_id)
+                                      REFERENCES hubs(id) ON DELETE CASCADE,
+                                  CONSTRAINT check_role_assignment_role
+                                      CHECK (role IN ('admin', 'hub_manager', 'picker', 'read_only', 'integration'))
+);
+
+CREATE UNIQUE INDEX role_assignments_unique
+    ON role_assignments(tenant_id, subject, role, COALESCE(hub_id, '00000000-0000-0000-0000-000000000000'));
+CREATE INDEX idx_role_assignments_subject ON role_assignments(tenant_id, subject);
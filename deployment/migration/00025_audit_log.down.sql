@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS reject_audit_entries_change ON audit_entries;
+DROP FUNCTION IF EXISTS reject_audit_change();
+DROP INDEX IF EXISTS idx_audit_entries_entity;
+DROP INDEX IF EXISTS idx_audit_entries_tenant_id;
+DROP TABLE IF EXISTS audit_entries;
@@ -0,0 +1,32 @@
+CREATE TABLE audit_entries (
+                               seq bigserial PRIMARY KEY,
+                               tenant_id uuid,
+                               actor varchar(255) NOT NULL,
+                               action varchar(50) NOT NULL,
+                               entity varchar(50) NOT NULL,
+                               entity_id varchar(100),
+                               before json,
+                               after json,
+                               diff json,
+                               request_id varchar(100),
+                               created_at timestamptz NOT NULL,
+                               prev_hash varchar(64) NOT NULL,
+                               hash varchar(64) NOT NULL,
+                               CONSTRAINT audit_entries_hash_unique UNIQUE (hash)
+);
+
+CREATE INDEX idx_audit_entries_tenant_id ON audit_entries(tenant_id, seq);
+CREATE INDEX idx_audit_entries_entity ON audit_entries(entity, entity_id);
+
+-- the audit log is append-only
+CREATE OR REPLACE FUNCTION reject_audit_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit entries cannot be changed or deleted';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER reject_audit_entries_change
+    BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_change();
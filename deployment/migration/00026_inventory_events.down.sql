@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS record_inventories_change ON inventories;
+DROP FUNCTION IF EXISTS record_inventory_change();
+DELETE FROM events WHERE type = 'inventory.changed';
+DROP INDEX IF EXISTS idx_events_type_txid;
+ALTER TABLE events DROP COLUMN IF EXISTS txid;
@@ -0,0 +1,48 @@
+-- txid is the transaction an event was written in. Readers follow the events in
+-- (txid, id) order and only up to the oldest transaction still running: every event
+-- before that point is committed or gone for good, whatever order the ids were handed
+-- out in.
+ALTER TABLE events ADD COLUMN txid bigint NOT NULL DEFAULT txid_current();
+
+CREATE INDEX idx_events_type_txid ON events(type, txid, id);
+
+CREATE OR REPLACE FUNCTION record_inventory_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_available integer := 0;
+    old_allocated integer := 0;
+    old_damaged integer := 0;
+    old_quarantine integer := 0;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF NEW.available_qty = OLD.available_qty AND NEW.allocated_qty = OLD.allocated_qty
+            AND NEW.damaged_qty = OLD.damaged_qty AND NEW.quarantine_qty = OLD.quarantine_qty THEN
+            RETURN NEW;
+        END IF;
+        old_available := OLD.available_qty;
+        old_allocated := OLD.allocated_qty;
+        old_damaged := OLD.damaged_qty;
+        old_quarantine := OLD.quarantine_qty;
+    END IF;
+
+    INSERT INTO events (type, hub_id, entity_id, payload)
+    VALUES ('inventory.changed', NEW.hub_id, NEW.sku_id, jsonb_build_object(
+        'sku_id', NEW.sku_id,
+        'hub_id', NEW.hub_id,
+        'available_qty', NEW.available_qty,
+        'allocated_qty', NEW.allocated_qty,
+        'damaged_qty', NEW.damaged_qty,
+        'quarantine_qty', NEW.quarantine_qty,
+        'available_delta', NEW.available_qty - old_available,
+        'allocated_delta', NEW.allocated_qty - old_allocated,
+        'damaged_delta', NEW.damaged_qty - old_damaged,
+        'quarantine_delta', NEW.quarantine_qty - old_quarantine
+    ));
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_inventories_change
+    AFTER INSERT OR UPDATE ON inventories
+    FOR EACH ROW
+    EXECUTE FUNCTION record_inventory_change();
@@ -0,0 +1,10 @@
+ALTER TABLE serial_events ADD COLUMN bin varchar(50);
+UPDATE serial_events e SET bin = l.code FROM locations l WHERE l.id = e.location_id;
+ALTER TABLE serial_events DROP COLUMN IF EXISTS location_id;
+
+DROP INDEX IF EXISTS idx_serials_location_id;
+ALTER TABLE serials ADD COLUMN bin varchar(50);
+UPDATE serials s SET bin = l.code FROM locations l WHERE l.id = s.location_id;
+ALTER TABLE serials DROP COLUMN IF EXISTS location_id;
+
+-- Stock placed in the receiving docks stays there: it was on hand before as well
@@ -0,0 +1,74 @@
+-- Every unit on hand now sits in a bin. Hubs without an active dock or staging bin get the
+-- default ones new hubs are created with, _DOCK-A-1-1 and _STAGING-A-1-1 under zones of
+-- their own, then units that were never placed go to the first active dock bin of their hub.
+DO $$
+DECLARE
+    hub record;
+    bin_type text;
+    bin_code text;
+    parent uuid;
+    step record;
+BEGIN
+    FOR hub IN SELECT id, code FROM hubs LOOP
+        FOREACH bin_type IN ARRAY ARRAY['dock', 'staging'] LOOP
+            CONTINUE WHEN EXISTS (SELECT 1 FROM locations l
+                                  WHERE l.hub_id = hub.id AND l.level = 'bin' AND l.type = bin_type AND l.active);
+
+            bin_code := '_' || upper(bin_type);
+            IF EXISTS (SELECT 1 FROM locations l
+                       WHERE l.hub_id = hub.id
+                         AND l.code IN (bin_code, bin_code || '-A', bin_code || '-A-1', bin_code || '-A-1-1')) THEN
+                RAISE EXCEPTION 'hub % has no active % bin and the codes % to %-A-1-1 of a default one are taken',
+                    hub.code, bin_type, bin_code, bin_code;
+            END IF;
+
+            parent := NULL;
+            FOR step IN SELECT * FROM (VALUES (1, 'zone', ''), (2, 'aisle', '-A'), (3, 'rack', '-1'), (4, 'bin', '-1'))
+                            AS path(n, level, suffix) ORDER BY n LOOP
+                bin_code := bin_code || step.suffix;
+                INSERT INTO locations (hub_id, parent_id, code, level, type)
+                VALUES (hub.id, parent, bin_code, step.level, bin_type)
+                RETURNING id INTO parent;
+            END LOOP;
+        END LOOP;
+    END LOOP;
+END $$;
+
+CREATE TEMPORARY TABLE unplaced_stock AS
+SELECT i.hub_id, i.sku_id,
+       i.available_qty + i.allocated_qty + i.damaged_qty + i.quarantine_qty
+           - COALESCE((SELECT SUM(ls.qty) FROM location_stocks ls
+                       WHERE ls.sku_id = i.sku_id AND ls.hub_id = i.hub_id), 0) AS qty
+FROM inventories i;
+
+DELETE FROM unplaced_stock WHERE qty <= 0;
+
+INSERT INTO location_stocks (hub_id, sku_id, location_id, qty)
+SELECT u.hub_id, u.sku_id,
+       (SELECT l.id FROM locations l
+        WHERE l.hub_id = u.hub_id AND l.level = 'bin' AND l.type = 'dock' AND l.active
+        ORDER BY l.sequence, l.code LIMIT 1),
+       u.qty
+FROM unplaced_stock u
+ON CONFLICT (location_id, sku_id)
+DO UPDATE SET qty = location_stocks.qty + EXCLUDED.qty, updated_at = CURRENT_TIMESTAMP;
+
+DROP TABLE unplaced_stock;
+
+-- Serials point at the bin they sit in instead of naming it
+ALTER TABLE serials ADD COLUMN location_id uuid;
+ALTER TABLE serials ADD CONSTRAINT fk_serials_location FOREIGN KEY (location_id)
+    REFERENCES locations(id) ON DELETE RESTRICT;
+UPDATE serials s SET location_id = l.id
+FROM locations l
+WHERE l.hub_id = s.hub_id AND l.code = s.bin AND l.level = 'bin' AND s.status <> 'removed';
+ALTER TABLE serials DROP COLUMN bin;
+CREATE INDEX idx_serials_location_id ON serials(location_id);
+
+ALTER TABLE serial_events ADD COLUMN location_id uuid;
+ALTER TABLE serial_events ADD CONSTRAINT fk_serial_events_location FOREIGN KEY (location_id)
+    REFERENCES locations(id) ON DELETE RESTRICT;
+UPDATE serial_events e SET location_id = l.id
+FROM locations l
+WHERE l.hub_id = e.hub_id AND l.code = e.bin AND l.level = 'bin';
+ALTER TABLE serial_events DROP COLUMN bin;
@@ -0,0 +1 @@
+ALTER TABLE waves DROP COLUMN IF EXISTS zone_id;
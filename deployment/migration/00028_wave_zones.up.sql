@@ -0,0 +1,4 @@
+-- Waves group orders by the zone they are mostly picked from, next to carrier and cutoff
+ALTER TABLE waves ADD COLUMN zone_id uuid;
+ALTER TABLE waves ADD CONSTRAINT fk_waves_zone FOREIGN KEY (zone_id)
+    REFERENCES locations(id) ON DELETE RESTRICT;
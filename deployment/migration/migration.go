@@ -0,0 +1,32 @@
+// Package migration embeds the SQL migrations of the schema, so the binary knows the
+// schema version it was built against
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Version returns the number of the last migration, the schema version the code expects
+func Version() (uint, error) {
+	names, err := fs.Glob(files, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+	var version uint
+	for _, name := range names {
+		number, _, _ := strings.Cut(name, "_")
+		n, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s is not numbered: %w", name, err)
+		}
+		version = max(version, uint(n))
+	}
+	return version, nil
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"time"
+)
+
+// RequestIDKey is the key the ID of a request is stored under, next to its principal
+const RequestIDKey = "wms.request_id"
+
+// RequestIDFrom returns the ID the request ID middleware gave a request
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// WithRequestID places the ID of a request on a context, for transports whose contexts
+// are not gin's
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+// AuditEntry records a write made through the service layer: who made it, for which
+// tenant, what it did to which entity, and the entity before and after, with a diff of
+// the changed fields. The entries of each tenant form a hash chain: each hash covers the
+// entry and the hash of the tenant's entry before it, so editing or deleting an entry
+// breaks the chain.
+// Before, After and Diff are kept as json rather than jsonb so the hashed text is stored
+// verbatim.
+type AuditEntry struct {
+	Seq       int64          `gorm:"primaryKey;autoIncrement" json:"seq"`
+	TenantID  *uuid.UUID     `gorm:"type:uuid;index" json:"tenant_id,omitempty"`
+	Actor     string         `gorm:"type:varchar(255);not null" json:"actor"`
+	Action    string         `gorm:"type:varchar(50);not null" json:"action"`
+	Entity    string         `gorm:"type:varchar(50);not null" json:"entity"`
+	EntityID  string         `gorm:"type:varchar(100)" json:"entity_id"`
+	Before    datatypes.JSON `gorm:"type:json" json:"before,omitempty"`
+	After     datatypes.JSON `gorm:"type:json" json:"after,omitempty"`
+	Diff      datatypes.JSON `gorm:"type:json" json:"diff,omitempty"`
+	RequestID string         `gorm:"type:varchar(100)" json:"request_id,omitempty"`
+	CreatedAt time.Time      `gorm:"type:timestamptz;not null" json:"created_at"`
+	PrevHash  string         `gorm:"type:varchar(64);not null" json:"prev_hash"`
+	Hash      string         `gorm:"type:varchar(64);not null" json:"hash"`
+}
+
+// ComputeHash hashes the entry together with PrevHash. Seq is left out: it is only known
+// once the entry is stored, and the chain itself orders the entries.
+func (e AuditEntry) ComputeHash() string {
+	tenantID := ""
+	if e.TenantID != nil {
+		tenantID = e.TenantID.String()
+	}
+	fields, _ := json.Marshal([]string{
+		e.PrevHash,
+		tenantID,
+		e.Actor,
+		e.Action,
+		e.Entity,
+		e.EntityID,
+		string(e.Before),
+		string(e.After),
+		string(e.Diff),
+		e.RequestID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter narrows down the audit entries of a tenant
+type AuditFilter struct {
+	TenantID uuid.UUID
+	Actor    string
+	Action   string
+	Entity   string
+	EntityID string
+	From     *time.Time
+	To       *time.Time
+	AfterSeq int64
+	Limit    int
+}
+
+// AuditVerification is the result of checking the hash chain of a tenant's audit log
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Entities only the audit log refers to, next to the ones actions are authorized on
+const (
+	EntityTenant          = "tenant"
+	EntityAPIKey          = "api_key"
+	EntityRoleAssignment  = "role_assignment"
+	EntityUOM             = "uom"
+	EntityQcRule          = "qc_rule"
+	EntityInventory       = "inventory"
+	EntityStockTransition = "stock_transition"
+)
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// PrincipalKey is the key the authenticated principal of a request is stored under. It
+// is a string so a gin context resolves it from the keys set by the auth middleware.
+const PrincipalKey = "wms.principal"
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise
+const APIKeyPrefix = "wms_"
+
+// Principal is who made a request, the tenant it acts for and the roles it holds there
+type Principal struct {
+	Subject  string    `json:"subject"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	Method   string    `json:"method"`
+	Grants   []Grant   `json:"grants"`
+}
+
+// PrincipalFrom returns the principal placed on a context by the auth middleware
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(PrincipalKey).(Principal)
+	return principal, ok
+}
+
+// WithPrincipal places the principal of a request on a context, for transports whose
+// contexts are not gin's
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, PrincipalKey, principal)
+}
+
+// APIKey is a key a tenant's systems authenticate with. Only the SHA-256 hash of the key
+// is stored; Prefix is its first characters, enough to tell keys apart in a listing.
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	TenantID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(12);not null" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	LastUsedAt *time.Time `gorm:"type:timestamptz" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// NewAPIKey is a freshly created API key with the plain key, which is shown only once
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	BarcodeTypeEAN13    = "ean13"
+	BarcodeTypeUPCA     = "upca"
+	BarcodeTypeGTIN14   = "gtin14"
+	BarcodeTypeInternal = "internal"
+)
+
+const (
+	ScanKindSku      = "sku"
+	ScanKindPack     = "pack"
+	ScanKindLocation = "location"
+	ScanKindSerial   = "serial"
+	ScanKindLot      = "lot"
+)
+
+// barcodeLengths is the number of digits of each GS1 barcode type, check digit included
+var barcodeLengths = map[string]int{
+	BarcodeTypeEAN13:  13,
+	BarcodeTypeUPCA:   12,
+	BarcodeTypeGTIN14: 14,
+}
+
+func IsBarcodeType(barcodeType string) bool {
+	_, ok := barcodeLengths[barcodeType]
+	return ok || barcodeType == BarcodeTypeInternal
+}
+
+// ValidBarcode checks the length and GS1 check digit of EAN-13, UPC-A and GTIN-14 codes.
+// Internal codes only need to be non-empty.
+func ValidBarcode(barcodeType, code string) bool {
+	if barcodeType == BarcodeTypeInternal {
+		return code != ""
+	}
+	length, ok := barcodeLengths[barcodeType]
+	if !ok || len(code) != length {
+		return false
+	}
+
+	check, ok := GS1CheckDigit(code[:length-1])
+	return ok && code[length-1] == check
+}
+
+// GS1CheckDigit computes the mod 10 check digit GS1 codes end with. It fails on anything but digits.
+func GS1CheckDigit(digits string) (byte, bool) {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		digit := digits[len(digits)-1-i]
+		if digit < '0' || digit > '9' {
+			return 0, false
+		}
+		weight := 1
+		if i%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10), true
+}
+
+// Barcode identifies a SKU, one of its pack levels or a location when scanned. A SKU
+// barcode with a UOM marks a pack of that UOM; without one it marks a base unit.
+type Barcode struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Code       string     `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Type       string     `gorm:"type:varchar(10);not null" json:"type"`
+	SkuID      *uuid.UUID `gorm:"type:uuid;index" json:"sku_id,omitempty"`
+	UOM        string     `gorm:"type:varchar(20)" json:"uom,omitempty"`
+	LocationID *uuid.UUID `gorm:"type:uuid;index" json:"location_id,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// ScanResult is what a scanned value resolves to: a registered barcode or SKU code, a serial,
+// a lot or a location code. Serial and lot numbers can match units of several SKUs or hubs.
+type ScanResult struct {
+	Code     string    `json:"code"`
+	Kind     string    `json:"kind"`
+	Barcode  *Barcode  `json:"barcode,omitempty"`
+	Sku      *SKU      `json:"sku,omitempty"`
+	UOM      string    `json:"uom,omitempty"`
+	Factor   int       `json:"factor,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	Serials  []Serial  `json:"serials,omitempty"`
+	Lots     []Lot     `json:"lots,omitempty"`
+}
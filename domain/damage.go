@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	TransitionQuarantine     = "quarantine"
+	TransitionRelease        = "release"
+	TransitionReject         = "reject"
+	TransitionDamage         = "damage"
+	TransitionRepair         = "repair"
+	TransitionWriteOff       = "write_off"
+	TransitionReturnToVendor = "return_to_vendor"
+)
+
+// TransitionRule is where a transition takes stock from and to, and the reason codes it
+// accepts. An empty To takes the stock out of the hub.
+type TransitionRule struct {
+	From    string
+	To      string
+	Reasons []string
+}
+
+// TransitionRules are the moves allowed between the available, quarantine and damaged buckets.
+// Quarantine holds stock pending QC: not sellable, not confirmed damaged either.
+var TransitionRules = map[string]TransitionRule{
+	TransitionQuarantine: {
+		From: LedgerBucketAvailable, To: LedgerBucketQuarantine,
+		Reasons: []string{"pending_qc", "suspected_damage", "recall"},
+	},
+	TransitionRelease: {
+		From: LedgerBucketQuarantine, To: LedgerBucketAvailable,
+		Reasons: []string{"qc_passed", "recall_lifted"},
+	},
+	TransitionReject: {
+		From: LedgerBucketQuarantine, To: LedgerBucketDamaged,
+		Reasons: []string{"qc_failed", "defective", "expired"},
+	},
+	TransitionDamage: {
+		From: LedgerBucketAvailable, To: LedgerBucketDamaged,
+		Reasons: []string{"handling", "transit", "defective", "expired", "other"},
+	},
+	TransitionRepair: {
+		From: LedgerBucketDamaged, To: LedgerBucketAvailable,
+		Reasons: []string{"repaired", "repackaged", "refurbished"},
+	},
+	TransitionWriteOff: {
+		From:    LedgerBucketDamaged,
+		Reasons: []string{"beyond_repair", "expired", "destroyed", "lost", "other"},
+	},
+	TransitionReturnToVendor: {
+		From:    LedgerBucketDamaged,
+		Reasons: []string{"defective", "recall", "warranty", "other"},
+	},
+}
+
+// StockTransition is the document of units of a SKU moved between the non-sellable buckets,
+// written off or returned to their seller. Write-offs carry their cost.
+type StockTransition struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"hub_id"`
+	SkuID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"sku_id"`
+	Type       string         `gorm:"type:varchar(20);not null" json:"type"`
+	Qty        int            `gorm:"not null;check:qty > 0" json:"qty"`
+	ReasonCode string         `gorm:"type:varchar(50);not null" json:"reason_code"`
+	DocumentNo string         `gorm:"type:varchar(50)" json:"document_no"`
+	UnitCost   float64        `gorm:"type:numeric(12,2);not null;default:0" json:"unit_cost"`
+	TotalCost  float64        `gorm:"type:numeric(14,2);not null;default:0" json:"total_cost"`
+	SellerID   *uuid.UUID     `gorm:"type:uuid" json:"seller_id,omitempty"`
+	Serials    pq.StringArray `gorm:"type:text[]" json:"serials,omitempty"`
+	Note       string         `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt  time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TransitionReason tells whether a reason code is accepted for a transition type
+func TransitionReason(transitionType, reasonCode string) bool {
+	rule, ok := TransitionRules[transitionType]
+	if !ok {
+		return false
+	}
+	for _, reason := range rule.Reasons {
+		if reason == reasonCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+)
+
+// Dimensions is the shape of SKU.Dimensions, in centimetres.
+type Dimensions struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Volume in cubic centimetres
+func (d Dimensions) Volume() float64 {
+	return d.Length * d.Width * d.Height
+}
+
+// IsZero reports whether no dimensions were recorded
+func (d Dimensions) IsZero() bool {
+	return d.Length == 0 && d.Width == 0 && d.Height == 0
+}
+
+// ParseDimensions reads the dimensions of a SKU. A SKU without dimensions has zero Dimensions.
+func (s SKU) ParseDimensions() (Dimensions, error) {
+	var dimensions Dimensions
+	if len(s.Dimensions) == 0 || string(s.Dimensions) == "null" {
+		return dimensions, nil
+	}
+	if err := json.Unmarshal(s.Dimensions, &dimensions); err != nil {
+		return Dimensions{}, Invalid("malformed dimensions for SKU %s: %v", s.Code, err)
+	}
+	if dimensions.Length < 0 || dimensions.Width < 0 || dimensions.Height < 0 {
+		return Dimensions{}, Invalid("dimensions of SKU %s must be non-negative", s.Code)
+	}
+	return dimensions, nil
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of the kinds of errors the API answers with. They are part of the API: clients
+// match on them rather than on messages.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeUnauthenticated   = "unauthenticated"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeInsufficientStock = "insufficient_stock"
+	CodeValidation        = "validation_failed"
+	CodeForbidden         = "forbidden"
+	CodeUnavailable       = "unavailable"
+	CodeInternal          = "internal"
+)
+
+// Error is an error of a known kind. Repositories and services return them, or wrap them
+// with %w to add context; the transports map the code to their own status.
+type Error struct {
+	Code    string
+	Message string
+	Fields  []FieldError
+	cause   error
+}
+
+// FieldError is a violation of one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the cause of the error, which tells apart errors of the same code
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors of the same code, so errors.Is(err, ErrNotFound) holds for any error
+// of something not found
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	ErrNotFound          = &Error{Code: CodeNotFound, Message: "not found"}
+	ErrConflict          = &Error{Code: CodeConflict, Message: "conflict"}
+	ErrInsufficientStock = &Error{Code: CodeInsufficientStock, Message: "insufficient stock"}
+	ErrValidation        = &Error{Code: CodeValidation, Message: "validation failed"}
+	// ErrForbidden is wrapped by the errors of actions the principal of a request may not take
+	ErrForbidden   = &Error{Code: CodeForbidden, Message: "forbidden"}
+	ErrUnavailable = &Error{Code: CodeUnavailable, Message: "unavailable"}
+	// ErrDuplicate is the cause of the conflicts of records that already exist
+	ErrDuplicate = errors.New("already exists")
+)
+
+// NotFound is the error of a record that does not exist
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Code: CodeNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict is the error of an action the current state of a record does not allow
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Code: CodeConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Duplicate is the conflict of a record that already exists
+func Duplicate(format string, args ...interface{}) error {
+	return &Error{Code: CodeConflict, Message: fmt.Sprintf(format, args...), cause: ErrDuplicate}
+}
+
+// InsufficientStock is the error of an action needing more units than there are
+func InsufficientStock(format string, args ...interface{}) error {
+	return &Error{Code: CodeInsufficientStock, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid is the error of a request that can never succeed as it is
+func Invalid(format string, args ...interface{}) error {
+	return &Error{Code: CodeValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidFields is the error of a request with invalid fields, listing every violation
+func InvalidFields(fields ...FieldError) error {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return &Error{Code: CodeValidation, Message: strings.Join(messages, "; "), Fields: fields}
+}
+
+// fieldChecks collects the violations of the fields of a model, worded like the ones the
+// API reports for request bodies
+type fieldChecks []FieldError
+
+func (c *fieldChecks) add(field, message string) {
+	*c = append(*c, FieldError{Field: field, Message: message})
+}
+
+func (c *fieldChecks) required(field, value string, max int) {
+	if strings.TrimSpace(value) == "" {
+		c.add(field, "is required")
+		return
+	}
+	c.maxLength(field, value, max)
+}
+
+func (c *fieldChecks) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		c.add(field, fmt.Sprintf("must have at most %d characters", max))
+	}
+}
+
+func (c *fieldChecks) optional(field string, value *string, max int) {
+	if value != nil {
+		c.maxLength(field, *value, max)
+	}
+}
+
+func (c *fieldChecks) nonNegative(field string, value float64) {
+	if value < 0 {
+		c.add(field, "must be at least 0")
+	}
+}
+
+func (c fieldChecks) err() error {
+	if len(c) == 0 {
+		return nil
+	}
+	return InvalidFields(c...)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"time"
+)
+
+const EventShipmentShipped = "shipment.shipped"
+
+// Event is an entry of the outbox of things that happened in the WMS, in the order
+// they were committed. Other systems read it to follow along.
+type Event struct {
+	ID        int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type      string         `gorm:"type:varchar(50);not null" json:"type"`
+	TxID      int64          `gorm:"column:txid;->" json:"-"`
+	HubID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"hub_id"`
+	EntityID  uuid.UUID      `gorm:"type:uuid;not null" json:"entity_id"`
+	Payload   datatypes.JSON `gorm:"type:jsonb" json:"payload"`
+	CreatedAt time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// EventInventoryChanged is recorded by the database whenever the quantities of an
+// inventory record change, whichever path changed them
+const EventInventoryChanged = "inventory.changed"
+
+// InventoryChange is the payload of an EventInventoryChanged event: the quantities of an
+// inventory record after the change, and by how much each moved
+type InventoryChange struct {
+	SkuID           uuid.UUID `json:"sku_id"`
+	HubID           uuid.UUID `json:"hub_id"`
+	AvailableQty    int       `json:"available_qty"`
+	AllocatedQty    int       `json:"allocated_qty"`
+	DamagedQty      int       `json:"damaged_qty"`
+	QuarantineQty   int       `json:"quarantine_qty"`
+	AvailableDelta  int       `json:"available_delta"`
+	AllocatedDelta  int       `json:"allocated_delta"`
+	DamagedDelta    int       `json:"damaged_delta"`
+	QuarantineDelta int       `json:"quarantine_delta"`
+}
+
+// InventoryEvent is a change of inventory as streamed to clients. Events are streamed in
+// the order of the transactions that recorded them, so IDs are unique but not always
+// increasing; clients resume after the last one they saw.
+type InventoryEvent struct {
+	ID   int64 `json:"id"`
+	TxID int64 `json:"-"` // transaction the event was recorded in
+	InventoryChange
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InventoryEventFilter narrows down the inventory events of a tenant to a hub and SKUs.
+// AfterTxID and AfterID are the position of the last event read.
+type InventoryEventFilter struct {
+	TenantID  uuid.UUID
+	HubID     *uuid.UUID
+	SkuIDs    []uuid.UUID
+	AfterTxID int64
+	AfterID   int64
+	Limit     int
+}
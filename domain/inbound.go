@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	PurchaseOrderStatusOpen              = "open"
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	PurchaseOrderStatusReceived          = "received"
+)
+
+const (
+	AsnStatusExpected  = "expected"
+	AsnStatusReceiving = "receiving"
+	AsnStatusClosed    = "closed"
+)
+
+// PurchaseOrder is what a seller has been asked to send to a hub
+type PurchaseOrder struct {
+	ID         uuid.UUID           `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SellerID   uuid.UUID           `gorm:"type:uuid;not null;index" json:"seller_id"`
+	HubID      uuid.UUID           `gorm:"type:uuid;not null;index" json:"hub_id"`
+	PoNo       string              `gorm:"type:varchar(50);not null" json:"po_no"`
+	Status     string              `gorm:"type:varchar(20);not null" json:"status"`
+	ExpectedAt *time.Time          `gorm:"type:date" json:"expected_at,omitempty"`
+	CreatedAt  time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Lines      []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID" json:"lines"`
+}
+
+type PurchaseOrderLine struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	PurchaseOrderID uuid.UUID `gorm:"type:uuid;not null;index" json:"purchase_order_id"`
+	SkuID           uuid.UUID `gorm:"type:uuid;not null" json:"sku_id"`
+	OrderedQty      int       `gorm:"not null;check:ordered_qty > 0" json:"ordered_qty"`
+	ReceivedQty     int       `gorm:"not null;default:0" json:"received_qty"`
+	CreatedAt       time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// ASN is an advance shipping notice: what a seller says is on its way to a hub, optionally
+// against a purchase order. Units received above a line's expected quantity plus the
+// over-receipt tolerance, and SKUs that weren't expected at all, are received into quarantine.
+type ASN struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SellerID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"seller_id"`
+	HubID            uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	PurchaseOrderID  *uuid.UUID `gorm:"type:uuid;index" json:"purchase_order_id,omitempty"`
+	AsnNo            string     `gorm:"type:varchar(50);not null" json:"asn_no"`
+	Status           string     `gorm:"type:varchar(20);not null" json:"status"`
+	ExpectedAt       *time.Time `gorm:"type:date" json:"expected_at,omitempty"`
+	OverTolerancePct int        `gorm:"not null;default:0" json:"over_tolerance_pct"`
+	ClosedAt         *time.Time `gorm:"type:timestamptz" json:"closed_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Lines            []AsnLine  `gorm:"foreignKey:AsnID" json:"lines"`
+}
+
+// AsnLine is an expected SKU of an ASN. Unexpected lines are added by receipts of SKUs
+// that weren't on the ASN; they expect nothing.
+type AsnLine struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	AsnID          uuid.UUID `gorm:"type:uuid;not null;index" json:"asn_id"`
+	SkuID          uuid.UUID `gorm:"type:uuid;not null" json:"sku_id"`
+	ExpectedQty    int       `gorm:"not null;default:0" json:"expected_qty"`
+	ReceivedQty    int       `gorm:"not null;default:0" json:"received_qty"`
+	QuarantinedQty int       `gorm:"not null;default:0" json:"quarantined_qty"`
+	Unexpected     bool      `gorm:"not null;default:false" json:"unexpected"`
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// MaxReceivable is how many units of the line can be received into available stock
+func (l AsnLine) MaxReceivable(overTolerancePct int) int {
+	return l.ExpectedQty + (l.ExpectedQty*overTolerancePct+99)/100
+}
+
+// AsnReceipt is a lot of a SKU received against an ASN
+type AsnReceipt struct {
+	SkuID uuid.UUID `json:"sku_id"`
+	LotReceipt
+}
+
+// AsnVariance sums up, for a seller and SKU, what closed ASNs expected against what arrived
+type AsnVariance struct {
+	SellerID      uuid.UUID `json:"seller_id"`
+	SkuID         uuid.UUID `json:"sku_id"`
+	SkuCode       string    `json:"sku_code"`
+	Asns          int       `json:"asns"`
+	ExpectedQty   int       `json:"expected_qty"`
+	ReceivedQty   int       `json:"received_qty"`
+	OverQty       int       `json:"over_qty"`
+	ShortQty      int       `json:"short_qty"`
+	UnexpectedQty int       `json:"unexpected_qty"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	KittingStatusOpen      = "open"
+	KittingStatusCompleted = "completed"
+	KittingStatusCancelled = "cancelled"
+)
+
+// KitComponent is one line of the bill of materials of a kit SKU: Qty units of a
+// component SKU go into every kit. A SKU with components is a kit; kits don't nest.
+type KitComponent struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	KitSkuID       uuid.UUID `gorm:"type:uuid;not null;index" json:"kit_sku_id"`
+	ComponentSkuID uuid.UUID `gorm:"type:uuid;not null" json:"component_sku_id"`
+	Qty            int       `gorm:"not null;check:qty > 0" json:"qty"`
+	CreatedAt      time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// KitAvailability is how many kits a hub can sell: assembled kits in stock plus the kits
+// its available component stock can still make up.
+type KitAvailability struct {
+	HubID        uuid.UUID           `json:"hub_id"`
+	KitSkuID     uuid.UUID           `json:"kit_sku_id"`
+	AssembledQty int                 `json:"assembled_qty"`
+	BuildableQty int                 `json:"buildable_qty"`
+	AvailableQty int                 `json:"available_qty"`
+	Components   []KitComponentStock `json:"components"`
+}
+
+// KitComponentStock is the available stock of one component at a hub and the kits it covers
+type KitComponentStock struct {
+	SkuID        uuid.UUID `json:"sku_id"`
+	QtyPerKit    int       `json:"qty_per_kit"`
+	AvailableQty int       `json:"available_qty"`
+	KitQty       int       `json:"kit_qty"`
+}
+
+// KittingOrder assembles Qty kits at a hub. Opening it reserves the components, completing
+// it consumes them and receives the kits into available stock.
+type KittingOrder struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	KitSkuID    uuid.UUID  `gorm:"type:uuid;not null" json:"kit_sku_id"`
+	Qty         int        `gorm:"not null;check:qty > 0" json:"qty"`
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"`
+	LotNumber   string     `gorm:"type:varchar(50)" json:"lot_number"`
+	ExpiryDate  *time.Time `gorm:"type:date" json:"expiry_date,omitempty"`
+	LocationID  *uuid.UUID `gorm:"type:uuid" json:"location_id,omitempty"`
+	CompletedAt *time.Time `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
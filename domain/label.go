@@ -0,0 +1,38 @@
+package domain
+
+import "fmt"
+
+const (
+	LabelFormatZPL = "zpl"
+	LabelFormatPDF = "pdf"
+)
+
+func IsLabelFormat(format string) bool {
+	return format == LabelFormatZPL || format == LabelFormatPDF
+}
+
+// ValidCompanyPrefix checks a GS1 company prefix, 7 to 10 digits long
+func ValidCompanyPrefix(prefix string) bool {
+	if len(prefix) < 7 || len(prefix) > 10 {
+		return false
+	}
+	_, ok := GS1CheckDigit(prefix)
+	return ok
+}
+
+// SSCC builds the 18 digit serial shipping container code of a carton from the extension
+// digit 0, a GS1 company prefix and a serial reference filling the remaining digits
+func SSCC(companyPrefix string, serial int64) (string, error) {
+	width := 16 - len(companyPrefix)
+	reference := fmt.Sprintf("%0*d", width, serial)
+	if len(reference) > width {
+		return "", Conflict("company prefix %s has run out of SSCC serial references", companyPrefix)
+	}
+
+	digits := "0" + companyPrefix + reference
+	check, ok := GS1CheckDigit(digits)
+	if !ok {
+		return "", Invalid("invalid company prefix %s", companyPrefix)
+	}
+	return digits + string(check), nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	LedgerBucketAvailable        = "available"
+	LedgerBucketQuarantine       = "quarantine"
+	LedgerBucketDamaged          = "damaged"
+	LedgerBucketWrittenOff       = "written_off"
+	LedgerBucketReturnedToVendor = "returned_to_vendor"
+)
+
+const (
+	LedgerRefReturn     = "return"
+	LedgerRefTransition = "transition"
+	LedgerRefAsn        = "asn"
+	LedgerRefQc         = "qc"
+	LedgerRefKitting    = "kitting"
+	LedgerRefLot        = "lot"
+)
+
+// LedgerEntry records a quantity of a SKU entering (positive) or leaving (negative) a
+// stock bucket at a hub, with the document that caused it. Units written off never
+// reach a bucket and are recorded against written_off.
+type LedgerEntry struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SkuID     uuid.UUID `gorm:"type:uuid;not null" json:"sku_id"`
+	HubID     uuid.UUID `gorm:"type:uuid;not null" json:"hub_id"`
+	Bucket    string    `gorm:"type:varchar(20);not null" json:"bucket"`
+	Qty       int       `gorm:"not null" json:"qty"`
+	Reason    string    `gorm:"type:varchar(50)" json:"reason"`
+	RefType   string    `gorm:"type:varchar(20);not null" json:"ref_type"`
+	RefID     uuid.UUID `gorm:"type:uuid;not null" json:"ref_id"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
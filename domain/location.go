@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Location levels, from the outermost to the innermost
+const (
+	LocationLevelZone  = "zone"
+	LocationLevelAisle = "aisle"
+	LocationLevelRack  = "rack"
+	LocationLevelBin   = "bin"
+)
+
+const (
+	LocationTypePick       = "pick"
+	LocationTypeReserve    = "reserve"
+	LocationTypeStaging    = "staging"
+	LocationTypeDock       = "dock"
+	LocationTypeQuarantine = "quarantine"
+)
+
+// ReservedLocationPrefix starts the codes of the locations a hub is created with. Codes
+// starting with it cannot be given to new locations, so they never clash with the hub's own.
+const ReservedLocationPrefix = "_"
+
+// ParentLevel returns the level a location of the given level must hang under,
+// or "" for zones which are the roots of the hierarchy.
+func ParentLevel(level string) string {
+	switch level {
+	case LocationLevelAisle:
+		return LocationLevelZone
+	case LocationLevelRack:
+		return LocationLevelAisle
+	case LocationLevelBin:
+		return LocationLevelRack
+	}
+	return ""
+}
+
+func IsLocationLevel(level string) bool {
+	switch level {
+	case LocationLevelZone, LocationLevelAisle, LocationLevelRack, LocationLevelBin:
+		return true
+	}
+	return false
+}
+
+func IsLocationType(locationType string) bool {
+	switch locationType {
+	case LocationTypePick, LocationTypeReserve, LocationTypeStaging, LocationTypeDock, LocationTypeQuarantine:
+		return true
+	}
+	return false
+}
+
+// Location is a node of the zone → aisle → rack → bin hierarchy of a hub.
+// Stock is only ever held in bins. Zero capacities mean unlimited.
+// Category reserves a zone for SKUs of that category, Sequence is the position
+// of a bin on the walk path from the dock and ShelfLevel its height, 0 being the floor.
+type Location struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	ParentID   *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Code       string     `gorm:"type:varchar(50);not null" json:"code"`
+	Level      string     `gorm:"type:varchar(10);not null" json:"level"`
+	Type       string     `gorm:"type:varchar(20);not null" json:"type"`
+	MaxUnits   int        `gorm:"not null;default:0" json:"max_units"`
+	MaxWeight  float64    `gorm:"type:numeric(10,3);not null;default:0" json:"max_weight"`
+	MaxVolume  float64    `gorm:"type:numeric(14,3);not null;default:0" json:"max_volume"`
+	Category   string     `gorm:"type:varchar(100)" json:"category,omitempty"`
+	Sequence   int        `gorm:"not null;default:0" json:"sequence"`
+	ShelfLevel int        `gorm:"not null;default:0" json:"shelf_level"`
+	Active     bool       `gorm:"not null;default:true" json:"active"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// LocationStock is the number of units of a SKU physically held in a bin.
+type LocationStock struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID      uuid.UUID `gorm:"type:uuid;not null;index" json:"hub_id"`
+	SkuID      uuid.UUID `gorm:"type:uuid;not null;index" json:"sku_id"`
+	LocationID uuid.UUID `gorm:"type:uuid;not null;index" json:"location_id"`
+	Qty        int       `gorm:"not null;default:0;check:qty >= 0" json:"qty"`
+	CreatedAt  time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+
+	Location *Location `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+	Sku      *SKU      `gorm:"foreignKey:SkuID" json:"sku,omitempty"`
+}
+
+// SkuLocationStock is where the stock of a SKU at a hub sits. Every unit on hand is in a
+// bin, so OnHandQty is the sum of the bins.
+type SkuLocationStock struct {
+	SkuID     uuid.UUID       `json:"sku_id"`
+	HubID     uuid.UUID       `json:"hub_id"`
+	OnHandQty int             `json:"on_hand_qty"`
+	Locations []LocationStock `json:"locations"`
+}
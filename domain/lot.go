@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	LotStatusActive  = "active"
+	LotStatusBlocked = "blocked"
+)
+
+// Lot is a batch of a SKU at a hub sharing the same manufacturing and expiry dates.
+// The quantities of all active lots of an inventory row add up to at most the
+// quantities on the row itself; anything above that is untracked legacy stock.
+type Lot struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	InventoryID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"inventory_id"`
+	SkuID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"sku_id"`
+	HubID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	LotNumber     string     `gorm:"type:varchar(50);not null" json:"lot_number"`
+	MfgDate       *time.Time `gorm:"type:date" json:"mfg_date,omitempty"`
+	ExpiryDate    *time.Time `gorm:"type:date" json:"expiry_date,omitempty"`
+	AvailableQty  int        `gorm:"not null;default:0;check:available_qty >= 0" json:"available_qty"`
+	AllocatedQty  int        `gorm:"not null;default:0;check:allocated_qty >= 0" json:"allocated_qty"`
+	QuarantineQty int        `gorm:"not null;default:0;check:quarantine_qty >= 0" json:"quarantine_qty"`
+	Status        string     `gorm:"type:varchar(20);not null;default:active" json:"status"`
+	CreatedAt     time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// IsExpired reports whether the lot is past its expiry date on the given day.
+func (l Lot) IsExpired(on time.Time) bool {
+	return l.ExpiryDate != nil && !l.ExpiryDate.After(on)
+}
+
+// LotReceipt is a quantity of a SKU arriving at a hub under a single lot.
+// An empty LotNumber receives the quantity as untracked stock. Serialized
+// SKUs list exactly Qty serial numbers. The quantity goes into the bin of
+// LocationID, or into the receiving dock of the hub when it is nil.
+type LotReceipt struct {
+	LotNumber  string     `json:"lot_number"`
+	MfgDate    *time.Time `json:"mfg_date,omitempty"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	Qty        int        `json:"qty"`
+	Serials    []string   `json:"serials,omitempty"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+}
+
+// NearExpiryLot is a row of the near expiry report of a hub.
+type NearExpiryLot struct {
+	LotID        uuid.UUID `json:"lot_id"`
+	SkuID        uuid.UUID `json:"sku_id"`
+	SkuCode      string    `json:"sku_code"`
+	SkuName      string    `json:"sku_name"`
+	LotNumber    string    `json:"lot_number"`
+	ExpiryDate   time.Time `json:"expiry_date"`
+	DaysLeft     int       `json:"days_left"`
+	AvailableQty int       `json:"available_qty"`
+	AllocatedQty int       `json:"allocated_qty"`
+}
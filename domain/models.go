@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -8,13 +9,17 @@ import (
 )
 
 type Tenant struct {
-	ID        uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(100);not null;unique" json:"name"`
-	Email     string         `gorm:"type:varchar(100);not null;unique" json:"email"`
-	GSTIN     *string        `gorm:"type:varchar(15)" json:"gstin,omitempty"`
-	CreatedAt time.Time      `gorm:"default:current_timestamp" json:"created_at"`
-	UpdatedAt time.Time      `gorm:"default:current_timestamp" json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete support
+	ID    uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name  string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Email string    `gorm:"type:varchar(100);not null;unique" json:"email"`
+	GSTIN *string   `gorm:"type:varchar(15)" json:"gstin,omitempty"`
+	// GS1CompanyPrefix numbers the SSCCs of the tenant's shipping cartons, SsccSerial
+	// being the last serial reference handed out
+	GS1CompanyPrefix string         `gorm:"type:varchar(10)" json:"gs1_company_prefix,omitempty"`
+	SsccSerial       int64          `gorm:"not null;default:0" json:"-"`
+	CreatedAt        time.Time      `gorm:"default:current_timestamp" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"default:current_timestamp" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete support
 }
 
 type Hub struct {
@@ -35,6 +40,24 @@ type Hub struct {
 	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:RESTRICT" json:"tenant"` // Relation with Tenant
 }
 
+// Validate checks the fields of a hub to create against the limits of its columns, so
+// that every transport applies the same rules
+func (h Hub) Validate() error {
+	var checks fieldChecks
+	if h.TenantID == uuid.Nil {
+		checks.add("tenant_id", "is required")
+	}
+	checks.required("name", h.Name, 100)
+	checks.required("code", h.Code, 20)
+	checks.required("address", h.Address, 255)
+	checks.optional("city", h.City, 100)
+	checks.optional("state", h.State, 100)
+	checks.optional("country", h.Country, 100)
+	checks.optional("pincode", h.Pincode, 20)
+	checks.optional("location", h.Location, 30)
+	return checks.err()
+}
+
 type SKU struct {
 	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 	SellerID    uuid.UUID      `gorm:"type:uuid;not null" json:"seller_id"`
@@ -45,9 +68,10 @@ type SKU struct {
 	Subcategory string         `gorm:"type:varchar(100)" json:"subcategory"`
 	Brand       string         `gorm:"type:varchar(100)" json:"brand"`
 	Model       string         `gorm:"type:varchar(100)" json:"model"`
-	UOM         string         `gorm:"type:varchar(20);not null" json:"uom"` // Unit of Measure
+	UOM         string         `gorm:"type:varchar(20);not null" json:"uom"` // Base unit of measure, from the UOM catalogue
 	Weight      float64        `gorm:"type:numeric(10,3)" json:"weight"`
 	Dimensions  datatypes.JSON `gorm:"type:jsonb" json:"dimensions"` // JSONB for storing dimensions
+	Serialized  bool           `gorm:"not null;default:false" json:"serialized"`
 	CreatedAt   time.Time      `gorm:"type:timestamptz;default:current_timestamp" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"type:timestamptz;default:current_timestamp" json:"updated_at"`
 
@@ -66,6 +90,35 @@ type Seller struct {
 	UpdatedAt     time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
+// Validate checks the fields of a SKU to create against the limits of its columns, so
+// that every transport applies the same rules
+func (s SKU) Validate() error {
+	var checks fieldChecks
+	if s.SellerID == uuid.Nil {
+		checks.add("seller_id", "is required")
+	}
+	checks.required("name", s.Name, 100)
+	checks.required("code", s.Code, 50)
+	checks.maxLength("description", s.Description, 500)
+	checks.maxLength("category", s.Category, 100)
+	checks.maxLength("subcategory", s.Subcategory, 100)
+	checks.maxLength("brand", s.Brand, 100)
+	checks.maxLength("model", s.Model, 100)
+	checks.required("uom", s.UOM, 20)
+	checks.nonNegative("weight", s.Weight)
+	if len(s.Dimensions) > 0 && string(s.Dimensions) != "null" {
+		var dimensions Dimensions
+		if err := json.Unmarshal(s.Dimensions, &dimensions); err != nil {
+			checks.add("dimensions", "is not a valid dimensions object")
+		} else {
+			checks.nonNegative("dimensions.length", dimensions.Length)
+			checks.nonNegative("dimensions.width", dimensions.Width)
+			checks.nonNegative("dimensions.height", dimensions.Height)
+		}
+	}
+	return checks.err()
+}
+
 type Inventory struct {
 	ID            uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 	SkuID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"sku_id"`
@@ -73,7 +126,8 @@ type Inventory struct {
 	AvailableQty  int        `gorm:"not null;default:0;check:available_qty >= 0" json:"available_qty"`
 	AllocatedQty  int        `gorm:"not null;default:0;check:allocated_qty >= 0" json:"allocated_qty"`
 	DamagedQty    int        `gorm:"not null;default:0;check:damaged_qty >= 0" json:"damaged_qty"`
-	Zone          string     `gorm:"type:varchar(50)" json:"zone"`
+	QuarantineQty int        `gorm:"not null;default:0;check:quarantine_qty >= 0" json:"quarantine_qty"`
+	Zone          string     `gorm:"type:varchar(50)" json:"zone"` // Free-text location, superseded by LocationStock
 	Rack          string     `gorm:"type:varchar(50)" json:"rack"`
 	Bin           string     `gorm:"type:varchar(50)" json:"bin"`
 	MinThreshold  int        `gorm:"default:0" json:"min_threshold"`
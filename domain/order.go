@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	OrderStatusAllocated   = "allocated"
+	OrderStatusWaved       = "waved"
+	OrderStatusPicked      = "picked"
+	OrderStatusShortPicked = "short_picked"
+)
+
+const (
+	WaveStatusPlanned   = "planned"
+	WaveStatusReleased  = "released"
+	WaveStatusCompleted = "completed"
+)
+
+const (
+	PickStatusOpen   = "open"
+	PickStatusPicked = "picked"
+	PickStatusShort  = "short"
+)
+
+// OutboundOrder is a customer order to be picked and shipped from a hub.
+// Its stock is allocated when the order is taken in.
+type OutboundOrder struct {
+	ID        uuid.UUID           `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"hub_id"`
+	OrderNo   string              `gorm:"type:varchar(50);not null" json:"order_no"`
+	Carrier   string              `gorm:"type:varchar(50)" json:"carrier"`
+	CutoffAt  *time.Time          `gorm:"type:timestamptz" json:"cutoff_at,omitempty"`
+	Status    string              `gorm:"type:varchar(20);not null" json:"status"`
+	WaveID    *uuid.UUID          `gorm:"type:uuid;index" json:"wave_id,omitempty"`
+	CreatedAt time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time           `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Lines     []OutboundOrderLine `gorm:"foreignKey:OrderID" json:"lines"`
+}
+
+// OutboundOrderLine is a SKU and quantity of an order. Picking turns allocated
+// units into picked or short units, packing consumes the picked ones and
+// dispatch ships the packed ones. Kits short of assembled stock allocate the
+// rest as component lines pointing at the kit line through KitLineID.
+type OutboundOrderLine struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	OrderID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
+	SkuID        uuid.UUID  `gorm:"type:uuid;not null" json:"sku_id"`
+	KitLineID    *uuid.UUID `gorm:"type:uuid" json:"kit_line_id,omitempty"`
+	Qty          int        `gorm:"not null;check:qty > 0" json:"qty"`
+	AllocatedQty int        `gorm:"not null;default:0" json:"allocated_qty"`
+	PickedQty    int        `gorm:"not null;default:0" json:"picked_qty"`
+	ShortQty     int        `gorm:"not null;default:0" json:"short_qty"`
+	PackedQty    int        `gorm:"not null;default:0" json:"packed_qty"`
+	ShippedQty   int        `gorm:"not null;default:0" json:"shipped_qty"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// Wave is a batch of orders of a hub sharing a carrier, cutoff and zone, picked together.
+// The zone of an order is the one its units are mostly picked from; ZoneID is nil for
+// orders whose stock is in no pick or reserve bin yet.
+type Wave struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	Carrier   string     `gorm:"type:varchar(50)" json:"carrier"`
+	CutoffAt  *time.Time `gorm:"type:timestamptz" json:"cutoff_at,omitempty"`
+	ZoneID    *uuid.UUID `gorm:"type:uuid" json:"zone_id,omitempty"`
+	Status    string     `gorm:"type:varchar(20);not null" json:"status"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+
+	Orders []OutboundOrder `gorm:"foreignKey:WaveID" json:"orders,omitempty"`
+}
+
+// PickTask is one line of a pick list: take Qty units of a SKU from a bin for an order line.
+// Pick lists are split by zone and walked in bin sequence.
+type PickTask struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	WaveID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"wave_id"`
+	OrderID     uuid.UUID  `gorm:"type:uuid;not null" json:"order_id"`
+	OrderLineID uuid.UUID  `gorm:"type:uuid;not null" json:"order_line_id"`
+	SkuID       uuid.UUID  `gorm:"type:uuid;not null" json:"sku_id"`
+	LocationID  uuid.UUID  `gorm:"type:uuid;not null" json:"location_id"`
+	ZoneID      uuid.UUID  `gorm:"type:uuid;not null" json:"zone_id"`
+	Sequence    int        `gorm:"not null;default:0" json:"sequence"`
+	Qty         int        `gorm:"not null;check:qty > 0" json:"qty"`
+	PickedQty   int        `gorm:"not null;default:0" json:"picked_qty"`
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"`
+	PickedAt    *time.Time `gorm:"type:timestamptz" json:"picked_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+
+	Location *Location `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+}
@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"sort"
+	"time"
+)
+
+// VolumetricDivisor turns a volume in cubic centimetres into a volumetric weight in kg
+const VolumetricDivisor = 5000.0
+
+const OrderStatusPacked = "packed"
+
+const (
+	PackageStatusOpen   = "open"
+	PackageStatusClosed = "closed"
+)
+
+const ShipmentStatusPacked = "packed"
+
+// Carton is a box size of the carton catalogue of a hub. Dimensions are inner
+// dimensions in centimetres, weights in kg. A zero MaxWeight means unlimited.
+type Carton struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID       uuid.UUID `gorm:"type:uuid;not null;index" json:"hub_id"`
+	Code        string    `gorm:"type:varchar(20);not null" json:"code"`
+	InnerLength float64   `gorm:"type:numeric(8,2);not null" json:"inner_length"`
+	InnerWidth  float64   `gorm:"type:numeric(8,2);not null" json:"inner_width"`
+	InnerHeight float64   `gorm:"type:numeric(8,2);not null" json:"inner_height"`
+	TareWeight  float64   `gorm:"type:numeric(10,3);not null;default:0" json:"tare_weight"`
+	MaxWeight   float64   `gorm:"type:numeric(10,3);not null;default:0" json:"max_weight"`
+	Active      bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// Dimensions of the inside of the carton
+func (c Carton) Dimensions() Dimensions {
+	return Dimensions{Length: c.InnerLength, Width: c.InnerWidth, Height: c.InnerHeight}
+}
+
+// CartonFillFactor is the share of a carton's volume that can really be filled. Cartonization
+// and scanning both hold cartons to it, so a suggested carton takes what is scanned into it.
+const CartonFillFactor = 0.85
+
+// PackUnit is one unit of a SKU with its dimensions sorted smallest first
+type PackUnit struct {
+	SkuID  uuid.UUID
+	Sides  [3]float64
+	Volume float64
+	Weight float64
+}
+
+func NewPackUnit(sku SKU) (PackUnit, error) {
+	dimensions, err := sku.ParseDimensions()
+	if err != nil {
+		return PackUnit{}, err
+	}
+	return PackUnit{
+		SkuID:  sku.ID,
+		Sides:  sortedSides(dimensions),
+		Volume: dimensions.Volume(),
+		Weight: sku.Weight,
+	}, nil
+}
+
+// CartonBox is a carton and the units of each SKU put into it so far
+type CartonBox struct {
+	Carton Carton
+	Items  map[uuid.UUID]int
+	sides  [3]float64
+	volume float64
+	weight float64
+}
+
+func NewCartonBox(carton Carton) *CartonBox {
+	return &CartonBox{
+		Carton: carton,
+		Items:  make(map[uuid.UUID]int),
+		sides:  sortedSides(carton.Dimensions()),
+	}
+}
+
+// NewPackageBox returns the carton of a package holding its items. The carton and the SKUs
+// of the items must be loaded.
+func NewPackageBox(pkg Package) (*CartonBox, error) {
+	if pkg.Carton == nil {
+		return nil, fmt.Errorf("carton of package %s is not loaded", pkg.ID)
+	}
+	box := NewCartonBox(*pkg.Carton)
+	for _, item := range pkg.Items {
+		if item.Sku == nil {
+			return nil, fmt.Errorf("SKU of package item %s is not loaded", item.ID)
+		}
+		unit, err := NewPackUnit(*item.Sku)
+		if err != nil {
+			return nil, err
+		}
+		box.Add(unit, item.Qty)
+	}
+	return box, nil
+}
+
+// Fits reports whether qty more units go into the box, filled up to CartonFillFactor of
+// its volume
+func (b *CartonBox) Fits(unit PackUnit, qty int) bool {
+	for i := range unit.Sides {
+		if unit.Sides[i] > b.sides[i] {
+			return false
+		}
+	}
+	if b.volume+unit.Volume*float64(qty) > b.Carton.Dimensions().Volume()*CartonFillFactor {
+		return false
+	}
+	if b.Carton.MaxWeight > 0 && b.weight+unit.Weight*float64(qty) > b.Carton.MaxWeight {
+		return false
+	}
+	return true
+}
+
+func (b *CartonBox) Add(unit PackUnit, qty int) {
+	b.volume += unit.Volume * float64(qty)
+	b.weight += unit.Weight * float64(qty)
+	b.Items[unit.SkuID] += qty
+}
+
+func (b *CartonBox) GrossWeight() float64 {
+	return b.Carton.TareWeight + b.weight
+}
+
+func (b *CartonBox) VolumetricWeight() float64 {
+	return b.Carton.Dimensions().Volume() / VolumetricDivisor
+}
+
+func sortedSides(d Dimensions) [3]float64 {
+	sides := []float64{d.Length, d.Width, d.Height}
+	sort.Float64s(sides)
+	return [3]float64{sides[0], sides[1], sides[2]}
+}
+
+// Package is a carton being or having been packed at a packing station for an order.
+type Package struct {
+	ID               uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID            uuid.UUID     `gorm:"type:uuid;not null" json:"hub_id"`
+	OrderID          uuid.UUID     `gorm:"type:uuid;not null;index" json:"order_id"`
+	CartonID         uuid.UUID     `gorm:"type:uuid;not null" json:"carton_id"`
+	ShipmentID       *uuid.UUID    `gorm:"type:uuid;index" json:"shipment_id,omitempty"`
+	Status           string        `gorm:"type:varchar(20);not null" json:"status"`
+	Weight           float64       `gorm:"type:numeric(10,3);not null;default:0" json:"weight"`
+	VolumetricWeight float64       `gorm:"type:numeric(10,3);not null;default:0" json:"volumetric_weight"`
+	TrackingNumber   string        `gorm:"type:varchar(100)" json:"tracking_number"`
+	SSCC             *string       `gorm:"type:varchar(18);uniqueIndex" json:"sscc,omitempty"`
+	CreatedAt        time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Items            []PackageItem `gorm:"foreignKey:PackageID" json:"items"`
+
+	Carton *Carton `gorm:"foreignKey:CartonID" json:"carton,omitempty"`
+}
+
+// ChargeableWeight is what carriers bill: the greater of actual and volumetric weight
+func (p Package) ChargeableWeight() float64 {
+	return max(p.Weight, p.VolumetricWeight)
+}
+
+// PackageItem is a quantity of a SKU scanned into a package. Serialized SKUs
+// record the serials scanned.
+type PackageItem struct {
+	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	PackageID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"package_id"`
+	OrderLineID uuid.UUID      `gorm:"type:uuid;not null" json:"order_line_id"`
+	SkuID       uuid.UUID      `gorm:"type:uuid;not null" json:"sku_id"`
+	Qty         int            `gorm:"not null;check:qty > 0" json:"qty"`
+	Serials     pq.StringArray `gorm:"type:text[]" json:"serials,omitempty"`
+	CreatedAt   time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Sku *SKU `gorm:"foreignKey:SkuID" json:"sku,omitempty"`
+}
+
+// Shipment is the packed output of an order, handed to a carrier as one or more packages.
+type Shipment struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	OrderID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	Status       string     `gorm:"type:varchar(20);not null" json:"status"`
+	TotalWeight  float64    `gorm:"type:numeric(10,3);not null;default:0" json:"total_weight"`
+	Carrier      string     `gorm:"type:varchar(50)" json:"carrier"`
+	Service      string     `gorm:"type:varchar(50)" json:"service"`
+	ManifestID   *uuid.UUID `gorm:"type:uuid;index" json:"manifest_id,omitempty"`
+	DispatchedAt *time.Time `gorm:"type:timestamptz" json:"dispatched_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Packages     []Package  `gorm:"foreignKey:ShipmentID" json:"packages,omitempty"`
+
+	Order *OutboundOrder `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+// CartonSuggestion is one box of a cartonization result with what goes into it.
+type CartonSuggestion struct {
+	CartonID         uuid.UUID        `json:"carton_id"`
+	CartonCode       string           `json:"carton_code"`
+	Items            []CartonItemPlan `json:"items"`
+	Weight           float64          `json:"weight"`
+	VolumetricWeight float64          `json:"volumetric_weight"`
+}
+
+type CartonItemPlan struct {
+	SkuID uuid.UUID `json:"sku_id"`
+	Qty   int       `json:"qty"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	PutawayStatusOpen      = "open"
+	PutawayStatusDone      = "done"
+	PutawayStatusCancelled = "cancelled"
+)
+
+// Putaway rules, in the order they are applied
+const (
+	PutawayRuleConsolidation = "same_sku_consolidation"
+	PutawayRuleCategoryZone  = "category_zoning"
+	PutawayRuleHeavyLow      = "heavy_items_low"
+	PutawayRuleNearestEmpty  = "nearest_empty"
+	PutawayRuleAnyFit        = "any_fit"
+	PutawayRuleManual        = "manual"
+)
+
+// PutawaySuggestion proposes shelving Qty units of a SKU into a bin.
+type PutawaySuggestion struct {
+	LocationID   uuid.UUID `json:"location_id"`
+	LocationCode string    `json:"location_code"`
+	Qty          int       `json:"qty"`
+	Rule         string    `json:"rule"`
+}
+
+// PutawayPlan is the set of bins proposed for a quantity of a SKU. UnplacedQty is
+// what no bin has room for.
+type PutawayPlan struct {
+	Suggestions []PutawaySuggestion `json:"suggestions"`
+	UnplacedQty int                 `json:"unplaced_qty"`
+}
+
+// PutawayTask is an instruction to move received stock from a dock to a storage bin.
+type PutawayTask struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	SkuID          uuid.UUID  `gorm:"type:uuid;not null" json:"sku_id"`
+	FromLocationID uuid.UUID  `gorm:"type:uuid;not null" json:"from_location_id"`
+	ToLocationID   uuid.UUID  `gorm:"type:uuid;not null" json:"to_location_id"`
+	Qty            int        `gorm:"not null;check:qty > 0" json:"qty"`
+	Rule           string     `gorm:"type:varchar(30)" json:"rule"`
+	Status         string     `gorm:"type:varchar(20);not null;default:open" json:"status"`
+	CompletedAt    *time.Time `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+
+	Sku *SKU `gorm:"foreignKey:SkuID" json:"sku,omitempty"`
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	QcStatusPending = "pending"
+	QcStatusPassed  = "passed"
+	QcStatusFailed  = "failed"
+)
+
+const (
+	QcSourceReceipt = "receipt"
+	QcSourceAsn     = "asn"
+)
+
+// QcRule asks for a sampling inspection of received stock before it becomes sellable. A rule
+// applies to a seller, a SKU category or both; the most specific active rule wins. The sample
+// is the larger of SampleSize units and SamplePct of the quantity received, and the stock
+// passes while no more than MaxFailures sampled units fail.
+type QcRule struct {
+	ID          uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SellerID    *uuid.UUID     `gorm:"type:uuid;index" json:"seller_id,omitempty"`
+	Category    string         `gorm:"type:varchar(100)" json:"category"`
+	SampleSize  int            `gorm:"not null;default:0" json:"sample_size"`
+	SamplePct   int            `gorm:"not null;default:0" json:"sample_pct"`
+	MaxFailures int            `gorm:"not null;default:0" json:"max_failures"`
+	Checklist   pq.StringArray `gorm:"type:text[]" json:"checklist"`
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+}
+
+// Sample is how many of qty received units the rule inspects
+func (r QcRule) Sample(qty int) int {
+	sample := max(r.SampleSize, (qty*r.SamplePct+99)/100, 1)
+	return min(sample, qty)
+}
+
+// QcInspection holds received stock in quarantine until its sample has been inspected.
+// Failed units go to damaged stock; the rest is released to available stock when the
+// inspection passes and moved to damaged stock when it fails.
+type QcInspection struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"hub_id"`
+	SkuID        uuid.UUID      `gorm:"type:uuid;not null" json:"sku_id"`
+	RuleID       uuid.UUID      `gorm:"type:uuid;not null" json:"rule_id"`
+	Source       string         `gorm:"type:varchar(20);not null" json:"source"`
+	RefID        *uuid.UUID     `gorm:"type:uuid" json:"ref_id,omitempty"`
+	Status       string         `gorm:"type:varchar(20);not null" json:"status"`
+	Qty          int            `gorm:"not null;check:qty > 0" json:"qty"`
+	SampleSize   int            `gorm:"not null" json:"sample_size"`
+	MaxFailures  int            `gorm:"not null;default:0" json:"max_failures"`
+	Checklist    pq.StringArray `gorm:"type:text[]" json:"checklist"`
+	Serials      pq.StringArray `gorm:"type:text[]" json:"serials,omitempty"`
+	InspectedQty int            `gorm:"not null;default:0" json:"inspected_qty"`
+	FailedQty    int            `gorm:"not null;default:0" json:"failed_qty"`
+	CompletedAt  *time.Time     `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	CreatedAt    time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Results      []QcResult     `gorm:"foreignKey:InspectionID" json:"results,omitempty"`
+}
+
+// QcResult is the outcome of inspecting one unit, or Qty units of a sample alike.
+// Serialized SKUs inspect unit by unit.
+type QcResult struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	InspectionID uuid.UUID      `gorm:"type:uuid;not null;index" json:"inspection_id"`
+	Qty          int            `gorm:"not null;default:1;check:qty > 0" json:"qty"`
+	Serial       string         `gorm:"type:varchar(100)" json:"serial,omitempty"`
+	Passed       bool           `gorm:"not null" json:"passed"`
+	FailedChecks pq.StringArray `gorm:"type:text[]" json:"failed_checks,omitempty"`
+	Note         string         `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt    time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	RoleAdmin       = "admin"
+	RoleHubManager  = "hub_manager"
+	RolePicker      = "picker"
+	RoleReadOnly    = "read_only"
+	RoleIntegration = "integration"
+)
+
+// Permissions are the actions roles grant
+const (
+	PermAccessManage    = "access:manage"    // API keys, role assignments and tenant settings
+	PermHubManage       = "hub:manage"       // creating hubs
+	PermCatalogManage   = "catalog:manage"   // SKUs, UOMs, packs, barcodes, kits and QC rules
+	PermLocationManage  = "location:manage"  // locations and cartons of a hub
+	PermInventoryRead   = "inventory:read"   // reading anything
+	PermInventoryAdjust = "inventory:adjust" // adjusting, allocating and moving stock
+	PermInboundReceive  = "inbound:receive"  // purchase orders, ASNs, receipts and returns
+	PermOrderManage     = "order:manage"     // orders, waves, kitting orders, carriers and manifests
+	PermTaskExecute     = "task:execute"     // picking, packing, putaway, QC and printing labels
+)
+
+// rolePermissions are the permissions of each role
+var rolePermissions = map[string][]string{
+	RoleAdmin: {PermAccessManage, PermHubManage, PermCatalogManage, PermLocationManage, PermInventoryRead,
+		PermInventoryAdjust, PermInboundReceive, PermOrderManage, PermTaskExecute},
+	RoleHubManager: {PermLocationManage, PermInventoryRead, PermInventoryAdjust, PermInboundReceive,
+		PermOrderManage, PermTaskExecute},
+	RolePicker:      {PermInventoryRead, PermTaskExecute},
+	RoleReadOnly:    {PermInventoryRead},
+	RoleIntegration: {PermCatalogManage, PermInventoryRead, PermInventoryAdjust, PermInboundReceive, PermOrderManage},
+}
+
+// Entities whose owning tenant and hub are looked up to authorize actions on them
+const (
+	EntityHub           = "hub"
+	EntitySeller        = "seller"
+	EntitySku           = "sku"
+	EntityLocation      = "location"
+	EntityBarcode       = "barcode"
+	EntityCarton        = "carton"
+	EntityOrder         = "order"
+	EntityWave          = "wave"
+	EntityPickTask      = "pick_task"
+	EntityPackage       = "package"
+	EntityShipment      = "shipment"
+	EntityManifest      = "manifest"
+	EntityReturn        = "return"
+	EntityPurchaseOrder = "purchase_order"
+	EntityASN           = "asn"
+	EntityQcInspection  = "qc_inspection"
+	EntityKittingOrder  = "kitting_order"
+	EntityPutawayTask   = "putaway_task"
+)
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleGrants reports whether a role grants a permission
+func RoleGrants(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Grant is a role a principal holds, across its tenant or at one hub only
+type Grant struct {
+	Role  string     `json:"role"`
+	HubID *uuid.UUID `json:"hub_id,omitempty"`
+}
+
+// Can reports whether the grants allow a permission at a hub. A nil hub asks for the
+// permission across the tenant, which only tenant-wide grants give.
+func (p Principal) Can(permission string, hubID *uuid.UUID) bool {
+	for _, grant := range p.Grants {
+		if !RoleGrants(grant.Role, permission) {
+			continue
+		}
+		if grant.HubID == nil || (hubID != nil && *grant.HubID == *hubID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds reports whether the principal already has a grant of the same role at the same hub
+func (p Principal) Holds(grant Grant) bool {
+	for _, held := range p.Grants {
+		if held.Role != grant.Role || (held.HubID == nil) != (grant.HubID == nil) {
+			continue
+		}
+		if held.HubID == nil || *held.HubID == *grant.HubID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAnywhere reports whether the grants allow a permission at any hub at all
+func (p Principal) CanAnywhere(permission string) bool {
+	for _, grant := range p.Grants {
+		if RoleGrants(grant.Role, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAssignment gives a subject a role in a tenant, at one hub when HubID is set.
+// Subjects are JWT subjects or api-key:<id> for API keys.
+type RoleAssignment struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	TenantID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	Subject   string     `gorm:"type:varchar(255);not null" json:"subject"`
+	Role      string     `gorm:"type:varchar(20);not null" json:"role"`
+	HubID     *uuid.UUID `gorm:"type:uuid" json:"hub_id,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// APIKeySubject is the subject an API key authenticates as
+func APIKeySubject(id uuid.UUID) string {
+	return "api-key:" + id.String()
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	ReturnStatusAuthorized = "authorized"
+	ReturnStatusReceived   = "received"
+	ReturnStatusCompleted  = "completed"
+)
+
+const (
+	ReturnGradeResellable = "resellable"
+	ReturnGradeDamaged    = "damaged"
+	ReturnGradeScrap      = "scrap"
+)
+
+const (
+	ReturnReasonDamagedInTransit = "damaged_in_transit"
+	ReturnReasonDefective        = "defective"
+	ReturnReasonWrongItem        = "wrong_item"
+	ReturnReasonNotAsDescribed   = "not_as_described"
+	ReturnReasonNotNeeded        = "no_longer_needed"
+	ReturnReasonOther            = "other"
+)
+
+func IsReturnGrade(grade string) bool {
+	switch grade {
+	case ReturnGradeResellable, ReturnGradeDamaged, ReturnGradeScrap:
+		return true
+	}
+	return false
+}
+
+func IsReturnReason(reason string) bool {
+	switch reason {
+	case ReturnReasonDamagedInTransit, ReturnReasonDefective, ReturnReasonWrongItem,
+		ReturnReasonNotAsDescribed, ReturnReasonNotNeeded, ReturnReasonOther:
+		return true
+	}
+	return false
+}
+
+// Return is a return authorisation (RMA) against a shipped order. It may be received
+// at a different hub than the one that shipped the order.
+type Return struct {
+	ID            uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	RmaNo         string       `gorm:"type:varchar(50);not null;unique" json:"rma_no"`
+	OrderID       uuid.UUID    `gorm:"type:uuid;not null;index" json:"order_id"`
+	Status        string       `gorm:"type:varchar(20);not null" json:"status"`
+	ReceivedHubID *uuid.UUID   `gorm:"type:uuid" json:"received_hub_id,omitempty"`
+	ReceivedAt    *time.Time   `gorm:"type:timestamptz" json:"received_at,omitempty"`
+	CreatedAt     time.Time    `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time    `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Lines         []ReturnLine `gorm:"foreignKey:ReturnID" json:"lines"`
+}
+
+// ReturnLine is a quantity of an order line the customer sends back, with the reason given.
+// Received units are graded one way or another until none are left ungraded.
+type ReturnLine struct {
+	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ReturnID    uuid.UUID    `gorm:"type:uuid;not null;index" json:"return_id"`
+	OrderLineID uuid.UUID    `gorm:"type:uuid;not null" json:"order_line_id"`
+	SkuID       uuid.UUID    `gorm:"type:uuid;not null" json:"sku_id"`
+	Qty         int          `gorm:"not null;check:qty > 0" json:"qty"`
+	ReceivedQty int          `gorm:"not null;default:0" json:"received_qty"`
+	GradedQty   int          `gorm:"not null;default:0" json:"graded_qty"`
+	Reason      string       `gorm:"type:varchar(50);not null" json:"reason"`
+	CreatedAt   time.Time    `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time    `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Grades      []ReturnItem `gorm:"foreignKey:ReturnLineID" json:"grades,omitempty"`
+}
+
+// ReturnItem is a quantity of received units of a return line given the same grade
+type ReturnItem struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ReturnLineID uuid.UUID      `gorm:"type:uuid;not null;index" json:"return_line_id"`
+	Grade        string         `gorm:"type:varchar(20);not null" json:"grade"`
+	Qty          int            `gorm:"not null;check:qty > 0" json:"qty"`
+	Serials      pq.StringArray `gorm:"type:text[]" json:"serials,omitempty"`
+	Note         string         `gorm:"type:varchar(255)" json:"note"`
+	CreatedAt    time.Time      `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// ReturnReport sums up returned units of a SKU for one reason, by grade
+type ReturnReport struct {
+	SellerID      uuid.UUID `json:"seller_id"`
+	SkuID         uuid.UUID `json:"sku_id"`
+	SkuCode       string    `json:"sku_code"`
+	Reason        string    `json:"reason"`
+	ReturnedQty   int       `json:"returned_qty"`
+	ResellableQty int       `json:"resellable_qty"`
+	DamagedQty    int       `json:"damaged_qty"`
+	ScrapQty      int       `json:"scrap_qty"`
+}
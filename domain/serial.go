@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	SerialStatusAvailable   = "available"
+	SerialStatusAllocated   = "allocated"
+	SerialStatusQuarantined = "quarantined"
+	SerialStatusDamaged     = "damaged"
+	SerialStatusRemoved     = "removed"
+)
+
+const (
+	SerialEventReceived  = "received"
+	SerialEventAllocated = "allocated"
+	SerialEventRemoved   = "removed"
+	SerialEventShipped   = "shipped"
+	SerialEventReturned  = "returned"
+	SerialEventQcPassed  = "qc_passed"
+	SerialEventQcFailed  = "qc_failed"
+)
+
+// Serial is a single unit of a serialized SKU. Serial numbers are unique per SKU.
+// LocationID is the bin the unit was received into, cleared once it leaves the hub.
+type Serial struct {
+	ID           uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SkuID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"sku_id"`
+	HubID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"hub_id"`
+	SerialNumber string        `gorm:"type:varchar(100);not null" json:"serial_number"`
+	LocationID   *uuid.UUID    `gorm:"type:uuid;index" json:"location_id,omitempty"`
+	Status       string        `gorm:"type:varchar(20);not null" json:"status"`
+	CreatedAt    time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time     `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP;autoUpdateTime" json:"updated_at"`
+	Location     *Location     `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+	Events       []SerialEvent `gorm:"foreignKey:SerialID" json:"events,omitempty"`
+}
+
+// SerialEvent is one step in the lifecycle of a serial.
+type SerialEvent struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SerialID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"serial_id"`
+	HubID      uuid.UUID  `gorm:"type:uuid;not null" json:"hub_id"`
+	Event      string     `gorm:"type:varchar(20);not null" json:"event"`
+	LocationID *uuid.UUID `gorm:"type:uuid" json:"location_id,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
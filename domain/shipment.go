@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ShipmentStatusManifested = "manifested"
+	ShipmentStatusDispatched = "dispatched"
+)
+
+const OrderStatusShipped = "shipped"
+
+// Manifest is the end of day hand-over list of the shipments of a hub to one carrier.
+type Manifest struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	HubID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"hub_id"`
+	Carrier      string     `gorm:"type:varchar(50);not null" json:"carrier"`
+	ManifestDate time.Time  `gorm:"type:date;not null" json:"manifest_date"`
+	CreatedAt    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Shipments    []Shipment `gorm:"foreignKey:ManifestID" json:"shipments,omitempty"`
+}
+
+// ShippedEvent is the payload of an EventShipmentShipped event.
+type ShippedEvent struct {
+	ShipmentID   uuid.UUID         `json:"shipment_id"`
+	OrderID      uuid.UUID         `json:"order_id"`
+	OrderNo      string            `json:"order_no"`
+	Carrier      string            `json:"carrier"`
+	Service      string            `json:"service"`
+	DispatchedAt time.Time         `json:"dispatched_at"`
+	Packages     []ShippedPackage  `json:"packages"`
+	Lines        []ShippedLineItem `json:"lines"`
+}
+
+type ShippedPackage struct {
+	PackageID      uuid.UUID `json:"package_id"`
+	TrackingNumber string    `json:"tracking_number"`
+}
+
+type ShippedLineItem struct {
+	SkuID uuid.UUID `json:"sku_id"`
+	Qty   int       `json:"qty"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"math"
+	"time"
+)
+
+// UOM is a unit of measure of the catalogue SKUs and their packs draw from
+type UOM struct {
+	Code      string    `gorm:"type:varchar(20);primaryKey" json:"code"`
+	Name      string    `gorm:"type:varchar(50);not null" json:"name"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// SkuPack is one level of the pack hierarchy of a SKU: a pack in UOM holds Factor units
+// of the SKU's base UOM. Each level holds a whole number of the level below it.
+// Stock is always kept in the base UOM.
+type SkuPack struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	SkuID     uuid.UUID `gorm:"type:uuid;not null;index" json:"sku_id"`
+	UOM       string    `gorm:"type:varchar(20);not null" json:"uom"`
+	Factor    int       `gorm:"not null;check:factor > 1" json:"factor"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// InPacks renders a quantity of base units in packs of factor units, to three decimals
+func InPacks(qty, factor int) float64 {
+	return math.Round(float64(qty)/float64(factor)*1000) / 1000
+}
+
+// InventoryInUOM is an inventory with its quantities rendered in a requested UOM
+type InventoryInUOM struct {
+	Inventory
+	UOM           string  `json:"uom"`
+	AvailableQty  float64 `json:"available_qty"`
+	AllocatedQty  float64 `json:"allocated_qty"`
+	DamagedQty    float64 `json:"damaged_qty"`
+	QuarantineQty float64 `json:"quarantine_qty"`
+}
+
+// LotInUOM is a lot with its quantities rendered in a requested UOM
+type LotInUOM struct {
+	Lot
+	UOM           string  `json:"uom"`
+	AvailableQty  float64 `json:"available_qty"`
+	AllocatedQty  float64 `json:"allocated_qty"`
+	QuarantineQty float64 `json:"quarantine_qty"`
+}
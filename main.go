@@ -8,23 +8,34 @@ import (
 	"github.com/omniful/go_commons/http"
 	"github.com/omniful/go_commons/log"
 	"github.com/omniful/go_commons/shutdown"
+	"net"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 	"wms/init"
+	"wms/pkg"
+	"wms/pkg/auth"
+	"wms/repo"
 	"wms/router"
+	"wms/rpc"
+	"wms/service"
 )
 
 const (
 	modeWorker     = "worker"
 	modeHttp       = "http"
+	modeGrpc       = "grpc"
 	modeMigration  = "migration"
 	upMigration    = "up"
 	downMigration  = "down"
 	forceMigration = "force"
 )
 
+// grpcStopTimeout is how long shutdown waits for open RPCs before closing them. Inventory
+// watches never end by themselves, so without it a subscribed client would hang shutdown.
+const grpcStopTimeout = 10 * time.Second
+
 func main() {
 	// Initialize config
 	os.Setenv("CONFIG_SOURCE", "local")
@@ -49,7 +60,7 @@ func main() {
 		&mode,
 		"mode",
 		modeHttp,
-		"Pass the flag to run in different modes (worker or default)",
+		"Pass the flag to run in different modes (http, grpc, worker or migration)",
 	)
 
 	flag.StringVar(
@@ -74,6 +85,8 @@ func main() {
 	switch strings.ToLower(mode) {
 	case modeHttp:
 		runHttp(ctx)
+	case modeGrpc:
+		runGrpc(ctx)
 	case modeWorker:
 		//runWorker(ctx)
 	case modeMigration:
@@ -114,6 +127,45 @@ func runHttp(ctx context.Context) {
 	<-shutdown.GetWaitChannel()
 }
 
+// runGrpc serves the gRPC API on grpc.port, stopping gracefully on shutdown and closing the
+// RPCs still open after grpcStopTimeout
+func runGrpc(ctx context.Context) {
+	s := service.NewService(repo.NewRepository(pkg.GetCluster().DbCluster))
+	authenticators, err := auth.NewAuthenticators(ctx, s.AuthenticateAPIKey)
+	if err != nil {
+		log.Errorf(err.Error())
+		panic(err)
+	}
+	server := rpc.NewServer(s, authenticators)
+
+	listener, err := net.Listen("tcp", ":"+config.GetString(ctx, "grpc.port"))
+	if err != nil {
+		log.Errorf(err.Error())
+		panic(err)
+	}
+
+	log.Infof("Starting gRPC server on port " + config.GetString(ctx, "grpc.port"))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Errorf(err.Error())
+			panic(err)
+		}
+	}()
+
+	<-shutdown.GetWaitChannel()
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(grpcStopTimeout):
+		log.Infof("gRPC calls still open after %s, stopping the server", grpcStopTimeout)
+		server.Stop()
+	}
+}
+
 func runMigration(ctx context.Context, migrationType string, number string) {
 	database := config.GetString(ctx, "postgresql.database")
 	mysqlWriteHost := config.GetString(ctx, "postgresql.master.host")
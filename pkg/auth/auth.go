@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/omniful/go_commons/config"
+	"net/http"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+// APIKeyHeader is the header API keys are sent in. They are accepted as bearer tokens too.
+const APIKeyHeader = "X-API-Key"
+
+// ErrNoCredentials is returned by an authenticator when a request carries no credentials
+// of its kind, so the next authenticator gets a go
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator resolves the credentials of a request to the principal making it
+type Authenticator interface {
+	Authenticate(ctx context.Context, r *http.Request) (domain.Principal, error)
+}
+
+// JWTAuthenticator accepts bearer JWTs carrying the tenant of their subject in a
+// tenant_id claim. Roles of a roles claim hold across the tenant; unknown ones are ignored.
+type JWTAuthenticator struct {
+	Verifier *Verifier
+}
+
+func (a JWTAuthenticator) Authenticate(ctx context.Context, r *http.Request) (domain.Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok || strings.HasPrefix(token, domain.APIKeyPrefix) {
+		return domain.Principal{}, ErrNoCredentials
+	}
+
+	claims, err := a.Verifier.Verify(token, time.Now())
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	if claims.Subject == "" {
+		return domain.Principal{}, errors.New("token has no subject")
+	}
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("token has no valid tenant_id claim")
+	}
+	principal := domain.Principal{Subject: claims.Subject, TenantID: tenantID, Method: domain.AuthMethodJWT}
+	for _, role := range claims.Roles {
+		if domain.IsRole(role) {
+			principal.Grants = append(principal.Grants, domain.Grant{Role: role})
+		}
+	}
+	return principal, nil
+}
+
+// APIKeyAuthenticator looks the API key of a request up with a function, typically one
+// matching its hash against the stored keys
+type APIKeyAuthenticator func(ctx context.Context, key string) (domain.Principal, error)
+
+func (f APIKeyAuthenticator) Authenticate(ctx context.Context, r *http.Request) (domain.Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		token, ok := bearerToken(r)
+		if !ok || !strings.HasPrefix(token, domain.APIKeyPrefix) {
+			return domain.Principal{}, ErrNoCredentials
+		}
+		key = token
+	}
+	return f(ctx, key)
+}
+
+// NewAuthenticators sets up the credentials the APIs accept: API keys, looked up with
+// apiKeys, always, and JWTs when auth.jwt.jwksFile points at the JWKS of the token issuer
+func NewAuthenticators(ctx context.Context, apiKeys APIKeyAuthenticator) ([]Authenticator, error) {
+	authenticators := []Authenticator{apiKeys}
+
+	jwksFile := config.GetString(ctx, "auth.jwt.jwksFile")
+	if jwksFile == "" {
+		return authenticators, nil
+	}
+	keys, err := LoadJWKS(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+	verifier := &Verifier{
+		Keys:     keys,
+		Issuer:   config.GetString(ctx, "auth.jwt.issuer"),
+		Audience: config.GetString(ctx, "auth.jwt.audience"),
+		Leeway:   config.GetDuration(ctx, "auth.jwt.leeway"),
+	}
+	return append(authenticators, JWTAuthenticator{Verifier: verifier}), nil
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
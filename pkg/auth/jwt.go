@@ -0,0 +1,221 @@
+// Package auth verifies the credentials requests to the API carry: JWT bearer tokens
+// signed with HS256 or RS256 by a key of a local JWKS file, and API keys.
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// Key is a verification key of a JWKS, either an HMAC secret or an RSA public key
+type Key struct {
+	ID        string
+	Algorithm string
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+}
+
+// LoadJWKS reads the keys of a JSON Web Key Set file. Symmetric (oct) keys verify HS256
+// and RSA keys RS256 tokens; other key types are skipped.
+func LoadJWKS(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %v", err)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			K   string `json:"k"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %v", err)
+	}
+
+	var keys []Key
+	for i, jwk := range set.Keys {
+		key := Key{ID: jwk.Kid}
+		switch jwk.Kty {
+		case "oct":
+			key.Algorithm = HS256
+			if key.Secret, err = base64.RawURLEncoding.DecodeString(jwk.K); err != nil || len(key.Secret) == 0 {
+				return nil, fmt.Errorf("JWKS key %d: invalid secret", i+1)
+			}
+		case "RSA":
+			key.Algorithm = RS256
+			n, nErr := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, eErr := base64.RawURLEncoding.DecodeString(jwk.E)
+			if nErr != nil || eErr != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("JWKS key %d: invalid RSA public key", i+1)
+			}
+			key.PublicKey = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		default:
+			continue
+		}
+		if jwk.Alg != "" && jwk.Alg != key.Algorithm {
+			return nil, fmt.Errorf("JWKS key %d: %s key cannot be used with %s", i+1, jwk.Kty, jwk.Alg)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no HS256 or RS256 keys")
+	}
+	return keys, nil
+}
+
+// Claims are the registered claims of a token the API relies on, plus the tenant the
+// subject acts for and the roles the issuer gives it there
+type Claims struct {
+	Subject   string   `json:"sub"`
+	TenantID  string   `json:"tenant_id"`
+	Roles     []string `json:"roles"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// audience is the aud claim, which is either a single string or an array of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("invalid aud claim")
+	}
+	*a = many
+	return nil
+}
+
+// Verifier checks the signature and the validity of JWTs. Issuer and Audience are only
+// checked when set; Leeway allows for clock skew between the issuer and this server.
+type Verifier struct {
+	Keys     []Key
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Verify returns the claims of a token signed by one of the keys and valid at now.
+// Tokens must expire; the algorithm of the header has to match the key's, so an RSA
+// public key is never used as an HMAC secret.
+func (v *Verifier) Verify(token string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, errors.New("malformed token header")
+	}
+	key, err := v.key(header.Alg, header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, errors.New("malformed token signature")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch key.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return Claims{}, errors.New("invalid token signature")
+		}
+	case RS256:
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return Claims{}, errors.New("invalid token signature")
+		}
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, errors.New("malformed token claims")
+	}
+	leeway := int64(v.Leeway / time.Second)
+	if claims.ExpiresAt == 0 {
+		return Claims{}, errors.New("token has no expiry")
+	}
+	if now.Unix() > claims.ExpiresAt+leeway {
+		return Claims{}, errors.New("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore-leeway {
+		return Claims{}, errors.New("token is not valid yet")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return Claims{}, errors.New("token has an unexpected issuer")
+	}
+	if v.Audience != "" && !contains(claims.Audience, v.Audience) {
+		return Claims{}, errors.New("token is not meant for this audience")
+	}
+	return claims, nil
+}
+
+// key picks the key a token was signed with: the one with the key ID of its header, or
+// the only key of its algorithm when the header has none
+func (v *Verifier) key(alg, kid string) (Key, error) {
+	if alg != HS256 && alg != RS256 {
+		return Key{}, fmt.Errorf("unsupported token algorithm %q", alg)
+	}
+
+	var found []Key
+	for _, key := range v.Keys {
+		if key.Algorithm == alg && (kid == "" || key.ID == kid) {
+			found = append(found, key)
+		}
+	}
+	if len(found) != 1 {
+		return Key{}, errors.New("no unique key to verify the token with")
+	}
+	return found[0], nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/omniful/go_commons/config"
+	"net/http"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+// TicketParam is the query parameter stream tickets are sent in, by clients such as
+// browsers that cannot set headers on EventSource and WebSocket requests
+const TicketParam = "ticket"
+
+// defaultTicketTTL is how long a stream ticket is valid when auth.ticket.ttl is not set
+const defaultTicketTTL = time.Minute
+
+// Tickets issues and verifies stream tickets: short-lived tokens standing for the principal
+// they were issued to, signed with HMAC-SHA256. They end up in URLs and logs, so they only
+// open a stream and expire within TTL.
+type Tickets struct {
+	Secret []byte
+	TTL    time.Duration
+}
+
+// ticketClaims is the payload of a ticket
+type ticketClaims struct {
+	Principal domain.Principal `json:"principal"`
+	ExpiresAt int64            `json:"exp"`
+}
+
+// NewTickets sets up stream tickets signed with auth.ticket.secret and valid for
+// auth.ticket.ttl. Without a secret a random one is drawn, so tickets are only accepted
+// by the instance that issued them.
+func NewTickets(ctx context.Context) (*Tickets, error) {
+	tickets := &Tickets{
+		Secret: []byte(config.GetString(ctx, "auth.ticket.secret")),
+		TTL:    config.GetDuration(ctx, "auth.ticket.ttl"),
+	}
+	if len(tickets.Secret) == 0 {
+		tickets.Secret = make([]byte, 32)
+		if _, err := rand.Read(tickets.Secret); err != nil {
+			return nil, err
+		}
+	}
+	if tickets.TTL <= 0 {
+		tickets.TTL = defaultTicketTTL
+	}
+	return tickets, nil
+}
+
+// Issue returns a ticket for a principal valid from now, with its expiry
+func (t *Tickets) Issue(principal domain.Principal, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(t.TTL)
+	payload, err := json.Marshal(ticketClaims{Principal: principal, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.sign(encoded)), expiresAt, nil
+}
+
+// Verify returns the principal of a ticket signed with the secret and valid at now
+func (t *Tickets) Verify(ticket string, now time.Time) (domain.Principal, error) {
+	encoded, signature, ok := strings.Cut(ticket, ".")
+	if !ok {
+		return domain.Principal{}, errors.New("malformed ticket")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, t.sign(encoded)) {
+		return domain.Principal{}, errors.New("invalid ticket signature")
+	}
+
+	var claims ticketClaims
+	if err = decodeSegment(encoded, &claims); err != nil {
+		return domain.Principal{}, errors.New("malformed ticket")
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return domain.Principal{}, errors.New("ticket has expired")
+	}
+	return claims.Principal, nil
+}
+
+func (t *Tickets) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, t.Secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// TicketAuthenticator accepts the stream tickets of the ticket query parameter
+type TicketAuthenticator struct {
+	Tickets *Tickets
+}
+
+func (a TicketAuthenticator) Authenticate(_ context.Context, r *http.Request) (domain.Principal, error) {
+	ticket := r.URL.Query().Get(TicketParam)
+	if ticket == "" {
+		return domain.Principal{}, ErrNoCredentials
+	}
+	return a.Tickets.Verify(ticket, time.Now())
+}
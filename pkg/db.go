@@ -1,9 +1,12 @@
 package pkg
 
 import (
+	"database/sql/driver"
 	"errors"
 	"github.com/lib/pq"
 	"github.com/omniful/go_commons/db/sql/postgres"
+	"net"
+	"wms/domain"
 )
 
 type Db struct {
@@ -29,3 +32,38 @@ func IsViolatesUniqueConstraint(err error) bool {
 
 	return pqError.Code == "23505"
 }
+
+// ClassifyError gives an error the kind the API reports it as: the domain error it wraps,
+// or the kind of failure a Postgres error stands for. Anything else is internal. The
+// message is the error's own, with the detail of constraint violations.
+func ClassifyError(err error) *domain.Error {
+	var domainError *domain.Error
+	if errors.As(err, &domainError) {
+		return &domain.Error{Code: domainError.Code, Message: err.Error(), Fields: domainError.Fields}
+	}
+
+	var pqError *pq.Error
+	if errors.As(err, &pqError) {
+		message := pqError.Message
+		if pqError.Detail != "" {
+			message = pqError.Detail
+		}
+		switch {
+		case pqError.Code == "23505", pqError.Code == "40001", pqError.Code == "40P01":
+			// unique violation, serialization failure, deadlock
+			return &domain.Error{Code: domain.CodeConflict, Message: message}
+		case pqError.Code.Class() == "23", pqError.Code.Class() == "22":
+			// other integrity constraint violations and invalid data
+			return &domain.Error{Code: domain.CodeValidation, Message: message}
+		case pqError.Code.Class() == "08", pqError.Code.Class() == "53", pqError.Code.Class() == "57":
+			// connection failures, insufficient resources, operator intervention
+			return &domain.Error{Code: domain.CodeUnavailable, Message: "database is unavailable"}
+		}
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) || errors.Is(err, driver.ErrBadConn) {
+		return &domain.Error{Code: domain.CodeUnavailable, Message: "database is unavailable"}
+	}
+	return &domain.Error{Code: domain.CodeInternal, Message: err.Error()}
+}
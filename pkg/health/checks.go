@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+	"wms/domain"
+	"wms/pkg"
+)
+
+// Database checks that the master database of the cluster answers a ping
+func Database(cluster *pkg.Db) Checker {
+	return func(ctx context.Context) Check {
+		db, err := masterDB(ctx, cluster)
+		if err != nil {
+			return fail(err.Error())
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fail(err.Error())
+		}
+		if err = sqlDB.PingContext(ctx); err != nil {
+			return fail("database is unreachable: " + err.Error())
+		}
+		stats := sqlDB.Stats()
+		return Check{Status: StatusPass, Details: map[string]interface{}{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+		}}
+	}
+}
+
+// Migrations checks the version of the schema against the one the code was built for. A
+// schema behind the code, or left dirty by a failed migration, fails; one ahead of it, as
+// during a rollout migrating before the old instances are replaced, warns.
+func Migrations(cluster *pkg.Db, expected uint) Checker {
+	return func(ctx context.Context) Check {
+		db, err := masterDB(ctx, cluster)
+		if err != nil {
+			return fail(err.Error())
+		}
+		var migration struct {
+			Version uint
+			Dirty   bool
+		}
+		result := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&migration)
+		if result.Error != nil {
+			return fail("failed to read the schema version: " + result.Error.Error())
+		}
+
+		details := map[string]interface{}{"version": migration.Version, "expected": expected, "dirty": migration.Dirty}
+		switch {
+		case result.RowsAffected == 0:
+			return Check{Status: StatusFail, Message: "no migration has run", Details: details}
+		case migration.Dirty:
+			return Check{Status: StatusFail, Message: fmt.Sprintf("migration %d failed halfway", migration.Version), Details: details}
+		case migration.Version < expected:
+			return Check{Status: StatusFail, Message: "schema is behind the code", Details: details}
+		case migration.Version > expected:
+			return Check{Status: StatusWarn, Message: "schema is ahead of the code", Details: details}
+		}
+		return Check{Status: StatusPass, Details: details}
+	}
+}
+
+// maxBacklogEvents bounds the events EventBacklog counts, so a probe stays cheap however
+// far behind the streams are
+const maxBacklogEvents = 10000
+
+// EventBacklog checks the events committed but held back from the inventory streams by an
+// older transaction still running. Streams resume once it ends, so a backlog older than
+// maxLag warns rather than fails: it holds back every instance alike. At most
+// maxBacklogEvents are counted, the oldest first.
+func EventBacklog(cluster *pkg.Db, maxLag time.Duration) Checker {
+	return func(ctx context.Context) Check {
+		db, err := masterDB(ctx, cluster)
+		if err != nil {
+			return fail(err.Error())
+		}
+		var backlog struct {
+			Events int64
+			Oldest *time.Time
+		}
+		err = db.Raw(`
+			SELECT COUNT(*) AS events, MIN(created_at) AS oldest
+			FROM (
+				SELECT created_at
+				FROM events
+				WHERE type = ? AND txid >= txid_snapshot_xmin(txid_current_snapshot())
+				ORDER BY txid, id
+				LIMIT ?
+			) backlog
+		`, domain.EventInventoryChanged, maxBacklogEvents).Scan(&backlog).Error
+		if err != nil {
+			return fail("failed to read the event backlog: " + err.Error())
+		}
+
+		var lag time.Duration
+		if backlog.Oldest != nil {
+			lag = time.Since(*backlog.Oldest)
+		}
+		details := map[string]interface{}{"events": backlog.Events, "lag_seconds": int64(lag.Seconds())}
+		if maxLag > 0 && lag > maxLag {
+			return Check{Status: StatusWarn, Message: fmt.Sprintf("events are held back for more than %s", maxLag), Details: details}
+		}
+		return Check{Status: StatusPass, Details: details}
+	}
+}
+
+func masterDB(ctx context.Context, cluster *pkg.Db) (*gorm.DB, error) {
+	if cluster == nil || cluster.DbCluster == nil {
+		return nil, errors.New("database is not initialised")
+	}
+	db := cluster.GetMasterDB(ctx)
+	if db == nil {
+		return nil, errors.New("database is not initialised")
+	}
+	return db, nil
+}
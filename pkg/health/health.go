@@ -0,0 +1,76 @@
+// Package health checks the dependencies of the service for the readiness probe: each
+// check passes, warns or fails, and an instance is ready unless one fails.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Statuses of checks and of a report as a whole, worst last
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// checkTimeout bounds every check, so a hung dependency fails its check rather than the probe
+const checkTimeout = 2 * time.Second
+
+// Check is the state of one dependency. Details are what was observed, such as versions
+// or counts.
+type Check struct {
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// Checker checks a dependency, giving up when the context ends
+type Checker func(ctx context.Context) Check
+
+// Report is the state of every dependency and the worst of them
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// Ready reports whether traffic may be routed to the instance. Warnings do not hold it back.
+func (r Report) Ready() bool {
+	return r.Status != StatusFail
+}
+
+// Run runs the checkers side by side, each under its own timeout
+func Run(ctx context.Context, checkers map[string]Checker) Report {
+	report := Report{Status: StatusPass, Checks: make(map[string]Check, len(checkers))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker Checker) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			check := checker(checkCtx)
+			check.DurationMs = time.Since(start).Milliseconds()
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = check
+			if severity[check.Status] > severity[report.Status] {
+				report.Status = check.Status
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+	return report
+}
+
+var severity = map[string]int{StatusPass: 0, StatusWarn: 1, StatusFail: 2}
+
+func fail(message string) Check {
+	return Check{Status: StatusFail, Message: message}
+}
@@ -0,0 +1,177 @@
+// Package label renders label templates as ZPL for Zebra printers or as PDF for laser
+// printers. Templates are laid out in millimetres from the top left corner of the label
+// and fill their text and barcodes from named fields written as {field}.
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"wms/pkg/pdf"
+)
+
+// Element kinds
+const (
+	Text    = "text"
+	Bold    = "bold"
+	Barcode = "barcode"
+	Line    = "line"
+)
+
+// Barcode symbologies
+const (
+	Code128 = "code128"
+	GS1128  = "gs1-128"
+	EAN13   = "ean13"
+	UPCA    = "upca"
+)
+
+// dotsPerMM is the resolution of 203 dpi Zebra printers
+const dotsPerMM = 8
+
+const pointsPerMM = 72 / 25.4
+
+// Template is the layout of one label
+type Template struct {
+	Width    float64
+	Height   float64
+	Elements []Element
+}
+
+// Element is a piece of text, a barcode or a horizontal line at X, Y. Size is the text
+// height, or the bar height of a barcode whose human readable text is printed beneath.
+// A barcode spans Width; a GS1-128 barcode takes the digits of its application
+// identifiers and data, such as 00 followed by an SSCC. Value and Symbology may both be
+// filled from fields.
+type Element struct {
+	Kind      string
+	X         float64
+	Y         float64
+	Size      float64
+	Width     float64
+	Symbology string
+	Value     string
+}
+
+// fill replaces the {field} placeholders of a value
+func fill(value string, fields map[string]string) string {
+	pairs := make([]string, 0, 2*len(fields))
+	for name, v := range fields {
+		pairs = append(pairs, "{"+name+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(value)
+}
+
+// ZPL renders one label per set of fields, each printed copies times
+func ZPL(t Template, labels []map[string]string, copies int) []byte {
+	dots := func(mm float64) int { return int(mm*dotsPerMM + 0.5) }
+
+	var out bytes.Buffer
+	for _, fields := range labels {
+		fmt.Fprintf(&out, "^XA\n^CI28\n^PW%d\n^LL%d\n", dots(t.Width), dots(t.Height))
+		for _, e := range t.Elements {
+			value := zplSafe(fill(e.Value, fields))
+			switch e.Kind {
+			case Text, Bold:
+				fmt.Fprintf(&out, "^FO%d,%d^A0N,%d,%d^FD%s^FS\n", dots(e.X), dots(e.Y), dots(e.Size), dots(e.Size), value)
+			case Line:
+				fmt.Fprintf(&out, "^FO%d,%d^GB%d,0,%d^FS\n", dots(e.X), dots(e.Y), dots(e.Width), max(dots(e.Size), 1))
+			case Barcode:
+				if value == "" {
+					continue
+				}
+				symbology := fill(e.Symbology, fields)
+				modules := barcodeModules(symbology, value)
+				fmt.Fprintf(&out, "^FO%d,%d^BY%d\n", dots(e.X), dots(e.Y), max(dots(e.Width)/max(len(modules), 1), 1))
+				switch symbology {
+				case EAN13:
+					fmt.Fprintf(&out, "^BEN,%d,Y,N^FD%s^FS\n", dots(e.Size), value[:min(len(value), 12)])
+				case UPCA:
+					fmt.Fprintf(&out, "^BUN,%d,Y,N,Y^FD%s^FS\n", dots(e.Size), value[:min(len(value), 11)])
+				case GS1128:
+					fmt.Fprintf(&out, "^BCN,%d,Y,N,N,D^FD%s^FS\n", dots(e.Size), gs1Readable(value))
+				default:
+					fmt.Fprintf(&out, "^BCN,%d,Y,N,N^FD%s^FS\n", dots(e.Size), value)
+				}
+			}
+		}
+		if copies > 1 {
+			fmt.Fprintf(&out, "^PQ%d\n", copies)
+		}
+		out.WriteString("^XZ\n")
+	}
+	return out.Bytes()
+}
+
+// PDF renders one page per set of fields, each repeated copies times
+func PDF(t Template, labels []map[string]string, copies int) []byte {
+	width, height := t.Width*pointsPerMM, t.Height*pointsPerMM
+
+	doc := pdf.New()
+	for _, fields := range labels {
+		for c := 0; c < max(copies, 1); c++ {
+			page := doc.AddPage(width, height)
+			for _, e := range t.Elements {
+				value := fill(e.Value, fields)
+				x, top := e.X*pointsPerMM, height-e.Y*pointsPerMM
+				size := e.Size * pointsPerMM
+				switch e.Kind {
+				case Text:
+					page.Text(x, top-size, size, value)
+				case Bold:
+					page.BoldText(x, top-size, size, value)
+				case Line:
+					page.Line(x, top, x+e.Width*pointsPerMM, top, max(size, 0.5))
+				case Barcode:
+					if value == "" {
+						continue
+					}
+					symbology := fill(e.Symbology, fields)
+					modules := barcodeModules(symbology, value)
+					module := e.Width * pointsPerMM / float64(len(modules))
+					for i, bar := range modules {
+						if bar {
+							page.Rect(x+float64(i)*module, top-size, module, size)
+						}
+					}
+					readable := value
+					if symbology == GS1128 {
+						readable = gs1Readable(value)
+					}
+					page.Text(x, top-size-10, 8, readable)
+				}
+			}
+		}
+	}
+	return doc.Bytes()
+}
+
+// barcodeModules encodes a barcode as its modules from left to right, true being a bar
+func barcodeModules(symbology, value string) []bool {
+	switch symbology {
+	case EAN13:
+		return ean13Modules(value)
+	case UPCA:
+		return ean13Modules("0" + value)
+	case GS1128:
+		return code128Modules(value, true)
+	}
+	return code128Modules(value, false)
+}
+
+// gs1Readable puts the application identifier of a GS1-128 value in brackets. Only the
+// SSCC identifier 00 and the GTIN identifier 01 are recognised.
+func gs1Readable(value string) string {
+	switch {
+	case strings.HasPrefix(value, "00") && len(value) == 20:
+		return "(00)" + value[2:]
+	case strings.HasPrefix(value, "01") && len(value) == 16:
+		return "(01)" + value[2:]
+	}
+	return value
+}
+
+// zplSafe drops the ZPL command prefixes from field data
+func zplSafe(value string) string {
+	return strings.NewReplacer("^", " ", "~", " ").Replace(value)
+}
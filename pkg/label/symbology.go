@@ -0,0 +1,130 @@
+package label
+
+// code128Patterns are the bar and space widths of the Code 128 symbols 0-106, 106 being the stop
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128FNC1   = 102
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// code128Modules encodes a value in Code 128, using code set C for an even run of digits
+// and code set B otherwise. GS1-128 values start with FNC1.
+func code128Modules(value string, gs1 bool) []bool {
+	digits := len(value)%2 == 0
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			digits = false
+		}
+	}
+
+	symbols := []int{code128StartB}
+	if digits {
+		symbols[0] = code128StartC
+	}
+	if gs1 {
+		symbols = append(symbols, code128FNC1)
+	}
+	if digits {
+		for i := 0; i < len(value); i += 2 {
+			symbols = append(symbols, int(value[i]-'0')*10+int(value[i+1]-'0'))
+		}
+	} else {
+		for _, r := range value {
+			if r < 32 || r > 126 {
+				r = '?'
+			}
+			symbols = append(symbols, int(r)-32)
+		}
+	}
+
+	check := symbols[0]
+	for i, symbol := range symbols[1:] {
+		check += (i + 1) * symbol
+	}
+	symbols = append(symbols, check%103, code128Stop)
+
+	var modules []bool
+	for _, symbol := range symbols {
+		for i, width := range code128Patterns[symbol] {
+			for w := 0; w < int(width-'0'); w++ {
+				modules = append(modules, i%2 == 0)
+			}
+		}
+	}
+	return modules
+}
+
+// eanL are the left hand odd parity digit patterns of EAN-13; the even parity patterns are
+// them reversed and inverted, and the right hand patterns them inverted
+var eanL = [...]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+
+// eanParity picks odd (L) or even (G) parity for the left hand digits from the first digit
+var eanParity = [...]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+
+// ean13Modules encodes 13 digits as EAN-13. Anything else falls back to Code 128.
+func ean13Modules(value string) []bool {
+	if len(value) != 13 {
+		return code128Modules(value, false)
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return code128Modules(value, false)
+		}
+	}
+
+	pattern := "101"
+	parity := eanParity[value[0]-'0']
+	for i := 1; i <= 6; i++ {
+		l := eanL[value[i]-'0']
+		if parity[i-1] == 'G' {
+			l = reverse(invert(l))
+		}
+		pattern += l
+	}
+	pattern += "01010"
+	for i := 7; i <= 12; i++ {
+		pattern += invert(eanL[value[i]-'0'])
+	}
+	pattern += "101"
+
+	modules := make([]bool, len(pattern))
+	for i := range pattern {
+		modules[i] = pattern[i] == '1'
+	}
+	return modules
+}
+
+func invert(bits string) string {
+	b := []byte(bits)
+	for i := range b {
+		if b[i] == '0' {
+			b[i] = '1'
+		} else {
+			b[i] = '0'
+		}
+	}
+	return string(b)
+}
+
+func reverse(bits string) string {
+	b := []byte(bits)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
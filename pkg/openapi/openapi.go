@@ -0,0 +1,490 @@
+// Package openapi builds the OpenAPI 3 document of the API from a table of its
+// operations, deriving the schemas of request and response bodies from their Go types,
+// and checks the table against the routes gin actually serves.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Operation documents one route of the API
+type Operation struct {
+	Method     string
+	Path       string // relative to the base path, as registered with gin, e.g. /hub/:id
+	ID         string
+	Tag        string
+	Summary    string
+	Permission string // permission the route requires, empty when any principal may call it
+	// Permissions are the permissions any of which the route accepts, when there are several
+	Permissions []string
+	Public      bool // served without credentials
+	Ticket      bool // also accepts a stream ticket in the ticket query parameter
+	Query       []Param
+	// Request is a value of the type of the JSON body, nil when the route takes none.
+	// OptionalBody marks bodies that may be left out.
+	Request      interface{}
+	OptionalBody bool
+	// Response is a value of the type of data in the success envelope, nil when data is null
+	Response interface{}
+	Status   int  // status of success, 200 when zero
+	Location bool // the success response points the Location header at the created resource
+	// ContentTypes are the media types of files a success response may be instead of the
+	// JSON envelope, which is left out when there is no Response
+	ContentTypes []string
+	// Stream is a value of the type of the messages of a streaming response: server-sent
+	// events, or WebSocket messages when Status is 101
+	Stream interface{}
+}
+
+// Param is a query parameter. Format is a string format such as uuid or date, or integer
+// for numbers.
+type Param struct {
+	Name        string
+	Description string
+	Format      string
+	Required    bool
+}
+
+// Spec describes the API as a whole. Enums lists the values of the custom validation
+// tags of request fields, which are documented as enums.
+type Spec struct {
+	Title    string
+	Version  string
+	BasePath string
+	Enums    map[string][]string
+}
+
+// Document returns the OpenAPI 3 document of the operations
+func (s Spec) Document(operations []Operation) map[string]interface{} {
+	gen := &generator{enums: s.Enums, components: map[string]interface{}{}, names: map[reflect.Type]string{}}
+	gen.components["Error"] = errorSchema
+
+	paths := map[string]interface{}{}
+	tags := map[string]bool{}
+	for _, op := range operations {
+		path := pathTemplate(op.Path)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = gen.operation(op)
+		tags[op.Tag] = true
+	}
+
+	tagList := make([]interface{}, 0, len(tags))
+	for _, tag := range sortedKeys(tags) {
+		tagList = append(tagList, map[string]interface{}{"name": tag})
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": s.Title, "version": s.Version},
+		"servers": []interface{}{map[string]interface{}{"url": s.BasePath}},
+		"tags":    tagList,
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"apiKey":     map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"streamTicket": map[string]interface{}{"type": "apiKey", "in": "query", "name": "ticket",
+					"description": "Short-lived ticket issued by POST /inventory/stream/ticket, for the streams only"},
+			},
+		},
+		"security": []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+			map[string]interface{}{"apiKey": []string{}},
+		},
+	}
+}
+
+// Check compares the routes registered under basePath with the operations, failing when a
+// route is not documented or a documented route is not registered
+func Check(basePath string, routes gin.RoutesInfo, operations []Operation) error {
+	documented := map[string]bool{}
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	var undocumented []string
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, basePath) {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, basePath)
+		if path == "" {
+			path = "/"
+		}
+		key := route.Method + " " + path
+		if !documented[key] {
+			undocumented = append(undocumented, key)
+		}
+		delete(documented, key)
+	}
+
+	var problems []string
+	if len(undocumented) > 0 {
+		sort.Strings(undocumented)
+		problems = append(problems, "routes missing from the OpenAPI document: "+strings.Join(undocumented, ", "))
+	}
+	if len(documented) > 0 {
+		problems = append(problems, "documented routes that are not registered: "+strings.Join(sortedKeys(documented), ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+var errorSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"status", "code", "message"},
+	"properties": map[string]interface{}{
+		"status":  map[string]interface{}{"type": "string", "enum": []string{"error"}},
+		"code":    map[string]interface{}{"type": "string"},
+		"message": map[string]interface{}{"type": "string"},
+		"errors": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"field":   map[string]interface{}{"type": "string"},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+type generator struct {
+	enums      map[string][]string
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+func (g *generator) operation(op Operation) map[string]interface{} {
+	operation := map[string]interface{}{
+		"operationId": op.ID,
+		"tags":        []string{op.Tag},
+		"summary":     op.Summary,
+	}
+	if op.Permission != "" {
+		operation["description"] = "Requires the " + op.Permission + " permission."
+	}
+	if len(op.Permissions) > 0 {
+		operation["description"] = "Requires the " + strings.Join(op.Permissions, " or ") + " permission."
+	}
+	if op.Public {
+		operation["security"] = []interface{}{}
+	}
+	if op.Ticket {
+		operation["security"] = []interface{}{
+			map[string]interface{}{"bearerAuth": []string{}},
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"streamTicket": []string{}},
+		}
+	}
+
+	var parameters []interface{}
+	for _, name := range pathParams(op.Path) {
+		schema := map[string]interface{}{"type": "string"}
+		if name == "id" {
+			schema["format"] = "uuid"
+		}
+		parameters = append(parameters, map[string]interface{}{"name": name, "in": "path", "required": true, "schema": schema})
+	}
+	for _, param := range op.Query {
+		schema := map[string]interface{}{"type": "string"}
+		if param.Format == "integer" {
+			schema["type"] = "integer"
+		} else if param.Format != "" {
+			schema["format"] = param.Format
+		}
+		parameter := map[string]interface{}{"name": param.Name, "in": "query", "required": param.Required, "schema": schema}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.Request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": !op.OptionalBody,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": g.schema(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	content := map[string]interface{}{}
+	for _, contentType := range op.ContentTypes {
+		content[contentType] = map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}
+	}
+	if op.Stream != nil && status != http.StatusSwitchingProtocols {
+		content["text/event-stream"] = map[string]interface{}{"schema": g.schema(reflect.TypeOf(op.Stream))}
+	}
+	if op.Response != nil || (len(op.ContentTypes) == 0 && op.Stream == nil) {
+		data := map[string]interface{}{"nullable": true}
+		if op.Response != nil {
+			data = g.schema(reflect.TypeOf(op.Response))
+		}
+		content["application/json"] = map[string]interface{}{"schema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"status":  map[string]interface{}{"type": "string", "enum": []string{"success"}},
+				"message": map[string]interface{}{"type": "string"},
+				"data":    data,
+			},
+		}}
+	}
+	success := map[string]interface{}{"description": http.StatusText(status), "content": content}
+	if op.Stream != nil && status == http.StatusSwitchingProtocols {
+		// OpenAPI has no way to describe WebSocket messages but as an extension
+		success = map[string]interface{}{"description": http.StatusText(status), "x-websocket-message": g.schema(reflect.TypeOf(op.Stream))}
+	}
+	if op.Location {
+		success["headers"] = map[string]interface{}{
+			"Location": map[string]interface{}{
+				"description": "URL of the created resource",
+				"schema":      map[string]interface{}{"type": "string"},
+			},
+		}
+	}
+	operation["responses"] = map[string]interface{}{
+		fmt.Sprint(status): success,
+		"default": map[string]interface{}{
+			"description": "Error",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}},
+			},
+		},
+	}
+	return operation
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	uuidType      = reflect.TypeOf(uuid.UUID{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema returns the schema of values of a type as encoding/json writes them. Named
+// structs become components referenced by name.
+func (g *generator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case t.Implements(marshalerType):
+		// raw JSON, such as the dimensions of a SKU or the states of an audit entry
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			g.names[t] = name
+			g.components[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// componentName names the component of a struct after the type, qualified by its package
+// when another package has a type of the same name
+func (g *generator) componentName(t reflect.Type) string {
+	name := exported(t.Name())
+	if _, taken := g.components[name]; taken {
+		name = exported(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+	}
+	return name
+}
+
+func (g *generator) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	g.fields(t, properties, &required)
+
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// fields adds the properties of the fields of a struct, those of embedded structs
+// included, with the constraints of their binding tags
+func (g *generator) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.SplitN(tag, ",", 2)[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := g.schema(field.Type)
+		if g.constrain(schema, field.Type, field.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// constrain adds the rules of a binding tag up to dive to the schema of a field, reporting
+// whether the field is required. Referenced schemas are left as they are.
+func (g *generator) constrain(schema map[string]interface{}, t reflect.Type, binding string) bool {
+	if binding == "" {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, ref := schema["$ref"]
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			break
+		}
+		if name == "required" {
+			required = true
+		}
+		if ref {
+			continue
+		}
+
+		bound, _ := strconv.ParseFloat(param, 64)
+		switch name {
+		case "gt":
+			schema["minimum"], schema["exclusiveMinimum"] = bound, true
+		case "gte":
+			schema["minimum"] = bound
+		case "lt":
+			schema["maximum"], schema["exclusiveMaximum"] = bound, true
+		case "lte":
+			schema["maximum"] = bound
+		case "min", "max":
+			switch t.Kind() {
+			case reflect.String:
+				schema[name+"Length"] = bound
+			case reflect.Slice, reflect.Array:
+				schema[name+"Items"] = bound
+			case reflect.Int, reflect.Int64, reflect.Float64:
+				schema[map[string]string{"min": "minimum", "max": "maximum"}[name]] = bound
+			}
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "datetime":
+			if param == "2006-01-02" {
+				schema["format"] = "date"
+			} else {
+				schema["description"] = "formatted as " + param
+			}
+		default:
+			if values, ok := g.enums[name]; ok {
+				schema["enum"] = values
+			}
+		}
+	}
+	return required
+}
+
+// pathTemplate turns the parameters of a gin path into those of an OpenAPI path template
+func pathTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+		}
+	}
+	return params
+}
+
+func exported(name string) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,488 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proto/inventory.proto
+
+// The gRPC API of the WMS, next to the HTTP one and backed by the same service layer.
+// Calls carry credentials in metadata: "authorization: Bearer <JWT or API key>" or
+// "x-api-key: <API key>". Domain errors map to status codes, with an ErrorInfo detail
+// whose reason is the error code of the HTTP API.
+//
+// Regenerate pkg/pb after changing this file:
+//   protoc --go_out=. --go_opt=module=wms --go-grpc_out=. --go-grpc_opt=module=wms proto/inventory.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	InventoryService_ListHubs_FullMethodName          = "/wms.v1.InventoryService/ListHubs"
+	InventoryService_GetHub_FullMethodName            = "/wms.v1.InventoryService/GetHub"
+	InventoryService_CreateHub_FullMethodName         = "/wms.v1.InventoryService/CreateHub"
+	InventoryService_ListSkus_FullMethodName          = "/wms.v1.InventoryService/ListSkus"
+	InventoryService_GetSku_FullMethodName            = "/wms.v1.InventoryService/GetSku"
+	InventoryService_CreateSku_FullMethodName         = "/wms.v1.InventoryService/CreateSku"
+	InventoryService_GetInventory_FullMethodName      = "/wms.v1.InventoryService/GetInventory"
+	InventoryService_AllocateInventory_FullMethodName = "/wms.v1.InventoryService/AllocateInventory"
+	InventoryService_DecreaseInventory_FullMethodName = "/wms.v1.InventoryService/DecreaseInventory"
+	InventoryService_WatchInventory_FullMethodName    = "/wms.v1.InventoryService/WatchInventory"
+)
+
+// InventoryServiceClient is the client API for InventoryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type InventoryServiceClient interface {
+	ListHubs(ctx context.Context, in *ListHubsRequest, opts ...grpc.CallOption) (*ListHubsResponse, error)
+	GetHub(ctx context.Context, in *GetHubRequest, opts ...grpc.CallOption) (*Hub, error)
+	CreateHub(ctx context.Context, in *CreateHubRequest, opts ...grpc.CallOption) (*Hub, error)
+	ListSkus(ctx context.Context, in *ListSkusRequest, opts ...grpc.CallOption) (*ListSkusResponse, error)
+	GetSku(ctx context.Context, in *GetSkuRequest, opts ...grpc.CallOption) (*Sku, error)
+	CreateSku(ctx context.Context, in *CreateSkuRequest, opts ...grpc.CallOption) (*Sku, error)
+	GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error)
+	// AllocateInventory allocates available stock first-expired-first-out
+	AllocateInventory(ctx context.Context, in *AllocateInventoryRequest, opts ...grpc.CallOption) (*Inventory, error)
+	// DecreaseInventory removes available stock first-expired-first-out
+	DecreaseInventory(ctx context.Context, in *DecreaseInventoryRequest, opts ...grpc.CallOption) (*Inventory, error)
+	// WatchInventory streams the inventory changes of the caller's tenant, narrowed down to
+	// a hub and SKUs. It starts after after_event_id, or from now when it is 0; clients
+	// reconnecting pass the id of the last event they received.
+	WatchInventory(ctx context.Context, in *WatchInventoryRequest, opts ...grpc.CallOption) (InventoryService_WatchInventoryClient, error)
+}
+
+type inventoryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInventoryServiceClient(cc grpc.ClientConnInterface) InventoryServiceClient {
+	return &inventoryServiceClient{cc}
+}
+
+func (c *inventoryServiceClient) ListHubs(ctx context.Context, in *ListHubsRequest, opts ...grpc.CallOption) (*ListHubsResponse, error) {
+	out := new(ListHubsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListHubs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetHub(ctx context.Context, in *GetHubRequest, opts ...grpc.CallOption) (*Hub, error) {
+	out := new(Hub)
+	err := c.cc.Invoke(ctx, InventoryService_GetHub_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) CreateHub(ctx context.Context, in *CreateHubRequest, opts ...grpc.CallOption) (*Hub, error) {
+	out := new(Hub)
+	err := c.cc.Invoke(ctx, InventoryService_CreateHub_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListSkus(ctx context.Context, in *ListSkusRequest, opts ...grpc.CallOption) (*ListSkusResponse, error) {
+	out := new(ListSkusResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListSkus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetSku(ctx context.Context, in *GetSkuRequest, opts ...grpc.CallOption) (*Sku, error) {
+	out := new(Sku)
+	err := c.cc.Invoke(ctx, InventoryService_GetSku_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) CreateSku(ctx context.Context, in *CreateSkuRequest, opts ...grpc.CallOption) (*Sku, error) {
+	out := new(Sku)
+	err := c.cc.Invoke(ctx, InventoryService_CreateSku_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error) {
+	out := new(Inventory)
+	err := c.cc.Invoke(ctx, InventoryService_GetInventory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) AllocateInventory(ctx context.Context, in *AllocateInventoryRequest, opts ...grpc.CallOption) (*Inventory, error) {
+	out := new(Inventory)
+	err := c.cc.Invoke(ctx, InventoryService_AllocateInventory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) DecreaseInventory(ctx context.Context, in *DecreaseInventoryRequest, opts ...grpc.CallOption) (*Inventory, error) {
+	out := new(Inventory)
+	err := c.cc.Invoke(ctx, InventoryService_DecreaseInventory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) WatchInventory(ctx context.Context, in *WatchInventoryRequest, opts ...grpc.CallOption) (InventoryService_WatchInventoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &InventoryService_ServiceDesc.Streams[0], InventoryService_WatchInventory_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &inventoryServiceWatchInventoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type InventoryService_WatchInventoryClient interface {
+	Recv() (*InventoryEvent, error)
+	grpc.ClientStream
+}
+
+type inventoryServiceWatchInventoryClient struct {
+	grpc.ClientStream
+}
+
+func (x *inventoryServiceWatchInventoryClient) Recv() (*InventoryEvent, error) {
+	m := new(InventoryEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// InventoryServiceServer is the server API for InventoryService service.
+// All implementations must embed UnimplementedInventoryServiceServer
+// for forward compatibility
+type InventoryServiceServer interface {
+	ListHubs(context.Context, *ListHubsRequest) (*ListHubsResponse, error)
+	GetHub(context.Context, *GetHubRequest) (*Hub, error)
+	CreateHub(context.Context, *CreateHubRequest) (*Hub, error)
+	ListSkus(context.Context, *ListSkusRequest) (*ListSkusResponse, error)
+	GetSku(context.Context, *GetSkuRequest) (*Sku, error)
+	CreateSku(context.Context, *CreateSkuRequest) (*Sku, error)
+	GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error)
+	// AllocateInventory allocates available stock first-expired-first-out
+	AllocateInventory(context.Context, *AllocateInventoryRequest) (*Inventory, error)
+	// DecreaseInventory removes available stock first-expired-first-out
+	DecreaseInventory(context.Context, *DecreaseInventoryRequest) (*Inventory, error)
+	// WatchInventory streams the inventory changes of the caller's tenant, narrowed down to
+	// a hub and SKUs. It starts after after_event_id, or from now when it is 0; clients
+	// reconnecting pass the id of the last event they received.
+	WatchInventory(*WatchInventoryRequest, InventoryService_WatchInventoryServer) error
+	mustEmbedUnimplementedInventoryServiceServer()
+}
+
+// UnimplementedInventoryServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedInventoryServiceServer struct {
+}
+
+func (UnimplementedInventoryServiceServer) ListHubs(context.Context, *ListHubsRequest) (*ListHubsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHubs not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetHub(context.Context, *GetHubRequest) (*Hub, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHub not implemented")
+}
+func (UnimplementedInventoryServiceServer) CreateHub(context.Context, *CreateHubRequest) (*Hub, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateHub not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListSkus(context.Context, *ListSkusRequest) (*ListSkusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSkus not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetSku(context.Context, *GetSkuRequest) (*Sku, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSku not implemented")
+}
+func (UnimplementedInventoryServiceServer) CreateSku(context.Context, *CreateSkuRequest) (*Sku, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSku not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInventory not implemented")
+}
+func (UnimplementedInventoryServiceServer) AllocateInventory(context.Context, *AllocateInventoryRequest) (*Inventory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AllocateInventory not implemented")
+}
+func (UnimplementedInventoryServiceServer) DecreaseInventory(context.Context, *DecreaseInventoryRequest) (*Inventory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecreaseInventory not implemented")
+}
+func (UnimplementedInventoryServiceServer) WatchInventory(*WatchInventoryRequest, InventoryService_WatchInventoryServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchInventory not implemented")
+}
+func (UnimplementedInventoryServiceServer) mustEmbedUnimplementedInventoryServiceServer() {}
+
+// UnsafeInventoryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InventoryServiceServer will
+// result in compilation errors.
+type UnsafeInventoryServiceServer interface {
+	mustEmbedUnimplementedInventoryServiceServer()
+}
+
+func RegisterInventoryServiceServer(s grpc.ServiceRegistrar, srv InventoryServiceServer) {
+	s.RegisterService(&InventoryService_ServiceDesc, srv)
+}
+
+func _InventoryService_ListHubs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHubsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListHubs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListHubs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListHubs(ctx, req.(*ListHubsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetHub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetHub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetHub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetHub(ctx, req.(*GetHubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_CreateHub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CreateHub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CreateHub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CreateHub(ctx, req.(*CreateHubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListSkus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSkusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListSkus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListSkus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListSkus(ctx, req.(*ListSkusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetSku_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSkuRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetSku(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetSku_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetSku(ctx, req.(*GetSkuRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_CreateSku_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSkuRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CreateSku(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CreateSku_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CreateSku(ctx, req.(*CreateSkuRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetInventory(ctx, req.(*GetInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_AllocateInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AllocateInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).AllocateInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_AllocateInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).AllocateInventory(ctx, req.(*AllocateInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_DecreaseInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecreaseInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).DecreaseInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_DecreaseInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).DecreaseInventory(ctx, req.(*DecreaseInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_WatchInventory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchInventoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InventoryServiceServer).WatchInventory(m, &inventoryServiceWatchInventoryServer{stream})
+}
+
+type InventoryService_WatchInventoryServer interface {
+	Send(*InventoryEvent) error
+	grpc.ServerStream
+}
+
+type inventoryServiceWatchInventoryServer struct {
+	grpc.ServerStream
+}
+
+func (x *inventoryServiceWatchInventoryServer) Send(m *InventoryEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InventoryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wms.v1.InventoryService",
+	HandlerType: (*InventoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListHubs",
+			Handler:    _InventoryService_ListHubs_Handler,
+		},
+		{
+			MethodName: "GetHub",
+			Handler:    _InventoryService_GetHub_Handler,
+		},
+		{
+			MethodName: "CreateHub",
+			Handler:    _InventoryService_CreateHub_Handler,
+		},
+		{
+			MethodName: "ListSkus",
+			Handler:    _InventoryService_ListSkus_Handler,
+		},
+		{
+			MethodName: "GetSku",
+			Handler:    _InventoryService_GetSku_Handler,
+		},
+		{
+			MethodName: "CreateSku",
+			Handler:    _InventoryService_CreateSku_Handler,
+		},
+		{
+			MethodName: "GetInventory",
+			Handler:    _InventoryService_GetInventory_Handler,
+		},
+		{
+			MethodName: "AllocateInventory",
+			Handler:    _InventoryService_AllocateInventory_Handler,
+		},
+		{
+			MethodName: "DecreaseInventory",
+			Handler:    _InventoryService_DecreaseInventory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchInventory",
+			Handler:       _InventoryService_WatchInventory_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/inventory.proto",
+}
@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+// auditChainLock is the advisory lock serialising appends to the audit hash chains, taken
+// per tenant together with a hash of the tenant ID
+const auditChainLock = 43
+
+// auditVerifyBatch is the number of entries read at a time when verifying the chain
+const auditVerifyBatch = 1000
+
+type AuditRepository interface {
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (domain.AuditEntry, error)
+	GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	VerifyAuditChain(ctx context.Context, tenantID *uuid.UUID) (domain.AuditVerification, error)
+}
+
+// AppendAuditEntry links an entry to the last one of its tenant's chain and stores it.
+// Within a transaction the entry is only stored if the transaction commits, and the chain
+// of the tenant stays locked until then.
+func (r *repository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", auditChainLock, auditChainKey(entry.TenantID)).Error; err != nil {
+			return fmt.Errorf("failed to lock audit log: %w", err)
+		}
+
+		var last []domain.AuditEntry
+		if err := auditChain(tx, entry.TenantID).Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+			return fmt.Errorf("failed to fetch audit log: %w", err)
+		}
+		entry.PrevHash = ""
+		if len(last) > 0 {
+			entry.PrevHash = last[0].Hash
+		}
+		entry.Hash = entry.ComputeHash()
+
+		if err := tx.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to record audit entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.AuditEntry{}, err
+	}
+	return entry, nil
+}
+
+func (r *repository) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	query := r.master(ctx).Where("tenant_id = ? AND seq > ?", filter.TenantID, filter.AfterSeq)
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var entries []domain.AuditEntry
+	if err := query.Order("seq").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyAuditChain walks the audit chain of a tenant in order, or the chain of entries
+// without a tenant when tenantID is nil, recomputing each hash and checking that each
+// entry links to the one before it
+func (r *repository) VerifyAuditChain(ctx context.Context, tenantID *uuid.UUID) (domain.AuditVerification, error) {
+	db := r.master(ctx)
+
+	verification := domain.AuditVerification{Valid: true}
+	prevHash, afterSeq := "", int64(0)
+	for {
+		var entries []domain.AuditEntry
+		err := auditChain(db, tenantID).Where("seq > ?", afterSeq).Order("seq").Limit(auditVerifyBatch).Find(&entries).Error
+		if err != nil {
+			return domain.AuditVerification{}, fmt.Errorf("failed to fetch audit entries: %w", err)
+		}
+
+		for _, entry := range entries {
+			reason := ""
+			switch {
+			case entry.PrevHash != prevHash:
+				reason = "entry does not link to the entry before it"
+			case entry.ComputeHash() != entry.Hash:
+				reason = "entry does not match its hash"
+			}
+			if reason != "" {
+				seq := entry.Seq
+				verification.Valid = false
+				verification.BrokenAt = &seq
+				verification.Reason = reason
+				return verification, nil
+			}
+			verification.Entries++
+			prevHash, afterSeq = entry.Hash, entry.Seq
+		}
+		if len(entries) < auditVerifyBatch {
+			return verification, nil
+		}
+	}
+}
+
+// auditChain narrows a query down to the audit chain of a tenant, or to the entries
+// without a tenant when tenantID is nil
+func auditChain(db *gorm.DB, tenantID *uuid.UUID) *gorm.DB {
+	if tenantID == nil {
+		return db.Where("tenant_id IS NULL")
+	}
+	return db.Where("tenant_id = ?", *tenantID)
+}
+
+func auditChainKey(tenantID *uuid.UUID) string {
+	if tenantID == nil {
+		return ""
+	}
+	return tenantID.String()
+}
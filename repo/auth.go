@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"wms/domain"
+)
+
+type AuthRepository interface {
+	CreateAPIKey(ctx context.Context, key domain.APIKey, grant domain.Grant) (domain.APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id uuid.UUID) (domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error)
+	GetAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+// CreateAPIKey stores a key together with the role it is assigned
+func (r *repository) CreateAPIKey(ctx context.Context, key domain.APIKey, grant domain.Grant) (domain.APIKey, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&key).Error; err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		assignment := domain.RoleAssignment{
+			TenantID: key.TenantID,
+			Subject:  domain.APIKeySubject(key.ID),
+			Role:     grant.Role,
+			HubID:    grant.HubID,
+		}
+		if err := tx.Create(&assignment).Error; err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (r *repository) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.master(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		return domain.APIKey{}, notFound(err, "API key")
+	}
+	return key, nil
+}
+
+func (r *repository) GetAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.master(ctx).Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return domain.APIKey{}, notFound(err, "API key")
+	}
+	return key, nil
+}
+
+func (r *repository) GetAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	if err := r.master(ctx).Where("tenant_id = ?", tenantID).Order("created_at").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// TouchAPIKey records the use of a key, at most once a minute so busy integrations do not
+// write on every request
+func (r *repository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	err := r.master(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	result := r.master(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("API key not found or already revoked")
+	}
+	return nil
+}
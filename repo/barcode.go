@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+type BarcodeRepository interface {
+	CreateBarcode(ctx context.Context, barcode domain.Barcode) (domain.Barcode, error)
+	GetBarcodes(ctx context.Context, skuID, locationID *uuid.UUID) ([]domain.Barcode, error)
+	GetBarcodeByID(ctx context.Context, id uuid.UUID) (domain.Barcode, error)
+	DeleteBarcode(ctx context.Context, id uuid.UUID) error
+	ResolveScan(ctx context.Context, code string, hubID *uuid.UUID) (domain.ScanResult, error)
+}
+
+func (r *repository) CreateBarcode(ctx context.Context, barcode domain.Barcode) (domain.Barcode, error) {
+	var taken int64
+	err := r.master(ctx).Model(&domain.Barcode{}).Where("code = ?", barcode.Code).Count(&taken).Error
+	if err != nil {
+		return domain.Barcode{}, fmt.Errorf("failed to fetch barcodes: %w", err)
+	}
+	if taken > 0 {
+		return domain.Barcode{}, domain.Conflict("barcode %s is already registered", barcode.Code)
+	}
+
+	if err = r.master(ctx).Create(&barcode).Error; err != nil {
+		return domain.Barcode{}, fmt.Errorf("failed to create barcode: %w", err)
+	}
+	return barcode, nil
+}
+
+// GetBarcodes lists the barcodes of a SKU, its pack levels included, or of a location
+func (r *repository) GetBarcodes(ctx context.Context, skuID, locationID *uuid.UUID) ([]domain.Barcode, error) {
+	query := r.master(ctx)
+	if skuID != nil {
+		query = query.Where("sku_id = ?", *skuID)
+	}
+	if locationID != nil {
+		query = query.Where("location_id = ?", *locationID)
+	}
+
+	var barcodes []domain.Barcode
+	if err := query.Order("created_at").Find(&barcodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch barcodes: %w", err)
+	}
+	return barcodes, nil
+}
+
+func (r *repository) GetBarcodeByID(ctx context.Context, id uuid.UUID) (domain.Barcode, error) {
+	var barcode domain.Barcode
+	err := r.master(ctx).Where("id = ?", id).First(&barcode).Error
+	if err != nil {
+		return domain.Barcode{}, notFound(err, "barcode")
+	}
+	return barcode, nil
+}
+
+func (r *repository) DeleteBarcode(ctx context.Context, id uuid.UUID) error {
+	result := r.master(ctx).Where("id = ?", id).Delete(&domain.Barcode{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete barcode: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("barcode not found")
+	}
+	return nil
+}
+
+// ResolveScan looks a scanned value up in one query against registered barcodes, SKU codes,
+// serial numbers, lot numbers and location codes, in that order of precedence. A hub ID
+// narrows serials, lots and locations down to that hub.
+func (r *repository) ResolveScan(ctx context.Context, code string, hubID *uuid.UUID) (domain.ScanResult, error) {
+	db := r.master(ctx)
+
+	var matches []struct {
+		Rank int
+		Kind string
+		ID   uuid.UUID
+	}
+	err := db.Raw(`
+		SELECT rank, kind, id FROM (
+		    SELECT 1 AS rank, 'barcode' AS kind, id FROM barcodes WHERE code = $1
+		    UNION ALL
+		    SELECT 2, 'sku', id FROM skus WHERE code = $1
+		    UNION ALL
+		    SELECT 3, 'serial', id FROM serials WHERE serial_number = $1 AND ($2::uuid IS NULL OR hub_id = $2)
+		    UNION ALL
+		    SELECT 4, 'lot', id FROM lots WHERE lot_number = $1 AND ($2::uuid IS NULL OR hub_id = $2)
+		    UNION ALL
+		    SELECT 5, 'location', id FROM locations WHERE code = $1 AND ($2::uuid IS NULL OR hub_id = $2)
+		) m
+		ORDER BY rank
+	`, code, hubID).Scan(&matches).Error
+	if err != nil {
+		return domain.ScanResult{}, fmt.Errorf("failed to resolve scan: %w", err)
+	}
+	if len(matches) == 0 {
+		return domain.ScanResult{}, domain.NotFound("%s does not match any barcode, SKU, serial, lot or location", code)
+	}
+
+	kind := matches[0].Kind
+	var ids []uuid.UUID
+	for _, match := range matches {
+		if match.Rank == matches[0].Rank {
+			ids = append(ids, match.ID)
+		}
+	}
+
+	result := domain.ScanResult{Code: code}
+	switch kind {
+	case "barcode":
+		var barcode domain.Barcode
+		if err = db.Where("id = ?", ids[0]).First(&barcode).Error; err != nil {
+			return domain.ScanResult{}, notFound(err, "barcode")
+		}
+		result.Barcode = &barcode
+		if barcode.LocationID != nil {
+			result.Kind = domain.ScanKindLocation
+			result.Location, err = scanLocation(db, *barcode.LocationID)
+			return result, err
+		}
+		result.Kind = domain.ScanKindSku
+		if barcode.UOM != "" {
+			result.Kind = domain.ScanKindPack
+			result.UOM = barcode.UOM
+		}
+		result.Sku, err = scanSku(db, *barcode.SkuID)
+		return result, err
+
+	case "sku":
+		if len(ids) > 1 {
+			return domain.ScanResult{}, domain.Invalid("%s is the code of %d SKUs of different sellers, scan a barcode instead", code, len(ids))
+		}
+		result.Kind = domain.ScanKindSku
+		result.Sku, err = scanSku(db, ids[0])
+		return result, err
+
+	case "serial":
+		result.Kind = domain.ScanKindSerial
+		if err = db.Where("id IN ?", ids).Order("created_at").Find(&result.Serials).Error; err != nil {
+			return domain.ScanResult{}, fmt.Errorf("failed to fetch serials: %w", err)
+		}
+		if len(result.Serials) == 1 {
+			result.Sku, err = scanSku(db, result.Serials[0].SkuID)
+		}
+		return result, err
+
+	case "lot":
+		result.Kind = domain.ScanKindLot
+		if err = db.Where("id IN ?", ids).Order("created_at").Find(&result.Lots).Error; err != nil {
+			return domain.ScanResult{}, fmt.Errorf("failed to fetch lots: %w", err)
+		}
+		skuID := result.Lots[0].SkuID
+		for _, lot := range result.Lots {
+			if lot.SkuID != skuID {
+				return result, nil
+			}
+		}
+		result.Sku, err = scanSku(db, skuID)
+		return result, err
+	}
+
+	if len(ids) > 1 {
+		return domain.ScanResult{}, domain.Invalid("%s is a location code at %d hubs, pass a hub ID", code, len(ids))
+	}
+	result.Kind = domain.ScanKindLocation
+	result.Location, err = scanLocation(db, ids[0])
+	return result, err
+}
+
+func scanSku(db *gorm.DB, id uuid.UUID) (*domain.SKU, error) {
+	var sku domain.SKU
+	if err := db.Where("id = ?", id).First(&sku).Error; err != nil {
+		return nil, notFound(err, "SKU")
+	}
+	return &sku, nil
+}
+
+func scanLocation(db *gorm.DB, id uuid.UUID) (*domain.Location, error) {
+	var location domain.Location
+	if err := db.Where("id = ?", id).First(&location).Error; err != nil {
+		return nil, notFound(err, "location")
+	}
+	return &location, nil
+}
@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+type DamageRepository interface {
+	CreateStockTransition(ctx context.Context, transition domain.StockTransition) (domain.StockTransition, error)
+	GetStockTransitionByID(ctx context.Context, id uuid.UUID) (domain.StockTransition, error)
+	GetStockTransitions(ctx context.Context, hubID uuid.UUID, transitionType string, skuID *uuid.UUID) ([]domain.StockTransition, error)
+}
+
+// bucketSerialStatus is the status of the serials of units held in a ledger bucket.
+// Units leaving the hub have their serials removed.
+var bucketSerialStatus = map[string]string{
+	domain.LedgerBucketAvailable:  domain.SerialStatusAvailable,
+	domain.LedgerBucketQuarantine: domain.SerialStatusQuarantined,
+	domain.LedgerBucketDamaged:    domain.SerialStatusDamaged,
+	"":                            domain.SerialStatusRemoved,
+}
+
+// transitionOutBuckets is the ledger bucket recorded for units a transition takes out of the hub
+var transitionOutBuckets = map[string]string{
+	domain.TransitionWriteOff:       domain.LedgerBucketWrittenOff,
+	domain.TransitionReturnToVendor: domain.LedgerBucketReturnedToVendor,
+}
+
+// CreateStockTransition moves units of a SKU as its transition rule says, moving their serials
+// along, and records the document with a ledger entry for each side of the move
+func (r *repository) CreateStockTransition(ctx context.Context, transition domain.StockTransition) (domain.StockTransition, error) {
+	rule, ok := domain.TransitionRules[transition.Type]
+	if !ok {
+		return domain.StockTransition{}, fmt.Errorf("unknown transition %s", transition.Type)
+	}
+
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		err := moveStockFEFO(tx, transition.SkuID, transition.HubID, transition.Qty, bucketColumns[rule.From], bucketColumns[rule.To])
+		if err != nil {
+			return err
+		}
+		if rule.To == "" {
+			err = drainLocationStock(tx, transition.SkuID, transition.HubID, transition.Qty, domain.LocationTypeQuarantine)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(transition.Serials) > 0 {
+			err = moveSerials(tx, transition.SkuID, transition.HubID, transition.Serials,
+				[]string{bucketSerialStatus[rule.From]}, bucketSerialStatus[rule.To], transition.Type)
+			if err != nil {
+				return err
+			}
+			if err = checkSerialCounts(tx, transition.SkuID, transition.HubID); err != nil {
+				return err
+			}
+		}
+
+		if err = tx.Create(&transition).Error; err != nil {
+			return fmt.Errorf("failed to record %s: %w", transition.Type, err)
+		}
+
+		to := rule.To
+		if to == "" {
+			to = transitionOutBuckets[transition.Type]
+		}
+		return recordLedgerMove(tx, transition.SkuID, transition.HubID, rule.From, to, transition.Qty,
+			transition.ReasonCode, domain.LedgerRefTransition, transition.ID)
+	})
+	if err != nil {
+		return domain.StockTransition{}, err
+	}
+	return transition, nil
+}
+
+func (r *repository) GetStockTransitionByID(ctx context.Context, id uuid.UUID) (domain.StockTransition, error) {
+	var transition domain.StockTransition
+	err := r.master(ctx).Where("id = ?", id).First(&transition).Error
+	if err != nil {
+		return domain.StockTransition{}, notFound(err, "stock transition")
+	}
+	return transition, nil
+}
+
+func (r *repository) GetStockTransitions(ctx context.Context, hubID uuid.UUID, transitionType string, skuID *uuid.UUID) ([]domain.StockTransition, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if transitionType != "" {
+		query = query.Where("type = ?", transitionType)
+	}
+	if skuID != nil {
+		query = query.Where("sku_id = ?", *skuID)
+	}
+
+	var transitions []domain.StockTransition
+	if err := query.Order("created_at DESC").Find(&transitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stock transitions: %w", err)
+	}
+	return transitions, nil
+}
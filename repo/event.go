@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+type EventRepository interface {
+	GetInventoryEvents(ctx context.Context, filter domain.InventoryEventFilter) ([]domain.InventoryEvent, error)
+	GetEventTxID(ctx context.Context, tenantID uuid.UUID, id int64) (int64, error)
+	GetEventHorizon(ctx context.Context) (int64, error)
+	HasInventoryEventsBetween(ctx context.Context, fromTxID, toTxID int64) (bool, error)
+}
+
+// recordEvent appends an event to the outbox in the same transaction as the change it describes
+func recordEvent(tx *gorm.DB, eventType string, hubID, entityID uuid.UUID, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	err = tx.Create(&domain.Event{
+		Type:     eventType,
+		HubID:    hubID,
+		EntityID: entityID,
+		Payload:  data,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// GetInventoryEvents reads the inventory events of a tenant after a position, in (txid, id)
+// order. Only events of transactions older than the horizon are read: those are committed
+// or rolled back for good, so no event can later turn up behind the position, however the
+// IDs of concurrent transactions were handed out.
+func (r *repository) GetInventoryEvents(ctx context.Context, filter domain.InventoryEventFilter) ([]domain.InventoryEvent, error) {
+	query := r.master(ctx).Model(&domain.Event{}).
+		Joins("JOIN hubs ON hubs.id = events.hub_id").
+		Where("events.type = ? AND hubs.tenant_id = ?", domain.EventInventoryChanged, filter.TenantID).
+		Where("(events.txid, events.id) > (?, ?)", filter.AfterTxID, filter.AfterID).
+		Where("events.txid < txid_snapshot_xmin(txid_current_snapshot())")
+	if filter.HubID != nil {
+		query = query.Where("events.hub_id = ?", *filter.HubID)
+	}
+	if len(filter.SkuIDs) > 0 {
+		query = query.Where("events.entity_id IN ?", filter.SkuIDs)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []domain.Event
+	if err := query.Select("events.*").Order("events.txid, events.id").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory events: %w", err)
+	}
+
+	inventoryEvents := make([]domain.InventoryEvent, len(events))
+	for i, event := range events {
+		inventoryEvents[i] = domain.InventoryEvent{ID: event.ID, TxID: event.TxID, CreatedAt: event.CreatedAt}
+		if err := json.Unmarshal(event.Payload, &inventoryEvents[i].InventoryChange); err != nil {
+			return nil, fmt.Errorf("failed to decode inventory event %d: %w", event.ID, err)
+		}
+	}
+	return inventoryEvents, nil
+}
+
+// GetEventTxID returns the transaction an inventory event of a tenant was recorded in, the
+// position a reader resuming after it starts from
+func (r *repository) GetEventTxID(ctx context.Context, tenantID uuid.UUID, id int64) (int64, error) {
+	var event domain.Event
+	err := r.master(ctx).Select("events.txid").
+		Joins("JOIN hubs ON hubs.id = events.hub_id").
+		Where("events.id = ? AND events.type = ? AND hubs.tenant_id = ?", id, domain.EventInventoryChanged, tenantID).
+		First(&event).Error
+	if err != nil {
+		return 0, notFound(err, "event")
+	}
+	return event.TxID, nil
+}
+
+// GetEventHorizon returns the oldest transaction still running. Events of older
+// transactions are final; those of it and newer ones may still be committed.
+func (r *repository) GetEventHorizon(ctx context.Context) (int64, error) {
+	var horizon int64
+	err := r.master(ctx).Raw("SELECT txid_snapshot_xmin(txid_current_snapshot())").Scan(&horizon).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch event horizon: %w", err)
+	}
+	return horizon, nil
+}
+
+// HasInventoryEventsBetween reports whether inventory events were recorded by transactions
+// from fromTxID up to, but not including, toTxID
+func (r *repository) HasInventoryEventsBetween(ctx context.Context, fromTxID, toTxID int64) (bool, error) {
+	var found bool
+	err := r.master(ctx).Raw(
+		"SELECT EXISTS (SELECT 1 FROM events WHERE type = ? AND txid >= ? AND txid < ?)",
+		domain.EventInventoryChanged, fromTxID, toTxID,
+	).Scan(&found).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check inventory events: %w", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,369 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type InboundRepository interface {
+	CreatePurchaseOrder(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error)
+	GetPurchaseOrderByID(ctx context.Context, id uuid.UUID) (domain.PurchaseOrder, error)
+	GetPurchaseOrders(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.PurchaseOrder, error)
+	CreateASN(ctx context.Context, asn domain.ASN) (domain.ASN, error)
+	GetASNByID(ctx context.Context, id uuid.UUID) (domain.ASN, error)
+	GetASNs(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.ASN, error)
+	ReceiveASN(ctx context.Context, id uuid.UUID, receipts []domain.AsnReceipt) (domain.ASN, error)
+	CloseASN(ctx context.Context, id uuid.UUID) (domain.ASN, error)
+	GetAsnVariance(ctx context.Context, sellerID, hubID *uuid.UUID, from, to *time.Time) ([]domain.AsnVariance, error)
+}
+
+func (r *repository) CreatePurchaseOrder(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, line := range po.Lines {
+			if err := checkSellerSku(tx, po.SellerID, line.SkuID); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+
+		po.Status = domain.PurchaseOrderStatusOpen
+		if err := tx.Create(&po).Error; err != nil {
+			return fmt.Errorf("failed to create purchase order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return po, nil
+}
+
+func (r *repository) GetPurchaseOrderByID(ctx context.Context, id uuid.UUID) (domain.PurchaseOrder, error) {
+	var po domain.PurchaseOrder
+	err := r.master(ctx).Preload("Lines").Where("id = ?", id).First(&po).Error
+	if err != nil {
+		return domain.PurchaseOrder{}, notFound(err, "purchase order")
+	}
+	return po, nil
+}
+
+func (r *repository) GetPurchaseOrders(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.PurchaseOrder, error) {
+	query := r.master(ctx).Preload("Lines").Where("hub_id = ?", hubID)
+	if sellerID != nil {
+		query = query.Where("seller_id = ?", *sellerID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var pos []domain.PurchaseOrder
+	if err := query.Order("created_at").Find(&pos).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch purchase orders: %w", err)
+	}
+	return pos, nil
+}
+
+// CreateASN records a notice of an inbound shipment. Against a purchase order, the ASN has to
+// be for the same seller and hub and only carry SKUs on the order.
+func (r *repository) CreateASN(ctx context.Context, asn domain.ASN) (domain.ASN, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var ordered map[uuid.UUID]bool
+		if asn.PurchaseOrderID != nil {
+			var po domain.PurchaseOrder
+			err := tx.Preload("Lines").Where("id = ?", *asn.PurchaseOrderID).First(&po).Error
+			if err != nil {
+				return notFound(err, "purchase order")
+			}
+			if po.SellerID != asn.SellerID || po.HubID != asn.HubID {
+				return domain.Invalid("purchase order is for another seller or hub")
+			}
+			ordered = make(map[uuid.UUID]bool, len(po.Lines))
+			for _, line := range po.Lines {
+				ordered[line.SkuID] = true
+			}
+		}
+
+		for i, line := range asn.Lines {
+			if err := checkSellerSku(tx, asn.SellerID, line.SkuID); err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if ordered != nil && !ordered[line.SkuID] {
+				return domain.Invalid("line %d: SKU is not on the purchase order", i+1)
+			}
+		}
+
+		asn.Status = domain.AsnStatusExpected
+		if err := tx.Create(&asn).Error; err != nil {
+			return fmt.Errorf("failed to create ASN: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return asn, nil
+}
+
+func (r *repository) GetASNByID(ctx context.Context, id uuid.UUID) (domain.ASN, error) {
+	var asn domain.ASN
+	err := r.master(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Where("id = ?", id).First(&asn).Error
+	if err != nil {
+		return domain.ASN{}, notFound(err, "ASN")
+	}
+	return asn, nil
+}
+
+func (r *repository) GetASNs(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.ASN, error) {
+	query := r.master(ctx).Preload("Lines").Where("hub_id = ?", hubID)
+	if sellerID != nil {
+		query = query.Where("seller_id = ?", *sellerID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var asns []domain.ASN
+	if err := query.Order("expected_at ASC NULLS LAST, created_at").Find(&asns).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ASNs: %w", err)
+	}
+	return asns, nil
+}
+
+// ReceiveASN receives lots against an ASN. Units up to what a line may receive go to available
+// stock; the excess, and SKUs of the seller that weren't on the ASN, go to quarantine.
+func (r *repository) ReceiveASN(ctx context.Context, id uuid.UUID, receipts []domain.AsnReceipt) (domain.ASN, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var asn domain.ASN
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&asn).Error
+		if err != nil {
+			return notFound(err, "ASN")
+		}
+		if asn.Status == domain.AsnStatusClosed {
+			return domain.Conflict("ASN is already closed")
+		}
+
+		var lines []domain.AsnLine
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("asn_id = ?", id).Find(&lines).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch ASN lines: %w", err)
+		}
+		bySku := make(map[uuid.UUID]*domain.AsnLine, len(lines))
+		for i := range lines {
+			bySku[lines[i].SkuID] = &lines[i]
+		}
+
+		var skuOrder []uuid.UUID
+		grouped := make(map[uuid.UUID][]domain.LotReceipt)
+		for _, receipt := range receipts {
+			if _, ok := grouped[receipt.SkuID]; !ok {
+				skuOrder = append(skuOrder, receipt.SkuID)
+			}
+			grouped[receipt.SkuID] = append(grouped[receipt.SkuID], receipt.LotReceipt)
+		}
+
+		for _, skuID := range skuOrder {
+			line, ok := bySku[skuID]
+			if !ok {
+				if err = checkSellerSku(tx, asn.SellerID, skuID); err != nil {
+					return err
+				}
+				line = &domain.AsnLine{AsnID: id, SkuID: skuID, Unexpected: true}
+				if err = tx.Create(line).Error; err != nil {
+					return fmt.Errorf("failed to add unexpected ASN line: %w", err)
+				}
+				bySku[skuID] = line
+			}
+
+			allowed := 0
+			if !line.Unexpected {
+				allowed = max(line.MaxReceivable(asn.OverTolerancePct)-(line.ReceivedQty-line.QuarantinedQty), 0)
+			}
+
+			var accepted, excess []domain.LotReceipt
+			for _, receipt := range grouped[skuID] {
+				take := min(receipt.Qty, allowed)
+				allowed -= take
+				if take > 0 {
+					part := receipt
+					part.Qty = take
+					if len(receipt.Serials) > 0 {
+						part.Serials = receipt.Serials[:take]
+					}
+					accepted = append(accepted, part)
+				}
+				if take < receipt.Qty {
+					part := receipt
+					part.Qty = receipt.Qty - take
+					if len(receipt.Serials) > 0 {
+						part.Serials = receipt.Serials[take:]
+					}
+					excess = append(excess, part)
+				}
+			}
+
+			acceptedQty, excessQty := 0, 0
+			acceptedBucket := domain.LedgerBucketAvailable
+			if len(accepted) > 0 {
+				acceptedBucket, err = receiveForQc(tx, skuID, asn.HubID, accepted, domain.QcSourceAsn, &id)
+				if err != nil {
+					return err
+				}
+				for _, receipt := range accepted {
+					acceptedQty += receipt.Qty
+				}
+			}
+			if len(excess) > 0 {
+				if err = receiveLots(tx, skuID, asn.HubID, qtyQuarantine, excess); err != nil {
+					return err
+				}
+				for _, receipt := range excess {
+					excessQty += receipt.Qty
+				}
+			}
+
+			if acceptedQty > 0 {
+				err = recordLedgerEntry(tx, domain.LedgerEntry{
+					SkuID: skuID, HubID: asn.HubID, Bucket: acceptedBucket, Qty: acceptedQty,
+					Reason: "asn_receipt", RefType: domain.LedgerRefAsn, RefID: id,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if excessQty > 0 {
+				reason := "over_receipt"
+				if line.Unexpected {
+					reason = "unexpected_item"
+				}
+				err = recordLedgerEntry(tx, domain.LedgerEntry{
+					SkuID: skuID, HubID: asn.HubID, Bucket: domain.LedgerBucketQuarantine, Qty: excessQty,
+					Reason: reason, RefType: domain.LedgerRefAsn, RefID: id,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			err = tx.Model(&domain.AsnLine{}).Where("id = ?", line.ID).Updates(map[string]interface{}{
+				"received_qty":    gorm.Expr("received_qty + ?", acceptedQty+excessQty),
+				"quarantined_qty": gorm.Expr("quarantined_qty + ?", excessQty),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to update ASN line: %w", err)
+			}
+			line.ReceivedQty += acceptedQty + excessQty
+			line.QuarantinedQty += excessQty
+
+			if asn.PurchaseOrderID != nil && acceptedQty > 0 {
+				err = tx.Model(&domain.PurchaseOrderLine{}).
+					Where("purchase_order_id = ? AND sku_id = ?", *asn.PurchaseOrderID, skuID).
+					Update("received_qty", gorm.Expr("received_qty + ?", acceptedQty)).Error
+				if err != nil {
+					return fmt.Errorf("failed to update purchase order line: %w", err)
+				}
+			}
+		}
+
+		if asn.PurchaseOrderID != nil {
+			if err = updatePurchaseOrderStatus(tx, *asn.PurchaseOrderID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.ASN{}).Where("id = ?", id).Update("status", domain.AsnStatusReceiving).Error
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return r.GetASNByID(ctx, id)
+}
+
+// CloseASN ends receiving against an ASN. Whatever hasn't arrived by then is short.
+func (r *repository) CloseASN(ctx context.Context, id uuid.UUID) (domain.ASN, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var asn domain.ASN
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&asn).Error
+		if err != nil {
+			return notFound(err, "ASN")
+		}
+		if asn.Status == domain.AsnStatusClosed {
+			return domain.Conflict("ASN is already closed")
+		}
+		return tx.Model(&domain.ASN{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":    domain.AsnStatusClosed,
+			"closed_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return r.GetASNByID(ctx, id)
+}
+
+// GetAsnVariance compares expected and received units of closed ASNs per seller and SKU
+func (r *repository) GetAsnVariance(ctx context.Context, sellerID, hubID *uuid.UUID, from, to *time.Time) ([]domain.AsnVariance, error) {
+	var variance []domain.AsnVariance
+	err := r.master(ctx).Raw(`
+		SELECT a.seller_id,
+		       l.sku_id,
+		       s.code AS sku_code,
+		       COUNT(DISTINCT a.id) AS asns,
+		       SUM(l.expected_qty) AS expected_qty,
+		       SUM(l.received_qty) AS received_qty,
+		       COALESCE(SUM(GREATEST(l.received_qty - l.expected_qty, 0)) FILTER (WHERE NOT l.unexpected), 0) AS over_qty,
+		       SUM(GREATEST(l.expected_qty - l.received_qty, 0)) AS short_qty,
+		       COALESCE(SUM(l.received_qty) FILTER (WHERE l.unexpected), 0) AS unexpected_qty
+		FROM asn_lines l
+		JOIN asns a ON a.id = l.asn_id
+		JOIN skus s ON s.id = l.sku_id
+		WHERE a.status = $1
+		  AND ($2::uuid IS NULL OR a.seller_id = $2)
+		  AND ($3::uuid IS NULL OR a.hub_id = $3)
+		  AND ($4::timestamptz IS NULL OR a.closed_at >= $4)
+		  AND ($5::timestamptz IS NULL OR a.closed_at < $5)
+		GROUP BY a.seller_id, l.sku_id, s.code
+		ORDER BY a.seller_id, s.code
+	`, domain.AsnStatusClosed, sellerID, hubID, from, to).Scan(&variance).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ASN variance: %w", err)
+	}
+	return variance, nil
+}
+
+func updatePurchaseOrderStatus(tx *gorm.DB, id uuid.UUID) error {
+	var lines []domain.PurchaseOrderLine
+	if err := tx.Where("purchase_order_id = ?", id).Find(&lines).Error; err != nil {
+		return fmt.Errorf("failed to fetch purchase order lines: %w", err)
+	}
+
+	status := domain.PurchaseOrderStatusReceived
+	received := 0
+	for _, line := range lines {
+		received += line.ReceivedQty
+		if line.ReceivedQty < line.OrderedQty {
+			status = domain.PurchaseOrderStatusPartiallyReceived
+		}
+	}
+	if received == 0 {
+		status = domain.PurchaseOrderStatusOpen
+	}
+	return tx.Model(&domain.PurchaseOrder{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func checkSellerSku(tx *gorm.DB, sellerID, skuID uuid.UUID) error {
+	var sku domain.SKU
+	if err := tx.Where("id = ?", skuID).First(&sku).Error; err != nil {
+		return notFound(err, "SKU")
+	}
+	if sku.SellerID != sellerID {
+		return domain.Invalid("SKU %s does not belong to the seller", sku.Code)
+	}
+	return nil
+}
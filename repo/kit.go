@@ -0,0 +1,328 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type KitRepository interface {
+	SetKitComponents(ctx context.Context, kitSkuID uuid.UUID, components []domain.KitComponent) ([]domain.KitComponent, error)
+	GetKitComponents(ctx context.Context, kitSkuID uuid.UUID) ([]domain.KitComponent, error)
+	GetKitAvailability(ctx context.Context, kitSkuID uuid.UUID, hubID *uuid.UUID) ([]domain.KitAvailability, error)
+	CreateKittingOrder(ctx context.Context, order domain.KittingOrder) (domain.KittingOrder, error)
+	GetKittingOrderByID(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+	GetKittingOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.KittingOrder, error)
+	CompleteKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+	CancelKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+}
+
+// SetKitComponents replaces the bill of materials of a kit SKU. Kits don't nest, and the
+// components of a kit can't change while kitting orders for it are open.
+func (r *repository) SetKitComponents(ctx context.Context, kitSkuID uuid.UUID, components []domain.KitComponent) ([]domain.KitComponent, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var usedIn int64
+		err := tx.Model(&domain.KitComponent{}).Where("component_sku_id = ?", kitSkuID).Count(&usedIn).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch kit components: %w", err)
+		}
+		if usedIn > 0 {
+			return domain.Conflict("SKU is a component of another kit")
+		}
+
+		var open int64
+		err = tx.Model(&domain.KittingOrder{}).
+			Where("kit_sku_id = ? AND status = ?", kitSkuID, domain.KittingStatusOpen).Count(&open).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch kitting orders: %w", err)
+		}
+		if open > 0 {
+			return domain.Conflict("kit has open kitting orders")
+		}
+
+		for i, component := range components {
+			var nested int64
+			err = tx.Model(&domain.KitComponent{}).Where("kit_sku_id = ?", component.ComponentSkuID).Count(&nested).Error
+			if err != nil {
+				return fmt.Errorf("failed to fetch kit components: %w", err)
+			}
+			if nested > 0 {
+				return domain.Invalid("component %d is itself a kit", i+1)
+			}
+			components[i].KitSkuID = kitSkuID
+		}
+
+		if err = tx.Where("kit_sku_id = ?", kitSkuID).Delete(&domain.KitComponent{}).Error; err != nil {
+			return fmt.Errorf("failed to clear kit components: %w", err)
+		}
+		if len(components) == 0 {
+			return nil
+		}
+		if err = tx.Create(&components).Error; err != nil {
+			return fmt.Errorf("failed to save kit components: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+func (r *repository) GetKitComponents(ctx context.Context, kitSkuID uuid.UUID) ([]domain.KitComponent, error) {
+	return kitComponents(r.master(ctx), kitSkuID)
+}
+
+// GetKitAvailability computes the kits each hub can sell from its assembled kit stock and its
+// available component stock, for one hub or every hub holding any of them
+func (r *repository) GetKitAvailability(ctx context.Context, kitSkuID uuid.UUID, hubID *uuid.UUID) ([]domain.KitAvailability, error) {
+	db := r.master(ctx)
+	components, err := kitComponents(db, kitSkuID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, domain.Invalid("SKU is not a kit")
+	}
+
+	skuIDs := []string{kitSkuID.String()}
+	for _, component := range components {
+		skuIDs = append(skuIDs, component.ComponentSkuID.String())
+	}
+
+	var stock []struct {
+		HubID        uuid.UUID
+		SkuID        uuid.UUID
+		AvailableQty int
+	}
+	err = db.Raw(`
+		SELECT i.hub_id,
+		       i.sku_id,
+		       i.available_qty - COALESCE((
+		           SELECT SUM(l.available_qty)
+		           FROM lots l
+		           WHERE l.inventory_id = i.id AND l.status = $1 AND l.expiry_date <= CURRENT_DATE
+		       ), 0) AS available_qty
+		FROM inventories i
+		WHERE i.sku_id = ANY($2::uuid[])
+		  AND ($3::uuid IS NULL OR i.hub_id = $3)
+		ORDER BY i.hub_id
+	`, domain.LotStatusActive, pq.Array(skuIDs), hubID).Scan(&stock).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kit stock: %w", err)
+	}
+
+	var hubOrder []uuid.UUID
+	byHub := make(map[uuid.UUID]map[uuid.UUID]int)
+	for _, row := range stock {
+		if _, ok := byHub[row.HubID]; !ok {
+			hubOrder = append(hubOrder, row.HubID)
+			byHub[row.HubID] = make(map[uuid.UUID]int)
+		}
+		byHub[row.HubID][row.SkuID] = row.AvailableQty
+	}
+	if hubID != nil && len(hubOrder) == 0 {
+		hubOrder = append(hubOrder, *hubID)
+	}
+
+	availability := make([]domain.KitAvailability, 0, len(hubOrder))
+	for _, hub := range hubOrder {
+		kit := domain.KitAvailability{
+			HubID:        hub,
+			KitSkuID:     kitSkuID,
+			AssembledQty: byHub[hub][kitSkuID],
+		}
+		for i, component := range components {
+			available := byHub[hub][component.ComponentSkuID]
+			kitQty := available / component.Qty
+			if i == 0 || kitQty < kit.BuildableQty {
+				kit.BuildableQty = kitQty
+			}
+			kit.Components = append(kit.Components, domain.KitComponentStock{
+				SkuID:        component.ComponentSkuID,
+				QtyPerKit:    component.Qty,
+				AvailableQty: available,
+				KitQty:       kitQty,
+			})
+		}
+		kit.AvailableQty = kit.AssembledQty + kit.BuildableQty
+		availability = append(availability, kit)
+	}
+	return availability, nil
+}
+
+// CreateKittingOrder opens a kitting order, reserving its components by allocating them
+func (r *repository) CreateKittingOrder(ctx context.Context, order domain.KittingOrder) (domain.KittingOrder, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		components, err := kitComponents(tx, order.KitSkuID)
+		if err != nil {
+			return err
+		}
+		if len(components) == 0 {
+			return domain.Invalid("SKU is not a kit")
+		}
+
+		for _, component := range components {
+			err = moveStockFEFO(tx, component.ComponentSkuID, order.HubID, component.Qty*order.Qty, qtyAvailable, qtyAllocated)
+			if err != nil {
+				return fmt.Errorf("component %s: %w", component.ComponentSkuID, err)
+			}
+		}
+
+		order.Status = domain.KittingStatusOpen
+		if err = tx.Create(&order).Error; err != nil {
+			return fmt.Errorf("failed to create kitting order: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return order, nil
+}
+
+func (r *repository) GetKittingOrderByID(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	var order domain.KittingOrder
+	if err := r.master(ctx).Where("id = ?", id).First(&order).Error; err != nil {
+		return domain.KittingOrder{}, notFound(err, "kitting order")
+	}
+	return order, nil
+}
+
+func (r *repository) GetKittingOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.KittingOrder, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []domain.KittingOrder
+	if err := query.Order("created_at").Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch kitting orders: %w", err)
+	}
+	return orders, nil
+}
+
+// CompleteKittingOrder consumes the reserved components and receives the assembled kits
+// into available stock, under the order's lot and in its location if it has them
+func (r *repository) CompleteKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		order, components, err := openKittingOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, component := range components {
+			qty := component.Qty * order.Qty
+			if err = moveStockFEFO(tx, component.ComponentSkuID, order.HubID, qty, qtyAllocated, ""); err != nil {
+				return fmt.Errorf("component %s: %w", component.ComponentSkuID, err)
+			}
+			if err = drainLocationStock(tx, component.ComponentSkuID, order.HubID, qty, ""); err != nil {
+				return fmt.Errorf("component %s: %w", component.ComponentSkuID, err)
+			}
+			err = recordLedgerEntry(tx, domain.LedgerEntry{
+				SkuID: component.ComponentSkuID, HubID: order.HubID, Bucket: domain.LedgerBucketAvailable, Qty: -qty,
+				Reason: "kit_assembly", RefType: domain.LedgerRefKitting, RefID: order.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = receiveLots(tx, order.KitSkuID, order.HubID, qtyAvailable, []domain.LotReceipt{{
+			LotNumber:  order.LotNumber,
+			ExpiryDate: order.ExpiryDate,
+			Qty:        order.Qty,
+			LocationID: order.LocationID,
+		}})
+		if err != nil {
+			return err
+		}
+		err = recordLedgerEntry(tx, domain.LedgerEntry{
+			SkuID: order.KitSkuID, HubID: order.HubID, Bucket: domain.LedgerBucketAvailable, Qty: order.Qty,
+			Reason: "kit_assembly", RefType: domain.LedgerRefKitting, RefID: order.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.KittingOrder{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":       domain.KittingStatusCompleted,
+			"completed_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return r.GetKittingOrderByID(ctx, id)
+}
+
+// CancelKittingOrder releases the components reserved by an open kitting order
+func (r *repository) CancelKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		order, components, err := openKittingOrder(tx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, component := range components {
+			err = moveStockFEFO(tx, component.ComponentSkuID, order.HubID, component.Qty*order.Qty, qtyAllocated, qtyAvailable)
+			if err != nil {
+				return fmt.Errorf("component %s: %w", component.ComponentSkuID, err)
+			}
+		}
+		return tx.Model(&domain.KittingOrder{}).Where("id = ?", id).Update("status", domain.KittingStatusCancelled).Error
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return r.GetKittingOrderByID(ctx, id)
+}
+
+// openKittingOrder locks an open kitting order and fetches the components of its kit
+func openKittingOrder(tx *gorm.DB, id uuid.UUID) (domain.KittingOrder, []domain.KitComponent, error) {
+	var order domain.KittingOrder
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return domain.KittingOrder{}, nil, notFound(err, "kitting order")
+	}
+	if order.Status != domain.KittingStatusOpen {
+		return domain.KittingOrder{}, nil, domain.Conflict("kitting order is already %s", order.Status)
+	}
+
+	components, err := kitComponents(tx, order.KitSkuID)
+	if err != nil {
+		return domain.KittingOrder{}, nil, err
+	}
+	return order, components, nil
+}
+
+func kitComponents(tx *gorm.DB, kitSkuID uuid.UUID) ([]domain.KitComponent, error) {
+	var components []domain.KitComponent
+	if err := tx.Where("kit_sku_id = ?", kitSkuID).Order("created_at").Find(&components).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch kit components: %w", err)
+	}
+	return components, nil
+}
+
+// usableAvailableQty is the available stock of a SKU at a hub, leaving out expired lots
+func usableAvailableQty(tx *gorm.DB, skuID, hubID uuid.UUID) (int, error) {
+	var qty int
+	err := tx.Raw(`
+		SELECT i.available_qty - COALESCE((
+		           SELECT SUM(l.available_qty)
+		           FROM lots l
+		           WHERE l.inventory_id = i.id AND l.status = $1 AND l.expiry_date <= CURRENT_DATE
+		       ), 0)
+		FROM inventories i
+		WHERE i.sku_id = $2 AND i.hub_id = $3
+	`, domain.LotStatusActive, skuID, hubID).Scan(&qty).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+	return qty, nil
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"wms/domain"
+)
+
+type LabelRepository interface {
+	SetGS1CompanyPrefix(ctx context.Context, tenantID uuid.UUID, prefix string) (domain.Tenant, error)
+	AssignSSCC(ctx context.Context, packageID uuid.UUID) (domain.Package, error)
+}
+
+// SetGS1CompanyPrefix sets the company prefix SSCCs of a tenant are numbered under. The
+// serial reference carries on across prefix changes, so codes are never handed out twice.
+func (r *repository) SetGS1CompanyPrefix(ctx context.Context, tenantID uuid.UUID, prefix string) (domain.Tenant, error) {
+	db := r.master(ctx)
+	result := db.Model(&domain.Tenant{}).Where("id = ?", tenantID).Update("gs1_company_prefix", prefix)
+	if result.Error != nil {
+		return domain.Tenant{}, fmt.Errorf("failed to update tenant: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.Tenant{}, domain.NotFound("tenant not found")
+	}
+
+	var tenant domain.Tenant
+	if err := db.Where("id = ?", tenantID).First(&tenant).Error; err != nil {
+		return domain.Tenant{}, notFound(err, "tenant")
+	}
+	return tenant, nil
+}
+
+// AssignSSCC gives a package the next SSCC of the tenant of its hub. A package keeps the
+// SSCC it was given first, so reprinting its label does not use up serial references.
+func (r *repository) AssignSSCC(ctx context.Context, packageID uuid.UUID) (domain.Package, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var pkg domain.Package
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", packageID).First(&pkg).Error
+		if err != nil {
+			return notFound(err, "package")
+		}
+		if pkg.SSCC != nil {
+			return nil
+		}
+
+		var tenant domain.Tenant
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = (SELECT tenant_id FROM hubs WHERE id = ?)", pkg.HubID).First(&tenant).Error
+		if err != nil {
+			return notFound(err, "tenant")
+		}
+		if tenant.GS1CompanyPrefix == "" {
+			return domain.Conflict("tenant %s has no GS1 company prefix to number SSCCs with", tenant.Name)
+		}
+
+		sscc, err := domain.SSCC(tenant.GS1CompanyPrefix, tenant.SsccSerial+1)
+		if err != nil {
+			return err
+		}
+		if err = tx.Model(&domain.Tenant{}).Where("id = ?", tenant.ID).Update("sscc_serial", tenant.SsccSerial+1).Error; err != nil {
+			return fmt.Errorf("failed to update tenant: %w", err)
+		}
+		if err = tx.Model(&domain.Package{}).Where("id = ?", pkg.ID).Update("sscc", sscc).Error; err != nil {
+			return fmt.Errorf("failed to assign SSCC: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Package{}, err
+	}
+	return r.GetPackageByID(ctx, packageID)
+}
@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+func recordLedgerEntry(tx *gorm.DB, entry domain.LedgerEntry) error {
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record ledger entry: %w", err)
+	}
+	return nil
+}
+
+// recordLedgerMove records qty of a SKU leaving one ledger bucket for another
+func recordLedgerMove(tx *gorm.DB, skuID, hubID uuid.UUID, from, to string, qty int, reason, refType string, refID uuid.UUID) error {
+	entries := []domain.LedgerEntry{
+		{Bucket: from, Qty: -qty},
+		{Bucket: to, Qty: qty},
+	}
+	for _, entry := range entries {
+		entry.SkuID = skuID
+		entry.HubID = hubID
+		entry.Reason = reason
+		entry.RefType = refType
+		entry.RefID = refID
+		if err := recordLedgerEntry(tx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketColumns maps the ledger buckets that are held in stock to their inventory quantity column
+var bucketColumns = map[string]string{
+	domain.LedgerBucketAvailable:  qtyAvailable,
+	domain.LedgerBucketQuarantine: qtyQuarantine,
+	domain.LedgerBucketDamaged:    qtyDamaged,
+}
+
+// ledgerBucket names an inventory quantity column as a ledger bucket
+func ledgerBucket(column string) string {
+	for bucket, c := range bucketColumns {
+		if c == column {
+			return bucket
+		}
+	}
+	return column
+}
@@ -0,0 +1,346 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"wms/domain"
+	"wms/pkg"
+)
+
+// locationDrainOrder is the order bins are emptied in when stock leaves a hub
+// without naming the bin it was taken from.
+const locationDrainOrder = `CASE l.type
+	WHEN 'pick' THEN 1 WHEN 'staging' THEN 2 WHEN 'dock' THEN 3
+	WHEN 'reserve' THEN 4 ELSE 5 END`
+
+type LocationRepository interface {
+	CreateLocation(ctx context.Context, location domain.Location) (domain.Location, error)
+	UpdateLocation(ctx context.Context, location domain.Location) error
+	GetLocationByID(ctx context.Context, id uuid.UUID) (domain.Location, error)
+	GetLocations(ctx context.Context, hubID uuid.UUID, level, locationType string) ([]domain.Location, error)
+	GetLocationStock(ctx context.Context, locationID uuid.UUID) ([]domain.LocationStock, error)
+	GetSkuLocationStock(ctx context.Context, skuID, hubID uuid.UUID) (domain.SkuLocationStock, error)
+	MoveLocationStock(ctx context.Context, skuID, hubID, fromID, toID uuid.UUID, qty int) error
+}
+
+func (r *repository) CreateLocation(ctx context.Context, location domain.Location) (domain.Location, error) {
+	err := r.master(ctx).Create(&location).Error
+	if err != nil {
+		if pkg.IsViolatesUniqueConstraint(err) {
+			return domain.Location{}, domain.Duplicate("location %s already exists", location.Code)
+		}
+		return domain.Location{}, fmt.Errorf("failed to create location: %w", err)
+	}
+	return location, nil
+}
+
+// UpdateLocation saves everything but the place of a location in the hierarchy
+func (r *repository) UpdateLocation(ctx context.Context, location domain.Location) error {
+	err := r.master(ctx).Model(&domain.Location{}).Where("id = ?", location.ID).
+		Select("type", "max_units", "max_weight", "max_volume", "category", "sequence", "shelf_level", "active").
+		Updates(&location).Error
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) GetLocationByID(ctx context.Context, id uuid.UUID) (domain.Location, error) {
+	var location domain.Location
+	err := r.master(ctx).Where("id = ?", id).First(&location).Error
+	if err != nil {
+		return domain.Location{}, notFound(err, "location")
+	}
+	return location, nil
+}
+
+// GetLocations lists the locations of a hub, optionally filtered by level and type
+func (r *repository) GetLocations(ctx context.Context, hubID uuid.UUID, level, locationType string) ([]domain.Location, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if level != "" {
+		query = query.Where("level = ?", level)
+	}
+	if locationType != "" {
+		query = query.Where("type = ?", locationType)
+	}
+
+	var locations []domain.Location
+	if err := query.Order("code").Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch locations: %w", err)
+	}
+	return locations, nil
+}
+
+func (r *repository) GetLocationStock(ctx context.Context, locationID uuid.UUID) ([]domain.LocationStock, error) {
+	var stock []domain.LocationStock
+	err := r.master(ctx).Where("location_id = ? AND qty > 0", locationID).Find(&stock).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+	return stock, nil
+}
+
+// GetSkuLocationStock fetches the bins holding a SKU at a hub, with the hub total summed from them
+func (r *repository) GetSkuLocationStock(ctx context.Context, skuID, hubID uuid.UUID) (domain.SkuLocationStock, error) {
+	var stock []domain.LocationStock
+	err := r.master(ctx).Preload("Location").
+		Where("sku_id = ? AND hub_id = ? AND qty > 0", skuID, hubID).
+		Find(&stock).Error
+	if err != nil {
+		return domain.SkuLocationStock{}, fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+
+	result := domain.SkuLocationStock{
+		SkuID:     skuID,
+		HubID:     hubID,
+		Locations: stock,
+	}
+	for _, s := range stock {
+		result.OnHandQty += s.Qty
+	}
+	return result, nil
+}
+
+// MoveLocationStock moves units of a SKU from one bin of a hub to another
+func (r *repository) MoveLocationStock(ctx context.Context, skuID, hubID, fromID, toID uuid.UUID, qty int) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := takeLocationStock(tx, skuID, fromID, qty); err != nil {
+			return err
+		}
+		return placeLocationStock(tx, skuID, hubID, toID, qty)
+	})
+}
+
+// receiveIntoBin places units of a SKU arriving at a hub into the given bin, or into the
+// receiving dock of the hub when none is given, and returns the bin they went to.
+// Every unit on hand sits in a bin, so the totals of a hub add up from its bins.
+func receiveIntoBin(tx *gorm.DB, skuID, hubID uuid.UUID, locationID *uuid.UUID, qty int) (uuid.UUID, error) {
+	if locationID == nil {
+		dock, err := hubBin(tx, hubID, domain.LocationTypeDock)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		locationID = &dock
+	}
+	if err := placeLocationStock(tx, skuID, hubID, *locationID, qty); err != nil {
+		return uuid.Nil, err
+	}
+	return *locationID, nil
+}
+
+// hubBin finds the first active bin of a type at a hub, where stock goes when no bin is named
+func hubBin(tx *gorm.DB, hubID uuid.UUID, locationType string) (uuid.UUID, error) {
+	var location domain.Location
+	err := tx.Where("hub_id = ? AND level = ? AND type = ? AND active", hubID, domain.LocationLevelBin, locationType).
+		Order("sequence, code").Limit(1).Find(&location).Error
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to fetch %s bin: %w", locationType, err)
+	}
+	if location.ID == uuid.Nil {
+		return uuid.Nil, domain.Conflict("hub has no active %s bin", locationType)
+	}
+	return location.ID, nil
+}
+
+// hubBinPath is the zone, aisle, rack and bin a default bin of a hub is created under,
+// with the suffix each level adds to the code of its parent
+var hubBinPath = []struct{ level, suffix string }{
+	{domain.LocationLevelZone, ""},
+	{domain.LocationLevelAisle, "-A"},
+	{domain.LocationLevelRack, "-1"},
+	{domain.LocationLevelBin, "-1"},
+}
+
+// seedHubBins creates the dock and staging bin of a new hub, coded _DOCK-A-1-1 and
+// _STAGING-A-1-1 under zones of their own
+func seedHubBins(tx *gorm.DB, hubID uuid.UUID) error {
+	for _, locationType := range []string{domain.LocationTypeDock, domain.LocationTypeStaging} {
+		code := domain.ReservedLocationPrefix + strings.ToUpper(locationType)
+		var parentID *uuid.UUID
+		for _, step := range hubBinPath {
+			code += step.suffix
+			location := domain.Location{
+				HubID:    hubID,
+				ParentID: parentID,
+				Code:     code,
+				Level:    step.level,
+				Type:     locationType,
+				Active:   true,
+			}
+			if err := tx.Create(&location).Error; err != nil {
+				return fmt.Errorf("failed to create %s bin: %w", locationType, err)
+			}
+			parentID = &location.ID
+		}
+	}
+	return nil
+}
+
+// placeLocationStock adds units of a SKU to a bin of the hub, as long as they are on hand
+// and the bin can take them.
+func placeLocationStock(tx *gorm.DB, skuID, hubID, locationID uuid.UUID, qty int) error {
+	var location domain.Location
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", locationID).First(&location).Error
+	if err != nil {
+		return notFound(err, "location")
+	}
+	if location.HubID != hubID {
+		return domain.Invalid("location %s does not belong to this hub", location.Code)
+	}
+	if location.Level != domain.LocationLevelBin {
+		return domain.Invalid("location %s is a %s, stock can only be placed in bins", location.Code, location.Level)
+	}
+	if !location.Active {
+		return domain.Invalid("location %s is inactive", location.Code)
+	}
+
+	var totals struct {
+		PlacedQty int
+		Units     int
+		Weight    float64
+	}
+	err = tx.Raw(`
+		SELECT
+			COALESCE(SUM(ls.qty) FILTER (WHERE ls.sku_id = $1 AND ls.hub_id = $2), 0) AS placed_qty,
+			COALESCE(SUM(ls.qty) FILTER (WHERE ls.location_id = $3), 0) AS units,
+			COALESCE(SUM(ls.qty * COALESCE(s.weight, 0)) FILTER (WHERE ls.location_id = $3), 0) AS weight
+		FROM location_stocks ls
+		JOIN skus s ON s.id = ls.sku_id
+		WHERE (ls.sku_id = $1 AND ls.hub_id = $2) OR ls.location_id = $3
+	`, skuID, hubID, locationID).Scan(&totals).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+
+	var inventory domain.Inventory
+	err = tx.Where("sku_id = ? AND hub_id = ?", skuID, hubID).Limit(1).Find(&inventory).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+	if totals.PlacedQty+qty > onHandQty(inventory) {
+		return domain.InsufficientStock("not enough quantity on hand")
+	}
+
+	if location.MaxUnits > 0 && totals.Units+qty > location.MaxUnits {
+		return domain.Conflict("location %s can hold %d more units", location.Code, max(location.MaxUnits-totals.Units, 0))
+	}
+	if location.MaxWeight == 0 && location.MaxVolume == 0 {
+		return addLocationStock(tx, skuID, hubID, locationID, qty)
+	}
+
+	var sku domain.SKU
+	if err = tx.Where("id = ?", skuID).First(&sku).Error; err != nil {
+		return notFound(err, "SKU")
+	}
+	if location.MaxWeight > 0 && totals.Weight+sku.Weight*float64(qty) > location.MaxWeight {
+		return domain.Conflict("location %s would exceed its weight capacity", location.Code)
+	}
+	if location.MaxVolume > 0 {
+		dimensions, err := sku.ParseDimensions()
+		if err != nil {
+			return err
+		}
+
+		var stock []domain.LocationStock
+		err = tx.Preload("Sku").Where("location_id = ? AND qty > 0", locationID).Find(&stock).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch location stock: %w", err)
+		}
+		volume, err := stockVolume(stock)
+		if err != nil {
+			return err
+		}
+		if volume+dimensions.Volume()*float64(qty) > location.MaxVolume {
+			return domain.Conflict("location %s would exceed its volume capacity", location.Code)
+		}
+	}
+
+	return addLocationStock(tx, skuID, hubID, locationID, qty)
+}
+
+func addLocationStock(tx *gorm.DB, skuID, hubID, locationID uuid.UUID, qty int) error {
+	err := tx.Exec(`
+		INSERT INTO location_stocks (hub_id, sku_id, location_id, qty)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (location_id, sku_id)
+		DO UPDATE SET qty = location_stocks.qty + EXCLUDED.qty, updated_at = CURRENT_TIMESTAMP
+	`, hubID, skuID, locationID, qty).Error
+	if err != nil {
+		return fmt.Errorf("failed to place stock: %w", err)
+	}
+	return nil
+}
+
+func takeLocationStock(tx *gorm.DB, skuID, locationID uuid.UUID, qty int) error {
+	result := tx.Exec(`
+		UPDATE location_stocks
+		SET qty = qty - $1, updated_at = CURRENT_TIMESTAMP
+		WHERE sku_id = $2 AND location_id = $3 AND qty >= $1
+	`, qty, skuID, locationID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to take stock from location: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.InsufficientStock("not enough quantity in location")
+	}
+	return nil
+}
+
+// drainLocationStock takes qty units of a SKU leaving a hub out of its bins, starting with
+// the bins of type first when given, then in the drain order. It runs when stock leaves
+// without naming the bin it came from.
+func drainLocationStock(tx *gorm.DB, skuID, hubID uuid.UUID, qty int, first string) error {
+	var stock []domain.LocationStock
+	err := tx.Raw(`
+		SELECT ls.*
+		FROM location_stocks ls
+		JOIN locations l ON l.id = ls.location_id
+		WHERE ls.sku_id = $1 AND ls.hub_id = $2 AND ls.qty > 0
+		ORDER BY l.type = $3 DESC, `+locationDrainOrder+`, ls.qty ASC
+		FOR UPDATE OF ls
+	`, skuID, hubID, first).Scan(&stock).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+
+	remaining := qty
+	for _, s := range stock {
+		if remaining == 0 {
+			break
+		}
+		take := min(s.Qty, remaining)
+		if err = takeLocationStock(tx, skuID, s.LocationID, take); err != nil {
+			return err
+		}
+		remaining -= take
+	}
+	if remaining > 0 {
+		return domain.Conflict("the bins of the hub hold %d units less than its inventory", remaining)
+	}
+	return nil
+}
+
+// stockVolume is the volume taken by the stock of a bin, with the SKUs preloaded
+func stockVolume(stock []domain.LocationStock) (float64, error) {
+	volume := 0.0
+	for _, s := range stock {
+		if s.Sku == nil {
+			continue
+		}
+		dimensions, err := s.Sku.ParseDimensions()
+		if err != nil {
+			return 0, err
+		}
+		volume += dimensions.Volume() * float64(s.Qty)
+	}
+	return volume, nil
+}
+
+// onHandQty is every unit of the inventory row physically in the hub
+func onHandQty(inventory domain.Inventory) int {
+	return inventory.AvailableQty + inventory.AllocatedQty + inventory.DamagedQty + inventory.QuarantineQty
+}
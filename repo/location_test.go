@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"testing"
+	"wms/domain"
+)
+
+// TestReceiveAndPickOnNewHub checks that a hub is created with the dock and staging bins
+// receipts and picks fall back on when they don't name a bin
+func TestReceiveAndPickOnNewHub(t *testing.T) {
+	r, ctx := testRepository(t)
+	hub := newTestHub(t, ctx, r)
+	sku := newTestSku(t, ctx, r, hub)
+
+	docks, err := r.GetLocations(ctx, hub.ID, domain.LocationLevelBin, domain.LocationTypeDock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stagings, err := r.GetLocations(ctx, hub.ID, domain.LocationLevelBin, domain.LocationTypeStaging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docks) != 1 || len(stagings) != 1 {
+		t.Fatalf("new hub has %d dock and %d staging bins, want 1 of each", len(docks), len(stagings))
+	}
+	dock, staging := docks[0], stagings[0]
+
+	if err = r.ReceiveLots(ctx, sku.ID, hub.ID, []domain.LotReceipt{{LotNumber: "LOT-1", Qty: 5}}); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if qty := binQty(t, ctx, r, dock.ID, sku.ID); qty != 5 {
+		t.Fatalf("dock bin holds %d units after receipt, want 5", qty)
+	}
+
+	pick := newTestBin(t, ctx, r, hub.ID, domain.LocationTypePick, 1)
+	if err = r.MoveLocationStock(ctx, sku.ID, hub.ID, dock.ID, pick.ID, 5); err != nil {
+		t.Fatalf("putaway: %v", err)
+	}
+
+	order, tasks := releaseTestOrder(t, ctx, r, hub.ID, sku.ID, 3)
+	if len(tasks) != 1 {
+		t.Fatalf("release wave: %d tasks, want 1", len(tasks))
+	}
+	if err = r.ConfirmPick(ctx, tasks[0].ID, 3); err != nil {
+		t.Fatalf("confirm pick: %v", err)
+	}
+
+	if qty := binQty(t, ctx, r, staging.ID, sku.ID); qty != 3 {
+		t.Errorf("staging bin holds %d units after pick, want 3", qty)
+	}
+	if qty := binQty(t, ctx, r, pick.ID, sku.ID); qty != 2 {
+		t.Errorf("pick bin holds %d units after pick, want 2", qty)
+	}
+	order, err = r.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != domain.OrderStatusPicked {
+		t.Errorf("order is %s after pick, want %s", order.Status, domain.OrderStatusPicked)
+	}
+}
@@ -0,0 +1,397 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+// Inventory quantity buckets, named after their columns.
+const (
+	qtyAvailable  = "available_qty"
+	qtyAllocated  = "allocated_qty"
+	qtyDamaged    = "damaged_qty"
+	qtyQuarantine = "quarantine_qty"
+)
+
+type LotRepository interface {
+	ReceiveLots(ctx context.Context, skuID, hubID uuid.UUID, receipts []domain.LotReceipt) error
+	AllocateFEFO(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error
+	DecreaseAvailableFEFO(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error
+	GetLots(ctx context.Context, skuID, hubID uuid.UUID) ([]domain.Lot, error)
+	GetNearExpiryLots(ctx context.Context, hubID uuid.UUID, days int) ([]domain.NearExpiryLot, error)
+	BlockExpiredLots(ctx context.Context, hubID uuid.UUID) (int, error)
+}
+
+func (r *repository) ReceiveLots(ctx context.Context, skuID, hubID uuid.UUID, receipts []domain.LotReceipt) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := receiveForQc(tx, skuID, hubID, receipts, domain.QcSourceReceipt, nil)
+		return err
+	})
+}
+
+// AllocateFEFO moves qty from available to allocated, taking the lots that expire first.
+// Serialized SKUs pass the serials being allocated.
+func (r *repository) AllocateFEFO(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := moveStockFEFO(tx, skuID, hubID, qty, qtyAvailable, qtyAllocated); err != nil {
+			return err
+		}
+		if len(serials) == 0 {
+			return nil
+		}
+		err := moveSerials(tx, skuID, hubID, serials, []string{domain.SerialStatusAvailable}, domain.SerialStatusAllocated, domain.SerialEventAllocated)
+		if err != nil {
+			return err
+		}
+		return checkSerialCounts(tx, skuID, hubID)
+	})
+}
+
+// DecreaseAvailableFEFO removes qty from available stock, taking the lots that expire first.
+// Serialized SKUs pass the serials being removed.
+func (r *repository) DecreaseAvailableFEFO(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := moveStockFEFO(tx, skuID, hubID, qty, qtyAvailable, ""); err != nil {
+			return err
+		}
+		if err := drainLocationStock(tx, skuID, hubID, qty, ""); err != nil {
+			return err
+		}
+		if len(serials) == 0 {
+			return nil
+		}
+		err := moveSerials(tx, skuID, hubID, serials, []string{domain.SerialStatusAvailable}, domain.SerialStatusRemoved, domain.SerialEventRemoved)
+		if err != nil {
+			return err
+		}
+		return checkSerialCounts(tx, skuID, hubID)
+	})
+}
+
+func (r *repository) GetLots(ctx context.Context, skuID, hubID uuid.UUID) ([]domain.Lot, error) {
+	var lots []domain.Lot
+	err := r.master(ctx).
+		Where("sku_id = ? AND hub_id = ?", skuID, hubID).
+		Order("expiry_date ASC NULLS LAST, created_at ASC").
+		Find(&lots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lots: %w", err)
+	}
+	return lots, nil
+}
+
+// GetNearExpiryLots lists the active lots of a hub expiring within the given number of days,
+// including lots that have already expired but still hold stock.
+func (r *repository) GetNearExpiryLots(ctx context.Context, hubID uuid.UUID, days int) ([]domain.NearExpiryLot, error) {
+	var lots []domain.NearExpiryLot
+	err := r.master(ctx).Raw(`
+		SELECT l.id AS lot_id, l.sku_id, s.code AS sku_code, s.name AS sku_name, l.lot_number,
+		       l.expiry_date, (l.expiry_date - CURRENT_DATE) AS days_left, l.available_qty, l.allocated_qty
+		FROM lots l
+		JOIN skus s ON s.id = l.sku_id
+		WHERE l.hub_id = $1 AND l.status = 'active' AND l.expiry_date IS NOT NULL
+		  AND l.expiry_date <= CURRENT_DATE + $2::int
+		  AND (l.available_qty > 0 OR l.allocated_qty > 0)
+		ORDER BY l.expiry_date, s.code
+	`, hubID, days).Scan(&lots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch near expiry lots: %w", err)
+	}
+	return lots, nil
+}
+
+// BlockExpiredLots blocks every active expired lot of a hub and moves its available
+// quantity to quarantine, where it stays on hand in its bins until it is rejected or written
+// off. Allocated quantity is left for the orders holding it.
+func (r *repository) BlockExpiredLots(ctx context.Context, hubID uuid.UUID) (int, error) {
+	blocked := 0
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var lots []domain.Lot
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hub_id = ? AND status = ? AND expiry_date <= CURRENT_DATE", hubID, domain.LotStatusActive).
+			Find(&lots).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch expired lots: %w", err)
+		}
+
+		for _, lot := range lots {
+			err = tx.Model(&domain.Lot{}).Where("id = ?", lot.ID).Updates(map[string]interface{}{
+				"status":      domain.LotStatusBlocked,
+				qtyAvailable:  0,
+				qtyQuarantine: gorm.Expr(qtyQuarantine+" + ?", lot.AvailableQty),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to block lot %s: %w", lot.LotNumber, err)
+			}
+			if lot.AvailableQty == 0 {
+				continue
+			}
+			if err = quarantineExpiredLot(tx, lot); err != nil {
+				return err
+			}
+		}
+		blocked = len(lots)
+		return nil
+	})
+	return blocked, err
+}
+
+// quarantineExpiredLot moves the available units of an expired lot to the quarantine bucket
+// of its inventory row, with the ledger entries of the move. Serials are not tracked per
+// lot, so serialized SKUs quarantine as many of their available serials, the ones received
+// first.
+func quarantineExpiredLot(tx *gorm.DB, lot domain.Lot) error {
+	err := tx.Model(&domain.Inventory{}).Where("id = ?", lot.InventoryID).Updates(map[string]interface{}{
+		qtyAvailable:  gorm.Expr(qtyAvailable+" - ?", lot.AvailableQty),
+		qtyQuarantine: gorm.Expr(qtyQuarantine+" + ?", lot.AvailableQty),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to quarantine lot %s: %w", lot.LotNumber, err)
+	}
+
+	var serials []string
+	err = tx.Model(&domain.Serial{}).
+		Where("sku_id = ? AND hub_id = ? AND status = ?", lot.SkuID, lot.HubID, domain.SerialStatusAvailable).
+		Order("updated_at ASC").Limit(lot.AvailableQty).
+		Pluck("serial_number", &serials).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch serials: %w", err)
+	}
+	if len(serials) > 0 {
+		err = moveSerials(tx, lot.SkuID, lot.HubID, serials, []string{domain.SerialStatusAvailable},
+			domain.SerialStatusQuarantined, domain.TransitionQuarantine)
+		if err != nil {
+			return err
+		}
+		if err = checkSerialCounts(tx, lot.SkuID, lot.HubID); err != nil {
+			return err
+		}
+	}
+
+	return recordLedgerMove(tx, lot.SkuID, lot.HubID, domain.LedgerBucketAvailable, domain.LedgerBucketQuarantine,
+		lot.AvailableQty, "expired", domain.LedgerRefLot, lot.ID)
+}
+
+// addToInventory adds qty to one bucket of the inventory row of a SKU at a hub,
+// creating the row when the SKU has never been stocked there.
+func addToInventory(tx *gorm.DB, skuID, hubID uuid.UUID, column string, qty int) (domain.Inventory, error) {
+	var inventory domain.Inventory
+	err := tx.Raw(`
+		INSERT INTO inventories (sku_id, hub_id, `+column+`)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (sku_id, hub_id)
+		DO UPDATE SET `+column+` = inventories.`+column+` + EXCLUDED.`+column+`, updated_at = CURRENT_TIMESTAMP
+		RETURNING *
+	`, skuID, hubID, qty).Scan(&inventory).Error
+	if err != nil {
+		return domain.Inventory{}, fmt.Errorf("failed to update inventory: %w", err)
+	}
+	return inventory, nil
+}
+
+// receiveLots receives lots of a SKU at a hub into the available or quarantine bucket,
+// placing each receipt in its bin or in the receiving dock
+func receiveLots(tx *gorm.DB, skuID, hubID uuid.UUID, column string, receipts []domain.LotReceipt) error {
+	total := 0
+	for _, receipt := range receipts {
+		total += receipt.Qty
+	}
+
+	inventory, err := addToInventory(tx, skuID, hubID, column, total)
+	if err != nil {
+		return err
+	}
+
+	serialized := false
+	for _, receipt := range receipts {
+		locationID, err := receiveIntoBin(tx, skuID, hubID, receipt.LocationID, receipt.Qty)
+		if err != nil {
+			return err
+		}
+		if len(receipt.Serials) > 0 {
+			serialized = true
+			if err = receiveSerials(tx, skuID, hubID, locationID, receipt.Serials); err != nil {
+				return err
+			}
+			if column == qtyQuarantine {
+				err = moveSerials(tx, skuID, hubID, receipt.Serials, []string{domain.SerialStatusAvailable},
+					domain.SerialStatusQuarantined, domain.TransitionQuarantine)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if receipt.LotNumber == "" {
+			continue
+		}
+
+		var lot domain.Lot
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("inventory_id = ? AND lot_number = ?", inventory.ID, receipt.LotNumber).
+			Limit(1).Find(&lot).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch lot %s: %w", receipt.LotNumber, err)
+		}
+
+		if lot.ID == uuid.Nil {
+			lot = domain.Lot{
+				InventoryID:  inventory.ID,
+				SkuID:        skuID,
+				HubID:        hubID,
+				LotNumber:    receipt.LotNumber,
+				MfgDate:      receipt.MfgDate,
+				ExpiryDate:   receipt.ExpiryDate,
+				AvailableQty: receipt.Qty,
+				Status:       domain.LotStatusActive,
+			}
+			if column == qtyQuarantine {
+				lot.AvailableQty, lot.QuarantineQty = 0, receipt.Qty
+			}
+			if err = tx.Create(&lot).Error; err != nil {
+				return fmt.Errorf("failed to create lot %s: %w", receipt.LotNumber, err)
+			}
+			continue
+		}
+
+		if !sameDate(lot.ExpiryDate, receipt.ExpiryDate) {
+			return domain.Conflict("lot %s was received earlier with a different expiry date", receipt.LotNumber)
+		}
+		if lot.Status != domain.LotStatusActive {
+			return domain.Conflict("lot %s is %s", receipt.LotNumber, lot.Status)
+		}
+		err = tx.Model(&domain.Lot{}).Where("id = ?", lot.ID).
+			Update(column, gorm.Expr(column+" + ?", receipt.Qty)).Error
+		if err != nil {
+			return fmt.Errorf("failed to update lot %s: %w", receipt.LotNumber, err)
+		}
+	}
+
+	if serialized {
+		return checkSerialCounts(tx, skuID, hubID)
+	}
+	return nil
+}
+
+// moveStockFEFO moves qty of a SKU at a hub from one bucket to another (or out of stock
+// when to is empty), consuming lots first-expired-first-out. Expired lots are never
+// taken from available stock. Whatever the lots can't cover comes from untracked stock.
+// Stock taken out leaves the bucket only: callers take it out of its bins as well.
+func moveStockFEFO(tx *gorm.DB, skuID, hubID uuid.UUID, qty int, from, to string) error {
+	var inventory domain.Inventory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ?", skuID, hubID).
+		First(&inventory).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+
+	var lots []domain.Lot
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("inventory_id = ? AND status = ?", inventory.ID, domain.LotStatusActive).
+		Order("expiry_date ASC NULLS LAST, created_at ASC").
+		Find(&lots).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch lots: %w", err)
+	}
+
+	today := time.Now()
+	expired := 0
+	for _, lot := range lots {
+		if lot.IsExpired(today) {
+			expired += lotQty(lot, from)
+		}
+	}
+
+	usable := inventoryQty(inventory, from)
+	if from == qtyAvailable {
+		usable -= expired
+	}
+	if usable < qty {
+		return domain.InsufficientStock("not enough %s", bucketName(from))
+	}
+
+	remaining := qty
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		if from == qtyAvailable && lot.IsExpired(today) {
+			continue
+		}
+
+		take := min(lotQty(lot, from), remaining)
+		if take == 0 {
+			continue
+		}
+
+		updates := map[string]interface{}{from: gorm.Expr(from+" - ?", take)}
+		if to == qtyAvailable || to == qtyAllocated || to == qtyQuarantine {
+			updates[to] = gorm.Expr(to+" + ?", take)
+		}
+		if err = tx.Model(&domain.Lot{}).Where("id = ?", lot.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update lot %s: %w", lot.LotNumber, err)
+		}
+		remaining -= take
+	}
+
+	updates := map[string]interface{}{from: gorm.Expr(from+" - ?", qty)}
+	if to != "" {
+		updates[to] = gorm.Expr(to+" + ?", qty)
+	}
+	if err = tx.Model(&domain.Inventory{}).Where("id = ?", inventory.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to decrease %s: %w", bucketName(from), err)
+	}
+	return nil
+}
+
+func inventoryQty(inventory domain.Inventory, column string) int {
+	switch column {
+	case qtyAvailable:
+		return inventory.AvailableQty
+	case qtyAllocated:
+		return inventory.AllocatedQty
+	case qtyDamaged:
+		return inventory.DamagedQty
+	case qtyQuarantine:
+		return inventory.QuarantineQty
+	}
+	return 0
+}
+
+func lotQty(lot domain.Lot, column string) int {
+	switch column {
+	case qtyAvailable:
+		return lot.AvailableQty
+	case qtyAllocated:
+		return lot.AllocatedQty
+	case qtyQuarantine:
+		return lot.QuarantineQty
+	}
+	return 0
+}
+
+func bucketName(column string) string {
+	switch column {
+	case qtyAvailable:
+		return "available quantity"
+	case qtyAllocated:
+		return "allocated quantity"
+	case qtyDamaged:
+		return "damaged quantity"
+	case qtyQuarantine:
+		return "quarantined quantity"
+	}
+	return column
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+}
@@ -0,0 +1,548 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order domain.OutboundOrder) (domain.OutboundOrder, error)
+	GetOrderByID(ctx context.Context, id uuid.UUID) (domain.OutboundOrder, error)
+	GetOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.OutboundOrder, error)
+	CreateWaves(ctx context.Context, hubID uuid.UUID, carrier string, cutoffBefore *time.Time) ([]domain.Wave, error)
+	GetWaveByID(ctx context.Context, id uuid.UUID) (domain.Wave, error)
+	GetWaves(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Wave, error)
+	ReleaseWave(ctx context.Context, id uuid.UUID) ([]domain.PickTask, error)
+	GetPickList(ctx context.Context, waveID uuid.UUID, zoneID *uuid.UUID) ([]domain.PickTask, error)
+	ConfirmPick(ctx context.Context, id uuid.UUID, pickedQty int) error
+}
+
+// pickBin is a bin holding stock of a SKU that no open pick task has claimed yet
+type pickBin struct {
+	LocationID uuid.UUID
+	ZoneID     uuid.UUID
+	Sequence   int
+	FreeQty    int
+}
+
+// CreateOrder takes in an order and allocates the stock of every line, all or nothing.
+// Kit lines allocate assembled kits first and the components of the rest, which
+// are added to the order as component lines of the kit line.
+func (r *repository) CreateOrder(ctx context.Context, order domain.OutboundOrder) (domain.OutboundOrder, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		componentLines := make(map[int][]domain.OutboundOrderLine)
+		for i, line := range order.Lines {
+			components, err := kitComponents(tx, line.SkuID)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if len(components) == 0 {
+				if err = moveStockFEFO(tx, line.SkuID, order.HubID, line.Qty, qtyAvailable, qtyAllocated); err != nil {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+				order.Lines[i].AllocatedQty = line.Qty
+				continue
+			}
+
+			assembled, err := usableAvailableQty(tx, line.SkuID, order.HubID)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			take := min(max(assembled, 0), line.Qty)
+			if take > 0 {
+				if err = moveStockFEFO(tx, line.SkuID, order.HubID, take, qtyAvailable, qtyAllocated); err != nil {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+			}
+			order.Lines[i].AllocatedQty = take
+
+			if short := line.Qty - take; short > 0 {
+				for _, component := range components {
+					qty := component.Qty * short
+					err = moveStockFEFO(tx, component.ComponentSkuID, order.HubID, qty, qtyAvailable, qtyAllocated)
+					if err != nil {
+						return fmt.Errorf("line %d: component %s: %w", i+1, component.ComponentSkuID, err)
+					}
+					componentLines[i] = append(componentLines[i], domain.OutboundOrderLine{
+						SkuID:        component.ComponentSkuID,
+						Qty:          qty,
+						AllocatedQty: qty,
+					})
+				}
+			}
+		}
+
+		order.Status = domain.OrderStatusAllocated
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		for i := range order.Lines {
+			lines := componentLines[i]
+			if len(lines) == 0 {
+				continue
+			}
+			kitLineID := order.Lines[i].ID
+			for j := range lines {
+				lines[j].OrderID = order.ID
+				lines[j].KitLineID = &kitLineID
+			}
+			if err := tx.Create(&lines).Error; err != nil {
+				return fmt.Errorf("failed to add kit component lines: %w", err)
+			}
+			order.Lines = append(order.Lines, lines...)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.OutboundOrder{}, err
+	}
+	return order, nil
+}
+
+func (r *repository) GetOrderByID(ctx context.Context, id uuid.UUID) (domain.OutboundOrder, error) {
+	var order domain.OutboundOrder
+	err := r.master(ctx).Preload("Lines").Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return domain.OutboundOrder{}, notFound(err, "order")
+	}
+	return order, nil
+}
+
+func (r *repository) GetOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.OutboundOrder, error) {
+	query := r.master(ctx).Preload("Lines").Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []domain.OutboundOrder
+	if err := query.Order("created_at").Find(&orders).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+	return orders, nil
+}
+
+// CreateWaves groups the allocated orders of a hub that aren't in a wave yet into one wave
+// per carrier, cutoff and zone. carrier and cutoffBefore narrow down the orders considered.
+func (r *repository) CreateWaves(ctx context.Context, hubID uuid.UUID, carrier string, cutoffBefore *time.Time) ([]domain.Wave, error) {
+	var waves []domain.Wave
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hub_id = ? AND status = ? AND wave_id IS NULL", hubID, domain.OrderStatusAllocated)
+		if carrier != "" {
+			query = query.Where("carrier = ?", carrier)
+		}
+		if cutoffBefore != nil {
+			query = query.Where("cutoff_at <= ?", *cutoffBefore)
+		}
+
+		var orders []domain.OutboundOrder
+		if err := query.Order("cutoff_at ASC NULLS LAST, created_at").Find(&orders).Error; err != nil {
+			return fmt.Errorf("failed to fetch orders: %w", err)
+		}
+
+		groups := make(map[string]*domain.Wave)
+		for _, order := range orders {
+			zoneID, err := orderZone(tx, order)
+			if err != nil {
+				return fmt.Errorf("order %s: %w", order.OrderNo, err)
+			}
+			key := order.Carrier + "|"
+			if order.CutoffAt != nil {
+				key += order.CutoffAt.UTC().Format(time.RFC3339)
+			}
+			key += "|"
+			if zoneID != nil {
+				key += zoneID.String()
+			}
+
+			wave, ok := groups[key]
+			if !ok {
+				wave = &domain.Wave{
+					HubID:    hubID,
+					Carrier:  order.Carrier,
+					CutoffAt: order.CutoffAt,
+					ZoneID:   zoneID,
+					Status:   domain.WaveStatusPlanned,
+				}
+				if err := tx.Omit("Orders").Create(wave).Error; err != nil {
+					return fmt.Errorf("failed to create wave: %w", err)
+				}
+				groups[key] = wave
+				waves = append(waves, *wave)
+			}
+
+			err = tx.Model(&domain.OutboundOrder{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+				"wave_id": wave.ID,
+				"status":  domain.OrderStatusWaved,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to add order %s to wave: %w", order.OrderNo, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waves, nil
+}
+
+func (r *repository) GetWaveByID(ctx context.Context, id uuid.UUID) (domain.Wave, error) {
+	var wave domain.Wave
+	err := r.master(ctx).Preload("Orders.Lines").Where("id = ?", id).First(&wave).Error
+	if err != nil {
+		return domain.Wave{}, notFound(err, "wave")
+	}
+	return wave, nil
+}
+
+func (r *repository) GetWaves(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Wave, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var waves []domain.Wave
+	if err := query.Order("created_at").Find(&waves).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch waves: %w", err)
+	}
+	return waves, nil
+}
+
+// ReleaseWave generates the pick tasks of a planned wave from the bins holding its SKUs,
+// pick bins before reserve bins and in walk order.
+func (r *repository) ReleaseWave(ctx context.Context, id uuid.UUID) ([]domain.PickTask, error) {
+	var tasks []domain.PickTask
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var wave domain.Wave
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&wave).Error
+		if err != nil {
+			return notFound(err, "wave")
+		}
+		if wave.Status != domain.WaveStatusPlanned {
+			return domain.Conflict("wave is already %s", wave.Status)
+		}
+
+		var orders []domain.OutboundOrder
+		if err = tx.Preload("Lines").Where("wave_id = ?", id).Find(&orders).Error; err != nil {
+			return fmt.Errorf("failed to fetch orders: %w", err)
+		}
+
+		for _, order := range orders {
+			for _, line := range order.Lines {
+				lineTasks, err := planPicks(tx, wave.HubID, wave.ID, order.ID, line.ID, line.SkuID, line.AllocatedQty)
+				if err != nil {
+					return fmt.Errorf("order %s: %w", order.OrderNo, err)
+				}
+				tasks = append(tasks, lineTasks...)
+			}
+		}
+
+		return tx.Model(&domain.Wave{}).Where("id = ?", id).Update("status", domain.WaveStatusReleased).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetPickList fetches the pick tasks of a wave in walk order, zone by zone
+func (r *repository) GetPickList(ctx context.Context, waveID uuid.UUID, zoneID *uuid.UUID) ([]domain.PickTask, error) {
+	query := r.master(ctx).Preload("Location").Where("wave_id = ?", waveID)
+	if zoneID != nil {
+		query = query.Where("zone_id = ?", *zoneID)
+	}
+
+	var tasks []domain.PickTask
+	if err := query.Order("zone_id, sequence, created_at").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch pick list: %w", err)
+	}
+	return tasks, nil
+}
+
+// ConfirmPick records what was found in the bin of a pick task. Picked units leave the bin
+// for the staging bin of the hub, where they wait to be packed and shipped. They stay
+// allocated until dispatch turns them into shipped units, so they still count as on hand.
+// Missing units are written off the bin and the allocated stock, then the line is
+// re-allocated from other bins when there is stock to do so; otherwise it stays short.
+func (r *repository) ConfirmPick(ctx context.Context, id uuid.UUID, pickedQty int) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var task domain.PickTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&task).Error
+		if err != nil {
+			return notFound(err, "pick task")
+		}
+		if task.Status != domain.PickStatusOpen {
+			return domain.Conflict("pick task is already %s", task.Status)
+		}
+		if pickedQty > task.Qty {
+			return domain.InsufficientStock("cannot pick more than %d units", task.Qty)
+		}
+
+		var order domain.OutboundOrder
+		if err = tx.Where("id = ?", task.OrderID).First(&order).Error; err != nil {
+			return notFound(err, "order")
+		}
+
+		if pickedQty > 0 {
+			staging, err := hubBin(tx, order.HubID, domain.LocationTypeStaging)
+			if err != nil {
+				return err
+			}
+			if err = takeLocationStock(tx, task.SkuID, task.LocationID, pickedQty); err != nil {
+				return err
+			}
+			if err = placeLocationStock(tx, task.SkuID, order.HubID, staging, pickedQty); err != nil {
+				return err
+			}
+		}
+
+		status := domain.PickStatusPicked
+		short := task.Qty - pickedQty
+		if short > 0 {
+			status = domain.PickStatusShort
+			if err = writeOffMissing(tx, task, order.HubID, short); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&domain.PickTask{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"picked_qty": pickedQty,
+			"status":     status,
+			"picked_at":  time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update pick task: %w", err)
+		}
+
+		err = tx.Model(&domain.OutboundOrderLine{}).Where("id = ?", task.OrderLineID).
+			Update("picked_qty", gorm.Expr("picked_qty + ?", pickedQty)).Error
+		if err != nil {
+			return fmt.Errorf("failed to update order line: %w", err)
+		}
+
+		if short > 0 {
+			if err = reallocateShort(tx, task, order.HubID, short); err != nil {
+				return err
+			}
+		}
+
+		return completePicking(tx, task.WaveID, task.OrderID)
+	})
+}
+
+// writeOffMissing removes units that weren't in the bin from the allocated stock, and from the
+// bin or, when the bin holds fewer, from the other bins of the hub
+func writeOffMissing(tx *gorm.DB, task domain.PickTask, hubID uuid.UUID, missing int) error {
+	var stock domain.LocationStock
+	err := tx.Where("location_id = ? AND sku_id = ?", task.LocationID, task.SkuID).Limit(1).Find(&stock).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+	inBin := min(stock.Qty, missing)
+	if inBin > 0 {
+		if err = takeLocationStock(tx, task.SkuID, task.LocationID, inBin); err != nil {
+			return err
+		}
+	}
+	if inBin < missing {
+		if err = drainLocationStock(tx, task.SkuID, hubID, missing-inBin, ""); err != nil {
+			return err
+		}
+	}
+
+	if err = moveStockFEFO(tx, task.SkuID, hubID, missing, qtyAllocated, ""); err != nil {
+		return err
+	}
+
+	return tx.Model(&domain.OutboundOrderLine{}).Where("id = ?", task.OrderLineID).Updates(map[string]interface{}{
+		"allocated_qty": gorm.Expr("allocated_qty - ?", missing),
+		"short_qty":     gorm.Expr("short_qty + ?", missing),
+	}).Error
+}
+
+// reallocateShort tries to cover a short pick from available stock sitting in other bins
+func reallocateShort(tx *gorm.DB, task domain.PickTask, hubID uuid.UUID, short int) error {
+	bins, err := freePickBins(tx, task.SkuID, hubID)
+	if err != nil {
+		return err
+	}
+	free := 0
+	for _, bin := range bins {
+		free += bin.FreeQty
+	}
+	if free < short {
+		return nil
+	}
+
+	var inventory domain.Inventory
+	if err = tx.Where("sku_id = ? AND hub_id = ?", task.SkuID, hubID).First(&inventory).Error; err != nil {
+		return fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+	if inventory.AvailableQty < short {
+		return nil
+	}
+
+	// Stock that can't be allocated (e.g. only expired lots left) leaves the line short
+	err = moveStockFEFO(tx, task.SkuID, hubID, short, qtyAvailable, qtyAllocated)
+	if errors.Is(err, domain.ErrInsufficientStock) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = planPicks(tx, hubID, task.WaveID, task.OrderID, task.OrderLineID, task.SkuID, short); err != nil {
+		return err
+	}
+	return tx.Model(&domain.OutboundOrderLine{}).Where("id = ?", task.OrderLineID).Updates(map[string]interface{}{
+		"allocated_qty": gorm.Expr("allocated_qty + ?", short),
+		"short_qty":     gorm.Expr("short_qty - ?", short),
+	}).Error
+}
+
+// planPicks creates the pick tasks taking qty units of a SKU for an order line
+func planPicks(tx *gorm.DB, hubID, waveID, orderID, orderLineID, skuID uuid.UUID, qty int) ([]domain.PickTask, error) {
+	bins, err := freePickBins(tx, skuID, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []domain.PickTask
+	remaining := qty
+	for _, bin := range bins {
+		if remaining == 0 {
+			break
+		}
+		take := min(bin.FreeQty, remaining)
+		tasks = append(tasks, domain.PickTask{
+			WaveID:      waveID,
+			OrderID:     orderID,
+			OrderLineID: orderLineID,
+			SkuID:       skuID,
+			LocationID:  bin.LocationID,
+			ZoneID:      bin.ZoneID,
+			Sequence:    bin.Sequence,
+			Qty:         take,
+			Status:      domain.PickStatusOpen,
+		})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, domain.InsufficientStock("%d units of SKU %s are not in any pick or reserve bin", remaining, skuID)
+	}
+
+	if len(tasks) > 0 {
+		if err = tx.Omit("Location").Create(&tasks).Error; err != nil {
+			return nil, fmt.Errorf("failed to create pick tasks: %w", err)
+		}
+	}
+	return tasks, nil
+}
+
+// freePickBins lists the pick and reserve bins holding a SKU with stock not yet claimed
+// by an open pick task, pick bins first, then in walk order
+func freePickBins(tx *gorm.DB, skuID, hubID uuid.UUID) ([]pickBin, error) {
+	var bins []pickBin
+	err := tx.Raw(`
+		SELECT ls.location_id, aisle.parent_id AS zone_id, l.sequence,
+		       ls.qty - COALESCE(t.open_qty, 0) AS free_qty
+		FROM location_stocks ls
+		JOIN locations l ON l.id = ls.location_id
+		JOIN locations rack ON rack.id = l.parent_id
+		JOIN locations aisle ON aisle.id = rack.parent_id
+		LEFT JOIN (
+			SELECT location_id, SUM(qty) AS open_qty
+			FROM pick_tasks
+			WHERE sku_id = $1 AND status = 'open'
+			GROUP BY location_id
+		) t ON t.location_id = ls.location_id
+		WHERE ls.sku_id = $1 AND ls.hub_id = $2 AND l.active
+		  AND l.type IN ('pick', 'reserve')
+		  AND ls.qty - COALESCE(t.open_qty, 0) > 0
+		ORDER BY CASE l.type WHEN 'pick' THEN 1 ELSE 2 END, l.sequence, l.code
+		FOR UPDATE OF ls
+	`, skuID, hubID).Scan(&bins).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pick bins: %w", err)
+	}
+	return bins, nil
+}
+
+// orderZone is the zone the free pick and reserve bins of the lines of an order hold most
+// of its allocated units in, nil when none of them is in such a bin
+func orderZone(tx *gorm.DB, order domain.OutboundOrder) (*uuid.UUID, error) {
+	var lines []domain.OutboundOrderLine
+	if err := tx.Where("order_id = ? AND allocated_qty > 0", order.ID).Find(&lines).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order lines: %w", err)
+	}
+
+	units := make(map[uuid.UUID]int)
+	for _, line := range lines {
+		bins, err := freePickBins(tx, line.SkuID, order.HubID)
+		if err != nil {
+			return nil, err
+		}
+		remaining := line.AllocatedQty
+		for _, bin := range bins {
+			if remaining == 0 {
+				break
+			}
+			take := min(bin.FreeQty, remaining)
+			units[bin.ZoneID] += take
+			remaining -= take
+		}
+	}
+
+	var zone *uuid.UUID
+	most := 0
+	for zoneID, qty := range units {
+		// ties go to the lowest zone ID, so the same orders always land in the same wave
+		if zone == nil || qty > most || (qty == most && zoneID.String() < zone.String()) {
+			zoneID := zoneID
+			zone, most = &zoneID, qty
+		}
+	}
+	return zone, nil
+}
+
+// completePicking closes the order and the wave once they have no open pick tasks left
+func completePicking(tx *gorm.DB, waveID, orderID uuid.UUID) error {
+	var open int64
+	err := tx.Model(&domain.PickTask{}).Where("order_id = ? AND status = ?", orderID, domain.PickStatusOpen).Count(&open).Error
+	if err != nil {
+		return fmt.Errorf("failed to count pick tasks: %w", err)
+	}
+	if open > 0 {
+		return nil
+	}
+
+	var short int64
+	err = tx.Model(&domain.OutboundOrderLine{}).Where("order_id = ? AND short_qty > 0", orderID).Count(&short).Error
+	if err != nil {
+		return fmt.Errorf("failed to count short lines: %w", err)
+	}
+	status := domain.OrderStatusPicked
+	if short > 0 {
+		status = domain.OrderStatusShortPicked
+	}
+	if err = tx.Model(&domain.OutboundOrder{}).Where("id = ?", orderID).Update("status", status).Error; err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	err = tx.Model(&domain.PickTask{}).Where("wave_id = ? AND status = ?", waveID, domain.PickStatusOpen).Count(&open).Error
+	if err != nil {
+		return fmt.Errorf("failed to count pick tasks: %w", err)
+	}
+	if open > 0 {
+		return nil
+	}
+	return tx.Model(&domain.Wave{}).Where("id = ?", waveID).Update("status", domain.WaveStatusCompleted).Error
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"testing"
+	"time"
+	"wms/domain"
+)
+
+// TestShortPickReallocates checks that the units missing from a pick are written off and the
+// line is allocated again from another bin, then picked from there
+func TestShortPickReallocates(t *testing.T) {
+	r, ctx := testRepository(t)
+	hub := newTestHub(t, ctx, r)
+	sku := newTestSku(t, ctx, r, hub)
+
+	first := newTestBin(t, ctx, r, hub.ID, domain.LocationTypePick, 1)
+	second := newTestBin(t, ctx, r, hub.ID, domain.LocationTypePick, 2)
+	err := r.ReceiveLots(ctx, sku.ID, hub.ID, []domain.LotReceipt{
+		{LotNumber: "LOT-1", Qty: 3, LocationID: &first.ID},
+		{LotNumber: "LOT-2", Qty: 2, LocationID: &second.ID},
+	})
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	order, tasks := releaseTestOrder(t, ctx, r, hub.ID, sku.ID, 3)
+	if len(tasks) != 1 || tasks[0].LocationID != first.ID {
+		t.Fatalf("release wave: %d tasks, want 1 in the first bin", len(tasks))
+	}
+
+	// Only 1 of the 3 units is found in the first bin
+	if err = r.ConfirmPick(ctx, tasks[0].ID, 1); err != nil {
+		t.Fatalf("confirm short pick: %v", err)
+	}
+	if qty := binQty(t, ctx, r, first.ID, sku.ID); qty != 0 {
+		t.Errorf("first bin holds %d units after the short pick, want 0", qty)
+	}
+
+	list, err := r.GetPickList(ctx, tasks[0].WaveID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var open []domain.PickTask
+	for _, task := range list {
+		if task.Status == domain.PickStatusOpen {
+			open = append(open, task)
+		}
+	}
+	if len(open) != 1 || open[0].LocationID != second.ID || open[0].Qty != 2 {
+		t.Fatalf("after the short pick: %d open tasks, want 1 of 2 units in the second bin", len(open))
+	}
+
+	order, err = r.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line := order.Lines[0]; line.AllocatedQty != 3 || line.ShortQty != 0 {
+		t.Errorf("line is allocated %d and short %d after reallocation, want 3 and 0", line.AllocatedQty, line.ShortQty)
+	}
+
+	if err = r.ConfirmPick(ctx, open[0].ID, 2); err != nil {
+		t.Fatalf("confirm reallocated pick: %v", err)
+	}
+	order, err = r.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != domain.OrderStatusPicked {
+		t.Errorf("order is %s, want %s", order.Status, domain.OrderStatusPicked)
+	}
+
+	inventory, err := r.GetInventory(ctx, sku.ID, hub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inventory.AvailableQty != 0 || inventory.AllocatedQty != 3 {
+		t.Errorf("inventory is %d available and %d allocated, want 0 and 3", inventory.AvailableQty, inventory.AllocatedQty)
+	}
+}
+
+// TestShortPickOfExpiredStockStaysShort checks that a short pick leaves the line short
+// instead of failing when the only stock left in other bins has expired
+func TestShortPickOfExpiredStockStaysShort(t *testing.T) {
+	r, ctx := testRepository(t)
+	hub := newTestHub(t, ctx, r)
+	sku := newTestSku(t, ctx, r, hub)
+
+	first := newTestBin(t, ctx, r, hub.ID, domain.LocationTypePick, 1)
+	second := newTestBin(t, ctx, r, hub.ID, domain.LocationTypePick, 2)
+	expired := time.Now().AddDate(0, 0, -1)
+	err := r.ReceiveLots(ctx, sku.ID, hub.ID, []domain.LotReceipt{
+		{LotNumber: "LOT-1", Qty: 3, LocationID: &first.ID},
+		{LotNumber: "LOT-2", Qty: 2, LocationID: &second.ID, ExpiryDate: &expired},
+	})
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+
+	order, tasks := releaseTestOrder(t, ctx, r, hub.ID, sku.ID, 3)
+	if len(tasks) != 1 {
+		t.Fatalf("release wave: %d tasks, want 1", len(tasks))
+	}
+	if err = r.ConfirmPick(ctx, tasks[0].ID, 1); err != nil {
+		t.Fatalf("confirm short pick: %v", err)
+	}
+
+	order, err = r.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != domain.OrderStatusShortPicked {
+		t.Errorf("order is %s, want %s", order.Status, domain.OrderStatusShortPicked)
+	}
+	if line := order.Lines[0]; line.AllocatedQty != 1 || line.ShortQty != 2 {
+		t.Errorf("line is allocated %d and short %d, want 1 and 2", line.AllocatedQty, line.ShortQty)
+	}
+}
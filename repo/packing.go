@@ -0,0 +1,293 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"wms/domain"
+)
+
+type PackingRepository interface {
+	CreateCarton(ctx context.Context, carton domain.Carton) (domain.Carton, error)
+	GetCartons(ctx context.Context, hubID uuid.UUID) ([]domain.Carton, error)
+	GetCartonByID(ctx context.Context, id uuid.UUID) (domain.Carton, error)
+	CreatePackage(ctx context.Context, pkg domain.Package) (domain.Package, error)
+	GetPackageByID(ctx context.Context, id uuid.UUID) (domain.Package, error)
+	GetPackages(ctx context.Context, orderID uuid.UUID) ([]domain.Package, error)
+	AddPackageItem(ctx context.Context, packageID, skuID uuid.UUID, qty int, serials []string) error
+	CompletePacking(ctx context.Context, orderID uuid.UUID, packages []domain.Package) (domain.Shipment, error)
+}
+
+func (r *repository) CreateCarton(ctx context.Context, carton domain.Carton) (domain.Carton, error) {
+	err := r.master(ctx).Create(&carton).Error
+	if err != nil {
+		return domain.Carton{}, err
+	}
+	return carton, nil
+}
+
+func (r *repository) GetCartons(ctx context.Context, hubID uuid.UUID) ([]domain.Carton, error) {
+	var cartons []domain.Carton
+	err := r.master(ctx).Where("hub_id = ?", hubID).
+		Order("inner_length * inner_width * inner_height").
+		Find(&cartons).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cartons: %w", err)
+	}
+	return cartons, nil
+}
+
+func (r *repository) GetCartonByID(ctx context.Context, id uuid.UUID) (domain.Carton, error) {
+	var carton domain.Carton
+	err := r.master(ctx).Where("id = ?", id).First(&carton).Error
+	if err != nil {
+		return domain.Carton{}, notFound(err, "carton")
+	}
+	return carton, nil
+}
+
+// CreatePackage opens a package for an order that has been picked
+func (r *repository) CreatePackage(ctx context.Context, pkg domain.Package) (domain.Package, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPackableOrder(tx, pkg.OrderID)
+		if err != nil {
+			return err
+		}
+
+		pkg.HubID = order.HubID
+		pkg.Status = domain.PackageStatusOpen
+		return tx.Omit("Items", "Carton").Create(&pkg).Error
+	})
+	if err != nil {
+		return domain.Package{}, err
+	}
+	return pkg, nil
+}
+
+func (r *repository) GetPackageByID(ctx context.Context, id uuid.UUID) (domain.Package, error) {
+	var pkg domain.Package
+	err := r.master(ctx).Preload("Items.Sku").Preload("Carton").Where("id = ?", id).First(&pkg).Error
+	if err != nil {
+		return domain.Package{}, notFound(err, "package")
+	}
+	return pkg, nil
+}
+
+func (r *repository) GetPackages(ctx context.Context, orderID uuid.UUID) ([]domain.Package, error) {
+	var packages []domain.Package
+	err := r.master(ctx).Preload("Items.Sku").Preload("Carton").
+		Where("order_id = ?", orderID).Order("created_at").
+		Find(&packages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch packages: %w", err)
+	}
+	return packages, nil
+}
+
+// AddPackageItem records units of a SKU scanned into an open package, consuming the
+// picked units of the order lines for that SKU that haven't been packed yet. Serialized
+// SKUs pass the serials scanned, which must be in stock at the hub and not packed yet.
+func (r *repository) AddPackageItem(ctx context.Context, packageID, skuID uuid.UUID, qty int, serials []string) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var pkg domain.Package
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", packageID).First(&pkg).Error
+		if err != nil {
+			return notFound(err, "package")
+		}
+		if pkg.Status != domain.PackageStatusOpen {
+			return domain.Conflict("package is already %s", pkg.Status)
+		}
+		if err = checkCartonFit(tx, pkg.ID, skuID, qty); err != nil {
+			return err
+		}
+
+		var lines []domain.OutboundOrderLine
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("order_id = ? AND sku_id = ? AND picked_qty > packed_qty", pkg.OrderID, skuID).
+			Order("created_at").Find(&lines).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch order lines: %w", err)
+		}
+
+		unpacked := 0
+		for _, line := range lines {
+			unpacked += line.PickedQty - line.PackedQty
+		}
+		if unpacked < qty {
+			return domain.InsufficientStock("only %d picked units of this SKU are left to pack", unpacked)
+		}
+
+		if len(serials) > 0 {
+			if err = checkPackableSerials(tx, skuID, pkg.HubID, serials); err != nil {
+				return err
+			}
+		}
+
+		remaining := qty
+		for _, line := range lines {
+			if remaining == 0 {
+				break
+			}
+			take := min(line.PickedQty-line.PackedQty, remaining)
+
+			item := domain.PackageItem{
+				PackageID:   packageID,
+				OrderLineID: line.ID,
+				SkuID:       skuID,
+				Qty:         take,
+			}
+			if len(serials) > 0 {
+				item.Serials = serials[qty-remaining : qty-remaining+take]
+			}
+			err = tx.Omit("Sku").Create(&item).Error
+			if err != nil {
+				return fmt.Errorf("failed to add package item: %w", err)
+			}
+
+			err = tx.Model(&domain.OutboundOrderLine{}).Where("id = ?", line.ID).
+				Update("packed_qty", gorm.Expr("packed_qty + ?", take)).Error
+			if err != nil {
+				return fmt.Errorf("failed to update order line: %w", err)
+			}
+			remaining -= take
+		}
+		return nil
+	})
+}
+
+// CompletePacking closes the open packages of an order with their computed weights and
+// records the packed shipment. Every picked unit has to be in a package.
+func (r *repository) CompletePacking(ctx context.Context, orderID uuid.UUID, packages []domain.Package) (domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockPackableOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		var unpacked int64
+		err = tx.Model(&domain.OutboundOrderLine{}).
+			Where("order_id = ? AND packed_qty < picked_qty", orderID).
+			Count(&unpacked).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch order lines: %w", err)
+		}
+		if unpacked > 0 {
+			return domain.Conflict("some picked units have not been packed")
+		}
+
+		shipment = domain.Shipment{
+			HubID:   order.HubID,
+			OrderID: orderID,
+			Status:  domain.ShipmentStatusPacked,
+			Carrier: order.Carrier,
+		}
+		for _, pkg := range packages {
+			shipment.TotalWeight += pkg.ChargeableWeight()
+		}
+		if err = tx.Omit("Packages").Create(&shipment).Error; err != nil {
+			return fmt.Errorf("failed to create shipment: %w", err)
+		}
+
+		for _, pkg := range packages {
+			result := tx.Model(&domain.Package{}).
+				Where("id = ? AND order_id = ? AND status = ?", pkg.ID, orderID, domain.PackageStatusOpen).
+				Updates(map[string]interface{}{
+					"status":            domain.PackageStatusClosed,
+					"weight":            pkg.Weight,
+					"volumetric_weight": pkg.VolumetricWeight,
+					"shipment_id":       shipment.ID,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to close package: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return domain.Conflict("package is no longer open")
+			}
+		}
+
+		return tx.Model(&domain.OutboundOrder{}).Where("id = ?", orderID).Update("status", domain.OrderStatusPacked).Error
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+func lockPackableOrder(tx *gorm.DB, orderID uuid.UUID) (domain.OutboundOrder, error) {
+	var order domain.OutboundOrder
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).First(&order).Error
+	if err != nil {
+		return domain.OutboundOrder{}, notFound(err, "order")
+	}
+	if order.Status != domain.OrderStatusPicked && order.Status != domain.OrderStatusShortPicked {
+		return domain.OutboundOrder{}, domain.Conflict("order is %s, only picked orders can be packed", order.Status)
+	}
+	return order, nil
+}
+
+// checkCartonFit makes sure qty more units of a SKU go into the carton of a package with
+// what it already holds. It runs under the lock of the package, so concurrent scans cannot
+// overfill the carton.
+func checkCartonFit(tx *gorm.DB, packageID, skuID uuid.UUID, qty int) error {
+	var pkg domain.Package
+	if err := tx.Preload("Items.Sku").Preload("Carton").Where("id = ?", packageID).First(&pkg).Error; err != nil {
+		return notFound(err, "package")
+	}
+	var sku domain.SKU
+	if err := tx.Where("id = ?", skuID).First(&sku).Error; err != nil {
+		return notFound(err, "SKU")
+	}
+
+	box, err := domain.NewPackageBox(pkg)
+	if err != nil {
+		return err
+	}
+	unit, err := domain.NewPackUnit(sku)
+	if err != nil {
+		return err
+	}
+	if !box.Fits(unit, qty) {
+		return domain.Invalid("%d units of SKU %s do not fit in carton %s", qty, sku.Code, pkg.Carton.Code)
+	}
+	return nil
+}
+
+// checkPackableSerials makes sure scanned serials are in stock at the hub and not in
+// a package that hasn't shipped yet
+func checkPackableSerials(tx *gorm.DB, skuID, hubID uuid.UUID, serials []string) error {
+	var inStock int64
+	err := tx.Model(&domain.Serial{}).
+		Where("sku_id = ? AND hub_id = ? AND status IN ? AND serial_number IN ?", skuID, hubID,
+			[]string{domain.SerialStatusAvailable, domain.SerialStatusAllocated}, serials).
+		Count(&inStock).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch serials: %w", err)
+	}
+	if int(inStock) != len(serials) {
+		return domain.Invalid("some serials are not in stock at this hub")
+	}
+
+	var packed []string
+	err = tx.Raw(`
+		SELECT s.serial
+		FROM package_items pi
+		JOIN packages p ON p.id = pi.package_id
+		LEFT JOIN shipments sh ON sh.id = p.shipment_id
+		CROSS JOIN LATERAL unnest(pi.serials) AS s(serial)
+		WHERE pi.sku_id = $1
+		  AND p.hub_id = $2
+		  AND (sh.id IS NULL OR sh.status <> $3)
+		  AND s.serial = ANY($4)
+	`, skuID, hubID, domain.ShipmentStatusDispatched, pq.Array(serials)).Scan(&packed).Error
+	if err != nil {
+		return fmt.Errorf("failed to check packed serials: %w", err)
+	}
+	if len(packed) > 0 {
+		return domain.Conflict("serial %s is already packed", packed[0])
+	}
+	return nil
+}
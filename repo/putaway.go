@@ -0,0 +1,121 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type PutawayRepository interface {
+	GetHubBinStock(ctx context.Context, hubID uuid.UUID) ([]domain.LocationStock, error)
+	CreatePutawayTasks(ctx context.Context, tasks []domain.PutawayTask) ([]domain.PutawayTask, error)
+	GetPutawayTasks(ctx context.Context, hubID uuid.UUID, status string) ([]domain.PutawayTask, error)
+	ConfirmPutawayTask(ctx context.Context, id, toLocationID uuid.UUID) error
+}
+
+// GetHubBinStock fetches the stock of every bin of a hub, with its SKU
+func (r *repository) GetHubBinStock(ctx context.Context, hubID uuid.UUID) ([]domain.LocationStock, error) {
+	var stock []domain.LocationStock
+	err := r.master(ctx).Preload("Sku").Where("hub_id = ? AND qty > 0", hubID).Find(&stock).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch location stock: %w", err)
+	}
+	return stock, nil
+}
+
+// CreatePutawayTasks saves tasks moving stock off the same source location, as long as
+// the source holds enough stock not already promised to other open tasks.
+func (r *repository) CreatePutawayTasks(ctx context.Context, tasks []domain.PutawayTask) ([]domain.PutawayTask, error) {
+	if len(tasks) == 0 {
+		return tasks, nil
+	}
+
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		first := tasks[0]
+
+		var source domain.LocationStock
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("location_id = ? AND sku_id = ?", first.FromLocationID, first.SkuID).
+			Limit(1).Find(&source).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch location stock: %w", err)
+		}
+
+		var pending int
+		err = tx.Model(&domain.PutawayTask{}).
+			Where("from_location_id = ? AND sku_id = ? AND status = ?", first.FromLocationID, first.SkuID, domain.PutawayStatusOpen).
+			Select("COALESCE(SUM(qty), 0)").Scan(&pending).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch open putaway tasks: %w", err)
+		}
+
+		requested := 0
+		for _, task := range tasks {
+			requested += task.Qty
+		}
+		if source.Qty-pending < requested {
+			return domain.InsufficientStock("not enough quantity in location")
+		}
+
+		if err = tx.Create(&tasks).Error; err != nil {
+			return fmt.Errorf("failed to create putaway tasks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *repository) GetPutawayTasks(ctx context.Context, hubID uuid.UUID, status string) ([]domain.PutawayTask, error) {
+	query := r.master(ctx).Preload("Sku").Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tasks []domain.PutawayTask
+	if err := query.Order("created_at").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch putaway tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ConfirmPutawayTask moves the stock of an open task into the bin it was actually put in,
+// or into the suggested bin when toLocationID is uuid.Nil
+func (r *repository) ConfirmPutawayTask(ctx context.Context, id, toLocationID uuid.UUID) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var task domain.PutawayTask
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&task).Error
+		if err != nil {
+			return notFound(err, "putaway task")
+		}
+		if task.Status != domain.PutawayStatusOpen {
+			return domain.Conflict("putaway task is %s", task.Status)
+		}
+		if toLocationID == uuid.Nil {
+			toLocationID = task.ToLocationID
+		}
+
+		if err = takeLocationStock(tx, task.SkuID, task.FromLocationID, task.Qty); err != nil {
+			return err
+		}
+		if err = placeLocationStock(tx, task.SkuID, task.HubID, toLocationID, task.Qty); err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.PutawayTask{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":         domain.PutawayStatusDone,
+			"to_location_id": toLocationID,
+			"completed_at":   time.Now(),
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update putaway task: %w", err)
+		}
+		return nil
+	})
+}
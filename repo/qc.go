@@ -0,0 +1,282 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type QcRepository interface {
+	CreateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error)
+	GetQcRuleByID(ctx context.Context, id uuid.UUID) (domain.QcRule, error)
+	GetQcRules(ctx context.Context, sellerID *uuid.UUID) ([]domain.QcRule, error)
+	UpdateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error)
+	GetQcInspectionByID(ctx context.Context, id uuid.UUID) (domain.QcInspection, error)
+	GetQcInspections(ctx context.Context, hubID uuid.UUID, status string) ([]domain.QcInspection, error)
+	RecordQcResults(ctx context.Context, id uuid.UUID, results []domain.QcResult) (domain.QcInspection, error)
+}
+
+func (r *repository) CreateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error) {
+	if err := r.master(ctx).Create(&rule).Error; err != nil {
+		return domain.QcRule{}, fmt.Errorf("failed to create QC rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *repository) GetQcRuleByID(ctx context.Context, id uuid.UUID) (domain.QcRule, error) {
+	var rule domain.QcRule
+	if err := r.master(ctx).Where("id = ?", id).First(&rule).Error; err != nil {
+		return domain.QcRule{}, notFound(err, "QC rule")
+	}
+	return rule, nil
+}
+
+func (r *repository) GetQcRules(ctx context.Context, sellerID *uuid.UUID) ([]domain.QcRule, error) {
+	query := r.master(ctx)
+	if sellerID != nil {
+		query = query.Where("seller_id = ?", *sellerID)
+	}
+
+	var rules []domain.QcRule
+	if err := query.Order("created_at").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch QC rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpdateQcRule changes the sampling of a rule. Inspections already open keep the sample they started with.
+func (r *repository) UpdateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error) {
+	result := r.master(ctx).Model(&domain.QcRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"sample_size":  rule.SampleSize,
+		"sample_pct":   rule.SamplePct,
+		"max_failures": rule.MaxFailures,
+		"checklist":    rule.Checklist,
+		"active":       rule.Active,
+	})
+	if result.Error != nil {
+		return domain.QcRule{}, fmt.Errorf("failed to update QC rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.QcRule{}, domain.NotFound("QC rule not found")
+	}
+	return r.GetQcRuleByID(ctx, rule.ID)
+}
+
+func (r *repository) GetQcInspectionByID(ctx context.Context, id uuid.UUID) (domain.QcInspection, error) {
+	var inspection domain.QcInspection
+	err := r.master(ctx).
+		Preload("Results", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Where("id = ?", id).First(&inspection).Error
+	if err != nil {
+		return domain.QcInspection{}, notFound(err, "QC inspection")
+	}
+	return inspection, nil
+}
+
+func (r *repository) GetQcInspections(ctx context.Context, hubID uuid.UUID, status string) ([]domain.QcInspection, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var inspections []domain.QcInspection
+	if err := query.Order("created_at").Find(&inspections).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch QC inspections: %w", err)
+	}
+	return inspections, nil
+}
+
+// RecordQcResults records inspected units. Failed units go to damaged stock straight away.
+// Once the sample is inspected, or more units failed than the rule allows, the inspection
+// completes: the remaining units are released to available stock if it passed and moved
+// to damaged stock if it failed.
+func (r *repository) RecordQcResults(ctx context.Context, id uuid.UUID, results []domain.QcResult) (domain.QcInspection, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var inspection domain.QcInspection
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&inspection).Error
+		if err != nil {
+			return notFound(err, "QC inspection")
+		}
+		if inspection.Status != domain.QcStatusPending {
+			return domain.Conflict("QC inspection is already %s", inspection.Status)
+		}
+
+		var inspectedSerials []string
+		err = tx.Model(&domain.QcResult{}).Where("inspection_id = ? AND serial <> ''", id).
+			Pluck("serial", &inspectedSerials).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch QC results: %w", err)
+		}
+		held := make(map[string]bool, len(inspection.Serials))
+		for _, serial := range inspection.Serials {
+			held[serial] = true
+		}
+		for _, serial := range inspectedSerials {
+			held[serial] = false
+		}
+
+		var failedSerials []string
+		failedQty := 0
+		for i, result := range results {
+			if len(inspection.Serials) > 0 {
+				if result.Serial == "" || result.Qty != 1 {
+					return domain.Invalid("result %d: serialized units are inspected one serial at a time", i+1)
+				}
+				if !held[result.Serial] {
+					return domain.Invalid("result %d: serial %s is not awaiting this inspection", i+1, result.Serial)
+				}
+				held[result.Serial] = false
+			} else if result.Serial != "" {
+				return domain.Invalid("result %d: SKU is not serialized", i+1)
+			}
+
+			inspection.InspectedQty += result.Qty
+			if inspection.InspectedQty > inspection.Qty {
+				return domain.Invalid("result %d: only %d units are held for inspection", i+1, inspection.Qty)
+			}
+			if !result.Passed {
+				failedQty += result.Qty
+				if result.Serial != "" {
+					failedSerials = append(failedSerials, result.Serial)
+				}
+			}
+			results[i].InspectionID = id
+		}
+		if err = tx.Create(&results).Error; err != nil {
+			return fmt.Errorf("failed to record QC results: %w", err)
+		}
+
+		if failedQty > 0 {
+			if err = releaseQcStock(tx, inspection, failedQty, failedSerials, domain.LedgerBucketDamaged, "qc_failed"); err != nil {
+				return err
+			}
+			inspection.FailedQty += failedQty
+		}
+
+		updates := map[string]interface{}{
+			"inspected_qty": inspection.InspectedQty,
+			"failed_qty":    inspection.FailedQty,
+		}
+		failed := inspection.FailedQty > inspection.MaxFailures
+		if failed || inspection.InspectedQty >= inspection.SampleSize {
+			var rejected []string
+			err = tx.Model(&domain.QcResult{}).Where("inspection_id = ? AND serial <> '' AND NOT passed", id).
+				Pluck("serial", &rejected).Error
+			if err != nil {
+				return fmt.Errorf("failed to fetch QC results: %w", err)
+			}
+			isRejected := make(map[string]bool, len(rejected))
+			for _, serial := range rejected {
+				isRejected[serial] = true
+			}
+			var remaining []string
+			for _, serial := range inspection.Serials {
+				if !isRejected[serial] {
+					remaining = append(remaining, serial)
+				}
+			}
+
+			status, to, reason := domain.QcStatusPassed, domain.LedgerBucketAvailable, "qc_passed"
+			if failed {
+				status, to, reason = domain.QcStatusFailed, domain.LedgerBucketDamaged, "qc_failed"
+			}
+			if remainingQty := inspection.Qty - inspection.FailedQty; remainingQty > 0 {
+				if err = releaseQcStock(tx, inspection, remainingQty, remaining, to, reason); err != nil {
+					return err
+				}
+			}
+			updates["status"] = status
+			updates["completed_at"] = time.Now()
+		}
+		return tx.Model(&domain.QcInspection{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		return domain.QcInspection{}, err
+	}
+	return r.GetQcInspectionByID(ctx, id)
+}
+
+// releaseQcStock moves qty units held for an inspection from quarantine to another ledger bucket
+func releaseQcStock(tx *gorm.DB, inspection domain.QcInspection, qty int, serials []string, to, reason string) error {
+	if err := moveStockFEFO(tx, inspection.SkuID, inspection.HubID, qty, qtyQuarantine, bucketColumns[to]); err != nil {
+		return err
+	}
+	if len(serials) > 0 {
+		event := domain.SerialEventQcPassed
+		if to == domain.LedgerBucketDamaged {
+			event = domain.SerialEventQcFailed
+		}
+		err := moveSerials(tx, inspection.SkuID, inspection.HubID, serials, []string{domain.SerialStatusQuarantined},
+			bucketSerialStatus[to], event)
+		if err != nil {
+			return err
+		}
+	}
+	return recordLedgerMove(tx, inspection.SkuID, inspection.HubID, domain.LedgerBucketQuarantine, to,
+		qty, reason, domain.LedgerRefQc, inspection.ID)
+}
+
+// findQcRule returns the most specific active QC rule for a SKU: one for its seller and
+// category, then its seller, then its category. It returns nil when no rule applies.
+func findQcRule(tx *gorm.DB, skuID uuid.UUID) (*domain.QcRule, error) {
+	var sku domain.SKU
+	if err := tx.Where("id = ?", skuID).First(&sku).Error; err != nil {
+		return nil, notFound(err, "SKU")
+	}
+
+	var rules []domain.QcRule
+	err := tx.Where("active").
+		Where("(seller_id = ? AND (COALESCE(category, '') = '' OR category = ?)) OR (seller_id IS NULL AND category = ?)",
+			sku.SellerID, sku.Category, sku.Category).
+		Order("seller_id IS NOT NULL DESC, COALESCE(category, '') <> '' DESC, created_at DESC").
+		Limit(1).Find(&rules).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch QC rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &rules[0], nil
+}
+
+// receiveForQc receives lots of a SKU. When a QC rule applies the stock is held in quarantine
+// under a new inspection; otherwise it is available straight away. It returns the ledger
+// bucket the stock went to.
+func receiveForQc(tx *gorm.DB, skuID, hubID uuid.UUID, receipts []domain.LotReceipt, source string, refID *uuid.UUID) (string, error) {
+	rule, err := findQcRule(tx, skuID)
+	if err != nil {
+		return "", err
+	}
+	if rule == nil {
+		return domain.LedgerBucketAvailable, receiveLots(tx, skuID, hubID, qtyAvailable, receipts)
+	}
+	if err = receiveLots(tx, skuID, hubID, qtyQuarantine, receipts); err != nil {
+		return "", err
+	}
+
+	inspection := domain.QcInspection{
+		HubID:       hubID,
+		SkuID:       skuID,
+		RuleID:      rule.ID,
+		Source:      source,
+		RefID:       refID,
+		Status:      domain.QcStatusPending,
+		MaxFailures: rule.MaxFailures,
+		Checklist:   rule.Checklist,
+	}
+	for _, receipt := range receipts {
+		inspection.Qty += receipt.Qty
+		inspection.Serials = append(inspection.Serials, receipt.Serials...)
+	}
+	inspection.SampleSize = rule.Sample(inspection.Qty)
+	if err = tx.Create(&inspection).Error; err != nil {
+		return "", fmt.Errorf("failed to create QC inspection: %w", err)
+	}
+	return domain.LedgerBucketQuarantine, nil
+}
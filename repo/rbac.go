@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+	"wms/pkg"
+)
+
+type RbacRepository interface {
+	CreateRoleAssignment(ctx context.Context, assignment domain.RoleAssignment) (domain.RoleAssignment, error)
+	GetRoleAssignmentByID(ctx context.Context, id uuid.UUID) (domain.RoleAssignment, error)
+	GetRoleAssignments(ctx context.Context, tenantID uuid.UUID, subject string) ([]domain.RoleAssignment, error)
+	DeleteRoleAssignment(ctx context.Context, id uuid.UUID) error
+	GetOwner(ctx context.Context, entity string, id uuid.UUID) (uuid.UUID, *uuid.UUID, error)
+}
+
+// ownerQueries find the tenant an entity belongs to and, for entities of a hub, the hub
+var ownerQueries = map[string]string{
+	domain.EntityHub:    `SELECT tenant_id, id AS hub_id FROM hubs WHERE id = $1`,
+	domain.EntitySeller: `SELECT tenant_id, NULL::uuid AS hub_id FROM sellers WHERE id = $1`,
+	domain.EntitySku: `SELECT s.tenant_id, NULL::uuid AS hub_id FROM skus k
+	    JOIN sellers s ON s.id = k.seller_id WHERE k.id = $1`,
+	domain.EntityBarcode: `SELECT COALESCE(h.tenant_id, s.tenant_id) AS tenant_id, l.hub_id FROM barcodes b
+	    LEFT JOIN locations l ON l.id = b.location_id
+	    LEFT JOIN hubs h ON h.id = l.hub_id
+	    LEFT JOIN skus k ON k.id = b.sku_id
+	    LEFT JOIN sellers s ON s.id = k.seller_id
+	    WHERE b.id = $1`,
+	domain.EntityLocation:        hubOwnerQuery("locations"),
+	domain.EntityCarton:          hubOwnerQuery("cartons"),
+	domain.EntityOrder:           hubOwnerQuery("outbound_orders"),
+	domain.EntityWave:            hubOwnerQuery("waves"),
+	domain.EntityPackage:         hubOwnerQuery("packages"),
+	domain.EntityShipment:        hubOwnerQuery("shipments"),
+	domain.EntityManifest:        hubOwnerQuery("manifests"),
+	domain.EntityPurchaseOrder:   hubOwnerQuery("purchase_orders"),
+	domain.EntityASN:             hubOwnerQuery("asns"),
+	domain.EntityQcInspection:    hubOwnerQuery("qc_inspections"),
+	domain.EntityKittingOrder:    hubOwnerQuery("kitting_orders"),
+	domain.EntityPutawayTask:     hubOwnerQuery("putaway_tasks"),
+	domain.EntityStockTransition: hubOwnerQuery("stock_transitions"),
+	domain.EntityPickTask: `SELECT h.tenant_id, w.hub_id FROM pick_tasks t
+	    JOIN waves w ON w.id = t.wave_id JOIN hubs h ON h.id = w.hub_id WHERE t.id = $1`,
+	domain.EntityReturn: `SELECT h.tenant_id, o.hub_id FROM returns r
+	    JOIN outbound_orders o ON o.id = r.order_id JOIN hubs h ON h.id = o.hub_id WHERE r.id = $1`,
+}
+
+func hubOwnerQuery(table string) string {
+	return `SELECT h.tenant_id, t.hub_id FROM ` + table + ` t JOIN hubs h ON h.id = t.hub_id WHERE t.id = $1`
+}
+
+func (r *repository) CreateRoleAssignment(ctx context.Context, assignment domain.RoleAssignment) (domain.RoleAssignment, error) {
+	if err := r.master(ctx).Create(&assignment).Error; err != nil {
+		if pkg.IsViolatesUniqueConstraint(err) {
+			return domain.RoleAssignment{}, domain.Duplicate("%s already has role %s there", assignment.Subject, assignment.Role)
+		}
+		return domain.RoleAssignment{}, fmt.Errorf("failed to assign role: %w", err)
+	}
+	return assignment, nil
+}
+
+func (r *repository) GetRoleAssignmentByID(ctx context.Context, id uuid.UUID) (domain.RoleAssignment, error) {
+	var assignment domain.RoleAssignment
+	if err := r.master(ctx).Where("id = ?", id).First(&assignment).Error; err != nil {
+		return domain.RoleAssignment{}, notFound(err, "role assignment")
+	}
+	return assignment, nil
+}
+
+// GetRoleAssignments lists the role assignments of a tenant, of one subject when one is given
+func (r *repository) GetRoleAssignments(ctx context.Context, tenantID uuid.UUID, subject string) ([]domain.RoleAssignment, error) {
+	query := r.master(ctx).Where("tenant_id = ?", tenantID)
+	if subject != "" {
+		query = query.Where("subject = ?", subject)
+	}
+
+	var assignments []domain.RoleAssignment
+	if err := query.Order("created_at").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch role assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+func (r *repository) DeleteRoleAssignment(ctx context.Context, id uuid.UUID) error {
+	result := r.master(ctx).Where("id = ?", id).Delete(&domain.RoleAssignment{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete role assignment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("role assignment not found")
+	}
+	return nil
+}
+
+// GetOwner returns the tenant an entity belongs to, and its hub unless it belongs to the
+// tenant as a whole
+func (r *repository) GetOwner(ctx context.Context, entity string, id uuid.UUID) (uuid.UUID, *uuid.UUID, error) {
+	query, ok := ownerQueries[entity]
+	if !ok {
+		return uuid.Nil, nil, fmt.Errorf("unknown entity %s", entity)
+	}
+
+	var owners []struct {
+		TenantID uuid.UUID
+		HubID    *uuid.UUID
+	}
+	if err := r.master(ctx).Raw(query, id).Scan(&owners).Error; err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to fetch %s: %w", entity, err)
+	}
+	if len(owners) == 0 {
+		return uuid.Nil, nil, domain.NotFound("%s not found", strings.ReplaceAll(entity, "_", " "))
+	}
+	return owners[0].TenantID, owners[0].HubID, nil
+}
@@ -6,22 +6,46 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/omniful/go_commons/db/sql/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"sync"
 	"wms/domain"
+	"wms/pkg"
 )
 
 type Repository interface {
 	GetAllHubs(ctx context.Context) ([]domain.Hub, error)
-	GetAllSkus(ctx context.Context) ([]domain.SKU, error)
+	GetAllSkus(ctx context.Context, tenantID uuid.UUID) ([]domain.SKU, error)
 	GetHubByID(ctx context.Context, id uuid.UUID) (domain.Hub, error)
 	GetSkuByID(ctx context.Context, id uuid.UUID) (domain.SKU, error)
-	CreateHub(ctx context.Context, hub domain.Hub) error
-	CreateSKU(ctx context.Context, sku domain.SKU) error
+	CreateHub(ctx context.Context, hub domain.Hub) (domain.Hub, error)
+	CreateSKU(ctx context.Context, sku domain.SKU) (domain.SKU, error)
 	DecreaseAvailableQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error
 	DecreaseAllocatedQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error
 	DecreaseDamagedQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error
 	DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid.UUID, availableQty, allocatedQty, damagedQty int) error
 	GetInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error)
+	LockInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error)
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	LotRepository
+	SerialRepository
+	LocationRepository
+	PutawayRepository
+	OrderRepository
+	PackingRepository
+	ShipmentRepository
+	ReturnRepository
+	DamageRepository
+	InboundRepository
+	QcRepository
+	KitRepository
+	UomRepository
+	BarcodeRepository
+	LabelRepository
+	AuthRepository
+	RbacRepository
+	AuditRepository
+	EventRepository
 }
 
 type repository struct {
@@ -41,38 +65,63 @@ func NewRepository(db *postgres.DbCluster) Repository {
 	return repo
 }
 
-func (r *repository) CreateHub(ctx context.Context, hub domain.Hub) error {
-	// Insert the new hub into the database
-	err := r.db.GetMasterDB(ctx).Create(&hub).Error
+// notFound tells a record that does not exist from a query that failed
+func notFound(err error, what string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.NotFound("%s not found", what)
+	}
+	return fmt.Errorf("failed to fetch %s: %w", what, err)
+}
+
+// CreateHub inserts a hub with its default dock and staging bins, where stock received or
+// picked without naming a bin goes
+func (r *repository) CreateHub(ctx context.Context, hub domain.Hub) (domain.Hub, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&hub).Error; err != nil {
+			return err
+		}
+		return seedHubBins(tx, hub.ID)
+	})
 	if err != nil {
-		return err
+		if pkg.IsViolatesUniqueConstraint(err) {
+			return domain.Hub{}, domain.Duplicate("hub %s already exists", hub.Code)
+		}
+		return domain.Hub{}, fmt.Errorf("failed to create hub: %w", err)
 	}
-	return nil
+	return hub, nil
 }
 
-func (r *repository) CreateSKU(ctx context.Context, sku domain.SKU) error {
+func (r *repository) CreateSKU(ctx context.Context, sku domain.SKU) (domain.SKU, error) {
 	// Insert the new SKU into the database
-	err := r.db.GetMasterDB(ctx).Create(&sku).Error
+	err := r.master(ctx).Create(&sku).Error
 	if err != nil {
-		return err
+		if pkg.IsViolatesUniqueConstraint(err) {
+			return domain.SKU{}, domain.Duplicate("SKU %s already exists", sku.Code)
+		}
+		return domain.SKU{}, fmt.Errorf("failed to create SKU: %w", err)
 	}
-	return nil
+	return sku, nil
 }
 
 func (r *repository) GetAllHubs(ctx context.Context) ([]domain.Hub, error) {
 	var hubs []domain.Hub
-	err := r.db.GetMasterDB(ctx).Find(&hubs).Error
+	err := r.master(ctx).Find(&hubs).Error
 	if err != nil {
-		return nil, errors.New("failed to fetch hubs")
+		return nil, fmt.Errorf("failed to fetch hubs: %w", err)
 	}
 	return hubs, nil
 }
 
-func (r *repository) GetAllSkus(ctx context.Context) ([]domain.SKU, error) {
+// GetAllSkus fetches the SKUs of a tenant's sellers
+func (r *repository) GetAllSkus(ctx context.Context, tenantID uuid.UUID) ([]domain.SKU, error) {
 	var skus []domain.SKU
-	err := r.db.GetMasterDB(ctx).Find(&skus).Error
+	err := r.master(ctx).
+		Joins("JOIN sellers ON sellers.id = skus.seller_id").
+		Where("sellers.tenant_id = ?", tenantID).
+		Select("skus.*").
+		Find(&skus).Error
 	if err != nil {
-		return nil, errors.New("failed to fetch SKUs")
+		return nil, fmt.Errorf("failed to fetch SKUs: %w", err)
 	}
 	return skus, nil
 }
@@ -80,9 +129,9 @@ func (r *repository) GetAllSkus(ctx context.Context) ([]domain.SKU, error) {
 // GetHubByID fetches a single hub by ID from the database
 func (r *repository) GetHubByID(ctx context.Context, id uuid.UUID) (domain.Hub, error) {
 	var hub domain.Hub
-	err := r.db.GetMasterDB(ctx).Where("id = ?", id).First(&hub).Error
+	err := r.master(ctx).Where("id = ?", id).First(&hub).Error
 	if err != nil {
-		return domain.Hub{}, errors.New("hub not found")
+		return domain.Hub{}, notFound(err, "hub")
 	}
 	return hub, nil
 }
@@ -90,28 +139,28 @@ func (r *repository) GetHubByID(ctx context.Context, id uuid.UUID) (domain.Hub,
 // GetSkuByID fetches a single SKU by ID from the database
 func (r *repository) GetSkuByID(ctx context.Context, id uuid.UUID) (domain.SKU, error) {
 	var sku domain.SKU
-	err := r.db.GetMasterDB(ctx).Where("id = ?", id).First(&sku).Error
+	err := r.master(ctx).Where("id = ?", id).First(&sku).Error
 	if err != nil {
-		return domain.SKU{}, errors.New("SKU not found")
+		return domain.SKU{}, notFound(err, "SKU")
 	}
 	return sku, nil
 }
 
 func (r *repository) DecreaseAvailableQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error {
 	// Decrease available_qty by the specified quantity
-	result := r.db.GetMasterDB(ctx).Exec(`
+	result := r.master(ctx).Exec(`
 		UPDATE inventories
 		SET available_qty = available_qty - $1, updated_at = CURRENT_TIMESTAMP
 		WHERE sku_id = $2 AND hub_id = $3 AND available_qty >= $1
 	`, qty, skuID, hubID)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to decrease available quantity: %v", result.Error)
+		return fmt.Errorf("failed to decrease available quantity: %w", result.Error)
 	}
 
 	// If no rows were affected, it means there wasn't enough stock
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("not enough available quantity")
+		return domain.InsufficientStock("not enough available quantity")
 	}
 
 	return nil
@@ -119,38 +168,38 @@ func (r *repository) DecreaseAvailableQty(ctx context.Context, skuID, hubID uuid
 
 func (r *repository) DecreaseAllocatedQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error {
 	// Decrease allocated_qty by the specified quantity
-	result := r.db.GetMasterDB(ctx).Exec(`
+	result := r.master(ctx).Exec(`
 		UPDATE inventories
 		SET allocated_qty = allocated_qty - $1, updated_at = CURRENT_TIMESTAMP
 		WHERE sku_id = $2 AND hub_id = $3 AND allocated_qty >= $1
 	`, qty, skuID, hubID)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to decrease allocated quantity: %v", result.Error)
+		return fmt.Errorf("failed to decrease allocated quantity: %w", result.Error)
 	}
 
 	// If no rows were affected, it means there wasn't enough stock allocated
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("not enough allocated quantity")
+		return domain.InsufficientStock("not enough allocated quantity")
 	}
 
 	return nil
 }
 func (r *repository) DecreaseDamagedQty(ctx context.Context, skuID, hubID uuid.UUID, qty int) error {
 	// Decrease damaged_qty by the specified quantity
-	result := r.db.GetMasterDB(ctx).Exec(`
+	result := r.master(ctx).Exec(`
 		UPDATE inventories
 		SET damaged_qty = damaged_qty - $1, updated_at = CURRENT_TIMESTAMP
 		WHERE sku_id = $2 AND hub_id = $3 AND damaged_qty >= $1
 	`, qty, skuID, hubID)
 
 	if result.Error != nil {
-		return fmt.Errorf("failed to decrease damaged quantity: %v", result.Error)
+		return fmt.Errorf("failed to decrease damaged quantity: %w", result.Error)
 	}
 
 	// If no rows were affected, it means there wasn't enough damaged stock
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("not enough damaged quantity")
+		return domain.InsufficientStock("not enough damaged quantity")
 	}
 
 	return nil
@@ -200,10 +249,22 @@ func (r *repository) DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid
 func (r *repository) GetInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error) {
 	var inventory domain.Inventory
 
-	err := r.db.GetMasterDB(ctx).Where("sku_id = ? AND hub_id = ?", skuID, hubID).First(&inventory).Error
+	err := r.master(ctx).Where("sku_id = ? AND hub_id = ?", skuID, hubID).First(&inventory).Error
 	if err != nil {
-		return domain.Inventory{}, fmt.Errorf("failed to fetch inventory: %v", err)
+		return domain.Inventory{}, notFound(err, "inventory")
 	}
 
 	return inventory, nil
 }
+
+// LockInventory fetches an inventory record and locks it until the transaction of the
+// context ends, so what is read stays current until the write that follows
+func (r *repository) LockInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error) {
+	var inventory domain.Inventory
+	err := r.master(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ?", skuID, hubID).First(&inventory).Error
+	if err != nil {
+		return domain.Inventory{}, notFound(err, "inventory")
+	}
+	return inventory, nil
+}
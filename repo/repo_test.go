@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+	"wms/domain"
+)
+
+// testDatabaseEnv names the Postgres database the repository tests run against, migrated
+// to the latest version. They are skipped when it is not set.
+const testDatabaseEnv = "WMS_TEST_DATABASE_URL"
+
+// testRepository returns a repository whose calls run in a transaction of the test
+// database, rolled back when the test ends, and the context to make them with
+func testRepository(t *testing.T) (*repository, context.Context) {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open the test database: %v", err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin a transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return &repository{}, context.WithValue(context.Background(), txKey{}, tx)
+}
+
+// testCode returns a code unique to the test run, so fixtures don't clash with existing rows
+func testCode(prefix string) string {
+	return prefix + "-" + uuid.NewString()[:8]
+}
+
+// newTestHub creates a tenant and a hub of it through CreateHub
+func newTestHub(t *testing.T, ctx context.Context, r *repository) domain.Hub {
+	t.Helper()
+	tenant := domain.Tenant{Name: testCode("tenant"), Email: testCode("tenant") + "@example.com"}
+	if err := r.master(ctx).Create(&tenant).Error; err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	hub, err := r.CreateHub(ctx, domain.Hub{TenantID: tenant.ID, Name: "Test hub", Code: testCode("HUB"), Address: "Test street"})
+	if err != nil {
+		t.Fatalf("failed to create hub: %v", err)
+	}
+	return hub
+}
+
+// newTestSku creates a SKU of a new seller of the hub's tenant
+func newTestSku(t *testing.T, ctx context.Context, r *repository, hub domain.Hub) domain.SKU {
+	t.Helper()
+	seller := domain.Seller{TenantID: hub.TenantID, Name: "Test seller", Code: testCode("SEL")}
+	if err := r.master(ctx).Create(&seller).Error; err != nil {
+		t.Fatalf("failed to create seller: %v", err)
+	}
+	sku, err := r.CreateSKU(ctx, domain.SKU{SellerID: seller.ID, Name: "Test SKU", Code: testCode("SKU"), UOM: "EA"})
+	if err != nil {
+		t.Fatalf("failed to create SKU: %v", err)
+	}
+	return sku
+}
+
+// newTestBin creates a bin of a type at a hub at a position of the walk path, under a zone,
+// aisle and rack of its own
+func newTestBin(t *testing.T, ctx context.Context, r *repository, hubID uuid.UUID, locationType string, sequence int) domain.Location {
+	t.Helper()
+	code := testCode(locationType)
+	var parentID *uuid.UUID
+	var location domain.Location
+	for _, level := range []string{domain.LocationLevelZone, domain.LocationLevelAisle, domain.LocationLevelRack, domain.LocationLevelBin} {
+		var err error
+		location, err = r.CreateLocation(ctx, domain.Location{
+			HubID:    hubID,
+			ParentID: parentID,
+			Code:     code + "-" + level,
+			Level:    level,
+			Type:     locationType,
+			Sequence: sequence,
+			Active:   true,
+		})
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", level, err)
+		}
+		parentID = &location.ID
+	}
+	return location
+}
+
+// binQty returns the units of a SKU in a bin
+func binQty(t *testing.T, ctx context.Context, r *repository, locationID, skuID uuid.UUID) int {
+	t.Helper()
+	stock, err := r.GetLocationStock(ctx, locationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stock {
+		if s.SkuID == skuID {
+			return s.Qty
+		}
+	}
+	return 0
+}
+
+// releaseTestOrder creates an order of qty units of a SKU, puts it in a wave of its own and
+// releases the wave, returning the order and its pick tasks
+func releaseTestOrder(t *testing.T, ctx context.Context, r *repository, hubID, skuID uuid.UUID, qty int) (domain.OutboundOrder, []domain.PickTask) {
+	t.Helper()
+	order, err := r.CreateOrder(ctx, domain.OutboundOrder{
+		HubID:   hubID,
+		OrderNo: testCode("ORD"),
+		Lines:   []domain.OutboundOrderLine{{SkuID: skuID, Qty: qty}},
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	waves, err := r.CreateWaves(ctx, hubID, "", nil)
+	if err != nil {
+		t.Fatalf("create waves: %v", err)
+	}
+	if len(waves) != 1 {
+		t.Fatalf("create waves: %d waves, want 1", len(waves))
+	}
+	tasks, err := r.ReleaseWave(ctx, waves[0].ID)
+	if err != nil {
+		t.Fatalf("release wave: %v", err)
+	}
+	return order, tasks
+}
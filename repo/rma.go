@@ -0,0 +1,356 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type ReturnRepository interface {
+	CreateReturn(ctx context.Context, ret domain.Return) (domain.Return, error)
+	GetReturnByID(ctx context.Context, id uuid.UUID) (domain.Return, error)
+	GetReturns(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Return, error)
+	ReceiveReturn(ctx context.Context, id, hubID uuid.UUID, receivedQty map[uuid.UUID]int) (domain.Return, error)
+	GradeReturn(ctx context.Context, id uuid.UUID, items []domain.ReturnItem) (domain.Return, error)
+	GetReturnReport(ctx context.Context, sellerID, skuID *uuid.UUID, from, to *time.Time) ([]domain.ReturnReport, error)
+}
+
+// gradeBuckets maps a return grade to the stock bucket its units go to. Scrap never re-enters stock.
+var gradeBuckets = map[string]string{
+	domain.ReturnGradeResellable: qtyAvailable,
+	domain.ReturnGradeDamaged:    qtyDamaged,
+}
+
+// CreateReturn authorises the return of shipped units of an order. A line can't return more
+// than was shipped, counting what earlier returns already cover.
+func (r *repository) CreateReturn(ctx context.Context, ret domain.Return) (domain.Return, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var order domain.OutboundOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ret.OrderID).First(&order).Error
+		if err != nil {
+			return notFound(err, "order")
+		}
+		if order.Status != domain.OrderStatusShipped {
+			return domain.Conflict("order is %s, only shipped orders can be returned", order.Status)
+		}
+
+		for i, line := range ret.Lines {
+			var orderLine domain.OutboundOrderLine
+			err = tx.Where("id = ? AND order_id = ?", line.OrderLineID, order.ID).First(&orderLine).Error
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, notFound(err, "order line"))
+			}
+
+			var returned int
+			err = tx.Model(&domain.ReturnLine{}).Where("order_line_id = ?", orderLine.ID).
+				Select("COALESCE(SUM(qty), 0)").Scan(&returned).Error
+			if err != nil {
+				return fmt.Errorf("failed to fetch return lines: %w", err)
+			}
+			for _, other := range ret.Lines[:i] {
+				if other.OrderLineID == orderLine.ID {
+					returned += other.Qty
+				}
+			}
+			if returned+line.Qty > orderLine.ShippedQty {
+				return domain.Invalid("line %d: only %d shipped units are left to return", i+1, orderLine.ShippedQty-returned)
+			}
+			ret.Lines[i].SkuID = orderLine.SkuID
+		}
+
+		ret.Status = domain.ReturnStatusAuthorized
+		if err = tx.Create(&ret).Error; err != nil {
+			return fmt.Errorf("failed to create return: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return ret, nil
+}
+
+func (r *repository) GetReturnByID(ctx context.Context, id uuid.UUID) (domain.Return, error) {
+	var ret domain.Return
+	err := r.master(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Preload("Lines.Grades").
+		Where("id = ?", id).First(&ret).Error
+	if err != nil {
+		return domain.Return{}, notFound(err, "return")
+	}
+	return ret, nil
+}
+
+// GetReturns lists the returns of orders shipped from a hub or received at it
+func (r *repository) GetReturns(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Return, error) {
+	query := r.master(ctx).Preload("Lines").
+		Where("received_hub_id = ? OR order_id IN (?)", hubID,
+			r.master(ctx).Model(&domain.OutboundOrder{}).Select("id").Where("hub_id = ?", hubID))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var returns []domain.Return
+	if err := query.Order("created_at").Find(&returns).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch returns: %w", err)
+	}
+	return returns, nil
+}
+
+// ReceiveReturn records the units of each line that arrived at a hub. Lines left out of
+// receivedQty came back empty.
+func (r *repository) ReceiveReturn(ctx context.Context, id, hubID uuid.UUID, receivedQty map[uuid.UUID]int) (domain.Return, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var ret domain.Return
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&ret).Error
+		if err != nil {
+			return notFound(err, "return")
+		}
+		if ret.Status != domain.ReturnStatusAuthorized {
+			return domain.Conflict("return is already %s", ret.Status)
+		}
+
+		var lines []domain.ReturnLine
+		if err = tx.Where("return_id = ?", id).Find(&lines).Error; err != nil {
+			return fmt.Errorf("failed to fetch return lines: %w", err)
+		}
+
+		known := make(map[uuid.UUID]domain.ReturnLine, len(lines))
+		for _, line := range lines {
+			known[line.ID] = line
+		}
+		for lineID, qty := range receivedQty {
+			line, ok := known[lineID]
+			if !ok {
+				return domain.Invalid("return line %s not found", lineID)
+			}
+			if qty > line.Qty {
+				return domain.Invalid("only %d units of return line %s were authorised", line.Qty, lineID)
+			}
+			err = tx.Model(&domain.ReturnLine{}).Where("id = ?", lineID).Update("received_qty", qty).Error
+			if err != nil {
+				return fmt.Errorf("failed to update return line: %w", err)
+			}
+		}
+
+		status := domain.ReturnStatusReceived
+		total := 0
+		for _, qty := range receivedQty {
+			total += qty
+		}
+		if total == 0 {
+			status = domain.ReturnStatusCompleted
+		}
+
+		return tx.Model(&domain.Return{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":          status,
+			"received_hub_id": hubID,
+			"received_at":     time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return r.GetReturnByID(ctx, id)
+}
+
+// GradeReturn grades received units of a return. Resellable units go back to available stock,
+// damaged ones to damaged stock and scrap is written off, each with a ledger entry. Serials of
+// resellable units come back into stock. The return completes once every received unit is graded.
+func (r *repository) GradeReturn(ctx context.Context, id uuid.UUID, items []domain.ReturnItem) (domain.Return, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var ret domain.Return
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&ret).Error
+		if err != nil {
+			return notFound(err, "return")
+		}
+		if ret.Status != domain.ReturnStatusReceived {
+			return domain.Conflict("return is %s, only received returns can be graded", ret.Status)
+		}
+		hubID := *ret.ReceivedHubID
+
+		var lines []domain.ReturnLine
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("return_id = ?", id).Find(&lines).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch return lines: %w", err)
+		}
+		byID := make(map[uuid.UUID]*domain.ReturnLine, len(lines))
+		for i := range lines {
+			byID[lines[i].ID] = &lines[i]
+		}
+
+		for i, item := range items {
+			line, ok := byID[item.ReturnLineID]
+			if !ok {
+				return domain.Invalid("item %d: return line not found", i+1)
+			}
+			if ungraded := line.ReceivedQty - line.GradedQty; item.Qty > ungraded {
+				return domain.Invalid("item %d: only %d received units are left to grade", i+1, ungraded)
+			}
+
+			if len(item.Serials) > 0 {
+				if err = checkReturnedSerials(tx, *line, item.Serials); err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+			}
+
+			bucket := domain.LedgerBucketWrittenOff
+			var locationID *uuid.UUID
+			if column, ok := gradeBuckets[item.Grade]; ok {
+				if _, err = addToInventory(tx, line.SkuID, hubID, column, item.Qty); err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+				dock, err := receiveIntoBin(tx, line.SkuID, hubID, nil, item.Qty)
+				if err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+				locationID = &dock
+				bucket = ledgerBucket(column)
+			}
+
+			if len(item.Serials) > 0 {
+				switch item.Grade {
+				case domain.ReturnGradeResellable:
+					err = receiveSerials(tx, line.SkuID, hubID, *locationID, item.Serials)
+				case domain.ReturnGradeDamaged:
+					err = recordReturnedSerials(tx, line.SkuID, hubID, item.Serials, domain.SerialStatusDamaged, locationID)
+				default:
+					err = recordReturnedSerials(tx, line.SkuID, hubID, item.Serials, domain.SerialStatusRemoved, nil)
+				}
+				if err != nil {
+					return fmt.Errorf("item %d: %w", i+1, err)
+				}
+			}
+			if len(item.Serials) > 0 && item.Grade == domain.ReturnGradeResellable {
+				if err = checkSerialCounts(tx, line.SkuID, hubID); err != nil {
+					return err
+				}
+			}
+
+			err = recordLedgerEntry(tx, domain.LedgerEntry{
+				SkuID:   line.SkuID,
+				HubID:   hubID,
+				Bucket:  bucket,
+				Qty:     item.Qty,
+				Reason:  line.Reason,
+				RefType: domain.LedgerRefReturn,
+				RefID:   ret.ID,
+			})
+			if err != nil {
+				return err
+			}
+
+			item.ID = uuid.Nil
+			if err = tx.Create(&item).Error; err != nil {
+				return fmt.Errorf("failed to record grade: %w", err)
+			}
+			err = tx.Model(&domain.ReturnLine{}).Where("id = ?", line.ID).
+				Update("graded_qty", gorm.Expr("graded_qty + ?", item.Qty)).Error
+			if err != nil {
+				return fmt.Errorf("failed to update return line: %w", err)
+			}
+			line.GradedQty += item.Qty
+		}
+
+		for _, line := range lines {
+			if line.GradedQty < line.ReceivedQty {
+				return nil
+			}
+		}
+		return tx.Model(&domain.Return{}).Where("id = ?", id).Update("status", domain.ReturnStatusCompleted).Error
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return r.GetReturnByID(ctx, id)
+}
+
+// GetReturnReport sums up the returned units per SKU and reason, with how they were graded
+func (r *repository) GetReturnReport(ctx context.Context, sellerID, skuID *uuid.UUID, from, to *time.Time) ([]domain.ReturnReport, error) {
+	var report []domain.ReturnReport
+	err := r.master(ctx).Raw(`
+		SELECT s.seller_id,
+		       rl.sku_id,
+		       s.code AS sku_code,
+		       rl.reason,
+		       SUM(rl.qty) AS returned_qty,
+		       SUM(g.resellable) AS resellable_qty,
+		       SUM(g.damaged) AS damaged_qty,
+		       SUM(g.scrap) AS scrap_qty
+		FROM return_lines rl
+		JOIN returns rt ON rt.id = rl.return_id
+		JOIN skus s ON s.id = rl.sku_id
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(SUM(ri.qty) FILTER (WHERE ri.grade = 'resellable'), 0) AS resellable,
+			       COALESCE(SUM(ri.qty) FILTER (WHERE ri.grade = 'damaged'), 0) AS damaged,
+			       COALESCE(SUM(ri.qty) FILTER (WHERE ri.grade = 'scrap'), 0) AS scrap
+			FROM return_items ri
+			WHERE ri.return_line_id = rl.id
+		) g
+		WHERE ($1::uuid IS NULL OR s.seller_id = $1)
+		  AND ($2::uuid IS NULL OR rl.sku_id = $2)
+		  AND ($3::timestamptz IS NULL OR rt.created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR rt.created_at < $4)
+		GROUP BY s.seller_id, rl.sku_id, s.code, rl.reason
+		ORDER BY s.seller_id, s.code, returned_qty DESC
+	`, sellerID, skuID, from, to).Scan(&report).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch return report: %w", err)
+	}
+	return report, nil
+}
+
+// checkReturnedSerials makes sure serials coming back were shipped on the order line being returned
+func checkReturnedSerials(tx *gorm.DB, line domain.ReturnLine, serials []string) error {
+	var shipped []string
+	err := tx.Raw(`
+		SELECT DISTINCT s.serial
+		FROM package_items pi
+		CROSS JOIN LATERAL unnest(pi.serials) AS s(serial)
+		WHERE pi.order_line_id = $1 AND s.serial = ANY($2)
+	`, line.OrderLineID, pq.Array(serials)).Scan(&shipped).Error
+	if err != nil {
+		return fmt.Errorf("failed to check shipped serials: %w", err)
+	}
+	if len(shipped) != len(serials) {
+		return domain.Invalid("some serials were not shipped on this order line")
+	}
+	return nil
+}
+
+// recordReturnedSerials notes that shipped serials came back to a hub outside of sellable stock,
+// either damaged into a bin or scrapped
+func recordReturnedSerials(tx *gorm.DB, skuID, hubID uuid.UUID, serialNumbers []string, status string, locationID *uuid.UUID) error {
+	var serials []domain.Serial
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND serial_number IN ?", skuID, serialNumbers).
+		Find(&serials).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch serials: %w", err)
+	}
+	for _, serial := range serials {
+		if serial.Status != domain.SerialStatusRemoved {
+			return domain.Conflict("serial %s is already in stock", serial.SerialNumber)
+		}
+		err = tx.Model(&domain.Serial{}).Where("id = ?", serial.ID).Updates(map[string]interface{}{
+			"hub_id":      hubID,
+			"location_id": locationID,
+			"status":      status,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update serial %s: %w", serial.SerialNumber, err)
+		}
+		if err = recordSerialEvent(tx, serial.ID, hubID, domain.SerialEventReturned, locationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
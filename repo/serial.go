@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"wms/domain"
+)
+
+type SerialRepository interface {
+	GetSerialsByNumber(ctx context.Context, tenantID uuid.UUID, serialNumber string) ([]domain.Serial, error)
+}
+
+// GetSerialsByNumber fetches every serial with the given number of a tenant's SKUs, across
+// SKUs, with its history
+func (r *repository) GetSerialsByNumber(ctx context.Context, tenantID uuid.UUID, serialNumber string) ([]domain.Serial, error) {
+	var serials []domain.Serial
+	err := r.master(ctx).
+		Preload("Location").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Joins("JOIN skus ON skus.id = serials.sku_id").
+		Joins("JOIN sellers ON sellers.id = skus.seller_id").
+		Where("serials.serial_number = ? AND sellers.tenant_id = ?", serialNumber, tenantID).
+		Select("serials.*").
+		Find(&serials).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch serials: %w", err)
+	}
+	return serials, nil
+}
+
+// receiveSerials puts serials into available stock in a bin of a hub. A serial that left stock
+// earlier may come back; one that is still in stock anywhere may not be received twice.
+func receiveSerials(tx *gorm.DB, skuID, hubID, locationID uuid.UUID, serialNumbers []string) error {
+	var existing []domain.Serial
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND serial_number IN ?", skuID, serialNumbers).
+		Find(&existing).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch serials: %w", err)
+	}
+
+	known := make(map[string]domain.Serial, len(existing))
+	for _, serial := range existing {
+		if serial.Status != domain.SerialStatusRemoved {
+			return domain.Conflict("serial %s is already in stock", serial.SerialNumber)
+		}
+		known[serial.SerialNumber] = serial
+	}
+
+	for _, serialNumber := range serialNumbers {
+		serial, ok := known[serialNumber]
+		if ok {
+			err = tx.Model(&domain.Serial{}).Where("id = ?", serial.ID).Updates(map[string]interface{}{
+				"hub_id":      hubID,
+				"location_id": locationID,
+				"status":      domain.SerialStatusAvailable,
+			}).Error
+		} else {
+			serial = domain.Serial{
+				SkuID:        skuID,
+				HubID:        hubID,
+				SerialNumber: serialNumber,
+				LocationID:   &locationID,
+				Status:       domain.SerialStatusAvailable,
+			}
+			err = tx.Create(&serial).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to receive serial %s: %w", serialNumber, err)
+		}
+
+		if err = recordSerialEvent(tx, serial.ID, hubID, domain.SerialEventReceived, &locationID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveSerials moves serials of a SKU at a hub from one of the from statuses to another
+// status. Every serial has to be at the hub in one of the from statuses.
+func moveSerials(tx *gorm.DB, skuID, hubID uuid.UUID, serialNumbers []string, from []string, to, event string) error {
+	var serials []domain.Serial
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ? AND status IN ? AND serial_number IN ?", skuID, hubID, from, serialNumbers).
+		Find(&serials).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch serials: %w", err)
+	}
+
+	if len(serials) != len(serialNumbers) {
+		found := make(map[string]bool, len(serials))
+		for _, serial := range serials {
+			found[serial.SerialNumber] = true
+		}
+		for _, serialNumber := range serialNumbers {
+			if !found[serialNumber] {
+				return domain.Invalid("serial %s is not %s at this hub", serialNumber, strings.Join(from, " or "))
+			}
+		}
+	}
+
+	for _, serial := range serials {
+		updates := map[string]interface{}{"status": to}
+		if to == domain.SerialStatusRemoved {
+			updates["location_id"] = nil
+		}
+		err = tx.Model(&domain.Serial{}).Where("id = ?", serial.ID).Updates(updates).Error
+		if err != nil {
+			return fmt.Errorf("failed to update serial %s: %w", serial.SerialNumber, err)
+		}
+		if err = recordSerialEvent(tx, serial.ID, hubID, event, serial.LocationID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordSerialEvent(tx *gorm.DB, serialID, hubID uuid.UUID, event string, locationID *uuid.UUID) error {
+	err := tx.Create(&domain.SerialEvent{
+		SerialID:   serialID,
+		HubID:      hubID,
+		Event:      event,
+		LocationID: locationID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record serial event: %w", err)
+	}
+	return nil
+}
+
+// checkSerialCounts makes sure the serials in stock match the units on hand of a serialized SKU.
+// Units allocated without naming serials keep their serials available until they are picked,
+// so available and allocated serials are counted together.
+func checkSerialCounts(tx *gorm.DB, skuID, hubID uuid.UUID) error {
+	var inStock int64
+	err := tx.Model(&domain.Serial{}).
+		Where("sku_id = ? AND hub_id = ? AND status IN ?", skuID, hubID,
+			[]string{domain.SerialStatusAvailable, domain.SerialStatusAllocated}).
+		Count(&inStock).Error
+	if err != nil {
+		return fmt.Errorf("failed to count serials: %w", err)
+	}
+
+	var inventory domain.Inventory
+	err = tx.Where("sku_id = ? AND hub_id = ?", skuID, hubID).First(&inventory).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch inventory: %w", err)
+	}
+
+	if qty := inventory.AvailableQty + inventory.AllocatedQty; int(inStock) != qty {
+		return domain.Conflict("serial count mismatch: %d serials in stock for %d available and allocated units", inStock, qty)
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"wms/domain"
+)
+
+type ShipmentRepository interface {
+	GetShipmentByID(ctx context.Context, id uuid.UUID) (domain.Shipment, error)
+	GetShipments(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Shipment, error)
+	AssignCarrier(ctx context.Context, id uuid.UUID, carrier, carrierService string) (domain.Shipment, error)
+	SetTrackingNumber(ctx context.Context, packageID uuid.UUID, trackingNumber string) error
+	CreateManifest(ctx context.Context, hubID uuid.UUID, carrier string, manifestDate time.Time) (domain.Manifest, error)
+	GetManifestByID(ctx context.Context, id uuid.UUID) (domain.Manifest, error)
+	GetManifests(ctx context.Context, hubID uuid.UUID, manifestDate *time.Time) ([]domain.Manifest, error)
+	DispatchShipment(ctx context.Context, id uuid.UUID) (domain.Shipment, error)
+	DispatchManifest(ctx context.Context, id uuid.UUID) (domain.Manifest, error)
+}
+
+func (r *repository) GetShipmentByID(ctx context.Context, id uuid.UUID) (domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := r.master(ctx).Preload("Packages.Items").Preload("Order").
+		Where("id = ?", id).First(&shipment).Error
+	if err != nil {
+		return domain.Shipment{}, notFound(err, "shipment")
+	}
+	return shipment, nil
+}
+
+func (r *repository) GetShipments(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Shipment, error) {
+	query := r.master(ctx).Preload("Packages").Where("hub_id = ?", hubID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var shipments []domain.Shipment
+	if err := query.Order("created_at").Find(&shipments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch shipments: %w", err)
+	}
+	return shipments, nil
+}
+
+// AssignCarrier sets the carrier and service of a shipment that hasn't been manifested yet
+func (r *repository) AssignCarrier(ctx context.Context, id uuid.UUID, carrier, carrierService string) (domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&shipment).Error
+		if err != nil {
+			return notFound(err, "shipment")
+		}
+		if shipment.Status != domain.ShipmentStatusPacked {
+			return domain.Conflict("shipment is already %s", shipment.Status)
+		}
+
+		shipment.Carrier = carrier
+		shipment.Service = carrierService
+		return tx.Model(&domain.Shipment{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"carrier": carrier,
+			"service": carrierService,
+		}).Error
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+// SetTrackingNumber records the carrier tracking number of a packed package that hasn't been dispatched
+func (r *repository) SetTrackingNumber(ctx context.Context, packageID uuid.UUID, trackingNumber string) error {
+	return r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var pkg domain.Package
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", packageID).First(&pkg).Error
+		if err != nil {
+			return notFound(err, "package")
+		}
+		if pkg.ShipmentID == nil {
+			return domain.Conflict("package has not been packed into a shipment yet")
+		}
+
+		var shipment domain.Shipment
+		if err = tx.Where("id = ?", *pkg.ShipmentID).First(&shipment).Error; err != nil {
+			return notFound(err, "shipment")
+		}
+		if shipment.Status == domain.ShipmentStatusDispatched {
+			return domain.Conflict("shipment has already been dispatched")
+		}
+
+		return tx.Model(&domain.Package{}).Where("id = ?", packageID).Update("tracking_number", trackingNumber).Error
+	})
+}
+
+// CreateManifest puts the packed shipments of a hub for a carrier that aren't on a manifest
+// yet onto a new one. Every package has to have its tracking number.
+func (r *repository) CreateManifest(ctx context.Context, hubID uuid.UUID, carrier string, manifestDate time.Time) (domain.Manifest, error) {
+	manifest := domain.Manifest{
+		HubID:        hubID,
+		Carrier:      carrier,
+		ManifestDate: manifestDate,
+	}
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var shipments []domain.Shipment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hub_id = ? AND carrier = ? AND status = ? AND manifest_id IS NULL", hubID, carrier, domain.ShipmentStatusPacked).
+			Order("created_at").Find(&shipments).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch shipments: %w", err)
+		}
+		if len(shipments) == 0 {
+			return domain.Conflict("no packed shipments for carrier %s", carrier)
+		}
+
+		shipmentIDs := make([]uuid.UUID, 0, len(shipments))
+		for _, shipment := range shipments {
+			shipmentIDs = append(shipmentIDs, shipment.ID)
+		}
+		if err = checkTrackingNumbers(tx, shipmentIDs); err != nil {
+			return err
+		}
+
+		if err = tx.Omit("Shipments").Create(&manifest).Error; err != nil {
+			return fmt.Errorf("failed to create manifest: %w", err)
+		}
+
+		err = tx.Model(&domain.Shipment{}).Where("id IN ?", shipmentIDs).Updates(map[string]interface{}{
+			"manifest_id": manifest.ID,
+			"status":      domain.ShipmentStatusManifested,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update shipments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	return r.GetManifestByID(ctx, manifest.ID)
+}
+
+func (r *repository) GetManifestByID(ctx context.Context, id uuid.UUID) (domain.Manifest, error) {
+	var manifest domain.Manifest
+	err := r.master(ctx).
+		Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Preload("Shipments.Packages").Preload("Shipments.Order").
+		Where("id = ?", id).First(&manifest).Error
+	if err != nil {
+		return domain.Manifest{}, notFound(err, "manifest")
+	}
+	return manifest, nil
+}
+
+func (r *repository) GetManifests(ctx context.Context, hubID uuid.UUID, manifestDate *time.Time) ([]domain.Manifest, error) {
+	query := r.master(ctx).Where("hub_id = ?", hubID)
+	if manifestDate != nil {
+		query = query.Where("manifest_date = ?", *manifestDate)
+	}
+
+	var manifests []domain.Manifest
+	if err := query.Order("created_at").Find(&manifests).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch manifests: %w", err)
+	}
+	return manifests, nil
+}
+
+// DispatchShipment hands a shipment over to its carrier
+func (r *repository) DispatchShipment(ctx context.Context, id uuid.UUID) (domain.Shipment, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var shipment domain.Shipment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&shipment).Error
+		if err != nil {
+			return notFound(err, "shipment")
+		}
+		return dispatchShipment(tx, shipment, time.Now())
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return r.GetShipmentByID(ctx, id)
+}
+
+// DispatchManifest hands every shipment of a manifest that is still waiting over to the carrier
+func (r *repository) DispatchManifest(ctx context.Context, id uuid.UUID) (domain.Manifest, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		var manifest domain.Manifest
+		if err := tx.Where("id = ?", id).First(&manifest).Error; err != nil {
+			return notFound(err, "manifest")
+		}
+
+		var shipments []domain.Shipment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("manifest_id = ? AND status = ?", id, domain.ShipmentStatusManifested).
+			Order("created_at").Find(&shipments).Error
+		if err != nil {
+			return fmt.Errorf("failed to fetch shipments: %w", err)
+		}
+		if len(shipments) == 0 {
+			return domain.Conflict("manifest has no shipments left to dispatch")
+		}
+
+		now := time.Now()
+		for _, shipment := range shipments {
+			if err = dispatchShipment(tx, shipment, now); err != nil {
+				return fmt.Errorf("shipment of order %s: %w", shipment.OrderID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	return r.GetManifestByID(ctx, id)
+}
+
+// dispatchShipment takes the packed units of a shipment out of the allocated stock, ships
+// their serials, marks the order shipped and records the shipped event
+func dispatchShipment(tx *gorm.DB, shipment domain.Shipment, dispatchedAt time.Time) error {
+	if shipment.Status != domain.ShipmentStatusPacked && shipment.Status != domain.ShipmentStatusManifested {
+		return domain.Conflict("shipment is already %s", shipment.Status)
+	}
+	if shipment.Carrier == "" {
+		return domain.Conflict("shipment has no carrier assigned")
+	}
+	if err := checkTrackingNumbers(tx, []uuid.UUID{shipment.ID}); err != nil {
+		return err
+	}
+
+	var order domain.OutboundOrder
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", shipment.OrderID).First(&order).Error
+	if err != nil {
+		return notFound(err, "order")
+	}
+
+	var lines []domain.OutboundOrderLine
+	if err = tx.Where("order_id = ?", order.ID).Order("created_at").Find(&lines).Error; err != nil {
+		return fmt.Errorf("failed to fetch order lines: %w", err)
+	}
+
+	var packages []domain.Package
+	if err = tx.Preload("Items").Where("shipment_id = ?", shipment.ID).Order("created_at").Find(&packages).Error; err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	event := domain.ShippedEvent{
+		ShipmentID:   shipment.ID,
+		OrderID:      order.ID,
+		OrderNo:      order.OrderNo,
+		Carrier:      shipment.Carrier,
+		Service:      shipment.Service,
+		DispatchedAt: dispatchedAt,
+	}
+
+	serials := make(map[uuid.UUID][]string)
+	for _, pkg := range packages {
+		event.Packages = append(event.Packages, domain.ShippedPackage{PackageID: pkg.ID, TrackingNumber: pkg.TrackingNumber})
+		for _, item := range pkg.Items {
+			serials[item.SkuID] = append(serials[item.SkuID], item.Serials...)
+		}
+	}
+
+	for _, line := range lines {
+		qty := line.PackedQty - line.ShippedQty
+		if qty <= 0 {
+			continue
+		}
+		if err = moveStockFEFO(tx, line.SkuID, shipment.HubID, qty, qtyAllocated, ""); err != nil {
+			return err
+		}
+		if err = drainLocationStock(tx, line.SkuID, shipment.HubID, qty, domain.LocationTypeStaging); err != nil {
+			return err
+		}
+		err = tx.Model(&domain.OutboundOrderLine{}).Where("id = ?", line.ID).Update("shipped_qty", line.PackedQty).Error
+		if err != nil {
+			return fmt.Errorf("failed to update order line: %w", err)
+		}
+		event.Lines = append(event.Lines, domain.ShippedLineItem{SkuID: line.SkuID, Qty: qty})
+	}
+
+	for skuID, serialNumbers := range serials {
+		if len(serialNumbers) == 0 {
+			continue
+		}
+		err = moveSerials(tx, skuID, shipment.HubID, serialNumbers,
+			[]string{domain.SerialStatusAvailable, domain.SerialStatusAllocated},
+			domain.SerialStatusRemoved, domain.SerialEventShipped)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Model(&domain.Shipment{}).Where("id = ?", shipment.ID).Updates(map[string]interface{}{
+		"status":        domain.ShipmentStatusDispatched,
+		"dispatched_at": dispatchedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update shipment: %w", err)
+	}
+
+	err = tx.Model(&domain.OutboundOrder{}).Where("id = ?", order.ID).Update("status", domain.OrderStatusShipped).Error
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	return recordEvent(tx, domain.EventShipmentShipped, shipment.HubID, shipment.ID, event)
+}
+
+// checkTrackingNumbers makes sure every package of the shipments has a tracking number
+func checkTrackingNumbers(tx *gorm.DB, shipmentIDs []uuid.UUID) error {
+	var missing int64
+	err := tx.Model(&domain.Package{}).
+		Where("shipment_id IN ? AND (tracking_number IS NULL OR tracking_number = '')", shipmentIDs).
+		Count(&missing).Error
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+	if missing > 0 {
+		return domain.Conflict("%d packages have no tracking number", missing)
+	}
+	return nil
+}
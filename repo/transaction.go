@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key of the transaction a request's writes run in
+type txKey struct{}
+
+// InTransaction runs fn in a transaction, committed when fn returns nil and rolled back
+// otherwise. Repository calls made with the context fn is given run in the transaction;
+// inside one already, fn joins it.
+func (r *repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return r.db.GetMasterDB(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// master returns the transaction of the context, or the master database outside of one
+func (r *repository) master(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return r.db.GetMasterDB(ctx)
+}
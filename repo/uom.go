@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"wms/domain"
+)
+
+type UomRepository interface {
+	CreateUOM(ctx context.Context, uom domain.UOM) (domain.UOM, error)
+	GetUOM(ctx context.Context, code string) (domain.UOM, error)
+	GetUOMs(ctx context.Context) ([]domain.UOM, error)
+	SetSkuPacks(ctx context.Context, skuID uuid.UUID, packs []domain.SkuPack) ([]domain.SkuPack, error)
+	GetSkuPacks(ctx context.Context, skuID uuid.UUID) ([]domain.SkuPack, error)
+}
+
+func (r *repository) CreateUOM(ctx context.Context, uom domain.UOM) (domain.UOM, error) {
+	if err := r.master(ctx).Create(&uom).Error; err != nil {
+		return domain.UOM{}, fmt.Errorf("failed to create UOM: %w", err)
+	}
+	return uom, nil
+}
+
+func (r *repository) GetUOM(ctx context.Context, code string) (domain.UOM, error) {
+	var uom domain.UOM
+	if err := r.master(ctx).Where("code = ?", code).First(&uom).Error; err != nil {
+		return domain.UOM{}, notFound(err, "UOM "+code)
+	}
+	return uom, nil
+}
+
+func (r *repository) GetUOMs(ctx context.Context) ([]domain.UOM, error) {
+	var uoms []domain.UOM
+	if err := r.master(ctx).Order("code").Find(&uoms).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch UOMs: %w", err)
+	}
+	return uoms, nil
+}
+
+// SetSkuPacks replaces the pack hierarchy of a SKU
+func (r *repository) SetSkuPacks(ctx context.Context, skuID uuid.UUID, packs []domain.SkuPack) ([]domain.SkuPack, error) {
+	err := r.master(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sku_id = ?", skuID).Delete(&domain.SkuPack{}).Error; err != nil {
+			return fmt.Errorf("failed to clear SKU packs: %w", err)
+		}
+		if len(packs) == 0 {
+			return nil
+		}
+		for i := range packs {
+			packs[i].SkuID = skuID
+		}
+		if err := tx.Create(&packs).Error; err != nil {
+			return fmt.Errorf("failed to save SKU packs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+// GetSkuPacks fetches the pack hierarchy of a SKU, smallest pack first
+func (r *repository) GetSkuPacks(ctx context.Context, skuID uuid.UUID) ([]domain.SkuPack, error) {
+	var packs []domain.SkuPack
+	if err := r.master(ctx).Where("sku_id = ?", skuID).Order("factor").Find(&packs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch SKU packs: %w", err)
+	}
+	return packs, nil
+}
@@ -3,38 +3,235 @@ package router
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/config"
 	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
 	"wms/controller"
+	"wms/deployment/migration"
+	"wms/domain"
 	"wms/pkg"
+	"wms/pkg/auth"
+	"wms/pkg/health"
 	"wms/repo"
 	"wms/service"
 )
 
 func InternalRoutes(ctx context.Context, s *http.Server) (err error) {
-	rtr := s.Engine.Group("/api/v1")
-
 	// todo go wire
 	newRepository := repo.NewRepository(pkg.GetCluster().DbCluster)
 	newService := service.NewService(newRepository)
 	newController := controller.NewController(newService)
 
+	schemaVersion, err := migration.Version()
+	if err != nil {
+		return err
+	}
+	readiness := map[string]health.Checker{
+		"database":      health.Database(pkg.GetCluster()),
+		"migrations":    health.Migrations(pkg.GetCluster(), schemaVersion),
+		"event_backlog": health.EventBacklog(pkg.GetCluster(), config.GetDuration(ctx, "health.maxEventLag")),
+	}
+
+	authenticators, err := auth.NewAuthenticators(ctx, newService.AuthenticateAPIKey)
+	if err != nil {
+		return err
+	}
+
+	tickets, err := auth.NewTickets(ctx)
+	if err != nil {
+		return err
+	}
+	streams := streamSettings{tickets: tickets, allowedOrigins: config.GetStringSlice(ctx, "stream.allowedOrigins")}
+
+	registerRoutes(s.Engine, newController, authenticators, streams, readiness)
+	// TestRoutesAreDocumented keeps undocumented routes out of builds; one getting through
+	// is logged rather than keeping the server down
+	if err = controller.CheckRoutes(s.Engine.Routes()); err != nil {
+		log.Errorf("OpenAPI document is out of date: %v", err)
+	}
+	return nil
+}
+
+// streamSettings are the tickets opening the inventory streams and the origins browsers
+// may open the WebSocket from
+type streamSettings struct {
+	tickets        *auth.Tickets
+	allowedOrigins []string
+}
+
+// registerRoutes mounts every route of the API on an engine
+func registerRoutes(engine *gin.Engine, newController *controller.Controller, authenticators []auth.Authenticator, streams streamSettings, readiness map[string]health.Checker) {
+	// Docs and probe routes, served without credentials
+	public := engine.Group(controller.BasePath, newController.RequestID())
+	public.GET("/openapi.json", newController.OpenAPI())
+	public.GET("/docs", newController.SwaggerUI())
+	public.GET("/health/live", newController.Live())
+	public.GET("/health/ready", newController.Ready(readiness))
+
+	// The probes again at the root, where orchestrators look for them
+	probes := engine.Group("", newController.RequestID())
+	probes.GET("/health/live", newController.Live())
+	probes.GET("/health/ready", newController.Ready(readiness))
+
+	rtr := engine.Group(controller.BasePath, newController.RequestID(), newController.Authenticate(authenticators...))
+
+	// Stream routes, which also take a stream ticket in the query for clients that cannot
+	// set headers on EventSource and WebSocket requests
+	streamAuthenticators := append([]auth.Authenticator{auth.TicketAuthenticator{Tickets: streams.tickets}}, authenticators...)
+	stream := engine.Group(controller.BasePath, newController.RequestID(), newController.Authenticate(streamAuthenticators...))
+	stream.GET("/inventory/stream", newController.Require(domain.PermInventoryRead), newController.StreamInventory())
+	stream.GET("/inventory/ws", newController.Require(domain.PermInventoryRead), newController.StreamInventoryWebSocket(streams.allowedOrigins))
+
 	rtr.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"msg": "mst"})
 	})
 
 	// Hub routes
-	rtr.GET("/hub", newController.GetHubs())
-	rtr.GET("/hub/:id", newController.GetHubByID())
-	rtr.POST("/hub", newController.CreateHub())
+	rtr.GET("/hub", newController.Require(domain.PermInventoryRead), newController.GetHubs())
+	rtr.GET("/hub/:id", newController.Require(domain.PermInventoryRead), newController.GetHubByID())
+	rtr.POST("/hub", newController.Require(domain.PermHubManage), newController.CreateHub())
+	rtr.GET("/hub/:id/near-expiry", newController.Require(domain.PermInventoryRead), newController.GetNearExpiryLots())
+	rtr.POST("/hub/:id/block-expired", newController.Require(domain.PermInventoryAdjust), newController.BlockExpiredLots())
+	rtr.GET("/hub/:id/location", newController.Require(domain.PermInventoryRead), newController.GetLocations())
+	rtr.POST("/hub/:id/location", newController.Require(domain.PermLocationManage), newController.CreateLocation())
+	rtr.GET("/hub/:id/putaway", newController.Require(domain.PermInventoryRead), newController.GetPutawayTasks())
+	rtr.GET("/hub/:id/order", newController.Require(domain.PermInventoryRead), newController.GetOrders())
+	rtr.GET("/hub/:id/wave", newController.Require(domain.PermInventoryRead), newController.GetWaves())
+	rtr.POST("/hub/:id/wave", newController.Require(domain.PermOrderManage), newController.PlanWaves())
+	rtr.GET("/hub/:id/carton", newController.Require(domain.PermInventoryRead), newController.GetCartons())
+	rtr.POST("/hub/:id/carton", newController.Require(domain.PermLocationManage), newController.CreateCarton())
+	rtr.GET("/hub/:id/shipment", newController.Require(domain.PermInventoryRead), newController.GetShipments())
+	rtr.GET("/hub/:id/manifest", newController.Require(domain.PermInventoryRead), newController.GetManifests())
+	rtr.POST("/hub/:id/manifest", newController.Require(domain.PermOrderManage), newController.CreateManifest())
+	rtr.GET("/hub/:id/return", newController.Require(domain.PermInventoryRead), newController.GetReturns())
+	rtr.GET("/hub/:id/transition", newController.Require(domain.PermInventoryRead), newController.GetStockTransitions())
+	rtr.GET("/hub/:id/purchase-order", newController.Require(domain.PermInventoryRead), newController.GetPurchaseOrders())
+	rtr.GET("/hub/:id/asn", newController.Require(domain.PermInventoryRead), newController.GetASNs())
+	rtr.GET("/hub/:id/qc", newController.Require(domain.PermInventoryRead), newController.GetQcInspections())
+	rtr.GET("/hub/:id/kitting", newController.Require(domain.PermInventoryRead), newController.GetKittingOrders())
+
+	// Location routes
+	rtr.GET("/location/:id", newController.Require(domain.PermInventoryRead), newController.GetLocationByID())
+	rtr.PUT("/location/:id", newController.Require(domain.PermLocationManage), newController.UpdateLocation())
+	rtr.GET("/location/:id/stock", newController.Require(domain.PermInventoryRead), newController.GetLocationStock())
+	rtr.GET("/location/:id/barcode", newController.Require(domain.PermInventoryRead), newController.GetLocationBarcodes())
+
+	// UOM routes
+	rtr.GET("/uom", newController.Require(domain.PermInventoryRead), newController.GetUOMs())
+	rtr.POST("/uom", newController.Require(domain.PermCatalogManage), newController.CreateUOM())
+	rtr.GET("/uom/:code", newController.Require(domain.PermInventoryRead), newController.GetUOM())
 
 	// SKU routes
-	rtr.GET("/sku", newController.GetSkus())
-	rtr.GET("/sku/:id", newController.GetSkuByID())
-	rtr.POST("/sku", newController.CreateSKU())
+	rtr.GET("/sku", newController.Require(domain.PermInventoryRead), newController.GetSkus())
+	rtr.GET("/sku/:id", newController.Require(domain.PermInventoryRead), newController.GetSkuByID())
+	rtr.POST("/sku", newController.Require(domain.PermCatalogManage), newController.CreateSKU())
+	rtr.GET("/sku/:id/pack", newController.Require(domain.PermInventoryRead), newController.GetSkuPacks())
+	rtr.GET("/sku/:id/barcode", newController.Require(domain.PermInventoryRead), newController.GetSkuBarcodes())
+	rtr.PUT("/sku/:id/pack", newController.Require(domain.PermCatalogManage), newController.SetSkuPacks())
+	rtr.GET("/sku/:id/kit", newController.Require(domain.PermInventoryRead), newController.GetKitComponents())
+	rtr.PUT("/sku/:id/kit", newController.Require(domain.PermCatalogManage), newController.SetKitComponents())
+	rtr.GET("/sku/:id/kit/availability", newController.Require(domain.PermInventoryRead), newController.GetKitAvailability())
 
 	// Inventory routes
-	rtr.POST("/inventory", newController.DecreaseInventory())
-	rtr.GET("/inventory", newController.GetInventory())
+	rtr.POST("/inventory", newController.Require(domain.PermInventoryAdjust), newController.DecreaseInventory())
+	rtr.GET("/inventory", newController.Require(domain.PermInventoryRead), newController.GetInventory())
+	rtr.POST("/inventory/stream/ticket", newController.Require(domain.PermInventoryRead), newController.IssueStreamTicket(streams.tickets))
+	rtr.POST("/inventory/receive", newController.Require(domain.PermInboundReceive), newController.ReceiveInventory())
+	rtr.POST("/inventory/allocate", newController.Require(domain.PermInventoryAdjust), newController.AllocateInventory())
+	rtr.GET("/inventory/lots", newController.Require(domain.PermInventoryRead), newController.GetLots())
+	rtr.GET("/inventory/locations", newController.Require(domain.PermInventoryRead), newController.GetSkuLocationStock())
+	rtr.POST("/inventory/move", newController.Require(domain.PermInventoryAdjust), newController.MoveLocationStock())
+	rtr.POST("/inventory/transition", newController.Require(domain.PermInventoryAdjust), newController.CreateStockTransition())
+	rtr.GET("/inventory/transition/:id", newController.Require(domain.PermInventoryRead), newController.GetStockTransitionByID())
+
+	// Putaway routes
+	rtr.POST("/putaway/suggest", newController.Require(domain.PermTaskExecute), newController.SuggestPutaway())
+	rtr.POST("/putaway", newController.Require(domain.PermInboundReceive), newController.CreatePutawayTasks())
+	rtr.POST("/putaway/:id/confirm", newController.Require(domain.PermTaskExecute), newController.ConfirmPutawayTask())
+
+	// Outbound order routes
+	rtr.POST("/order", newController.Require(domain.PermOrderManage), newController.CreateOrder())
+	rtr.GET("/order/:id", newController.Require(domain.PermInventoryRead), newController.GetOrderByID())
+	rtr.GET("/wave/:id", newController.Require(domain.PermInventoryRead), newController.GetWaveByID())
+	rtr.POST("/wave/:id/release", newController.Require(domain.PermOrderManage), newController.ReleaseWave())
+	rtr.GET("/wave/:id/picklist", newController.Require(domain.PermInventoryRead), newController.GetPickList())
+	rtr.POST("/pick/:id/confirm", newController.Require(domain.PermTaskExecute), newController.ConfirmPick())
+
+	// Packing routes
+	rtr.GET("/carton/:id", newController.Require(domain.PermInventoryRead), newController.GetCartonByID())
+	rtr.GET("/order/:id/cartonize", newController.Require(domain.PermTaskExecute), newController.CartonizeOrder())
+	rtr.GET("/order/:id/package", newController.Require(domain.PermInventoryRead), newController.GetPackages())
+	rtr.POST("/order/:id/package", newController.Require(domain.PermTaskExecute), newController.OpenPackage())
+	rtr.POST("/order/:id/pack", newController.Require(domain.PermTaskExecute), newController.CompletePacking())
+	rtr.GET("/package/:id", newController.Require(domain.PermInventoryRead), newController.GetPackageByID())
+	rtr.POST("/package/:id/scan", newController.Require(domain.PermTaskExecute), newController.ScanPackageItem())
+
+	// Shipment routes
+	rtr.GET("/shipment/:id", newController.Require(domain.PermInventoryRead), newController.GetShipmentByID())
+	rtr.PUT("/shipment/:id/carrier", newController.Require(domain.PermOrderManage), newController.AssignCarrier())
+	rtr.PUT("/package/:id/tracking", newController.Require(domain.PermOrderManage), newController.SetTrackingNumber())
+	rtr.POST("/shipment/:id/dispatch", newController.Require(domain.PermOrderManage), newController.DispatchShipment())
+	rtr.GET("/manifest/:id", newController.Require(domain.PermInventoryRead), newController.GetManifestByID())
+	rtr.POST("/manifest/:id/dispatch", newController.Require(domain.PermOrderManage), newController.DispatchManifest())
+
+	// Return routes
+	rtr.POST("/return", newController.Require(domain.PermOrderManage), newController.CreateReturn())
+	rtr.GET("/return/:id", newController.Require(domain.PermInventoryRead), newController.GetReturnByID())
+	rtr.POST("/return/:id/receive", newController.Require(domain.PermInboundReceive), newController.ReceiveReturn())
+	rtr.POST("/return/:id/grade", newController.Require(domain.PermInboundReceive), newController.GradeReturn())
+	rtr.GET("/report/returns", newController.Require(domain.PermInventoryRead), newController.GetReturnReport())
+
+	// Inbound routes
+	rtr.POST("/purchase-order", newController.Require(domain.PermInboundReceive), newController.CreatePurchaseOrder())
+	rtr.GET("/purchase-order/:id", newController.Require(domain.PermInventoryRead), newController.GetPurchaseOrderByID())
+	rtr.POST("/asn", newController.Require(domain.PermInboundReceive), newController.CreateASN())
+	rtr.GET("/asn/:id", newController.Require(domain.PermInventoryRead), newController.GetASNByID())
+	rtr.POST("/asn/:id/receive", newController.Require(domain.PermInboundReceive), newController.ReceiveASN())
+	rtr.POST("/asn/:id/close", newController.Require(domain.PermInboundReceive), newController.CloseASN())
+	rtr.GET("/report/asn-variance", newController.Require(domain.PermInventoryRead), newController.GetAsnVariance())
+
+	// QC routes
+	rtr.POST("/qc-rule", newController.Require(domain.PermCatalogManage), newController.CreateQcRule())
+	rtr.GET("/qc-rule", newController.Require(domain.PermInventoryRead), newController.GetQcRules())
+	rtr.GET("/qc-rule/:id", newController.Require(domain.PermInventoryRead), newController.GetQcRuleByID())
+	rtr.PUT("/qc-rule/:id", newController.Require(domain.PermCatalogManage), newController.UpdateQcRule())
+	rtr.GET("/qc/:id", newController.Require(domain.PermInventoryRead), newController.GetQcInspectionByID())
+	rtr.POST("/qc/:id/result", newController.Require(domain.PermTaskExecute), newController.RecordQcResults())
+
+	// Kitting routes
+	rtr.POST("/kitting", newController.Require(domain.PermOrderManage), newController.CreateKittingOrder())
+	rtr.GET("/kitting/:id", newController.Require(domain.PermInventoryRead), newController.GetKittingOrderByID())
+	rtr.POST("/kitting/:id/complete", newController.Require(domain.PermTaskExecute), newController.CompleteKittingOrder())
+	rtr.POST("/kitting/:id/cancel", newController.Require(domain.PermOrderManage), newController.CancelKittingOrder())
+
+	// Barcode routes
+	rtr.POST("/barcode", newController.Require(domain.PermCatalogManage, domain.PermLocationManage), newController.CreateBarcode())
+	rtr.GET("/barcode/:id", newController.Require(domain.PermInventoryRead), newController.GetBarcodeByID())
+	rtr.DELETE("/barcode/:id", newController.Require(domain.PermCatalogManage, domain.PermLocationManage), newController.DeleteBarcode())
+	rtr.GET("/scan/:code", newController.Require(domain.PermInventoryRead), newController.ResolveScan())
+
+	// Label routes
+	rtr.PUT("/tenant/:id/gs1", newController.Require(domain.PermAccessManage), newController.SetGS1CompanyPrefix())
+	rtr.GET("/sku/:id/label", newController.Require(domain.PermTaskExecute), newController.GetSkuLabel())
+	rtr.GET("/location/:id/label", newController.Require(domain.PermTaskExecute), newController.GetLocationLabel())
+	rtr.GET("/package/:id/label", newController.Require(domain.PermTaskExecute), newController.GetPackageLabel())
+
+	// Auth routes
+	rtr.GET("/whoami", newController.WhoAmI())
+	rtr.POST("/tenant/:id/api-key", newController.Require(domain.PermAccessManage), newController.CreateAPIKey())
+	rtr.GET("/tenant/:id/api-key", newController.Require(domain.PermAccessManage), newController.GetAPIKeys())
+	rtr.GET("/api-key/:id", newController.Require(domain.PermAccessManage), newController.GetAPIKeyByID())
+	rtr.DELETE("/api-key/:id", newController.Require(domain.PermAccessManage), newController.RevokeAPIKey())
+	rtr.POST("/tenant/:id/role", newController.Require(domain.PermAccessManage), newController.AssignRole())
+	rtr.GET("/tenant/:id/role", newController.Require(domain.PermAccessManage), newController.GetRoleAssignments())
+	rtr.GET("/role/:id", newController.Require(domain.PermAccessManage), newController.GetRoleAssignmentByID())
+	rtr.DELETE("/role/:id", newController.Require(domain.PermAccessManage), newController.RemoveRoleAssignment())
+
+	// Audit routes
+	rtr.GET("/audit", newController.Require(domain.PermAccessManage), newController.GetAuditEntries())
+	rtr.GET("/audit/export", newController.Require(domain.PermAccessManage), newController.ExportAuditEntries())
+	rtr.GET("/audit/verify", newController.Require(domain.PermAccessManage), newController.VerifyAuditLog())
 
-	return
+	// Serial routes
+	rtr.GET("/serial/:serial", newController.Require(domain.PermInventoryRead), newController.GetSerial())
 }
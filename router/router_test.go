@@ -0,0 +1,80 @@
+package router
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"wms/controller"
+)
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// TestRoutesAreDocumented checks that every route of the API is in the OpenAPI document it
+// serves, with its method, and that the document lists no route that is not registered
+func TestRoutesAreDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registerRoutes(engine, controller.NewController(nil), nil, streamSettings{}, nil)
+
+	if err := controller.CheckRoutes(engine.Routes()); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, controller.BasePath+"/openapi.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d, body %s", recorder.Code, recorder.Body)
+	}
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &document); err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+
+	registered := map[string]bool{}
+	for _, route := range engine.Routes() {
+		if !strings.HasPrefix(route.Path, controller.BasePath) {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, controller.BasePath)
+		if path == "" {
+			path = "/"
+		}
+		path = pathParam.ReplaceAllString(path, "{$1}")
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+
+		if _, ok := document.Paths[path][method]; !ok {
+			t.Errorf("%s %s is not in the OpenAPI document", route.Method, path)
+		}
+	}
+
+	for path, operations := range document.Paths {
+		for method := range operations {
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is documented but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// TestProbesAreServedAtRoot checks that the health probes answer without credentials both
+// under the base path and at the root, where orchestrators probe by default
+func TestProbesAreServedAtRoot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registerRoutes(engine, controller.NewController(nil), nil, streamSettings{}, nil)
+
+	for _, path := range []string{"/health/live", "/health/ready", controller.BasePath + "/health/live", controller.BasePath + "/health/ready"} {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d, body %s", path, recorder.Code, recorder.Body)
+		}
+	}
+}
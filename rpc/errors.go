@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/omniful/go_commons/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"wms/domain"
+	"wms/pkg"
+)
+
+// errorDomain is the domain of the ErrorInfo details of errors
+const errorDomain = "wms"
+
+// statusCodes maps the error codes of the domain to the gRPC status codes they answer with
+var statusCodes = map[string]codes.Code{
+	domain.CodeBadRequest:        codes.InvalidArgument,
+	domain.CodeUnauthenticated:   codes.Unauthenticated,
+	domain.CodeNotFound:          codes.NotFound,
+	domain.CodeConflict:          codes.FailedPrecondition,
+	domain.CodeInsufficientStock: codes.FailedPrecondition,
+	domain.CodeValidation:        codes.InvalidArgument,
+	domain.CodeForbidden:         codes.PermissionDenied,
+	domain.CodeUnavailable:       codes.Unavailable,
+	domain.CodeInternal:          codes.Internal,
+}
+
+// statusError turns an error of the service layer into the status a call fails with, records
+// that already exist failing with AlreadyExists. The error code rides along as the reason
+// of an ErrorInfo, field violations as a BadRequest.
+func statusError(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	e := pkg.ClassifyError(err)
+	if e.Code == domain.CodeInternal {
+		log.Errorf("%s: %v", method, err)
+		e.Message = "Internal server error"
+	}
+
+	code := statusCodes[e.Code]
+	if errors.Is(err, domain.ErrDuplicate) || pkg.IsViolatesUniqueConstraint(err) {
+		code = codes.AlreadyExists
+	}
+	st := status.New(code, e.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: e.Code, Domain: errorDomain}}
+	if len(e.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range e.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if detailed, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// parseID reads a UUID field of a request, failing with the violation of the field
+func parseID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, domain.InvalidFields(domain.FieldError{Field: field, Message: "is not a valid UUID"})
+	}
+	return id, nil
+}
@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"wms/domain"
+)
+
+// TestStatusErrorCodes checks the status codes errors of the service layer fail calls with
+func TestStatusErrorCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{domain.Duplicate("hub %s already exists", "BLR-1"), codes.AlreadyExists},
+		{fmt.Errorf("create hub: %w", domain.Duplicate("hub %s already exists", "BLR-1")), codes.AlreadyExists},
+		{domain.Conflict("wave is already released"), codes.FailedPrecondition},
+		{domain.InsufficientStock("not enough available stock"), codes.FailedPrecondition},
+		{domain.NotFound("hub not found"), codes.NotFound},
+		{domain.Invalid("invalid hub ID"), codes.InvalidArgument},
+	}
+	for _, test := range tests {
+		if code := status.Code(statusError("/test", test.err)); code != test.code {
+			t.Errorf("%q: status %s, want %s", test.err, code, test.code)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"wms/domain"
+	"wms/pkg/pb"
+)
+
+func (s *Server) ListHubs(ctx context.Context, _ *pb.ListHubsRequest) (*pb.ListHubsResponse, error) {
+	hubs, err := s.service.FetchHubs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListHubsResponse{Hubs: make([]*pb.Hub, len(hubs))}
+	for i, hub := range hubs {
+		response.Hubs[i] = newHub(hub)
+	}
+	return response, nil
+}
+
+func (s *Server) GetHub(ctx context.Context, request *pb.GetHubRequest) (*pb.Hub, error) {
+	id, err := parseID("id", request.GetId())
+	if err != nil {
+		return nil, err
+	}
+	hub, err := s.service.FetchHubByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newHub(hub), nil
+}
+
+func (s *Server) CreateHub(ctx context.Context, request *pb.CreateHubRequest) (*pb.Hub, error) {
+	tenantID, err := parseID("tenant_id", request.GetTenantId())
+	if err != nil {
+		return nil, err
+	}
+	hub, err := s.service.CreateHub(ctx, domain.Hub{
+		TenantID: tenantID,
+		Name:     request.GetName(),
+		Code:     request.GetCode(),
+		Address:  request.GetAddress(),
+		City:     request.City,
+		State:    request.State,
+		Country:  request.Country,
+		Pincode:  request.Pincode,
+		Location: request.Location,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newHub(hub), nil
+}
+
+func (s *Server) ListSkus(ctx context.Context, _ *pb.ListSkusRequest) (*pb.ListSkusResponse, error) {
+	skus, err := s.service.FetchSkus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListSkusResponse{Skus: make([]*pb.Sku, len(skus))}
+	for i, sku := range skus {
+		response.Skus[i] = newSku(sku)
+	}
+	return response, nil
+}
+
+func (s *Server) GetSku(ctx context.Context, request *pb.GetSkuRequest) (*pb.Sku, error) {
+	id, err := parseID("id", request.GetId())
+	if err != nil {
+		return nil, err
+	}
+	sku, err := s.service.FetchSkuByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newSku(sku), nil
+}
+
+func (s *Server) CreateSku(ctx context.Context, request *pb.CreateSkuRequest) (*pb.Sku, error) {
+	sellerID, err := parseID("seller_id", request.GetSellerId())
+	if err != nil {
+		return nil, err
+	}
+	sku := domain.SKU{
+		SellerID:    sellerID,
+		Name:        request.GetName(),
+		Code:        request.GetCode(),
+		Description: request.GetDescription(),
+		Category:    request.GetCategory(),
+		Subcategory: request.GetSubcategory(),
+		Brand:       request.GetBrand(),
+		Model:       request.GetModel(),
+		UOM:         request.GetUom(),
+		Weight:      request.GetWeight(),
+		Serialized:  request.GetSerialized(),
+	}
+	if dimensions := request.GetDimensions(); dimensions != nil {
+		sku.Dimensions, _ = json.Marshal(domain.Dimensions{
+			Length: dimensions.GetLength(),
+			Width:  dimensions.GetWidth(),
+			Height: dimensions.GetHeight(),
+		})
+	}
+	sku, err = s.service.CreateSKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	return newSku(sku), nil
+}
+
+func (s *Server) GetInventory(ctx context.Context, request *pb.GetInventoryRequest) (*pb.Inventory, error) {
+	skuID, hubID, err := parseInventoryIDs(request.GetSkuId(), request.GetHubId())
+	if err != nil {
+		return nil, err
+	}
+	inventory, err := s.service.FetchInventory(ctx, skuID, hubID)
+	if err != nil {
+		return nil, err
+	}
+	return newInventory(inventory), nil
+}
+
+func (s *Server) AllocateInventory(ctx context.Context, request *pb.AllocateInventoryRequest) (*pb.Inventory, error) {
+	skuID, hubID, qty, err := s.inventoryChange(ctx, request.GetSkuId(), request.GetHubId(), request.GetUom(), request.GetQty())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.service.AllocateInventory(ctx, skuID, hubID, qty, request.GetSerials()); err != nil {
+		return nil, err
+	}
+	return s.GetInventory(ctx, &pb.GetInventoryRequest{SkuId: request.GetSkuId(), HubId: request.GetHubId()})
+}
+
+func (s *Server) DecreaseInventory(ctx context.Context, request *pb.DecreaseInventoryRequest) (*pb.Inventory, error) {
+	skuID, hubID, qty, err := s.inventoryChange(ctx, request.GetSkuId(), request.GetHubId(), request.GetUom(), request.GetQty())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.service.DecreaseInventoryQty(ctx, skuID, hubID, qty, request.GetSerials()); err != nil {
+		return nil, err
+	}
+	return s.GetInventory(ctx, &pb.GetInventoryRequest{SkuId: request.GetSkuId(), HubId: request.GetHubId()})
+}
+
+func (s *Server) WatchInventory(request *pb.WatchInventoryRequest, stream pb.InventoryService_WatchInventoryServer) error {
+	filter := domain.InventoryEventFilter{AfterID: request.GetAfterEventId()}
+	if request.GetHubId() != "" {
+		hubID, err := parseID("hub_id", request.GetHubId())
+		if err != nil {
+			return err
+		}
+		filter.HubID = &hubID
+	}
+	for _, value := range request.GetSkuIds() {
+		skuID, err := parseID("sku_ids", value)
+		if err != nil {
+			return err
+		}
+		filter.SkuIDs = append(filter.SkuIDs, skuID)
+	}
+
+	events, errs, err := s.service.WatchInventory(stream.Context(), filter)
+	if err != nil {
+		return err
+	}
+	// Send blocks while the client's flow control window is full, so a slow client only
+	// holds back its own watch
+	for event := range events {
+		if err = stream.Send(newInventoryEvent(event)); err != nil {
+			return err
+		}
+	}
+	return <-errs
+}
+
+// inventoryChange reads the SKU, hub and quantity of a change of inventory, converting the
+// quantity to the SKU's base UOM
+func (s *Server) inventoryChange(ctx context.Context, sku, hub, uom string, qty int32) (uuid.UUID, uuid.UUID, int, error) {
+	skuID, hubID, err := parseInventoryIDs(sku, hub)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, 0, err
+	}
+	if qty <= 0 {
+		return uuid.Nil, uuid.Nil, 0, domain.InvalidFields(domain.FieldError{Field: "qty", Message: "must be greater than 0"})
+	}
+	baseQty := int(qty)
+	if uom != "" {
+		if baseQty, err = s.service.ToBaseQty(ctx, skuID, uom, baseQty); err != nil {
+			return uuid.Nil, uuid.Nil, 0, err
+		}
+	}
+	return skuID, hubID, baseQty, nil
+}
+
+func parseInventoryIDs(sku, hub string) (uuid.UUID, uuid.UUID, error) {
+	skuID, err := parseID("sku_id", sku)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	hubID, err := parseID("hub_id", hub)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return skuID, hubID, nil
+}
+
+func newHub(hub domain.Hub) *pb.Hub {
+	return &pb.Hub{
+		Id:        hub.ID.String(),
+		TenantId:  hub.TenantID.String(),
+		Name:      hub.Name,
+		Code:      hub.Code,
+		Address:   hub.Address,
+		City:      hub.City,
+		State:     hub.State,
+		Country:   hub.Country,
+		Pincode:   hub.Pincode,
+		Location:  hub.Location,
+		CreatedAt: timestamppb.New(hub.CreatedAt),
+		UpdatedAt: timestamppb.New(hub.UpdatedAt),
+	}
+}
+
+func newSku(sku domain.SKU) *pb.Sku {
+	response := &pb.Sku{
+		Id:          sku.ID.String(),
+		SellerId:    sku.SellerID.String(),
+		Name:        sku.Name,
+		Code:        sku.Code,
+		Description: sku.Description,
+		Category:    sku.Category,
+		Subcategory: sku.Subcategory,
+		Brand:       sku.Brand,
+		Model:       sku.Model,
+		Uom:         sku.UOM,
+		Weight:      sku.Weight,
+		Serialized:  sku.Serialized,
+		CreatedAt:   timestamppb.New(sku.CreatedAt),
+		UpdatedAt:   timestamppb.New(sku.UpdatedAt),
+	}
+	if dimensions, err := sku.ParseDimensions(); err == nil && !dimensions.IsZero() {
+		response.Dimensions = &pb.Dimensions{Length: dimensions.Length, Width: dimensions.Width, Height: dimensions.Height}
+	}
+	return response
+}
+
+func newInventory(inventory domain.Inventory) *pb.Inventory {
+	return &pb.Inventory{
+		Id:            inventory.ID.String(),
+		SkuId:         inventory.SkuID.String(),
+		HubId:         inventory.HubID.String(),
+		AvailableQty:  int32(inventory.AvailableQty),
+		AllocatedQty:  int32(inventory.AllocatedQty),
+		DamagedQty:    int32(inventory.DamagedQty),
+		QuarantineQty: int32(inventory.QuarantineQty),
+		UpdatedAt:     timestamppb.New(inventory.UpdatedAt),
+	}
+}
+
+func newInventoryEvent(event domain.InventoryEvent) *pb.InventoryEvent {
+	return &pb.InventoryEvent{
+		Id:              event.ID,
+		SkuId:           event.SkuID.String(),
+		HubId:           event.HubID.String(),
+		AvailableQty:    int32(event.AvailableQty),
+		AllocatedQty:    int32(event.AllocatedQty),
+		DamagedQty:      int32(event.DamagedQty),
+		QuarantineQty:   int32(event.QuarantineQty),
+		AvailableDelta:  int32(event.AvailableDelta),
+		AllocatedDelta:  int32(event.AllocatedDelta),
+		DamagedDelta:    int32(event.DamagedDelta),
+		QuarantineDelta: int32(event.QuarantineDelta),
+		CreatedAt:       timestamppb.New(event.CreatedAt),
+	}
+}
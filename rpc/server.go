@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/omniful/go_commons/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"wms/domain"
+	"wms/pkg/auth"
+	"wms/pkg/pb"
+	"wms/service"
+)
+
+const requestIDMetadata = "x-request-id"
+
+// permissions are what each method needs, as Require gives the HTTP routes theirs. Methods
+// missing from it are refused.
+var permissions = map[string]string{
+	pb.InventoryService_ListHubs_FullMethodName:          domain.PermInventoryRead,
+	pb.InventoryService_GetHub_FullMethodName:            domain.PermInventoryRead,
+	pb.InventoryService_CreateHub_FullMethodName:         domain.PermHubManage,
+	pb.InventoryService_ListSkus_FullMethodName:          domain.PermInventoryRead,
+	pb.InventoryService_GetSku_FullMethodName:            domain.PermInventoryRead,
+	pb.InventoryService_CreateSku_FullMethodName:         domain.PermCatalogManage,
+	pb.InventoryService_GetInventory_FullMethodName:      domain.PermInventoryRead,
+	pb.InventoryService_AllocateInventory_FullMethodName: domain.PermInventoryAdjust,
+	pb.InventoryService_DecreaseInventory_FullMethodName: domain.PermInventoryAdjust,
+	pb.InventoryService_WatchInventory_FullMethodName:    domain.PermInventoryRead,
+}
+
+// Server is the gRPC API, a transport over the same service layer as the HTTP one
+type Server struct {
+	pb.UnimplementedInventoryServiceServer
+	service        service.Service
+	authenticators []auth.Authenticator
+}
+
+// NewServer creates a gRPC server serving the API, authenticating every call with the
+// first authenticator finding credentials of its kind in the call's metadata
+func NewServer(s service.Service, authenticators []auth.Authenticator) *grpc.Server {
+	server := &Server{service: s, authenticators: authenticators}
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.unaryInterceptor),
+		grpc.ChainStreamInterceptor(server.streamInterceptor),
+	)
+	pb.RegisterInventoryServiceServer(grpcServer, server)
+	return grpcServer
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, statusError(info.FullMethod, err)
+	}
+	return resp, nil
+}
+
+func (s *Server) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if err = handler(srv, contextStream{ServerStream: stream, ctx: ctx}); err != nil {
+		return statusError(info.FullMethod, err)
+	}
+	return nil
+}
+
+// authenticate resolves the principal of a call from its metadata and checks it holds the
+// permission of the method, returning the context the handler runs with. The call is
+// tagged with the client's x-request-id, or a new one, which is sent back in the header.
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := first(md, requestIDMetadata)
+	if requestID == "" || len(requestID) > 128 {
+		requestID = uuid.NewString()
+	}
+	ctx = domain.WithRequestID(ctx, requestID)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+
+	// The authenticators read credentials off HTTP headers, which metadata mirrors
+	r := &http.Request{Header: http.Header{}}
+	for _, key := range []string{"Authorization", auth.APIKeyHeader} {
+		if value := first(md, key); value != "" {
+			r.Header.Set(key, value)
+		}
+	}
+
+	for _, authenticator := range s.authenticators {
+		principal, err := authenticator.Authenticate(ctx, r)
+		if errors.Is(err, auth.ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			log.Infof("%s: rejected credentials: %v", method, err)
+			return nil, status.Error(codes.Unauthenticated, "Invalid credentials")
+		}
+
+		principal, err = s.service.ResolveGrants(ctx, principal)
+		if err != nil {
+			return nil, statusError(method, err)
+		}
+		permission, ok := permissions[method]
+		if !ok || !principal.CanAnywhere(permission) {
+			return nil, status.Error(codes.PermissionDenied, "Missing permission "+permission)
+		}
+		return domain.WithPrincipal(ctx, principal), nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "Authentication required")
+}
+
+// first returns the first value of a metadata key, keys being case-insensitive
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream is a server stream running its handler with another context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gorm.io/datatypes"
+	"strconv"
+	"time"
+	"wms/domain"
+)
+
+type AuditService interface {
+	FetchAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	ExportAuditEntries(ctx context.Context, filter domain.AuditFilter, format string) ([]byte, error)
+	VerifyAuditLog(ctx context.Context) (domain.AuditVerification, error)
+}
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+var auditHeader = []string{"seq", "created_at", "actor", "action", "entity", "entity_id", "request_id", "diff", "prev_hash", "hash"}
+
+// FetchAuditEntries pages through the audit log of the principal's tenant, AfterSeq being
+// the seq of the last entry of the previous page
+func (s *service) FetchAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if err := s.authorizeAuditLog(ctx, &filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	filter.Limit = min(filter.Limit, maxAuditLimit)
+	return s.repo.GetAuditEntries(ctx, filter)
+}
+
+// ExportAuditEntries renders the whole audit log of the principal's tenant matching a
+// filter as CSV, or as JSON lines with the full before and after of every entry
+func (s *service) ExportAuditEntries(ctx context.Context, filter domain.AuditFilter, format string) ([]byte, error) {
+	if format != "csv" && format != "jsonl" {
+		return nil, domain.Invalid("invalid export format %q, expected csv or jsonl", format)
+	}
+	if err := s.authorizeAuditLog(ctx, &filter); err != nil {
+		return nil, err
+	}
+	filter.Limit = 0
+	entries, err := s.repo.GetAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if format == "jsonl" {
+		encoder := json.NewEncoder(&buf)
+		for _, entry := range entries {
+			if err = encoder.Encode(entry); err != nil {
+				return nil, fmt.Errorf("failed to export audit log: %w", err)
+			}
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := csv.NewWriter(&buf)
+	_ = w.Write(auditHeader)
+	for _, entry := range entries {
+		_ = w.Write([]string{
+			strconv.FormatInt(entry.Seq, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Actor,
+			entry.Action,
+			entry.Entity,
+			entry.EntityID,
+			entry.RequestID,
+			string(entry.Diff),
+			entry.PrevHash,
+			entry.Hash,
+		})
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyAuditLog checks the hash chain of the audit log of the principal's tenant
+func (s *service) VerifyAuditLog(ctx context.Context) (domain.AuditVerification, error) {
+	var filter domain.AuditFilter
+	if err := s.authorizeAuditLog(ctx, &filter); err != nil {
+		return domain.AuditVerification{}, err
+	}
+	return s.repo.VerifyAuditChain(ctx, &filter.TenantID)
+}
+
+// authorizeAuditLog limits a filter to the tenant of an admin of it
+func (s *service) authorizeAuditLog(ctx context.Context, filter *domain.AuditFilter) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if err = s.authorizeTenant(ctx, domain.PermAccessManage, p.TenantID); err != nil {
+		return err
+	}
+	filter.TenantID = p.TenantID
+	return nil
+}
+
+// audit records a write in the audit log. It is called in the transaction of the write,
+// so the write is rolled back when it cannot be recorded.
+func (s *service) audit(ctx context.Context, action, entity, entityID string, before, after interface{}) error {
+	entry := domain.AuditEntry{
+		Actor:     "system",
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		RequestID: domain.RequestIDFrom(ctx),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if p, ok := domain.PrincipalFrom(ctx); ok {
+		entry.Actor = p.Subject
+		entry.TenantID = &p.TenantID
+	}
+	entry.Before, entry.After = auditJSON(before), auditJSON(after)
+	entry.Diff = auditDiff(entry.Before, entry.After)
+
+	if _, err := s.repo.AppendAuditEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to audit %s of %s %s: %w", action, entity, entityID, err)
+	}
+	return nil
+}
+
+func auditJSON(v interface{}) datatypes.JSON {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// auditDiff lists the top level fields that differ between two JSON objects, with their
+// value before and after. Anything but two objects is left without a diff.
+func auditDiff(before, after datatypes.JSON) datatypes.JSON {
+	var b, a map[string]json.RawMessage
+	if before != nil && json.Unmarshal(before, &b) != nil {
+		return nil
+	}
+	if after != nil && json.Unmarshal(after, &a) != nil {
+		return nil
+	}
+	if b == nil && a == nil {
+		return nil
+	}
+
+	type change struct {
+		Before json.RawMessage `json:"before,omitempty"`
+		After  json.RawMessage `json:"after,omitempty"`
+	}
+	diff := map[string]change{}
+	for field, value := range b {
+		if !bytes.Equal(value, a[field]) {
+			diff[field] = change{Before: value, After: a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok {
+			diff[field] = change{After: value}
+		}
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	return auditJSON(diff)
+}
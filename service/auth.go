@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type AuthService interface {
+	CreateAPIKey(ctx context.Context, tenantID uuid.UUID, name string, grant domain.Grant) (domain.NewAPIKey, error)
+	FetchAPIKeyByID(ctx context.Context, id uuid.UUID) (domain.APIKey, error)
+	FetchAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error)
+}
+
+// CreateAPIKey generates a key for a tenant holding the role of a grant. The plain key is
+// only returned here; the key is stored as its hash.
+func (s *service) CreateAPIKey(ctx context.Context, tenantID uuid.UUID, name string, grant domain.Grant) (domain.NewAPIKey, error) {
+	if err := s.authorizeTenant(ctx, domain.PermAccessManage, tenantID); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.NewAPIKey{}, domain.Invalid("API key name cannot be empty")
+	}
+	if grant.Role == "" {
+		grant.Role = domain.RoleIntegration
+	}
+	if err := s.checkGrant(ctx, tenantID, grant.Role, grant.HubID); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return domain.NewAPIKey{}, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plain := domain.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	var key domain.APIKey
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		key, err = s.repo.CreateAPIKey(ctx, domain.APIKey{
+			TenantID: tenantID,
+			Name:     name,
+			Prefix:   plain[:12],
+			KeyHash:  hashAPIKey(plain),
+		}, grant)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityAPIKey, key.ID.String(), nil, key)
+	})
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	return domain.NewAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (s *service) FetchAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]domain.APIKey, error) {
+	if err := s.authorizeTenant(ctx, domain.PermAccessManage, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAPIKeys(ctx, tenantID)
+}
+
+func (s *service) FetchAPIKeyByID(ctx context.Context, id uuid.UUID) (domain.APIKey, error) {
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if err = s.authorizeTenant(ctx, domain.PermAccessManage, key.TenantID); err != nil {
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	key, err := s.repo.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = s.authorizeTenant(ctx, domain.PermAccessManage, key.TenantID); err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+		revoked, err := s.repo.GetAPIKeyByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, "revoke", domain.EntityAPIKey, id.String(), key, revoked)
+	})
+}
+
+// AuthenticateAPIKey resolves an API key to the tenant it was issued to
+func (s *service) AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error) {
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil || apiKey.RevokedAt != nil {
+		return domain.Principal{}, errors.New("invalid API key")
+	}
+	if err = s.repo.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		return domain.Principal{}, err
+	}
+	return domain.Principal{
+		Subject:  domain.APIKeySubject(apiKey.ID),
+		TenantID: apiKey.TenantID,
+		Method:   domain.AuthMethodAPIKey,
+	}, nil
+}
+
+// hashAPIKey hashes a key for storage. API keys are long random strings, so a fast hash
+// is as safe as a slow one and keeps authentication to one indexed lookup.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type BarcodeService interface {
+	CreateBarcode(ctx context.Context, barcode domain.Barcode) (domain.Barcode, error)
+	FetchBarcodeByID(ctx context.Context, id uuid.UUID) (domain.Barcode, error)
+	FetchBarcodes(ctx context.Context, skuID, locationID *uuid.UUID) ([]domain.Barcode, error)
+	DeleteBarcode(ctx context.Context, id uuid.UUID) error
+	ResolveScan(ctx context.Context, code string, hubID *uuid.UUID) (domain.ScanResult, error)
+}
+
+// CreateBarcode validates a barcode for a SKU, one of its pack levels or a location.
+// GS1 barcodes must carry a valid check digit.
+func (s *service) CreateBarcode(ctx context.Context, barcode domain.Barcode) (domain.Barcode, error) {
+	barcode.Code = strings.TrimSpace(barcode.Code)
+	barcode.Type = strings.ToLower(strings.TrimSpace(barcode.Type))
+	barcode.UOM = strings.ToUpper(strings.TrimSpace(barcode.UOM))
+	if !domain.IsBarcodeType(barcode.Type) {
+		return domain.Barcode{}, domain.Invalid("invalid barcode type %q", barcode.Type)
+	}
+	if !domain.ValidBarcode(barcode.Type, barcode.Code) {
+		return domain.Barcode{}, domain.Invalid("%q is not a valid %s barcode", barcode.Code, barcode.Type)
+	}
+	if (barcode.SkuID == nil) == (barcode.LocationID == nil) {
+		return domain.Barcode{}, domain.Invalid("a barcode needs either a SKU ID or a location ID")
+	}
+
+	if barcode.LocationID != nil {
+		if barcode.UOM != "" {
+			return domain.Barcode{}, domain.Invalid("a location barcode has no UOM")
+		}
+		if err := s.authorize(ctx, domain.PermLocationManage, domain.EntityLocation, *barcode.LocationID); err != nil {
+			return domain.Barcode{}, err
+		}
+		if _, err := s.repo.GetLocationByID(ctx, *barcode.LocationID); err != nil {
+			return domain.Barcode{}, err
+		}
+	} else {
+		if err := s.authorize(ctx, domain.PermCatalogManage, domain.EntitySku, *barcode.SkuID); err != nil {
+			return domain.Barcode{}, err
+		}
+		sku, err := s.repo.GetSkuByID(ctx, *barcode.SkuID)
+		if err != nil {
+			return domain.Barcode{}, err
+		}
+		if barcode.UOM == sku.UOM {
+			barcode.UOM = ""
+		}
+		if _, err = s.packFactor(ctx, sku.ID, barcode.UOM); err != nil {
+			return domain.Barcode{}, err
+		}
+	}
+
+	barcode.ID = uuid.Nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if barcode, err = s.repo.CreateBarcode(ctx, barcode); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityBarcode, barcode.ID.String(), nil, barcode)
+	})
+	if err != nil {
+		return domain.Barcode{}, err
+	}
+	return barcode, nil
+}
+
+func (s *service) FetchBarcodeByID(ctx context.Context, id uuid.UUID) (domain.Barcode, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityBarcode, id); err != nil {
+		return domain.Barcode{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Barcode{}, domain.Invalid("invalid barcode ID")
+	}
+	return s.repo.GetBarcodeByID(ctx, id)
+}
+
+func (s *service) FetchBarcodes(ctx context.Context, skuID, locationID *uuid.UUID) ([]domain.Barcode, error) {
+	var err error
+	switch {
+	case skuID != nil:
+		err = s.authorize(ctx, domain.PermInventoryRead, domain.EntitySku, *skuID)
+	case locationID != nil:
+		err = s.authorize(ctx, domain.PermInventoryRead, domain.EntityLocation, *locationID)
+	default:
+		err = s.authorizeHubs(ctx, domain.PermInventoryRead, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetBarcodes(ctx, skuID, locationID)
+}
+
+// DeleteBarcode needs the permission to manage locations for a location barcode and to
+// manage the catalogue for a SKU barcode
+func (s *service) DeleteBarcode(ctx context.Context, id uuid.UUID) error {
+	_, hubID, err := s.repo.GetOwner(ctx, domain.EntityBarcode, id)
+	if err != nil {
+		return err
+	}
+	permission := domain.PermCatalogManage
+	if hubID != nil {
+		permission = domain.PermLocationManage
+	}
+	if err = s.authorize(ctx, permission, domain.EntityBarcode, id); err != nil {
+		return err
+	}
+	barcode, err := s.repo.GetBarcodeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteBarcode(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "delete", domain.EntityBarcode, id.String(), barcode, nil)
+	})
+}
+
+// ResolveScan resolves a scanned value, giving the base units a scanned pack holds
+func (s *service) ResolveScan(ctx context.Context, code string, hubID *uuid.UUID) (domain.ScanResult, error) {
+	if err := s.authorizeHubs(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return domain.ScanResult{}, err
+	}
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return domain.ScanResult{}, domain.Invalid("scanned code cannot be empty")
+	}
+
+	result, err := s.repo.ResolveScan(ctx, code, hubID)
+	if err != nil {
+		return domain.ScanResult{}, err
+	}
+	if result.Sku != nil && (result.Kind == domain.ScanKindSku || result.Kind == domain.ScanKindPack) {
+		if result.UOM == "" {
+			result.UOM = result.Sku.UOM
+		}
+		if result.Factor, err = s.packFactor(ctx, result.Sku.ID, result.UOM); err != nil {
+			return domain.ScanResult{}, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"math"
+	"strings"
+	"wms/domain"
+)
+
+type DamageService interface {
+	CreateStockTransition(ctx context.Context, transition domain.StockTransition) (domain.StockTransition, error)
+	FetchStockTransitionByID(ctx context.Context, id uuid.UUID) (domain.StockTransition, error)
+	FetchStockTransitions(ctx context.Context, hubID uuid.UUID, transitionType string, skuID *uuid.UUID) ([]domain.StockTransition, error)
+}
+
+// CreateStockTransition validates a move into, between or out of the non-sellable buckets.
+// Write-offs need a document and their cost, returns to vendor a document and go back to the
+// seller of the SKU.
+func (s *service) CreateStockTransition(ctx context.Context, transition domain.StockTransition) (domain.StockTransition, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryAdjust, transition.HubID); err != nil {
+		return domain.StockTransition{}, err
+	}
+	transition.DocumentNo = strings.TrimSpace(transition.DocumentNo)
+	if transition.SkuID == uuid.Nil || transition.HubID == uuid.Nil {
+		return domain.StockTransition{}, domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	if _, ok := domain.TransitionRules[transition.Type]; !ok {
+		return domain.StockTransition{}, domain.Invalid("invalid transition type %q", transition.Type)
+	}
+	if !domain.TransitionReason(transition.Type, transition.ReasonCode) {
+		return domain.StockTransition{}, domain.Invalid("invalid reason code %q for %s, expected one of %s",
+			transition.ReasonCode, transition.Type, strings.Join(domain.TransitionRules[transition.Type].Reasons, ", "))
+	}
+	if transition.Qty <= 0 {
+		return domain.StockTransition{}, domain.Invalid("quantities must be positive")
+	}
+	if transition.UnitCost < 0 {
+		return domain.StockTransition{}, domain.Invalid("unit cost cannot be negative")
+	}
+	if err := s.checkSerials(ctx, transition.SkuID, transition.Qty, transition.Serials); err != nil {
+		return domain.StockTransition{}, err
+	}
+
+	transition.SellerID = nil
+	transition.TotalCost = 0
+	switch transition.Type {
+	case domain.TransitionWriteOff:
+		if transition.DocumentNo == "" {
+			return domain.StockTransition{}, domain.Invalid("a write-off needs a document number")
+		}
+		transition.TotalCost = math.Round(transition.UnitCost*float64(transition.Qty)*100) / 100
+	case domain.TransitionReturnToVendor:
+		if transition.DocumentNo == "" {
+			return domain.StockTransition{}, domain.Invalid("a return to vendor needs a document number")
+		}
+		sku, err := s.repo.GetSkuByID(ctx, transition.SkuID)
+		if err != nil {
+			return domain.StockTransition{}, err
+		}
+		transition.SellerID = &sku.SellerID
+	}
+
+	transition.ID = uuid.Nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if transition, err = s.repo.CreateStockTransition(ctx, transition); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityStockTransition, transition.ID.String(), nil, transition)
+	})
+	if err != nil {
+		return domain.StockTransition{}, err
+	}
+	return transition, nil
+}
+
+func (s *service) FetchStockTransitionByID(ctx context.Context, id uuid.UUID) (domain.StockTransition, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityStockTransition, id); err != nil {
+		return domain.StockTransition{}, err
+	}
+	if id == uuid.Nil {
+		return domain.StockTransition{}, domain.Invalid("invalid stock transition ID")
+	}
+	return s.repo.GetStockTransitionByID(ctx, id)
+}
+
+func (s *service) FetchStockTransitions(ctx context.Context, hubID uuid.UUID, transitionType string, skuID *uuid.UUID) ([]domain.StockTransition, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetStockTransitions(ctx, hubID, transitionType, skuID)
+}
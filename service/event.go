@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/omniful/go_commons/log"
+	"sync"
+	"time"
+	"wms/domain"
+	"wms/repo"
+)
+
+type EventService interface {
+	WatchInventory(ctx context.Context, filter domain.InventoryEventFilter) (<-chan domain.InventoryEvent, <-chan error, error)
+}
+
+// inventoryEventBatch is the number of events read at a time by a watcher
+const inventoryEventBatch = 100
+
+// eventPollInterval is how often the outbox is checked for new events while anyone watches
+const eventPollInterval = time.Second
+
+// WatchInventory streams the inventory changes of the principal's tenant, narrowed down to
+// a hub and SKUs, until the context ends. It starts after the event AfterID, which must be
+// one of the tenant's, or from now when AfterID is 0. Events come in the order their
+// transactions finished rather than by ID, so a client resumes after the last event it
+// received, not the highest ID. Events come one at a time on the first channel, which is
+// closed when the watch ends, with the error it ended on sent to the second. Each watch
+// follows the outbox at its own pace, so a slow consumer falls behind on its cursor rather
+// than holding events in memory.
+func (s *service) WatchInventory(ctx context.Context, filter domain.InventoryEventFilter) (<-chan domain.InventoryEvent, <-chan error, error) {
+	if err := s.authorizeHubs(ctx, domain.PermInventoryRead, filter.HubID); err != nil {
+		return nil, nil, err
+	}
+	if filter.AfterID < 0 {
+		return nil, nil, domain.Invalid("event ID must be non-negative")
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.TenantID = p.TenantID
+	filter.Limit = inventoryEventBatch
+
+	if filter.AfterID == 0 {
+		filter.AfterTxID, err = s.repo.GetEventHorizon(ctx)
+	} else {
+		filter.AfterTxID, err = s.repo.GetEventTxID(ctx, filter.TenantID, filter.AfterID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, nil, domain.Invalid("unknown event ID %d", filter.AfterID)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan domain.InventoryEvent)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(events)
+		errs <- s.watchInventory(ctx, filter, events)
+	}()
+	return events, errs, nil
+}
+
+func (s *service) watchInventory(ctx context.Context, filter domain.InventoryEventFilter, events chan<- domain.InventoryEvent) error {
+	for {
+		generation := s.events.current()
+		batch, err := s.repo.GetInventoryEvents(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, event := range batch {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			filter.AfterTxID, filter.AfterID = event.TxID, event.ID
+		}
+		if len(batch) == inventoryEventBatch {
+			continue
+		}
+		if err = s.events.wait(ctx, generation); err != nil {
+			return err
+		}
+	}
+}
+
+// eventNotifier tells watchers when inventory events have become readable. A single poller
+// follows the event horizon for all of them, and only while someone waits: whenever it
+// moves past transactions that recorded inventory events, the generation goes up.
+type eventNotifier struct {
+	repo       repo.Repository
+	mu         sync.Mutex
+	horizon    int64
+	generation int64
+	tick       chan struct{}
+	waiting    int
+}
+
+func newEventNotifier(r repo.Repository) *eventNotifier {
+	return &eventNotifier{repo: r}
+}
+
+// current returns the generation to wait on after a read finding nothing new. Taking it
+// before the read means events becoming readable during the read are not waited past.
+func (n *eventNotifier) current() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.generation
+}
+
+// wait blocks until the generation has moved past the given one, checked once per poll,
+// or until the context ends
+func (n *eventNotifier) wait(ctx context.Context, generation int64) error {
+	for {
+		n.mu.Lock()
+		if n.tick == nil {
+			n.tick = make(chan struct{})
+			go n.poll()
+		}
+		tick := n.tick
+		n.waiting++
+		n.mu.Unlock()
+
+		select {
+		case <-tick:
+		case <-ctx.Done():
+		}
+
+		n.mu.Lock()
+		n.waiting--
+		current := n.generation
+		n.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if current > generation {
+			return nil
+		}
+	}
+}
+
+// poll wakes the waiters after every read of the event horizon, stopping once a poll
+// finds no one waiting
+func (n *eventNotifier) poll() {
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := n.advance(context.Background()); err != nil {
+			log.Errorf("failed to poll events: %v", err)
+		}
+
+		n.mu.Lock()
+		idle := n.waiting == 0
+		close(n.tick)
+		n.tick = nil
+		if !idle {
+			n.tick = make(chan struct{})
+		}
+		n.mu.Unlock()
+		if idle {
+			return
+		}
+	}
+}
+
+// advance moves the horizon, starting a new generation when transactions it moved past
+// recorded inventory events. The first poll has nothing to compare with and always does.
+func (n *eventNotifier) advance(ctx context.Context) error {
+	horizon, err := n.repo.GetEventHorizon(ctx)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	previous := n.horizon
+	n.mu.Unlock()
+	if horizon <= previous {
+		return nil
+	}
+
+	changed := previous == 0
+	if !changed {
+		if changed, err = n.repo.HasInventoryEventsBetween(ctx, previous, horizon); err != nil {
+			return err
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.horizon = horizon
+	if changed {
+		n.generation++
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+type InboundService interface {
+	CreatePurchaseOrder(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error)
+	FetchPurchaseOrderByID(ctx context.Context, id uuid.UUID) (domain.PurchaseOrder, error)
+	FetchPurchaseOrders(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.PurchaseOrder, error)
+	CreateASN(ctx context.Context, asn domain.ASN) (domain.ASN, error)
+	FetchASNByID(ctx context.Context, id uuid.UUID) (domain.ASN, error)
+	FetchASNs(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.ASN, error)
+	ReceiveASN(ctx context.Context, id uuid.UUID, receipts []domain.AsnReceipt) (domain.ASN, error)
+	CloseASN(ctx context.Context, id uuid.UUID) (domain.ASN, error)
+	FetchAsnVariance(ctx context.Context, sellerID, hubID *uuid.UUID, from, to *time.Time) ([]domain.AsnVariance, error)
+}
+
+func (s *service) CreatePurchaseOrder(ctx context.Context, po domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermInboundReceive, po.HubID); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	po.PoNo = strings.TrimSpace(po.PoNo)
+	if po.SellerID == uuid.Nil || po.HubID == uuid.Nil {
+		return domain.PurchaseOrder{}, domain.Invalid("invalid seller ID or hub ID")
+	}
+	if po.PoNo == "" {
+		return domain.PurchaseOrder{}, domain.Invalid("purchase order number cannot be empty")
+	}
+	if len(po.Lines) == 0 {
+		return domain.PurchaseOrder{}, domain.Invalid("a purchase order needs at least one line")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(po.Lines))
+	for i, line := range po.Lines {
+		if line.SkuID == uuid.Nil {
+			return domain.PurchaseOrder{}, domain.Invalid("line %d: invalid SKU ID", i+1)
+		}
+		if seen[line.SkuID] {
+			return domain.PurchaseOrder{}, domain.Invalid("line %d: SKU is listed more than once", i+1)
+		}
+		seen[line.SkuID] = true
+		if line.OrderedQty <= 0 {
+			return domain.PurchaseOrder{}, domain.Invalid("line %d: quantities must be positive", i+1)
+		}
+		po.Lines[i].ReceivedQty = 0
+	}
+
+	po.ID = uuid.Nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if po, err = s.repo.CreatePurchaseOrder(ctx, po); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityPurchaseOrder, po.ID.String(), nil, po)
+	})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return po, nil
+}
+
+func (s *service) FetchPurchaseOrderByID(ctx context.Context, id uuid.UUID) (domain.PurchaseOrder, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityPurchaseOrder, id); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	if id == uuid.Nil {
+		return domain.PurchaseOrder{}, domain.Invalid("invalid purchase order ID")
+	}
+	return s.repo.GetPurchaseOrderByID(ctx, id)
+}
+
+func (s *service) FetchPurchaseOrders(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.PurchaseOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetPurchaseOrders(ctx, hubID, sellerID, status)
+}
+
+func (s *service) CreateASN(ctx context.Context, asn domain.ASN) (domain.ASN, error) {
+	if err := s.authorizeHub(ctx, domain.PermInboundReceive, asn.HubID); err != nil {
+		return domain.ASN{}, err
+	}
+	asn.AsnNo = strings.TrimSpace(asn.AsnNo)
+	if asn.SellerID == uuid.Nil || asn.HubID == uuid.Nil {
+		return domain.ASN{}, domain.Invalid("invalid seller ID or hub ID")
+	}
+	if asn.AsnNo == "" {
+		return domain.ASN{}, domain.Invalid("ASN number cannot be empty")
+	}
+	if asn.OverTolerancePct < 0 || asn.OverTolerancePct > 100 {
+		return domain.ASN{}, domain.Invalid("over-receipt tolerance must be between 0 and 100 percent")
+	}
+	if len(asn.Lines) == 0 {
+		return domain.ASN{}, domain.Invalid("an ASN needs at least one line")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(asn.Lines))
+	for i, line := range asn.Lines {
+		if line.SkuID == uuid.Nil {
+			return domain.ASN{}, domain.Invalid("line %d: invalid SKU ID", i+1)
+		}
+		if seen[line.SkuID] {
+			return domain.ASN{}, domain.Invalid("line %d: SKU is listed more than once", i+1)
+		}
+		seen[line.SkuID] = true
+		if line.ExpectedQty <= 0 {
+			return domain.ASN{}, domain.Invalid("line %d: quantities must be positive", i+1)
+		}
+		asn.Lines[i].ReceivedQty = 0
+		asn.Lines[i].QuarantinedQty = 0
+		asn.Lines[i].Unexpected = false
+	}
+
+	asn.ID = uuid.Nil
+	asn.ClosedAt = nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if asn, err = s.repo.CreateASN(ctx, asn); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityASN, asn.ID.String(), nil, asn)
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return asn, nil
+}
+
+func (s *service) FetchASNByID(ctx context.Context, id uuid.UUID) (domain.ASN, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityASN, id); err != nil {
+		return domain.ASN{}, err
+	}
+	if id == uuid.Nil {
+		return domain.ASN{}, domain.Invalid("invalid ASN ID")
+	}
+	return s.repo.GetASNByID(ctx, id)
+}
+
+func (s *service) FetchASNs(ctx context.Context, hubID uuid.UUID, sellerID *uuid.UUID, status string) ([]domain.ASN, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetASNs(ctx, hubID, sellerID, status)
+}
+
+// ReceiveASN validates the lots received per SKU the same way as a plain receipt
+func (s *service) ReceiveASN(ctx context.Context, id uuid.UUID, receipts []domain.AsnReceipt) (domain.ASN, error) {
+	if err := s.authorize(ctx, domain.PermInboundReceive, domain.EntityASN, id); err != nil {
+		return domain.ASN{}, err
+	}
+	if id == uuid.Nil {
+		return domain.ASN{}, domain.Invalid("invalid ASN ID")
+	}
+	if len(receipts) == 0 {
+		return domain.ASN{}, domain.Invalid("at least one lot must be received")
+	}
+
+	grouped := make(map[uuid.UUID][]domain.LotReceipt)
+	for _, receipt := range receipts {
+		if receipt.SkuID == uuid.Nil {
+			return domain.ASN{}, domain.Invalid("invalid SKU ID")
+		}
+		grouped[receipt.SkuID] = append(grouped[receipt.SkuID], receipt.LotReceipt)
+	}
+	for skuID, lots := range grouped {
+		if err := s.checkLotReceipts(ctx, skuID, lots); err != nil {
+			return domain.ASN{}, err
+		}
+	}
+
+	before, err := s.repo.GetASNByID(ctx, id)
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	var asn domain.ASN
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if asn, err = s.repo.ReceiveASN(ctx, id, receipts); err != nil {
+			return err
+		}
+		return s.audit(ctx, "receive", domain.EntityASN, id.String(), before, asn)
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return asn, nil
+}
+
+func (s *service) CloseASN(ctx context.Context, id uuid.UUID) (domain.ASN, error) {
+	if err := s.authorize(ctx, domain.PermInboundReceive, domain.EntityASN, id); err != nil {
+		return domain.ASN{}, err
+	}
+	if id == uuid.Nil {
+		return domain.ASN{}, domain.Invalid("invalid ASN ID")
+	}
+	before, err := s.repo.GetASNByID(ctx, id)
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	var asn domain.ASN
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if asn, err = s.repo.CloseASN(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "close", domain.EntityASN, id.String(), before, asn)
+	})
+	if err != nil {
+		return domain.ASN{}, err
+	}
+	return asn, nil
+}
+
+func (s *service) FetchAsnVariance(ctx context.Context, sellerID, hubID *uuid.UUID, from, to *time.Time) ([]domain.AsnVariance, error) {
+	if err := s.authorizeHubs(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, domain.Invalid("from must be before to")
+	}
+	return s.repo.GetAsnVariance(ctx, sellerID, hubID, from, to)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type KitService interface {
+	SetKitComponents(ctx context.Context, kitSkuID uuid.UUID, components []domain.KitComponent) ([]domain.KitComponent, error)
+	FetchKitComponents(ctx context.Context, kitSkuID uuid.UUID) ([]domain.KitComponent, error)
+	FetchKitAvailability(ctx context.Context, kitSkuID uuid.UUID, hubID *uuid.UUID) ([]domain.KitAvailability, error)
+	CreateKittingOrder(ctx context.Context, order domain.KittingOrder) (domain.KittingOrder, error)
+	FetchKittingOrderByID(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+	FetchKittingOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.KittingOrder, error)
+	CompleteKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+	CancelKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error)
+}
+
+// SetKitComponents validates the bill of materials of a kit. Serialized SKUs can't be kits or
+// components, since kits are allocated and assembled without serials. An empty list turns the
+// kit back into a plain SKU.
+func (s *service) SetKitComponents(ctx context.Context, kitSkuID uuid.UUID, components []domain.KitComponent) ([]domain.KitComponent, error) {
+	if err := s.authorize(ctx, domain.PermCatalogManage, domain.EntitySku, kitSkuID); err != nil {
+		return nil, err
+	}
+	kit, err := s.repo.GetSkuByID(ctx, kitSkuID)
+	if err != nil {
+		return nil, err
+	}
+	if kit.Serialized && len(components) > 0 {
+		return nil, domain.Invalid("a serialized SKU cannot be a kit")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(components))
+	for i, component := range components {
+		if component.ComponentSkuID == uuid.Nil || component.ComponentSkuID == kitSkuID {
+			return nil, domain.Invalid("component %d: invalid SKU ID", i+1)
+		}
+		if seen[component.ComponentSkuID] {
+			return nil, domain.Invalid("component %d: SKU is listed twice", i+1)
+		}
+		seen[component.ComponentSkuID] = true
+		if component.Qty <= 0 {
+			return nil, domain.Invalid("component %d: quantities must be positive", i+1)
+		}
+
+		sku, err := s.repo.GetSkuByID(ctx, component.ComponentSkuID)
+		if err != nil {
+			return nil, fmt.Errorf("component %d: %w", i+1, err)
+		}
+		if sku.Serialized {
+			return nil, domain.Invalid("component %d: a serialized SKU cannot be a kit component", i+1)
+		}
+		components[i].ID = uuid.Nil
+	}
+	before, err := s.repo.GetKitComponents(ctx, kitSkuID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if components, err = s.repo.SetKitComponents(ctx, kitSkuID, components); err != nil {
+			return err
+		}
+		return s.audit(ctx, "set_components", domain.EntitySku, kitSkuID.String(), before, components)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+func (s *service) FetchKitComponents(ctx context.Context, kitSkuID uuid.UUID) ([]domain.KitComponent, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntitySku, kitSkuID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetKitComponents(ctx, kitSkuID)
+}
+
+func (s *service) FetchKitAvailability(ctx context.Context, kitSkuID uuid.UUID, hubID *uuid.UUID) ([]domain.KitAvailability, error) {
+	if err := s.authorizeHubs(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetKitAvailability(ctx, kitSkuID, hubID)
+}
+
+func (s *service) CreateKittingOrder(ctx context.Context, order domain.KittingOrder) (domain.KittingOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermOrderManage, order.HubID); err != nil {
+		return domain.KittingOrder{}, err
+	}
+	order.LotNumber = strings.TrimSpace(order.LotNumber)
+	if order.KitSkuID == uuid.Nil || order.HubID == uuid.Nil {
+		return domain.KittingOrder{}, domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	if order.Qty <= 0 {
+		return domain.KittingOrder{}, domain.Invalid("quantities must be positive")
+	}
+	if order.ExpiryDate != nil && order.LotNumber == "" {
+		return domain.KittingOrder{}, domain.Invalid("an expiry date needs a lot number")
+	}
+	if order.LocationID != nil {
+		location, err := s.repo.GetLocationByID(ctx, *order.LocationID)
+		if err != nil {
+			return domain.KittingOrder{}, err
+		}
+		if location.HubID != order.HubID {
+			return domain.KittingOrder{}, domain.Invalid("location is not in this hub")
+		}
+	}
+
+	order.ID = uuid.Nil
+	order.CompletedAt = nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.repo.CreateKittingOrder(ctx, order); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityKittingOrder, order.ID.String(), nil, order)
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return order, nil
+}
+
+func (s *service) FetchKittingOrderByID(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityKittingOrder, id); err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return s.repo.GetKittingOrderByID(ctx, id)
+}
+
+func (s *service) FetchKittingOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.KittingOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetKittingOrders(ctx, hubID, status)
+}
+
+func (s *service) CompleteKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityKittingOrder, id); err != nil {
+		return domain.KittingOrder{}, err
+	}
+	before, err := s.repo.GetKittingOrderByID(ctx, id)
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	var order domain.KittingOrder
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.repo.CompleteKittingOrder(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "complete", domain.EntityKittingOrder, id.String(), before, order)
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return order, nil
+}
+
+func (s *service) CancelKittingOrder(ctx context.Context, id uuid.UUID) (domain.KittingOrder, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityKittingOrder, id); err != nil {
+		return domain.KittingOrder{}, err
+	}
+	before, err := s.repo.GetKittingOrderByID(ctx, id)
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	var order domain.KittingOrder
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.repo.CancelKittingOrder(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "cancel", domain.EntityKittingOrder, id.String(), before, order)
+	})
+	if err != nil {
+		return domain.KittingOrder{}, err
+	}
+	return order, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"wms/domain"
+	"wms/pkg/label"
+)
+
+type LabelService interface {
+	SetGS1CompanyPrefix(ctx context.Context, tenantID uuid.UUID, prefix string) (domain.Tenant, error)
+	SkuLabel(ctx context.Context, skuID uuid.UUID, format string, copies int) ([]byte, error)
+	LocationLabel(ctx context.Context, locationID uuid.UUID, format string, copies int) ([]byte, error)
+	PackageLabel(ctx context.Context, packageID uuid.UUID, format string, copies int) ([]byte, error)
+}
+
+// maxLabelCopies keeps a mistyped copy count from emptying a roll of labels
+const maxLabelCopies = 500
+
+// skuLabel is a 60 x 40 mm shelf and product label
+var skuLabel = label.Template{
+	Width:  60,
+	Height: 40,
+	Elements: []label.Element{
+		{Kind: label.Bold, X: 3, Y: 3, Size: 4, Value: "{name}"},
+		{Kind: label.Text, X: 3, Y: 8.5, Size: 3, Value: "{code}"},
+		{Kind: label.Barcode, X: 3, Y: 14, Size: 15, Width: 54, Symbology: "{symbology}", Value: "{barcode}"},
+	},
+}
+
+// locationLabel is a 100 x 50 mm rack label
+var locationLabel = label.Template{
+	Width:  100,
+	Height: 50,
+	Elements: []label.Element{
+		{Kind: label.Bold, X: 5, Y: 4, Size: 10, Value: "{code}"},
+		{Kind: label.Text, X: 5, Y: 16, Size: 4, Value: "{hub}  {level} {type}"},
+		{Kind: label.Barcode, X: 5, Y: 23, Size: 18, Width: 90, Symbology: "{symbology}", Value: "{barcode}"},
+	},
+}
+
+// cartonLabel is a 4 x 6 inch shipping label carrying the SSCC of the carton as GS1-128
+var cartonLabel = label.Template{
+	Width:  101.6,
+	Height: 152.4,
+	Elements: []label.Element{
+		{Kind: label.Text, X: 5, Y: 5, Size: 3, Value: "FROM"},
+		{Kind: label.Bold, X: 5, Y: 9, Size: 4, Value: "{hub}"},
+		{Kind: label.Text, X: 5, Y: 14, Size: 3, Value: "{address}"},
+		{Kind: label.Line, X: 0, Y: 20, Size: 0.3, Width: 101.6},
+		{Kind: label.Text, X: 5, Y: 23, Size: 3, Value: "ORDER"},
+		{Kind: label.Bold, X: 5, Y: 27, Size: 6, Value: "{order_no}"},
+		{Kind: label.Text, X: 55, Y: 23, Size: 3, Value: "CARRIER"},
+		{Kind: label.Bold, X: 55, Y: 27, Size: 5, Value: "{carrier} {service}"},
+		{Kind: label.Line, X: 0, Y: 36, Size: 0.3, Width: 101.6},
+		{Kind: label.Text, X: 5, Y: 39, Size: 3, Value: "TRACKING"},
+		{Kind: label.Barcode, X: 5, Y: 44, Size: 20, Width: 90, Symbology: label.Code128, Value: "{tracking}"},
+		{Kind: label.Line, X: 0, Y: 74, Size: 0.3, Width: 101.6},
+		{Kind: label.Text, X: 5, Y: 77, Size: 4, Value: "PACKAGE {package_no} OF {package_count}    {weight} KG"},
+		{Kind: label.Line, X: 0, Y: 86, Size: 0.3, Width: 101.6},
+		{Kind: label.Text, X: 5, Y: 90, Size: 3, Value: "SSCC"},
+		{Kind: label.Barcode, X: 5, Y: 96, Size: 30, Width: 92, Symbology: label.GS1128, Value: "00{sscc}"},
+	},
+}
+
+func (s *service) SetGS1CompanyPrefix(ctx context.Context, tenantID uuid.UUID, prefix string) (domain.Tenant, error) {
+	if tenantID == uuid.Nil {
+		return domain.Tenant{}, domain.Invalid("invalid tenant ID")
+	}
+	if err := s.authorizeTenant(ctx, domain.PermAccessManage, tenantID); err != nil {
+		return domain.Tenant{}, err
+	}
+	prefix = strings.TrimSpace(prefix)
+	if !domain.ValidCompanyPrefix(prefix) {
+		return domain.Tenant{}, domain.Invalid("GS1 company prefix must be 7 to 10 digits")
+	}
+	var tenant domain.Tenant
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if tenant, err = s.repo.SetGS1CompanyPrefix(ctx, tenantID, prefix); err != nil {
+			return err
+		}
+		return s.audit(ctx, "set_gs1_prefix", domain.EntityTenant, tenantID.String(), nil, tenant)
+	})
+	if err != nil {
+		return domain.Tenant{}, err
+	}
+	return tenant, nil
+}
+
+// SkuLabel prints the base unit barcode registered for a SKU, or its SKU code as Code 128
+// when it has none
+func (s *service) SkuLabel(ctx context.Context, skuID uuid.UUID, format string, copies int) ([]byte, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntitySku, skuID); err != nil {
+		return nil, err
+	}
+	sku, err := s.repo.GetSkuByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+	barcodes, err := s.repo.GetBarcodes(ctx, &skuID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{"name": sku.Name, "code": sku.Code}
+	fields["symbology"], fields["barcode"] = labelBarcode(barcodes, sku.Code)
+	return renderLabel(skuLabel, fields, format, copies)
+}
+
+func (s *service) LocationLabel(ctx context.Context, locationID uuid.UUID, format string, copies int) ([]byte, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityLocation, locationID); err != nil {
+		return nil, err
+	}
+	location, err := s.repo.GetLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	hub, err := s.repo.GetHubByID(ctx, location.HubID)
+	if err != nil {
+		return nil, err
+	}
+	barcodes, err := s.repo.GetBarcodes(ctx, nil, &locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{
+		"code":  location.Code,
+		"hub":   hub.Name,
+		"level": strings.ToUpper(location.Level),
+		"type":  strings.ToUpper(location.Type),
+	}
+	fields["symbology"], fields["barcode"] = labelBarcode(barcodes, location.Code)
+	return renderLabel(locationLabel, fields, format, copies)
+}
+
+// PackageLabel prints the shipping label of a carton, giving it an SSCC first if it has none
+func (s *service) PackageLabel(ctx context.Context, packageID uuid.UUID, format string, copies int) ([]byte, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityPackage, packageID); err != nil {
+		return nil, err
+	}
+	if err := checkLabelOptions(format, copies); err != nil {
+		return nil, err
+	}
+	pkg, err := s.repo.AssignSSCC(ctx, packageID)
+	if err != nil {
+		return nil, err
+	}
+	hub, err := s.repo.GetHubByID(ctx, pkg.HubID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.repo.GetOrderByID(ctx, pkg.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	packages, err := s.repo.GetPackages(ctx, pkg.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	carrier, carrierService := order.Carrier, ""
+	if pkg.ShipmentID != nil {
+		shipment, err := s.repo.GetShipmentByID(ctx, *pkg.ShipmentID)
+		if err != nil {
+			return nil, err
+		}
+		carrier, carrierService = shipment.Carrier, shipment.Service
+	}
+
+	packageNo := 0
+	for i, p := range packages {
+		if p.ID == pkg.ID {
+			packageNo = i + 1
+		}
+	}
+
+	address := hub.Address
+	for _, part := range []*string{hub.City, hub.Pincode} {
+		if part != nil && *part != "" {
+			address += ", " + *part
+		}
+	}
+
+	fields := map[string]string{
+		"hub":           hub.Name,
+		"address":       address,
+		"order_no":      order.OrderNo,
+		"carrier":       strings.ToUpper(carrier),
+		"service":       strings.ToUpper(carrierService),
+		"tracking":      pkg.TrackingNumber,
+		"package_no":    strconv.Itoa(packageNo),
+		"package_count": strconv.Itoa(len(packages)),
+		"weight":        strconv.FormatFloat(pkg.Weight, 'f', 3, 64),
+		"sscc":          *pkg.SSCC,
+	}
+	return renderLabel(cartonLabel, fields, format, copies)
+}
+
+// labelBarcode picks the barcode to print from the registered ones: the first base unit
+// barcode, encoded in the symbology of its type, or else the fallback code as Code 128
+func labelBarcode(barcodes []domain.Barcode, fallback string) (string, string) {
+	for _, barcode := range barcodes {
+		if barcode.UOM != "" {
+			continue
+		}
+		switch barcode.Type {
+		case domain.BarcodeTypeEAN13:
+			return label.EAN13, barcode.Code
+		case domain.BarcodeTypeUPCA:
+			return label.UPCA, barcode.Code
+		case domain.BarcodeTypeGTIN14:
+			return label.GS1128, "01" + barcode.Code
+		}
+		return label.Code128, barcode.Code
+	}
+	return label.Code128, fallback
+}
+
+func checkLabelOptions(format string, copies int) error {
+	if !domain.IsLabelFormat(format) {
+		return domain.Invalid("invalid label format %q, expected zpl or pdf", format)
+	}
+	if copies < 1 || copies > maxLabelCopies {
+		return domain.Invalid("copies must be between 1 and %d", maxLabelCopies)
+	}
+	return nil
+}
+
+func renderLabel(t label.Template, fields map[string]string, format string, copies int) ([]byte, error) {
+	if err := checkLabelOptions(format, copies); err != nil {
+		return nil, err
+	}
+	if format == domain.LabelFormatZPL {
+		return label.ZPL(t, []map[string]string{fields}, copies), nil
+	}
+	return label.PDF(t, []map[string]string{fields}, copies), nil
+}
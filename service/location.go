@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type LocationService interface {
+	CreateLocation(ctx context.Context, location domain.Location) (domain.Location, error)
+	UpdateLocation(ctx context.Context, location domain.Location) error
+	FetchLocationByID(ctx context.Context, id uuid.UUID) (domain.Location, error)
+	FetchLocations(ctx context.Context, hubID uuid.UUID, level, locationType string) ([]domain.Location, error)
+	FetchLocationStock(ctx context.Context, locationID uuid.UUID) ([]domain.LocationStock, error)
+	FetchSkuLocationStock(ctx context.Context, skuID, hubID uuid.UUID) (domain.SkuLocationStock, error)
+	MoveLocationStock(ctx context.Context, skuID, hubID, fromID, toID uuid.UUID, qty int) error
+}
+
+func (s *service) CreateLocation(ctx context.Context, location domain.Location) (domain.Location, error) {
+	if err := s.authorizeHub(ctx, domain.PermLocationManage, location.HubID); err != nil {
+		return domain.Location{}, err
+	}
+	location.Code = strings.TrimSpace(location.Code)
+	if location.HubID == uuid.Nil {
+		return domain.Location{}, domain.Invalid("invalid hub ID")
+	}
+	if location.Code == "" {
+		return domain.Location{}, domain.Invalid("location code cannot be empty")
+	}
+	if strings.HasPrefix(location.Code, domain.ReservedLocationPrefix) {
+		return domain.Location{}, domain.Invalid("location codes starting with %q are reserved", domain.ReservedLocationPrefix)
+	}
+	if !domain.IsLocationLevel(location.Level) {
+		return domain.Location{}, domain.Invalid("invalid location level %q", location.Level)
+	}
+	if err := checkCapacity(location); err != nil {
+		return domain.Location{}, err
+	}
+
+	parentLevel := domain.ParentLevel(location.Level)
+	switch {
+	case parentLevel == "" && location.ParentID != nil:
+		return domain.Location{}, domain.Invalid("a zone cannot have a parent location")
+	case parentLevel != "" && location.ParentID == nil:
+		return domain.Location{}, domain.Invalid("a %s must be placed in a %s", location.Level, parentLevel)
+	case parentLevel != "":
+		parent, err := s.repo.GetLocationByID(ctx, *location.ParentID)
+		if err != nil {
+			return domain.Location{}, err
+		}
+		if parent.HubID != location.HubID {
+			return domain.Location{}, domain.Invalid("parent location belongs to another hub")
+		}
+		if parent.Level != parentLevel {
+			return domain.Location{}, domain.Invalid("a %s must be placed in a %s, not a %s", location.Level, parentLevel, parent.Level)
+		}
+		if location.Type == "" {
+			location.Type = parent.Type
+		}
+	}
+
+	if location.Type == "" {
+		location.Type = domain.LocationTypeReserve
+	}
+	if !domain.IsLocationType(location.Type) {
+		return domain.Location{}, domain.Invalid("invalid location type %q", location.Type)
+	}
+
+	location.ID = uuid.Nil
+	location.Active = true
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if location, err = s.repo.CreateLocation(ctx, location); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityLocation, location.ID.String(), nil, location)
+	})
+	if err != nil {
+		return domain.Location{}, err
+	}
+	return location, nil
+}
+
+func (s *service) UpdateLocation(ctx context.Context, location domain.Location) error {
+	if err := s.authorize(ctx, domain.PermLocationManage, domain.EntityLocation, location.ID); err != nil {
+		return err
+	}
+	if location.ID == uuid.Nil {
+		return domain.Invalid("invalid location ID")
+	}
+	if !domain.IsLocationType(location.Type) {
+		return domain.Invalid("invalid location type %q", location.Type)
+	}
+	if err := checkCapacity(location); err != nil {
+		return err
+	}
+	before, err := s.repo.GetLocationByID(ctx, location.ID)
+	if err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateLocation(ctx, location); err != nil {
+			return err
+		}
+		after, err := s.repo.GetLocationByID(ctx, location.ID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, "update", domain.EntityLocation, location.ID.String(), before, after)
+	})
+}
+
+func (s *service) FetchLocationByID(ctx context.Context, id uuid.UUID) (domain.Location, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityLocation, id); err != nil {
+		return domain.Location{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Location{}, domain.Invalid("invalid location ID")
+	}
+	return s.repo.GetLocationByID(ctx, id)
+}
+
+func (s *service) FetchLocations(ctx context.Context, hubID uuid.UUID, level, locationType string) ([]domain.Location, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	if level != "" && !domain.IsLocationLevel(level) {
+		return nil, domain.Invalid("invalid location level %q", level)
+	}
+	if locationType != "" && !domain.IsLocationType(locationType) {
+		return nil, domain.Invalid("invalid location type %q", locationType)
+	}
+	return s.repo.GetLocations(ctx, hubID, level, locationType)
+}
+
+func (s *service) FetchLocationStock(ctx context.Context, locationID uuid.UUID) ([]domain.LocationStock, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityLocation, locationID); err != nil {
+		return nil, err
+	}
+	if locationID == uuid.Nil {
+		return nil, domain.Invalid("invalid location ID")
+	}
+	return s.repo.GetLocationStock(ctx, locationID)
+}
+
+func (s *service) FetchSkuLocationStock(ctx context.Context, skuID, hubID uuid.UUID) (domain.SkuLocationStock, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return domain.SkuLocationStock{}, err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil {
+		return domain.SkuLocationStock{}, domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	return s.repo.GetSkuLocationStock(ctx, skuID, hubID)
+}
+
+func (s *service) MoveLocationStock(ctx context.Context, skuID, hubID, fromID, toID uuid.UUID, qty int) error {
+	if err := s.authorizeHub(ctx, domain.PermInventoryAdjust, hubID); err != nil {
+		return err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil || fromID == uuid.Nil || toID == uuid.Nil {
+		return domain.Invalid("invalid SKU ID, Hub ID or location ID")
+	}
+	if fromID == toID {
+		return domain.Invalid("source and destination locations are the same")
+	}
+	if qty <= 0 {
+		return domain.Invalid("quantities must be positive")
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.MoveLocationStock(ctx, skuID, hubID, fromID, toID, qty); err != nil {
+			return err
+		}
+		return s.audit(ctx, "move_stock", domain.EntityLocation, toID.String(), nil, map[string]interface{}{"sku_id": skuID, "hub_id": hubID, "from_location_id": fromID, "to_location_id": toID, "qty": qty})
+	})
+}
+
+func checkCapacity(location domain.Location) error {
+	if location.MaxUnits < 0 || location.MaxWeight < 0 || location.MaxVolume < 0 {
+		return domain.Invalid("location capacities must be non-negative")
+	}
+	if location.Sequence < 0 || location.ShelfLevel < 0 {
+		return domain.Invalid("location sequence and shelf level must be non-negative")
+	}
+	return nil
+}
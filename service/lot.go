@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+	"wms/domain"
+)
+
+// defaultNearExpiryDays is the window of the near expiry report when none is given.
+const defaultNearExpiryDays = 30
+
+type LotService interface {
+	ReceiveInventory(ctx context.Context, skuID, hubID uuid.UUID, receipts []domain.LotReceipt) error
+	AllocateInventory(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error
+	FetchLots(ctx context.Context, skuID, hubID uuid.UUID) ([]domain.Lot, error)
+	FetchNearExpiryLots(ctx context.Context, hubID uuid.UUID, days *int) ([]domain.NearExpiryLot, error)
+	BlockExpiredLots(ctx context.Context, hubID uuid.UUID) (int, error)
+}
+
+func (s *service) ReceiveInventory(ctx context.Context, skuID, hubID uuid.UUID, receipts []domain.LotReceipt) error {
+	if err := s.authorizeHub(ctx, domain.PermInboundReceive, hubID); err != nil {
+		return err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil {
+		return domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	if len(receipts) == 0 {
+		return domain.Invalid("at least one lot must be received")
+	}
+
+	if err := s.checkLotReceipts(ctx, skuID, receipts); err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ReceiveLots(ctx, skuID, hubID, receipts); err != nil {
+			return err
+		}
+		return s.audit(ctx, "receive", domain.EntityInventory, skuID.String(), nil, map[string]interface{}{"sku_id": skuID, "hub_id": hubID, "receipts": receipts})
+	})
+}
+
+func (s *service) AllocateInventory(ctx context.Context, skuID, hubID uuid.UUID, qty int, serials []string) error {
+	if err := s.authorizeHub(ctx, domain.PermInventoryAdjust, hubID); err != nil {
+		return err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil {
+		return domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	if qty <= 0 {
+		return domain.Invalid("quantities must be positive")
+	}
+	if err := s.checkSerials(ctx, skuID, qty, serials); err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AllocateFEFO(ctx, skuID, hubID, qty, serials); err != nil {
+			return err
+		}
+		return s.audit(ctx, "allocate", domain.EntityInventory, skuID.String(), nil, map[string]interface{}{"sku_id": skuID, "hub_id": hubID, "qty": qty, "serials": serials})
+	})
+}
+
+func (s *service) FetchLots(ctx context.Context, skuID, hubID uuid.UUID) ([]domain.Lot, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	return s.repo.GetLots(ctx, skuID, hubID)
+}
+
+// FetchNearExpiryLots reports the lots of a hub expiring within days, defaultNearExpiryDays
+// when nil. 0 days reports the lots expiring today or already expired.
+func (s *service) FetchNearExpiryLots(ctx context.Context, hubID uuid.UUID, days *int) ([]domain.NearExpiryLot, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	window := defaultNearExpiryDays
+	if days != nil {
+		window = *days
+	}
+	if window < 0 {
+		return nil, domain.Invalid("days must be non-negative")
+	}
+	return s.repo.GetNearExpiryLots(ctx, hubID, window)
+}
+
+func (s *service) BlockExpiredLots(ctx context.Context, hubID uuid.UUID) (int, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryAdjust, hubID); err != nil {
+		return 0, err
+	}
+	if hubID == uuid.Nil {
+		return 0, domain.Invalid("invalid hub ID")
+	}
+	var blocked int
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if blocked, err = s.repo.BlockExpiredLots(ctx, hubID); err != nil {
+			return err
+		}
+		return s.audit(ctx, "block_expired_lots", domain.EntityHub, hubID.String(), nil, blocked)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return blocked, nil
+}
+
+// checkLotReceipts validates the lots of a SKU being received: positive quantities, lots
+// listed once with sensible dates, and the serials of serialized SKUs
+func (s *service) checkLotReceipts(ctx context.Context, skuID uuid.UUID, receipts []domain.LotReceipt) error {
+	seen := make(map[string]bool)
+	var serials []string
+	for _, receipt := range receipts {
+		if receipt.Qty <= 0 {
+			return domain.Invalid("quantities must be positive")
+		}
+		if err := s.checkSerials(ctx, skuID, receipt.Qty, receipt.Serials); err != nil {
+			return err
+		}
+		serials = append(serials, receipt.Serials...)
+
+		if receipt.LotNumber == "" {
+			continue
+		}
+		if seen[receipt.LotNumber] {
+			return domain.Invalid("lot %s is listed more than once", receipt.LotNumber)
+		}
+		seen[receipt.LotNumber] = true
+
+		if receipt.MfgDate != nil && receipt.ExpiryDate != nil && !receipt.ExpiryDate.After(*receipt.MfgDate) {
+			return domain.Invalid("lot %s expires before it was manufactured", receipt.LotNumber)
+		}
+		if (domain.Lot{ExpiryDate: receipt.ExpiryDate}).IsExpired(time.Now()) {
+			return domain.Invalid("lot %s has already expired", receipt.LotNumber)
+		}
+	}
+	return uniqueSerials(serials)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, order domain.OutboundOrder) (domain.OutboundOrder, error)
+	FetchOrderByID(ctx context.Context, id uuid.UUID) (domain.OutboundOrder, error)
+	FetchOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.OutboundOrder, error)
+	PlanWaves(ctx context.Context, hubID uuid.UUID, carrier string, cutoffBefore *time.Time) ([]domain.Wave, error)
+	FetchWaveByID(ctx context.Context, id uuid.UUID) (domain.Wave, error)
+	FetchWaves(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Wave, error)
+	ReleaseWave(ctx context.Context, id uuid.UUID) ([]domain.PickTask, error)
+	FetchPickList(ctx context.Context, waveID uuid.UUID, zoneID *uuid.UUID) ([]domain.PickTask, error)
+	ConfirmPick(ctx context.Context, id uuid.UUID, pickedQty int) error
+}
+
+func (s *service) CreateOrder(ctx context.Context, order domain.OutboundOrder) (domain.OutboundOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermOrderManage, order.HubID); err != nil {
+		return domain.OutboundOrder{}, err
+	}
+	order.OrderNo = strings.TrimSpace(order.OrderNo)
+	if order.HubID == uuid.Nil {
+		return domain.OutboundOrder{}, domain.Invalid("invalid hub ID")
+	}
+	if order.OrderNo == "" {
+		return domain.OutboundOrder{}, domain.Invalid("order number cannot be empty")
+	}
+	if len(order.Lines) == 0 {
+		return domain.OutboundOrder{}, domain.Invalid("an order needs at least one line")
+	}
+	for i, line := range order.Lines {
+		if line.SkuID == uuid.Nil {
+			return domain.OutboundOrder{}, domain.Invalid("line %d: invalid SKU ID", i+1)
+		}
+		if line.Qty <= 0 {
+			return domain.OutboundOrder{}, domain.Invalid("line %d: quantities must be positive", i+1)
+		}
+	}
+
+	order.ID = uuid.Nil
+	order.WaveID = nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if order, err = s.repo.CreateOrder(ctx, order); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityOrder, order.ID.String(), nil, order)
+	})
+	if err != nil {
+		return domain.OutboundOrder{}, err
+	}
+	return order, nil
+}
+
+func (s *service) FetchOrderByID(ctx context.Context, id uuid.UUID) (domain.OutboundOrder, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityOrder, id); err != nil {
+		return domain.OutboundOrder{}, err
+	}
+	if id == uuid.Nil {
+		return domain.OutboundOrder{}, domain.Invalid("invalid order ID")
+	}
+	return s.repo.GetOrderByID(ctx, id)
+}
+
+func (s *service) FetchOrders(ctx context.Context, hubID uuid.UUID, status string) ([]domain.OutboundOrder, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetOrders(ctx, hubID, status)
+}
+
+func (s *service) PlanWaves(ctx context.Context, hubID uuid.UUID, carrier string, cutoffBefore *time.Time) ([]domain.Wave, error) {
+	if err := s.authorizeHub(ctx, domain.PermOrderManage, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	var waves []domain.Wave
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if waves, err = s.repo.CreateWaves(ctx, hubID, strings.TrimSpace(carrier), cutoffBefore); err != nil {
+			return err
+		}
+		return s.audit(ctx, "plan_waves", domain.EntityHub, hubID.String(), nil, waves)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waves, nil
+}
+
+func (s *service) FetchWaveByID(ctx context.Context, id uuid.UUID) (domain.Wave, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityWave, id); err != nil {
+		return domain.Wave{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Wave{}, domain.Invalid("invalid wave ID")
+	}
+	return s.repo.GetWaveByID(ctx, id)
+}
+
+func (s *service) FetchWaves(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Wave, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetWaves(ctx, hubID, status)
+}
+
+func (s *service) ReleaseWave(ctx context.Context, id uuid.UUID) ([]domain.PickTask, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityWave, id); err != nil {
+		return nil, err
+	}
+	if id == uuid.Nil {
+		return nil, domain.Invalid("invalid wave ID")
+	}
+	before, err := s.repo.GetWaveByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []domain.PickTask
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if tasks, err = s.repo.ReleaseWave(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "release", domain.EntityWave, id.String(), before, tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *service) FetchPickList(ctx context.Context, waveID uuid.UUID, zoneID *uuid.UUID) ([]domain.PickTask, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityWave, waveID); err != nil {
+		return nil, err
+	}
+	if waveID == uuid.Nil {
+		return nil, domain.Invalid("invalid wave ID")
+	}
+	return s.repo.GetPickList(ctx, waveID, zoneID)
+}
+
+func (s *service) ConfirmPick(ctx context.Context, id uuid.UUID, pickedQty int) error {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityPickTask, id); err != nil {
+		return err
+	}
+	if id == uuid.Nil {
+		return domain.Invalid("invalid pick task ID")
+	}
+	if pickedQty < 0 {
+		return domain.Invalid("quantities must be non-negative")
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ConfirmPick(ctx, id, pickedQty); err != nil {
+			return err
+		}
+		return s.audit(ctx, "confirm", domain.EntityPickTask, id.String(), nil, map[string]interface{}{"picked_qty": pickedQty})
+	})
+}
@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"math"
+	"sort"
+	"strings"
+	"wms/domain"
+)
+
+type PackingService interface {
+	CreateCarton(ctx context.Context, carton domain.Carton) (domain.Carton, error)
+	FetchCartonByID(ctx context.Context, id uuid.UUID) (domain.Carton, error)
+	FetchCartons(ctx context.Context, hubID uuid.UUID) ([]domain.Carton, error)
+	CartonizeOrder(ctx context.Context, orderID uuid.UUID) ([]domain.CartonSuggestion, error)
+	OpenPackage(ctx context.Context, orderID uuid.UUID, cartonID *uuid.UUID) (domain.Package, error)
+	FetchPackageByID(ctx context.Context, id uuid.UUID) (domain.Package, error)
+	FetchPackages(ctx context.Context, orderID uuid.UUID) ([]domain.Package, error)
+	ScanPackageItem(ctx context.Context, packageID, skuID uuid.UUID, qty int, serials []string) error
+	CompletePacking(ctx context.Context, orderID uuid.UUID) (domain.Shipment, error)
+}
+
+func (s *service) CreateCarton(ctx context.Context, carton domain.Carton) (domain.Carton, error) {
+	if err := s.authorizeHub(ctx, domain.PermLocationManage, carton.HubID); err != nil {
+		return domain.Carton{}, err
+	}
+	carton.Code = strings.TrimSpace(carton.Code)
+	if carton.HubID == uuid.Nil {
+		return domain.Carton{}, domain.Invalid("invalid hub ID")
+	}
+	if carton.Code == "" {
+		return domain.Carton{}, domain.Invalid("carton code cannot be empty")
+	}
+	if carton.InnerLength <= 0 || carton.InnerWidth <= 0 || carton.InnerHeight <= 0 {
+		return domain.Carton{}, domain.Invalid("carton dimensions must be positive")
+	}
+	if carton.TareWeight < 0 || carton.MaxWeight < 0 {
+		return domain.Carton{}, domain.Invalid("carton weights must be non-negative")
+	}
+
+	carton.ID = uuid.Nil
+	carton.Active = true
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if carton, err = s.repo.CreateCarton(ctx, carton); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityCarton, carton.ID.String(), nil, carton)
+	})
+	if err != nil {
+		return domain.Carton{}, err
+	}
+	return carton, nil
+}
+
+func (s *service) FetchCartonByID(ctx context.Context, id uuid.UUID) (domain.Carton, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityCarton, id); err != nil {
+		return domain.Carton{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Carton{}, domain.Invalid("invalid carton ID")
+	}
+	return s.repo.GetCartonByID(ctx, id)
+}
+
+func (s *service) FetchCartons(ctx context.Context, hubID uuid.UUID) ([]domain.Carton, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetCartons(ctx, hubID)
+}
+
+// CartonizeOrder proposes cartons for the picked units of an order that aren't packed yet
+func (s *service) CartonizeOrder(ctx context.Context, orderID uuid.UUID) ([]domain.CartonSuggestion, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityOrder, orderID); err != nil {
+		return nil, err
+	}
+	if orderID == uuid.Nil {
+		return nil, domain.Invalid("invalid order ID")
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	cartons, err := s.repo.GetCartons(ctx, order.HubID)
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make(map[uuid.UUID]domain.SKU)
+	counts := make(map[uuid.UUID]int)
+	for _, line := range order.Lines {
+		qty := line.PickedQty - line.PackedQty
+		if qty <= 0 {
+			continue
+		}
+		if _, ok := skus[line.SkuID]; !ok {
+			sku, err := s.repo.GetSkuByID(ctx, line.SkuID)
+			if err != nil {
+				return nil, err
+			}
+			skus[line.SkuID] = sku
+		}
+		counts[line.SkuID] += qty
+	}
+	if len(counts) == 0 {
+		return nil, domain.Conflict("order has no picked units left to pack")
+	}
+
+	return cartonize(skus, counts, cartons)
+}
+
+// OpenPackage starts packing a carton for an order. Without a carton the first carton
+// of the cartonization is used.
+func (s *service) OpenPackage(ctx context.Context, orderID uuid.UUID, cartonID *uuid.UUID) (domain.Package, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityOrder, orderID); err != nil {
+		return domain.Package{}, err
+	}
+	if orderID == uuid.Nil {
+		return domain.Package{}, domain.Invalid("invalid order ID")
+	}
+
+	if cartonID == nil {
+		suggestions, err := s.CartonizeOrder(ctx, orderID)
+		if err != nil {
+			return domain.Package{}, err
+		}
+		cartonID = &suggestions[0].CartonID
+	}
+
+	order, err := s.repo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return domain.Package{}, err
+	}
+	carton, err := s.repo.GetCartonByID(ctx, *cartonID)
+	if err != nil {
+		return domain.Package{}, err
+	}
+	if carton.HubID != order.HubID {
+		return domain.Package{}, domain.Invalid("carton %s belongs to another hub", carton.Code)
+	}
+	if !carton.Active {
+		return domain.Package{}, domain.Invalid("carton %s is inactive", carton.Code)
+	}
+
+	var pkg domain.Package
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if pkg, err = s.repo.CreatePackage(ctx, domain.Package{OrderID: orderID, CartonID: carton.ID}); err != nil {
+			return err
+		}
+		return s.audit(ctx, "open", domain.EntityPackage, pkg.ID.String(), nil, pkg)
+	})
+	if err != nil {
+		return domain.Package{}, err
+	}
+	return pkg, nil
+}
+
+func (s *service) FetchPackageByID(ctx context.Context, id uuid.UUID) (domain.Package, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityPackage, id); err != nil {
+		return domain.Package{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Package{}, domain.Invalid("invalid package ID")
+	}
+	return s.repo.GetPackageByID(ctx, id)
+}
+
+func (s *service) FetchPackages(ctx context.Context, orderID uuid.UUID) ([]domain.Package, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityOrder, orderID); err != nil {
+		return nil, err
+	}
+	if orderID == uuid.Nil {
+		return nil, domain.Invalid("invalid order ID")
+	}
+	return s.repo.GetPackages(ctx, orderID)
+}
+
+// ScanPackageItem puts scanned units into a package when they fit in its carton.
+// Serialized SKUs have their serials scanned too.
+func (s *service) ScanPackageItem(ctx context.Context, packageID, skuID uuid.UUID, qty int, serials []string) error {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityPackage, packageID); err != nil {
+		return err
+	}
+	if packageID == uuid.Nil || skuID == uuid.Nil {
+		return domain.Invalid("invalid package ID or SKU ID")
+	}
+	if qty <= 0 {
+		return domain.Invalid("quantities must be positive")
+	}
+	if err := s.checkSerials(ctx, skuID, qty, serials); err != nil {
+		return err
+	}
+
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddPackageItem(ctx, packageID, skuID, qty, serials); err != nil {
+			return err
+		}
+		return s.audit(ctx, "scan_item", domain.EntityPackage, packageID.String(), nil, map[string]interface{}{"sku_id": skuID, "qty": qty, "serials": serials})
+	})
+}
+
+// CompletePacking weighs the open packages of an order and records the packed shipment
+func (s *service) CompletePacking(ctx context.Context, orderID uuid.UUID) (domain.Shipment, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityOrder, orderID); err != nil {
+		return domain.Shipment{}, err
+	}
+	if orderID == uuid.Nil {
+		return domain.Shipment{}, domain.Invalid("invalid order ID")
+	}
+
+	packages, err := s.repo.GetPackages(ctx, orderID)
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+
+	var open []domain.Package
+	for _, pkg := range packages {
+		if pkg.Status != domain.PackageStatusOpen {
+			continue
+		}
+		if len(pkg.Items) == 0 {
+			return domain.Shipment{}, domain.Conflict("package %s is empty", pkg.ID)
+		}
+
+		box, err := domain.NewPackageBox(pkg)
+		if err != nil {
+			return domain.Shipment{}, err
+		}
+		pkg.Weight = round3(box.GrossWeight())
+		pkg.VolumetricWeight = round3(box.VolumetricWeight())
+		open = append(open, pkg)
+	}
+	if len(open) == 0 {
+		return domain.Shipment{}, domain.Conflict("order has no open packages")
+	}
+
+	var shipment domain.Shipment
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if shipment, err = s.repo.CompletePacking(ctx, orderID, open); err != nil {
+			return err
+		}
+		return s.audit(ctx, "complete_packing", domain.EntityOrder, orderID.String(), nil, shipment)
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+// cartonize packs units into as few and as small cartons as it can. Everything goes into
+// the smallest single carton that takes it all; otherwise the largest carton is filled
+// biggest units first, resized down to the smallest carton holding what went in, and
+// the rest is packed the same way.
+func cartonize(skus map[uuid.UUID]domain.SKU, counts map[uuid.UUID]int, cartons []domain.Carton) ([]domain.CartonSuggestion, error) {
+	var active []domain.Carton
+	for _, carton := range cartons {
+		if carton.Active {
+			active = append(active, carton)
+		}
+	}
+	if len(active) == 0 {
+		return nil, domain.Conflict("hub has no active cartons")
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Dimensions().Volume() < active[j].Dimensions().Volume()
+	})
+
+	var units []domain.PackUnit
+	for skuID, qty := range counts {
+		unit, err := domain.NewPackUnit(skus[skuID])
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < qty; i++ {
+			units = append(units, unit)
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].Volume > units[j].Volume
+	})
+
+	var suggestions []domain.CartonSuggestion
+	for len(units) > 0 {
+		if box := smallestFit(active, units); box != nil {
+			suggestions = append(suggestions, suggest(box, skus))
+			break
+		}
+
+		largest := domain.NewCartonBox(active[len(active)-1])
+		var packed, rest []domain.PackUnit
+		for _, unit := range units {
+			if largest.Fits(unit, 1) {
+				largest.Add(unit, 1)
+				packed = append(packed, unit)
+			} else {
+				rest = append(rest, unit)
+			}
+		}
+		if len(packed) == 0 {
+			return nil, domain.Invalid("SKU %s does not fit in any carton", skus[units[0].SkuID].Code)
+		}
+
+		suggestions = append(suggestions, suggest(smallestFit(active, packed), skus))
+		units = rest
+	}
+	return suggestions, nil
+}
+
+// smallestFit returns the smallest carton holding all the units, filled, or nil
+func smallestFit(cartons []domain.Carton, units []domain.PackUnit) *domain.CartonBox {
+	for _, carton := range cartons {
+		box := domain.NewCartonBox(carton)
+		fits := true
+		for _, unit := range units {
+			if !box.Fits(unit, 1) {
+				fits = false
+				break
+			}
+			box.Add(unit, 1)
+		}
+		if fits {
+			return box
+		}
+	}
+	return nil
+}
+
+func suggest(box *domain.CartonBox, skus map[uuid.UUID]domain.SKU) domain.CartonSuggestion {
+	suggestion := domain.CartonSuggestion{
+		CartonID:         box.Carton.ID,
+		CartonCode:       box.Carton.Code,
+		Weight:           round3(box.GrossWeight()),
+		VolumetricWeight: round3(box.VolumetricWeight()),
+	}
+	for skuID, qty := range box.Items {
+		suggestion.Items = append(suggestion.Items, domain.CartonItemPlan{SkuID: skuID, Qty: qty})
+	}
+	sort.Slice(suggestion.Items, func(i, j int) bool {
+		return skus[suggestion.Items[i].SkuID].Code < skus[suggestion.Items[j].SkuID].Code
+	})
+	return suggestion
+}
+
+func round3(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"math"
+	"sort"
+	"strings"
+	"wms/domain"
+)
+
+// heavyItemWeight is the unit weight in kg from which a SKU is kept on the lowest shelves
+const heavyItemWeight = 20.0
+
+type PutawayService interface {
+	SuggestPutaway(ctx context.Context, skuID, hubID uuid.UUID, qty int) (domain.PutawayPlan, error)
+	CreatePutawayTasks(ctx context.Context, skuID, hubID, fromLocationID uuid.UUID, toLocationID *uuid.UUID, qty int) ([]domain.PutawayTask, error)
+	FetchPutawayTasks(ctx context.Context, hubID uuid.UUID, status string) ([]domain.PutawayTask, error)
+	ConfirmPutawayTask(ctx context.Context, id uuid.UUID, toLocationID *uuid.UUID) error
+}
+
+func (s *service) SuggestPutaway(ctx context.Context, skuID, hubID uuid.UUID, qty int) (domain.PutawayPlan, error) {
+	if err := s.authorizeHub(ctx, domain.PermTaskExecute, hubID); err != nil {
+		return domain.PutawayPlan{}, err
+	}
+	if skuID == uuid.Nil || hubID == uuid.Nil {
+		return domain.PutawayPlan{}, domain.Invalid("invalid SKU ID or Hub ID")
+	}
+	if qty <= 0 {
+		return domain.PutawayPlan{}, domain.Invalid("quantities must be positive")
+	}
+
+	sku, err := s.repo.GetSkuByID(ctx, skuID)
+	if err != nil {
+		return domain.PutawayPlan{}, err
+	}
+	locations, err := s.repo.GetLocations(ctx, hubID, "", "")
+	if err != nil {
+		return domain.PutawayPlan{}, err
+	}
+	stock, err := s.repo.GetHubBinStock(ctx, hubID)
+	if err != nil {
+		return domain.PutawayPlan{}, err
+	}
+	tasks, err := s.repo.GetPutawayTasks(ctx, hubID, domain.PutawayStatusOpen)
+	if err != nil {
+		return domain.PutawayPlan{}, err
+	}
+
+	return planPutaway(sku, qty, locations, stock, tasks)
+}
+
+// CreatePutawayTasks creates tasks to shelve qty units sitting on a dock. Without a
+// target bin the units are spread over the suggested bins.
+func (s *service) CreatePutawayTasks(ctx context.Context, skuID, hubID, fromLocationID uuid.UUID, toLocationID *uuid.UUID, qty int) ([]domain.PutawayTask, error) {
+	if err := s.authorizeHub(ctx, domain.PermInboundReceive, hubID); err != nil {
+		return nil, err
+	}
+	if fromLocationID == uuid.Nil {
+		return nil, domain.Invalid("invalid location ID")
+	}
+
+	from, err := s.repo.GetLocationByID(ctx, fromLocationID)
+	if err != nil {
+		return nil, err
+	}
+	if from.HubID != hubID {
+		return nil, domain.Invalid("location %s does not belong to this hub", from.Code)
+	}
+	if from.Type != domain.LocationTypeDock && from.Type != domain.LocationTypeStaging {
+		return nil, domain.Invalid("putaway starts from a dock or staging location, %s is a %s location", from.Code, from.Type)
+	}
+
+	var tasks []domain.PutawayTask
+	if toLocationID != nil {
+		if skuID == uuid.Nil || hubID == uuid.Nil {
+			return nil, domain.Invalid("invalid SKU ID or Hub ID")
+		}
+		if qty <= 0 {
+			return nil, domain.Invalid("quantities must be positive")
+		}
+		tasks = append(tasks, domain.PutawayTask{
+			HubID:          hubID,
+			SkuID:          skuID,
+			FromLocationID: fromLocationID,
+			ToLocationID:   *toLocationID,
+			Qty:            qty,
+			Rule:           domain.PutawayRuleManual,
+			Status:         domain.PutawayStatusOpen,
+		})
+	} else {
+		plan, err := s.SuggestPutaway(ctx, skuID, hubID, qty)
+		if err != nil {
+			return nil, err
+		}
+		if plan.UnplacedQty > 0 {
+			return nil, domain.InsufficientStock("no bin has room for %d of the %d units", plan.UnplacedQty, qty)
+		}
+		for _, suggestion := range plan.Suggestions {
+			tasks = append(tasks, domain.PutawayTask{
+				HubID:          hubID,
+				SkuID:          skuID,
+				FromLocationID: fromLocationID,
+				ToLocationID:   suggestion.LocationID,
+				Qty:            suggestion.Qty,
+				Rule:           suggestion.Rule,
+				Status:         domain.PutawayStatusOpen,
+			})
+		}
+	}
+
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if tasks, err = s.repo.CreatePutawayTasks(ctx, tasks); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create_putaway_tasks", domain.EntityLocation, fromLocationID.String(), nil, tasks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *service) FetchPutawayTasks(ctx context.Context, hubID uuid.UUID, status string) ([]domain.PutawayTask, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetPutawayTasks(ctx, hubID, status)
+}
+
+// ConfirmPutawayTask completes a task, into the bin the operator actually used when it
+// differs from the suggested one.
+func (s *service) ConfirmPutawayTask(ctx context.Context, id uuid.UUID, toLocationID *uuid.UUID) error {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityPutawayTask, id); err != nil {
+		return err
+	}
+	if id == uuid.Nil {
+		return domain.Invalid("invalid putaway task ID")
+	}
+
+	to := uuid.Nil
+	if toLocationID != nil {
+		to = *toLocationID
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ConfirmPutawayTask(ctx, id, to); err != nil {
+			return err
+		}
+		return s.audit(ctx, "confirm", domain.EntityPutawayTask, id.String(), nil, map[string]interface{}{"to_location_id": toLocationID})
+	})
+}
+
+// binState is a candidate bin with what it holds and what open tasks are bringing in
+type binState struct {
+	location domain.Location
+	zone     domain.Location
+	units    int
+	weight   float64
+	volume   float64
+	skuQty   int
+}
+
+// planPutaway ranks the bins of a hub for a SKU and fills them in order. Bins already
+// holding the SKU come first, then bins in a zone reserved for its category, then for
+// heavy SKUs the lowest shelves, then empty bins nearest the dock.
+func planPutaway(sku domain.SKU, qty int, locations []domain.Location, stock []domain.LocationStock, tasks []domain.PutawayTask) (domain.PutawayPlan, error) {
+	dimensions, err := sku.ParseDimensions()
+	if err != nil {
+		return domain.PutawayPlan{}, err
+	}
+
+	byID := make(map[uuid.UUID]domain.Location, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	bins := make(map[uuid.UUID]*binState)
+	for _, location := range locations {
+		if location.Level != domain.LocationLevelBin || !location.Active {
+			continue
+		}
+		if location.Type != domain.LocationTypePick && location.Type != domain.LocationTypeReserve {
+			continue
+		}
+
+		zone := zoneOf(location, byID)
+		if zone.Category != "" && !strings.EqualFold(zone.Category, sku.Category) {
+			continue
+		}
+		bins[location.ID] = &binState{location: location, zone: zone}
+	}
+
+	for _, s := range stock {
+		bin, ok := bins[s.LocationID]
+		if !ok {
+			continue
+		}
+		if err = bin.add(s.Sku, s.Qty, sku.ID); err != nil {
+			return domain.PutawayPlan{}, err
+		}
+	}
+	for _, task := range tasks {
+		bin, ok := bins[task.ToLocationID]
+		if !ok {
+			continue
+		}
+		if err = bin.add(task.Sku, task.Qty, sku.ID); err != nil {
+			return domain.PutawayPlan{}, err
+		}
+	}
+
+	heavy := sku.Weight >= heavyItemWeight
+	ranked := make([]*binState, 0, len(bins))
+	for _, bin := range bins {
+		ranked = append(ranked, bin)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if (a.skuQty > 0) != (b.skuQty > 0) {
+			return a.skuQty > 0
+		}
+		if a.categoryMatch(sku) != b.categoryMatch(sku) {
+			return a.categoryMatch(sku)
+		}
+		if heavy && a.location.ShelfLevel != b.location.ShelfLevel {
+			return a.location.ShelfLevel < b.location.ShelfLevel
+		}
+		if (a.units == 0) != (b.units == 0) {
+			return a.units == 0
+		}
+		if a.location.Sequence != b.location.Sequence {
+			return a.location.Sequence < b.location.Sequence
+		}
+		return a.location.Code < b.location.Code
+	})
+
+	plan := domain.PutawayPlan{Suggestions: []domain.PutawaySuggestion{}}
+	remaining := qty
+	for _, bin := range ranked {
+		if remaining == 0 {
+			break
+		}
+		fit := min(bin.room(sku.Weight, dimensions.Volume()), remaining)
+		if fit <= 0 {
+			continue
+		}
+
+		plan.Suggestions = append(plan.Suggestions, domain.PutawaySuggestion{
+			LocationID:   bin.location.ID,
+			LocationCode: bin.location.Code,
+			Qty:          fit,
+			Rule:         bin.rule(sku, heavy),
+		})
+		remaining -= fit
+	}
+	plan.UnplacedQty = remaining
+	return plan, nil
+}
+
+func (b *binState) add(sku *domain.SKU, qty int, skuID uuid.UUID) error {
+	b.units += qty
+	if sku == nil {
+		return nil
+	}
+	if sku.ID == skuID {
+		b.skuQty += qty
+	}
+
+	dimensions, err := sku.ParseDimensions()
+	if err != nil {
+		return err
+	}
+	b.weight += sku.Weight * float64(qty)
+	b.volume += dimensions.Volume() * float64(qty)
+	return nil
+}
+
+// room is how many more units of the given weight and volume fit in the bin
+func (b *binState) room(unitWeight, unitVolume float64) int {
+	room := math.MaxInt
+	if b.location.MaxUnits > 0 {
+		room = min(room, b.location.MaxUnits-b.units)
+	}
+	if b.location.MaxWeight > 0 && unitWeight > 0 {
+		room = min(room, int(math.Floor((b.location.MaxWeight-b.weight)/unitWeight)))
+	}
+	if b.location.MaxVolume > 0 && unitVolume > 0 {
+		room = min(room, int(math.Floor((b.location.MaxVolume-b.volume)/unitVolume)))
+	}
+	return room
+}
+
+func (b *binState) categoryMatch(sku domain.SKU) bool {
+	return b.zone.Category != "" && strings.EqualFold(b.zone.Category, sku.Category)
+}
+
+// rule names the reason the bin ranked where it did
+func (b *binState) rule(sku domain.SKU, heavy bool) string {
+	switch {
+	case b.skuQty > 0:
+		return domain.PutawayRuleConsolidation
+	case b.categoryMatch(sku):
+		return domain.PutawayRuleCategoryZone
+	case heavy:
+		return domain.PutawayRuleHeavyLow
+	case b.units == 0:
+		return domain.PutawayRuleNearestEmpty
+	}
+	return domain.PutawayRuleAnyFit
+}
+
+// zoneOf walks up the hierarchy to the zone a location sits in
+func zoneOf(location domain.Location, byID map[uuid.UUID]domain.Location) domain.Location {
+	for location.ParentID != nil {
+		parent, ok := byID[*location.ParentID]
+		if !ok {
+			break
+		}
+		location = parent
+	}
+	return location
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type QcService interface {
+	CreateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error)
+	FetchQcRuleByID(ctx context.Context, id uuid.UUID) (domain.QcRule, error)
+	FetchQcRules(ctx context.Context, sellerID *uuid.UUID) ([]domain.QcRule, error)
+	UpdateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error)
+	FetchQcInspectionByID(ctx context.Context, id uuid.UUID) (domain.QcInspection, error)
+	FetchQcInspections(ctx context.Context, hubID uuid.UUID, status string) ([]domain.QcInspection, error)
+	RecordQcResults(ctx context.Context, id uuid.UUID, results []domain.QcResult) (domain.QcInspection, error)
+}
+
+// CreateQcRule validates a QC rule for a seller, a SKU category or both
+func (s *service) CreateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error) {
+	rule.Category = strings.TrimSpace(rule.Category)
+	if rule.SellerID != nil && *rule.SellerID == uuid.Nil {
+		rule.SellerID = nil
+	}
+	if rule.SellerID == nil && rule.Category == "" {
+		return domain.QcRule{}, domain.Invalid("a QC rule needs a seller ID or a category")
+	}
+	if err := checkQcSampling(rule); err != nil {
+		return domain.QcRule{}, err
+	}
+	if rule.SellerID != nil {
+		if err := s.authorize(ctx, domain.PermCatalogManage, domain.EntitySeller, *rule.SellerID); err != nil {
+			return domain.QcRule{}, err
+		}
+	} else if err := s.authorizeHubs(ctx, domain.PermCatalogManage, nil); err != nil {
+		return domain.QcRule{}, err
+	}
+
+	rule.ID = uuid.Nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if rule, err = s.repo.CreateQcRule(ctx, rule); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityQcRule, rule.ID.String(), nil, rule)
+	})
+	if err != nil {
+		return domain.QcRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *service) FetchQcRuleByID(ctx context.Context, id uuid.UUID) (domain.QcRule, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return domain.QcRule{}, err
+	}
+	if id == uuid.Nil {
+		return domain.QcRule{}, domain.Invalid("invalid QC rule ID")
+	}
+	return s.repo.GetQcRuleByID(ctx, id)
+}
+
+func (s *service) FetchQcRules(ctx context.Context, sellerID *uuid.UUID) ([]domain.QcRule, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetQcRules(ctx, sellerID)
+}
+
+// UpdateQcRule changes the sampling, checklist and active flag of a rule. Its scope is fixed.
+func (s *service) UpdateQcRule(ctx context.Context, rule domain.QcRule) (domain.QcRule, error) {
+	if err := s.authorizeHubs(ctx, domain.PermCatalogManage, nil); err != nil {
+		return domain.QcRule{}, err
+	}
+	if rule.ID == uuid.Nil {
+		return domain.QcRule{}, domain.Invalid("invalid QC rule ID")
+	}
+	if err := checkQcSampling(rule); err != nil {
+		return domain.QcRule{}, err
+	}
+	before, err := s.repo.GetQcRuleByID(ctx, rule.ID)
+	if err != nil {
+		return domain.QcRule{}, err
+	}
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if rule, err = s.repo.UpdateQcRule(ctx, rule); err != nil {
+			return err
+		}
+		return s.audit(ctx, "update", domain.EntityQcRule, rule.ID.String(), before, rule)
+	})
+	if err != nil {
+		return domain.QcRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *service) FetchQcInspectionByID(ctx context.Context, id uuid.UUID) (domain.QcInspection, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityQcInspection, id); err != nil {
+		return domain.QcInspection{}, err
+	}
+	return s.repo.GetQcInspectionByID(ctx, id)
+}
+
+func (s *service) FetchQcInspections(ctx context.Context, hubID uuid.UUID, status string) ([]domain.QcInspection, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetQcInspections(ctx, hubID, status)
+}
+
+// RecordQcResults validates inspected units before recording them against an inspection
+func (s *service) RecordQcResults(ctx context.Context, id uuid.UUID, results []domain.QcResult) (domain.QcInspection, error) {
+	if err := s.authorize(ctx, domain.PermTaskExecute, domain.EntityQcInspection, id); err != nil {
+		return domain.QcInspection{}, err
+	}
+	if len(results) == 0 {
+		return domain.QcInspection{}, domain.Invalid("no results to record")
+	}
+
+	inspection, err := s.repo.GetQcInspectionByID(ctx, id)
+	if err != nil {
+		return domain.QcInspection{}, err
+	}
+	checks := make(map[string]bool, len(inspection.Checklist))
+	for _, check := range inspection.Checklist {
+		checks[check] = true
+	}
+
+	for i := range results {
+		results[i].ID = uuid.Nil
+		results[i].Serial = strings.TrimSpace(results[i].Serial)
+		if results[i].Qty == 0 {
+			results[i].Qty = 1
+		}
+		if results[i].Qty < 0 {
+			return domain.QcInspection{}, domain.Invalid("result %d: quantities must be positive", i+1)
+		}
+		if results[i].Passed && len(results[i].FailedChecks) > 0 {
+			return domain.QcInspection{}, domain.Invalid("result %d: a passed unit has no failed checks", i+1)
+		}
+		for _, check := range results[i].FailedChecks {
+			if !checks[check] {
+				return domain.QcInspection{}, domain.Invalid("result %d: %q is not on the checklist", i+1, check)
+			}
+		}
+	}
+	before, err := s.repo.GetQcInspectionByID(ctx, id)
+	if err != nil {
+		return domain.QcInspection{}, err
+	}
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if inspection, err = s.repo.RecordQcResults(ctx, id, results); err != nil {
+			return err
+		}
+		return s.audit(ctx, "record_results", domain.EntityQcInspection, id.String(), before, inspection)
+	})
+	if err != nil {
+		return domain.QcInspection{}, err
+	}
+	return inspection, nil
+}
+
+func checkQcSampling(rule domain.QcRule) error {
+	if rule.SampleSize < 0 || rule.MaxFailures < 0 {
+		return domain.Invalid("sample size and max failures cannot be negative")
+	}
+	if rule.SamplePct < 0 || rule.SamplePct > 100 {
+		return domain.Invalid("sample percentage must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type RbacService interface {
+	ResolveGrants(ctx context.Context, principal domain.Principal) (domain.Principal, error)
+	AssignRole(ctx context.Context, assignment domain.RoleAssignment) (domain.RoleAssignment, error)
+	FetchRoleAssignmentByID(ctx context.Context, id uuid.UUID) (domain.RoleAssignment, error)
+	FetchRoleAssignments(ctx context.Context, tenantID uuid.UUID, subject string) ([]domain.RoleAssignment, error)
+	RemoveRoleAssignment(ctx context.Context, id uuid.UUID) error
+}
+
+// ResolveGrants adds the roles assigned to a principal in its tenant to the ones its
+// credentials carry. Grants it already holds, as the principal of a stream ticket does,
+// are not added twice.
+func (s *service) ResolveGrants(ctx context.Context, principal domain.Principal) (domain.Principal, error) {
+	assignments, err := s.repo.GetRoleAssignments(ctx, principal.TenantID, principal.Subject)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	for _, assignment := range assignments {
+		grant := domain.Grant{Role: assignment.Role, HubID: assignment.HubID}
+		if !principal.Holds(grant) {
+			principal.Grants = append(principal.Grants, grant)
+		}
+	}
+	return principal, nil
+}
+
+// AssignRole gives a subject a role in a tenant, at one of its hubs when a hub ID is given
+func (s *service) AssignRole(ctx context.Context, assignment domain.RoleAssignment) (domain.RoleAssignment, error) {
+	if err := s.authorizeTenant(ctx, domain.PermAccessManage, assignment.TenantID); err != nil {
+		return domain.RoleAssignment{}, err
+	}
+	assignment.Subject = strings.TrimSpace(assignment.Subject)
+	if assignment.Subject == "" {
+		return domain.RoleAssignment{}, domain.Invalid("subject cannot be empty")
+	}
+	if err := s.checkGrant(ctx, assignment.TenantID, assignment.Role, assignment.HubID); err != nil {
+		return domain.RoleAssignment{}, err
+	}
+
+	assignment.ID = uuid.Nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if assignment, err = s.repo.CreateRoleAssignment(ctx, assignment); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityRoleAssignment, assignment.ID.String(), nil, assignment)
+	})
+	if err != nil {
+		return domain.RoleAssignment{}, err
+	}
+	return assignment, nil
+}
+
+func (s *service) FetchRoleAssignments(ctx context.Context, tenantID uuid.UUID, subject string) ([]domain.RoleAssignment, error) {
+	if err := s.authorizeTenant(ctx, domain.PermAccessManage, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRoleAssignments(ctx, tenantID, subject)
+}
+
+func (s *service) FetchRoleAssignmentByID(ctx context.Context, id uuid.UUID) (domain.RoleAssignment, error) {
+	assignment, err := s.repo.GetRoleAssignmentByID(ctx, id)
+	if err != nil {
+		return domain.RoleAssignment{}, err
+	}
+	if err = s.authorizeTenant(ctx, domain.PermAccessManage, assignment.TenantID); err != nil {
+		return domain.RoleAssignment{}, err
+	}
+	return assignment, nil
+}
+
+func (s *service) RemoveRoleAssignment(ctx context.Context, id uuid.UUID) error {
+	assignment, err := s.repo.GetRoleAssignmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = s.authorizeTenant(ctx, domain.PermAccessManage, assignment.TenantID); err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteRoleAssignment(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "delete", domain.EntityRoleAssignment, id.String(), assignment, nil)
+	})
+}
+
+// checkGrant validates a role to assign in a tenant, and the hub it is limited to
+func (s *service) checkGrant(ctx context.Context, tenantID uuid.UUID, role string, hubID *uuid.UUID) error {
+	if !domain.IsRole(role) {
+		return domain.Invalid("invalid role %q", role)
+	}
+	if hubID == nil {
+		return nil
+	}
+	hub, err := s.repo.GetHubByID(ctx, *hubID)
+	if err != nil {
+		return err
+	}
+	if hub.TenantID != tenantID {
+		return domain.Invalid("hub %s does not belong to the tenant", hub.Code)
+	}
+	return nil
+}
+
+// principal returns the principal of a request, failing when there is none
+func principal(ctx context.Context) (domain.Principal, error) {
+	p, ok := domain.PrincipalFrom(ctx)
+	if !ok {
+		return domain.Principal{}, fmt.Errorf("%w: request is not authenticated", domain.ErrForbidden)
+	}
+	return p, nil
+}
+
+func forbidden(p domain.Principal, permission, where string) error {
+	return fmt.Errorf("%w: %s may not %s %s", domain.ErrForbidden, p.Subject, permission, where)
+}
+
+// authorizeAnywhere checks that the principal of a request holds a permission at all. It
+// guards actions on data shared by every hub, such as the UOM catalogue.
+func authorizeAnywhere(ctx context.Context, permission string) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanAnywhere(permission) {
+		return forbidden(p, permission, "anywhere")
+	}
+	return nil
+}
+
+// authorizeTenant checks that the principal of a request acts for a tenant and holds a
+// permission across it
+func (s *service) authorizeTenant(ctx context.Context, permission string, tenantID uuid.UUID) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.TenantID != tenantID {
+		return forbidden(p, permission, "in tenant "+tenantID.String())
+	}
+	if !p.Can(permission, nil) {
+		return forbidden(p, permission, "across the tenant")
+	}
+	return nil
+}
+
+// authorize checks that the principal of a request may take an action on an entity: its
+// tenant has to own the entity, and the principal has to hold the permission at the hub
+// of the entity, or across the tenant for entities of no hub
+func (s *service) authorize(ctx context.Context, permission, entity string, id uuid.UUID) error {
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	tenantID, hubID, err := s.repo.GetOwner(ctx, entity, id)
+	if err != nil {
+		return err
+	}
+	if tenantID != p.TenantID {
+		return forbidden(p, permission, "in tenant "+tenantID.String())
+	}
+	if !p.Can(permission, hubID) {
+		if hubID != nil {
+			return forbidden(p, permission, "at hub "+hubID.String())
+		}
+		return forbidden(p, permission, "across the tenant")
+	}
+	return nil
+}
+
+// authorizeHub is authorize for an action at a hub
+func (s *service) authorizeHub(ctx context.Context, permission string, hubID uuid.UUID) error {
+	return s.authorize(ctx, permission, domain.EntityHub, hubID)
+}
+
+// authorizeHubs authorizes an action at a hub, or across the tenant when no hub is given
+func (s *service) authorizeHubs(ctx context.Context, permission string, hubID *uuid.UUID) error {
+	if hubID != nil {
+		return s.authorizeHub(ctx, permission, *hubID)
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	return s.authorizeTenant(ctx, permission, p.TenantID)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"wms/domain"
+)
+
+type ReturnService interface {
+	CreateReturn(ctx context.Context, ret domain.Return) (domain.Return, error)
+	FetchReturnByID(ctx context.Context, id uuid.UUID) (domain.Return, error)
+	FetchReturns(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Return, error)
+	ReceiveReturn(ctx context.Context, id, hubID uuid.UUID, receivedQty map[uuid.UUID]int) (domain.Return, error)
+	GradeReturn(ctx context.Context, id uuid.UUID, items []domain.ReturnItem) (domain.Return, error)
+	FetchReturnReport(ctx context.Context, sellerID, skuID *uuid.UUID, from, to *time.Time) ([]domain.ReturnReport, error)
+}
+
+func (s *service) CreateReturn(ctx context.Context, ret domain.Return) (domain.Return, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityOrder, ret.OrderID); err != nil {
+		return domain.Return{}, err
+	}
+	ret.RmaNo = strings.TrimSpace(ret.RmaNo)
+	if ret.OrderID == uuid.Nil {
+		return domain.Return{}, domain.Invalid("invalid order ID")
+	}
+	if ret.RmaNo == "" {
+		return domain.Return{}, domain.Invalid("RMA number cannot be empty")
+	}
+	if len(ret.Lines) == 0 {
+		return domain.Return{}, domain.Invalid("a return needs at least one line")
+	}
+	for i, line := range ret.Lines {
+		if line.OrderLineID == uuid.Nil {
+			return domain.Return{}, domain.Invalid("line %d: invalid order line ID", i+1)
+		}
+		if line.Qty <= 0 {
+			return domain.Return{}, domain.Invalid("line %d: quantities must be positive", i+1)
+		}
+		if !domain.IsReturnReason(line.Reason) {
+			return domain.Return{}, domain.Invalid("line %d: invalid return reason %q", i+1, line.Reason)
+		}
+		ret.Lines[i].ReceivedQty = 0
+		ret.Lines[i].GradedQty = 0
+	}
+
+	ret.ID = uuid.Nil
+	ret.ReceivedHubID = nil
+	ret.ReceivedAt = nil
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if ret, err = s.repo.CreateReturn(ctx, ret); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityReturn, ret.ID.String(), nil, ret)
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return ret, nil
+}
+
+func (s *service) FetchReturnByID(ctx context.Context, id uuid.UUID) (domain.Return, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityReturn, id); err != nil {
+		return domain.Return{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Return{}, domain.Invalid("invalid return ID")
+	}
+	return s.repo.GetReturnByID(ctx, id)
+}
+
+func (s *service) FetchReturns(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Return, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetReturns(ctx, hubID, status)
+}
+
+// ReceiveReturn records what arrived at a hub. Without quantities every authorised unit arrived.
+func (s *service) ReceiveReturn(ctx context.Context, id, hubID uuid.UUID, receivedQty map[uuid.UUID]int) (domain.Return, error) {
+	if err := s.authorizeHub(ctx, domain.PermInboundReceive, hubID); err != nil {
+		return domain.Return{}, err
+	}
+	if id == uuid.Nil || hubID == uuid.Nil {
+		return domain.Return{}, domain.Invalid("invalid return ID or hub ID")
+	}
+	for _, qty := range receivedQty {
+		if qty < 0 {
+			return domain.Return{}, domain.Invalid("quantities must be non-negative")
+		}
+	}
+
+	if len(receivedQty) == 0 {
+		ret, err := s.repo.GetReturnByID(ctx, id)
+		if err != nil {
+			return domain.Return{}, err
+		}
+		receivedQty = make(map[uuid.UUID]int, len(ret.Lines))
+		for _, line := range ret.Lines {
+			receivedQty[line.ID] = line.Qty
+		}
+	}
+	before, err := s.repo.GetReturnByID(ctx, id)
+	if err != nil {
+		return domain.Return{}, err
+	}
+	var ret domain.Return
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if ret, err = s.repo.ReceiveReturn(ctx, id, hubID, receivedQty); err != nil {
+			return err
+		}
+		return s.audit(ctx, "receive", domain.EntityReturn, id.String(), before, ret)
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return ret, nil
+}
+
+// GradeReturn grades received units. Serialized SKUs name the serial of every unit graded.
+func (s *service) GradeReturn(ctx context.Context, id uuid.UUID, items []domain.ReturnItem) (domain.Return, error) {
+	if err := s.authorize(ctx, domain.PermInboundReceive, domain.EntityReturn, id); err != nil {
+		return domain.Return{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Return{}, domain.Invalid("invalid return ID")
+	}
+	if len(items) == 0 {
+		return domain.Return{}, domain.Invalid("nothing to grade")
+	}
+
+	ret, err := s.repo.GetReturnByID(ctx, id)
+	if err != nil {
+		return domain.Return{}, err
+	}
+	skus := make(map[uuid.UUID]uuid.UUID, len(ret.Lines))
+	for _, line := range ret.Lines {
+		skus[line.ID] = line.SkuID
+	}
+
+	for i, item := range items {
+		skuID, ok := skus[item.ReturnLineID]
+		if !ok {
+			return domain.Return{}, domain.Invalid("item %d: return line not found", i+1)
+		}
+		if !domain.IsReturnGrade(item.Grade) {
+			return domain.Return{}, domain.Invalid("item %d: invalid grade %q", i+1, item.Grade)
+		}
+		if item.Qty <= 0 {
+			return domain.Return{}, domain.Invalid("item %d: quantities must be positive", i+1)
+		}
+		if err = s.checkSerials(ctx, skuID, item.Qty, item.Serials); err != nil {
+			return domain.Return{}, fmt.Errorf("item %d: %w", i+1, err)
+		}
+	}
+
+	before, err := s.repo.GetReturnByID(ctx, id)
+	if err != nil {
+		return domain.Return{}, err
+	}
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if ret, err = s.repo.GradeReturn(ctx, id, items); err != nil {
+			return err
+		}
+		return s.audit(ctx, "grade", domain.EntityReturn, id.String(), before, ret)
+	})
+	if err != nil {
+		return domain.Return{}, err
+	}
+	return ret, nil
+}
+
+func (s *service) FetchReturnReport(ctx context.Context, sellerID, skuID *uuid.UUID, from, to *time.Time) ([]domain.ReturnReport, error) {
+	if err := s.authorizeHubs(ctx, domain.PermInventoryRead, nil); err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, domain.Invalid("from must be before to")
+	}
+	return s.repo.GetReturnReport(ctx, sellerID, skuID, from, to)
+}
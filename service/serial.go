@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"wms/domain"
+)
+
+type SerialService interface {
+	FetchSerials(ctx context.Context, serialNumber string) ([]domain.Serial, error)
+}
+
+// FetchSerials looks a serial number up among the SKUs of the principal's tenant, keeping
+// the serials at hubs it may read
+func (s *service) FetchSerials(ctx context.Context, serialNumber string) ([]domain.Serial, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber = strings.TrimSpace(serialNumber)
+	if serialNumber == "" {
+		return nil, domain.Invalid("serial number cannot be empty")
+	}
+	serials, err := s.repo.GetSerialsByNumber(ctx, p.TenantID, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]domain.Serial, 0, len(serials))
+	for _, serial := range serials {
+		if p.Can(domain.PermInventoryRead, &serial.HubID) {
+			readable = append(readable, serial)
+		}
+	}
+	return readable, nil
+}
+
+// checkSerials enforces that a movement of a serialized SKU names exactly qty unique
+// serials, and that other SKUs name none.
+func (s *service) checkSerials(ctx context.Context, skuID uuid.UUID, qty int, serials []string) error {
+	sku, err := s.repo.GetSkuByID(ctx, skuID)
+	if err != nil {
+		return err
+	}
+
+	if !sku.Serialized {
+		if len(serials) > 0 {
+			return domain.Invalid("SKU %s is not serialized", sku.Code)
+		}
+		return nil
+	}
+
+	if len(serials) != qty {
+		return domain.Invalid("SKU %s is serialized: expected %d serials, got %d", sku.Code, qty, len(serials))
+	}
+	return uniqueSerials(serials)
+}
+
+func uniqueSerials(serials []string) error {
+	seen := make(map[string]bool, len(serials))
+	for _, serial := range serials {
+		if strings.TrimSpace(serial) == "" {
+			return domain.Invalid("serial numbers cannot be empty")
+		}
+		if seen[serial] {
+			return domain.Invalid("serial %s is listed more than once", serial)
+		}
+		seen[serial] = true
+	}
+	return nil
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"wms/domain"
@@ -15,69 +15,179 @@ type Service interface {
 	FetchHubByID(ctx context.Context, id uuid.UUID) (domain.Hub, error)
 	FetchSkuByID(ctx context.Context, id uuid.UUID) (domain.SKU, error)
 	FetchInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error)
-	CreateHub(ctx context.Context, hub domain.Hub) error
-	CreateSKU(ctx context.Context, sku domain.SKU) error
-	DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid.UUID, Qty int) error
+	CreateHub(ctx context.Context, hub domain.Hub) (domain.Hub, error)
+	CreateSKU(ctx context.Context, sku domain.SKU) (domain.SKU, error)
+	DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid.UUID, Qty int, serials []string) error
+	LotService
+	SerialService
+	LocationService
+	PutawayService
+	OrderService
+	PackingService
+	ShipmentService
+	ReturnService
+	DamageService
+	InboundService
+	QcService
+	KitService
+	UomService
+	BarcodeService
+	LabelService
+	AuthService
+	RbacService
+	AuditService
+	EventService
 }
 
 type service struct {
-	repo repo.Repository
+	repo   repo.Repository
+	events *eventNotifier
 }
 
 // NewService creates a new instance of the service.
 func NewService(r repo.Repository) Service {
 	return &service{
-		repo: r,
+		repo:   r,
+		events: newEventNotifier(r),
 	}
 }
 
-func (s *service) CreateHub(ctx context.Context, hub domain.Hub) error {
-	if hub.Name == "" {
-		return fmt.Errorf("hub name cannot be empty")
+func (s *service) CreateHub(ctx context.Context, hub domain.Hub) (domain.Hub, error) {
+	if err := s.authorizeTenant(ctx, domain.PermHubManage, hub.TenantID); err != nil {
+		return domain.Hub{}, err
 	}
-	return s.repo.CreateHub(ctx, hub)
+	hub.Name = strings.TrimSpace(hub.Name)
+	hub.Code = strings.TrimSpace(hub.Code)
+	hub.Address = strings.TrimSpace(hub.Address)
+	if err := hub.Validate(); err != nil {
+		return domain.Hub{}, err
+	}
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if hub, err = s.repo.CreateHub(ctx, hub); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityHub, hub.ID.String(), nil, hub)
+	})
+	if err != nil {
+		return domain.Hub{}, err
+	}
+	return hub, nil
 }
 
-func (s *service) CreateSKU(ctx context.Context, sku domain.SKU) error {
-	if sku.Name == "" {
-		return fmt.Errorf("SKU name cannot be empty")
+func (s *service) CreateSKU(ctx context.Context, sku domain.SKU) (domain.SKU, error) {
+	if err := s.authorize(ctx, domain.PermCatalogManage, domain.EntitySeller, sku.SellerID); err != nil {
+		return domain.SKU{}, err
+	}
+	sku.Name = strings.TrimSpace(sku.Name)
+	sku.Code = strings.TrimSpace(sku.Code)
+	sku.UOM = strings.ToUpper(strings.TrimSpace(sku.UOM))
+	if err := sku.Validate(); err != nil {
+		return domain.SKU{}, err
+	}
+	if _, err := s.repo.GetUOM(ctx, sku.UOM); err != nil {
+		return domain.SKU{}, err
 	}
-	return s.repo.CreateSKU(ctx, sku)
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if sku, err = s.repo.CreateSKU(ctx, sku); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntitySku, sku.ID.String(), nil, sku)
+	})
+	if err != nil {
+		return domain.SKU{}, err
+	}
+	return sku, nil
 }
 
+// FetchHubs lists the hubs of the principal's tenant it may read, all of them with a
+// tenant-wide grant
 func (s *service) FetchHubs(ctx context.Context) ([]domain.Hub, error) {
-	return s.repo.GetAllHubs(ctx)
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hubs, err := s.repo.GetAllHubs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]domain.Hub, 0, len(hubs))
+	for _, hub := range hubs {
+		if hub.TenantID == p.TenantID && p.Can(domain.PermInventoryRead, &hub.ID) {
+			readable = append(readable, hub)
+		}
+	}
+	return readable, nil
 }
 
+// FetchSkus lists the SKUs of the sellers of the principal's tenant
 func (s *service) FetchSkus(ctx context.Context) ([]domain.SKU, error) {
-	return s.repo.GetAllSkus(ctx)
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	p, err := principal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetAllSkus(ctx, p.TenantID)
 }
 
 func (s *service) FetchHubByID(ctx context.Context, id uuid.UUID) (domain.Hub, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, id); err != nil {
+		return domain.Hub{}, err
+	}
 	if id == uuid.Nil {
-		return domain.Hub{}, fmt.Errorf("invalid hub ID")
+		return domain.Hub{}, domain.Invalid("invalid hub ID")
 	}
 	return s.repo.GetHubByID(ctx, id)
 }
 
 func (s *service) FetchSkuByID(ctx context.Context, id uuid.UUID) (domain.SKU, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntitySku, id); err != nil {
+		return domain.SKU{}, err
+	}
 	if id == uuid.Nil {
-		return domain.SKU{}, fmt.Errorf("invalid SKU ID")
+		return domain.SKU{}, domain.Invalid("invalid SKU ID")
 	}
 	return s.repo.GetSkuByID(ctx, id)
 }
 
 // FetchInventory retrieves inventory details based on SKU ID and Hub ID
 func (s *service) FetchInventory(ctx context.Context, skuID, hubID uuid.UUID) (domain.Inventory, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return domain.Inventory{}, err
+	}
 	if skuID == uuid.Nil || hubID == uuid.Nil {
-		return domain.Inventory{}, fmt.Errorf("invalid SKU ID or Hub ID")
+		return domain.Inventory{}, domain.Invalid("invalid SKU ID or Hub ID")
 	}
 	return s.repo.GetInventory(ctx, skuID, hubID)
 }
 
-func (s *service) DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid.UUID, Qty int) error {
+func (s *service) DecreaseInventoryQty(ctx context.Context, skuID, hubID uuid.UUID, Qty int, serials []string) error {
+	if err := s.authorizeHub(ctx, domain.PermInventoryAdjust, hubID); err != nil {
+		return err
+	}
 	if Qty < 0 {
-		return fmt.Errorf("quantities must be non-negative")
+		return domain.Invalid("quantities must be non-negative")
+	}
+	if err := s.checkSerials(ctx, skuID, Qty, serials); err != nil {
+		return err
 	}
-	return s.repo.DecreaseAvailableQty(ctx, skuID, hubID, Qty)
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		// Read under the lock of the write, so the audit record holds what it changed
+		before, err := s.repo.LockInventory(ctx, skuID, hubID)
+		if err != nil {
+			return err
+		}
+		if err = s.repo.DecreaseAvailableFEFO(ctx, skuID, hubID, Qty, serials); err != nil {
+			return err
+		}
+		after, err := s.repo.GetInventory(ctx, skuID, hubID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, "decrease", domain.EntityInventory, before.ID.String(), before, after)
+	})
 }
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+	"wms/domain"
+	"wms/pkg/pdf"
+)
+
+type ShipmentService interface {
+	FetchShipmentByID(ctx context.Context, id uuid.UUID) (domain.Shipment, error)
+	FetchShipments(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Shipment, error)
+	AssignCarrier(ctx context.Context, id uuid.UUID, carrier, carrierService string) (domain.Shipment, error)
+	SetTrackingNumber(ctx context.Context, packageID uuid.UUID, trackingNumber string) error
+	CreateManifest(ctx context.Context, hubID uuid.UUID, carrier string, manifestDate *time.Time) (domain.Manifest, error)
+	FetchManifestByID(ctx context.Context, id uuid.UUID) (domain.Manifest, error)
+	FetchManifests(ctx context.Context, hubID uuid.UUID, manifestDate *time.Time) ([]domain.Manifest, error)
+	ManifestCSV(ctx context.Context, id uuid.UUID) ([]byte, error)
+	ManifestPDF(ctx context.Context, id uuid.UUID) ([]byte, error)
+	DispatchShipment(ctx context.Context, id uuid.UUID) (domain.Shipment, error)
+	DispatchManifest(ctx context.Context, id uuid.UUID) (domain.Manifest, error)
+}
+
+var manifestHeader = []string{"order_no", "shipment_id", "service", "package_id", "tracking_number", "weight_kg"}
+
+func (s *service) FetchShipmentByID(ctx context.Context, id uuid.UUID) (domain.Shipment, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityShipment, id); err != nil {
+		return domain.Shipment{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Shipment{}, domain.Invalid("invalid shipment ID")
+	}
+	return s.repo.GetShipmentByID(ctx, id)
+}
+
+func (s *service) FetchShipments(ctx context.Context, hubID uuid.UUID, status string) ([]domain.Shipment, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetShipments(ctx, hubID, status)
+}
+
+func (s *service) AssignCarrier(ctx context.Context, id uuid.UUID, carrier, carrierService string) (domain.Shipment, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityShipment, id); err != nil {
+		return domain.Shipment{}, err
+	}
+	carrier = strings.TrimSpace(carrier)
+	if id == uuid.Nil {
+		return domain.Shipment{}, domain.Invalid("invalid shipment ID")
+	}
+	if carrier == "" {
+		return domain.Shipment{}, domain.Invalid("carrier cannot be empty")
+	}
+	before, err := s.repo.GetShipmentByID(ctx, id)
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	var shipment domain.Shipment
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if shipment, err = s.repo.AssignCarrier(ctx, id, carrier, strings.TrimSpace(carrierService)); err != nil {
+			return err
+		}
+		return s.audit(ctx, "assign_carrier", domain.EntityShipment, id.String(), before, shipment)
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+func (s *service) SetTrackingNumber(ctx context.Context, packageID uuid.UUID, trackingNumber string) error {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityPackage, packageID); err != nil {
+		return err
+	}
+	trackingNumber = strings.TrimSpace(trackingNumber)
+	if packageID == uuid.Nil {
+		return domain.Invalid("invalid package ID")
+	}
+	if trackingNumber == "" {
+		return domain.Invalid("tracking number cannot be empty")
+	}
+	before, err := s.repo.GetPackageByID(ctx, packageID)
+	if err != nil {
+		return err
+	}
+	return s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.SetTrackingNumber(ctx, packageID, trackingNumber); err != nil {
+			return err
+		}
+		after, err := s.repo.GetPackageByID(ctx, packageID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, "set_tracking_number", domain.EntityPackage, packageID.String(), before, after)
+	})
+}
+
+// CreateManifest closes the day for a carrier at a hub, defaulting to today's manifest
+func (s *service) CreateManifest(ctx context.Context, hubID uuid.UUID, carrier string, manifestDate *time.Time) (domain.Manifest, error) {
+	if err := s.authorizeHub(ctx, domain.PermOrderManage, hubID); err != nil {
+		return domain.Manifest{}, err
+	}
+	carrier = strings.TrimSpace(carrier)
+	if hubID == uuid.Nil {
+		return domain.Manifest{}, domain.Invalid("invalid hub ID")
+	}
+	if carrier == "" {
+		return domain.Manifest{}, domain.Invalid("carrier cannot be empty")
+	}
+
+	date := time.Now()
+	if manifestDate != nil {
+		date = *manifestDate
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	var manifest domain.Manifest
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if manifest, err = s.repo.CreateManifest(ctx, hubID, carrier, date); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityManifest, manifest.ID.String(), nil, manifest)
+	})
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func (s *service) FetchManifestByID(ctx context.Context, id uuid.UUID) (domain.Manifest, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntityManifest, id); err != nil {
+		return domain.Manifest{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Manifest{}, domain.Invalid("invalid manifest ID")
+	}
+	return s.repo.GetManifestByID(ctx, id)
+}
+
+func (s *service) FetchManifests(ctx context.Context, hubID uuid.UUID, manifestDate *time.Time) ([]domain.Manifest, error) {
+	if err := s.authorizeHub(ctx, domain.PermInventoryRead, hubID); err != nil {
+		return nil, err
+	}
+	if hubID == uuid.Nil {
+		return nil, domain.Invalid("invalid hub ID")
+	}
+	return s.repo.GetManifests(ctx, hubID, manifestDate)
+}
+
+// ManifestCSV renders a manifest as CSV, one row per package
+func (s *service) ManifestCSV(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	manifest, err := s.FetchManifestByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err = writer.Write(manifestHeader); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	for _, row := range manifestRows(manifest) {
+		if err = writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ManifestPDF renders a manifest as a printable A4 hand-over sheet with room for signatures
+func (s *service) ManifestPDF(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	manifest, err := s.FetchManifestByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		margin     = 40.0
+		rowHeight  = 16.0
+		bottomLine = 120.0
+	)
+	columns := []float64{margin, 140, 210, 290, 360, 500}
+	rows := manifestRows(manifest)
+
+	doc := pdf.New()
+	var page *pdf.Page
+	y := 0.0
+	newPage := func() {
+		page = doc.AddPage(pdf.A4Width, pdf.A4Height)
+		y = pdf.A4Height - margin - 16
+		page.BoldText(margin, y, 16, fmt.Sprintf("Carrier manifest - %s", manifest.Carrier))
+		y -= 20
+		page.Text(margin, y, 10, fmt.Sprintf("Manifest %s    Date %s    Hub %s",
+			manifest.ID, manifest.ManifestDate.Format(time.DateOnly), manifest.HubID))
+		y -= 24
+		for i, title := range manifestHeader {
+			page.BoldText(columns[i], y, 9, title)
+		}
+		y -= 6
+		page.Line(margin, y, pdf.A4Width-margin, y, 0.5)
+		y -= rowHeight
+	}
+
+	totalWeight := 0.0
+	for _, shipment := range manifest.Shipments {
+		totalWeight += shipment.TotalWeight
+	}
+
+	newPage()
+	for _, row := range rows {
+		if y < bottomLine {
+			newPage()
+		}
+		// the short form of the shipment and package IDs is enough to match labels on the dock
+		row[1] = row[1][:8]
+		row[3] = row[3][:8]
+		for i, value := range row {
+			page.Text(columns[i], y, 9, value)
+		}
+		y -= rowHeight
+	}
+
+	if y < bottomLine {
+		newPage()
+	}
+	page.Line(margin, y+rowHeight-6, pdf.A4Width-margin, y+rowHeight-6, 0.5)
+	y -= 4
+	page.BoldText(margin, y, 10, fmt.Sprintf("Shipments: %d    Packages: %d    Total weight: %.3f kg",
+		len(manifest.Shipments), len(rows), totalWeight))
+	y -= 40
+	page.Line(margin, y, margin+200, y, 0.5)
+	page.Line(pdf.A4Width-margin-200, y, pdf.A4Width-margin, y, 0.5)
+	page.Text(margin, y-12, 9, "Handed over by")
+	page.Text(pdf.A4Width-margin-200, y-12, 9, "Received by (carrier)")
+
+	return doc.Bytes(), nil
+}
+
+// manifestRows lists the packages of a manifest in the manifestHeader column order
+func manifestRows(manifest domain.Manifest) [][]string {
+	var rows [][]string
+	for _, shipment := range manifest.Shipments {
+		orderNo := ""
+		if shipment.Order != nil {
+			orderNo = shipment.Order.OrderNo
+		}
+		for _, pkg := range shipment.Packages {
+			rows = append(rows, []string{
+				orderNo,
+				shipment.ID.String(),
+				shipment.Service,
+				pkg.ID.String(),
+				pkg.TrackingNumber,
+				strconv.FormatFloat(pkg.ChargeableWeight(), 'f', 3, 64),
+			})
+		}
+	}
+	return rows
+}
+
+func (s *service) DispatchShipment(ctx context.Context, id uuid.UUID) (domain.Shipment, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityShipment, id); err != nil {
+		return domain.Shipment{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Shipment{}, domain.Invalid("invalid shipment ID")
+	}
+	before, err := s.repo.GetShipmentByID(ctx, id)
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	var shipment domain.Shipment
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if shipment, err = s.repo.DispatchShipment(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "dispatch", domain.EntityShipment, id.String(), before, shipment)
+	})
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+func (s *service) DispatchManifest(ctx context.Context, id uuid.UUID) (domain.Manifest, error) {
+	if err := s.authorize(ctx, domain.PermOrderManage, domain.EntityManifest, id); err != nil {
+		return domain.Manifest{}, err
+	}
+	if id == uuid.Nil {
+		return domain.Manifest{}, domain.Invalid("invalid manifest ID")
+	}
+	before, err := s.repo.GetManifestByID(ctx, id)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	var manifest domain.Manifest
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if manifest, err = s.repo.DispatchManifest(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, "dispatch", domain.EntityManifest, id.String(), before, manifest)
+	})
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	return manifest, nil
+}
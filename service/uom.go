@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"wms/domain"
+)
+
+type UomService interface {
+	CreateUOM(ctx context.Context, uom domain.UOM) (domain.UOM, error)
+	FetchUOM(ctx context.Context, code string) (domain.UOM, error)
+	FetchUOMs(ctx context.Context) ([]domain.UOM, error)
+	SetSkuPacks(ctx context.Context, skuID uuid.UUID, packs []domain.SkuPack) ([]domain.SkuPack, error)
+	FetchSkuPacks(ctx context.Context, skuID uuid.UUID) ([]domain.SkuPack, error)
+	ToBaseQty(ctx context.Context, skuID uuid.UUID, uom string, qty int) (int, error)
+	FetchInventoryInUOM(ctx context.Context, skuID, hubID uuid.UUID, uom string) (domain.InventoryInUOM, error)
+	FetchLotsInUOM(ctx context.Context, skuID, hubID uuid.UUID, uom string) ([]domain.LotInUOM, error)
+}
+
+func (s *service) CreateUOM(ctx context.Context, uom domain.UOM) (domain.UOM, error) {
+	if err := s.authorizeHubs(ctx, domain.PermCatalogManage, nil); err != nil {
+		return domain.UOM{}, err
+	}
+	uom.Code = strings.ToUpper(strings.TrimSpace(uom.Code))
+	uom.Name = strings.TrimSpace(uom.Name)
+	if uom.Code == "" || uom.Name == "" {
+		return domain.UOM{}, domain.Invalid("UOM code and name cannot be empty")
+	}
+	err := s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if uom, err = s.repo.CreateUOM(ctx, uom); err != nil {
+			return err
+		}
+		return s.audit(ctx, "create", domain.EntityUOM, uom.Code, nil, uom)
+	})
+	if err != nil {
+		return domain.UOM{}, err
+	}
+	return uom, nil
+}
+
+func (s *service) FetchUOM(ctx context.Context, code string) (domain.UOM, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return domain.UOM{}, err
+	}
+	return s.repo.GetUOM(ctx, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (s *service) FetchUOMs(ctx context.Context) ([]domain.UOM, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return nil, err
+	}
+	return s.repo.GetUOMs(ctx)
+}
+
+// SetSkuPacks validates the pack hierarchy of a SKU above its base UOM. Packs must come from
+// the catalogue and each must hold a whole number of the next smaller one.
+func (s *service) SetSkuPacks(ctx context.Context, skuID uuid.UUID, packs []domain.SkuPack) ([]domain.SkuPack, error) {
+	if err := s.authorize(ctx, domain.PermCatalogManage, domain.EntitySku, skuID); err != nil {
+		return nil, err
+	}
+	sku, err := s.repo.GetSkuByID(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(packs))
+	for i := range packs {
+		packs[i].ID = uuid.Nil
+		packs[i].UOM = strings.ToUpper(strings.TrimSpace(packs[i].UOM))
+		if packs[i].UOM == sku.UOM {
+			return nil, domain.Invalid("pack %d: %s is the base UOM of the SKU", i+1, sku.UOM)
+		}
+		if seen[packs[i].UOM] {
+			return nil, domain.Invalid("pack %d: %s is listed twice", i+1, packs[i].UOM)
+		}
+		seen[packs[i].UOM] = true
+		if packs[i].Factor <= 1 {
+			return nil, domain.Invalid("pack %d: a pack must hold more than one %s", i+1, sku.UOM)
+		}
+		if _, err = s.repo.GetUOM(ctx, packs[i].UOM); err != nil {
+			return nil, fmt.Errorf("pack %d: %w", i+1, err)
+		}
+	}
+
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Factor < packs[j].Factor })
+	for i := 1; i < len(packs); i++ {
+		if packs[i].Factor%packs[i-1].Factor != 0 {
+			return nil, domain.Invalid("a %s of %d does not hold a whole number of %s of %d",
+				packs[i].UOM, packs[i].Factor, packs[i-1].UOM, packs[i-1].Factor)
+		}
+	}
+	before, err := s.repo.GetSkuPacks(ctx, skuID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if packs, err = s.repo.SetSkuPacks(ctx, skuID, packs); err != nil {
+			return err
+		}
+		return s.audit(ctx, "set_packs", domain.EntitySku, skuID.String(), before, packs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+func (s *service) FetchSkuPacks(ctx context.Context, skuID uuid.UUID) ([]domain.SkuPack, error) {
+	if err := s.authorize(ctx, domain.PermInventoryRead, domain.EntitySku, skuID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSkuPacks(ctx, skuID)
+}
+
+// ToBaseQty converts qty in any UOM configured for a SKU to its base UOM. An empty UOM is the base UOM.
+func (s *service) ToBaseQty(ctx context.Context, skuID uuid.UUID, uom string, qty int) (int, error) {
+	if err := authorizeAnywhere(ctx, domain.PermInventoryRead); err != nil {
+		return 0, err
+	}
+	factor, err := s.packFactor(ctx, skuID, uom)
+	if err != nil {
+		return 0, err
+	}
+	return qty * factor, nil
+}
+
+func (s *service) FetchInventoryInUOM(ctx context.Context, skuID, hubID uuid.UUID, uom string) (domain.InventoryInUOM, error) {
+	factor, err := s.packFactor(ctx, skuID, uom)
+	if err != nil {
+		return domain.InventoryInUOM{}, err
+	}
+	inventory, err := s.FetchInventory(ctx, skuID, hubID)
+	if err != nil {
+		return domain.InventoryInUOM{}, err
+	}
+	return domain.InventoryInUOM{
+		Inventory:     inventory,
+		UOM:           strings.ToUpper(strings.TrimSpace(uom)),
+		AvailableQty:  domain.InPacks(inventory.AvailableQty, factor),
+		AllocatedQty:  domain.InPacks(inventory.AllocatedQty, factor),
+		DamagedQty:    domain.InPacks(inventory.DamagedQty, factor),
+		QuarantineQty: domain.InPacks(inventory.QuarantineQty, factor),
+	}, nil
+}
+
+func (s *service) FetchLotsInUOM(ctx context.Context, skuID, hubID uuid.UUID, uom string) ([]domain.LotInUOM, error) {
+	factor, err := s.packFactor(ctx, skuID, uom)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := s.FetchLots(ctx, skuID, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make([]domain.LotInUOM, 0, len(lots))
+	for _, lot := range lots {
+		rendered = append(rendered, domain.LotInUOM{
+			Lot:           lot,
+			UOM:           strings.ToUpper(strings.TrimSpace(uom)),
+			AvailableQty:  domain.InPacks(lot.AvailableQty, factor),
+			AllocatedQty:  domain.InPacks(lot.AllocatedQty, factor),
+			QuarantineQty: domain.InPacks(lot.QuarantineQty, factor),
+		})
+	}
+	return rendered, nil
+}
+
+// packFactor is how many base units of a SKU one unit of uom holds
+func (s *service) packFactor(ctx context.Context, skuID uuid.UUID, uom string) (int, error) {
+	uom = strings.ToUpper(strings.TrimSpace(uom))
+	if uom == "" {
+		return 1, nil
+	}
+	sku, err := s.repo.GetSkuByID(ctx, skuID)
+	if err != nil {
+		return 0, err
+	}
+	if strings.EqualFold(uom, sku.UOM) {
+		return 1, nil
+	}
+
+	packs, err := s.repo.GetSkuPacks(ctx, skuID)
+	if err != nil {
+		return 0, err
+	}
+	for _, pack := range packs {
+		if pack.UOM == uom {
+			return pack.Factor, nil
+		}
+	}
+	return 0, domain.Invalid("UOM %s is not configured for SKU %s", uom, sku.Code)
+}